@@ -0,0 +1,169 @@
+//
+// Package cait is a collection of structures and functions
+// for interacting with ArchivesSpace's REST API
+//
+// @author R. S. Doiel, <rsdoiel@caltech.edu>
+//
+// Copyright (c) 2017, Caltech
+// All rights not granted herein are expressly reserved by Caltech.
+//
+// Redistribution and use in source and binary forms, with or without modification, are permitted provided that the following conditions are met:
+//
+// 1. Redistributions of source code must retain the above copyright notice, this list of conditions and the following disclaimer.
+//
+// 2. Redistributions in binary form must reproduce the above copyright notice, this list of conditions and the following disclaimer in the documentation and/or other materials provided with the distribution.
+//
+// 3. Neither the name of the copyright holder nor the names of its contributors may be used to endorse or promote products derived from this software without specific prior written permission.
+//
+// THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS" AND ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE ARE DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT HOLDER OR CONTRIBUTORS BE LIABLE FOR ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL DAMAGES (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR SERVICES; LOSS OF USE, DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER CAUSED AND ON ANY THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT LIABILITY, OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE OF THIS SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+//
+package cait
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+// AdvancedQueryTerm is a part of an advanced query, one of *BooleanQuery, *FieldQuery,
+// *DateFieldQuery or *BooleanFieldQuery. Build them with And, Or, AndNot, Field,
+// Literal, Empty, DateField and BoolField, e.g.
+//
+//	aq := And(Field("title", "papers"), Literal("primary_type", "resource"), DateField("create_time", "greater_than", "2020-01-01"))
+type AdvancedQueryTerm interface {
+	advancedQueryTerm()
+}
+
+func (q *BooleanQuery) advancedQueryTerm()      {}
+func (q *FieldQuery) advancedQueryTerm()        {}
+func (q *DateFieldQuery) advancedQueryTerm()    {}
+func (q *BooleanFieldQuery) advancedQueryTerm() {}
+
+// booleanQuery groups terms with op
+func booleanQuery(op string, terms []AdvancedQueryTerm) *BooleanQuery {
+	return &BooleanQuery{
+		JSONModelType: "boolean_query",
+		Op:            op,
+		Subqueries:    append([]AdvancedQueryTerm{}, terms...),
+	}
+}
+
+// And matches records matching all the terms
+func And(terms ...AdvancedQueryTerm) *BooleanQuery {
+	return booleanQuery("AND", terms)
+}
+
+// Or matches records matching any of the terms
+func Or(terms ...AdvancedQueryTerm) *BooleanQuery {
+	return booleanQuery("OR", terms)
+}
+
+// AndNot matches records matching include but none of exclude
+func AndNot(include AdvancedQueryTerm, exclude ...AdvancedQueryTerm) *BooleanQuery {
+	return booleanQuery("NOT", append([]AdvancedQueryTerm{include}, exclude...))
+}
+
+// Field matches records where field contains value, e.g. Field("title", "papers")
+func Field(field, value string) *FieldQuery {
+	return &FieldQuery{JSONModelType: "field_query", Field: field, Value: value, Comparator: "contains"}
+}
+
+// Literal matches records where field is exactly value, e.g. Literal("identifier", "MSS 12")
+func Literal(field, value string) *FieldQuery {
+	q := Field(field, value)
+	q.Literal = true
+	return q
+}
+
+// Empty matches records without a value for field
+func Empty(field string) *FieldQuery {
+	return &FieldQuery{JSONModelType: "field_query", Field: field, Comparator: "empty"}
+}
+
+// Negate makes the field query match the records it didn't
+func (q *FieldQuery) Negate() *FieldQuery {
+	q.Negated = q.Negated == false
+	return q
+}
+
+// DateField compares the date field (e.g. create_time) to value, a YYYY-MM-DD date,
+// with comparator "greater_than", "lesser_than" or "equal"
+func DateField(field, comparator, value string) *DateFieldQuery {
+	return &DateFieldQuery{JSONModelType: "date_field_query", Field: field, Comparator: comparator, Value: value}
+}
+
+// DateAfter matches records whose date field is after t
+func DateAfter(field string, t time.Time) *DateFieldQuery {
+	return DateField(field, "greater_than", t.Format("2006-01-02"))
+}
+
+// DateBefore matches records whose date field is before t
+func DateBefore(field string, t time.Time) *DateFieldQuery {
+	return DateField(field, "lesser_than", t.Format("2006-01-02"))
+}
+
+// Negate makes the date query match the records it didn't
+func (q *DateFieldQuery) Negate() *DateFieldQuery {
+	q.Negated = q.Negated == false
+	return q
+}
+
+// BoolField matches records whose boolean field (e.g. publish) is value
+func BoolField(field string, value bool) *BooleanFieldQuery {
+	return &BooleanFieldQuery{JSONModelType: "boolean_field_query", Field: field, Value: value}
+}
+
+// NewAdvancedQuery returns an AdvancedQuery for term
+func NewAdvancedQuery(term AdvancedQueryTerm) *AdvancedQuery {
+	return &AdvancedQuery{JSONModelType: "advanced_query", Query: term}
+}
+
+// validateTerm checks a term is complete before it is sent
+func validateTerm(term AdvancedQueryTerm) error {
+	switch q := term.(type) {
+	case *BooleanQuery:
+		if q.Op != "AND" && q.Op != "OR" && q.Op != "NOT" {
+			return fmt.Errorf("unknown boolean op %q", q.Op)
+		}
+		if len(q.Subqueries) == 0 {
+			return fmt.Errorf("%s query without subqueries", q.Op)
+		}
+		for _, sub := range q.Subqueries {
+			if err := validateTerm(sub); err != nil {
+				return err
+			}
+		}
+	case *FieldQuery:
+		if q.Field == "" {
+			return fmt.Errorf("field query without a field")
+		}
+	case *DateFieldQuery:
+		if q.Field == "" {
+			return fmt.Errorf("date query without a field")
+		}
+		if q.Comparator != "empty" {
+			if _, err := time.Parse("2006-01-02", q.Value); err != nil {
+				return fmt.Errorf("date query %s, %q is not YYYY-MM-DD", q.Field, q.Value)
+			}
+		}
+	case *BooleanFieldQuery:
+		if q.Field == "" {
+			return fmt.Errorf("boolean field query without a field")
+		}
+	default:
+		return fmt.Errorf("unsupported query term %T", term)
+	}
+	return nil
+}
+
+// String returns the advanced query as the JSON the search API takes
+func (aq *AdvancedQuery) String() string {
+	src, _ := json.Marshal(aq)
+	return string(src)
+}
+
+// Advanced narrows the search with an advanced query built from term
+func (req *SearchRequest) Advanced(term AdvancedQueryTerm) *SearchRequest {
+	req.AdvancedQuery = NewAdvancedQuery(term)
+	return req
+}
@@ -0,0 +1,200 @@
+//
+// Package cait is a collection of structures and functions
+// for interacting with ArchivesSpace's REST API
+//
+// @author R. S. Doiel, <rsdoiel@caltech.edu>
+//
+// Copyright (c) 2017, Caltech
+// All rights not granted herein are expressly reserved by Caltech.
+//
+// Redistribution and use in source and binary forms, with or without modification, are permitted provided that the following conditions are met:
+//
+// 1. Redistributions of source code must retain the above copyright notice, this list of conditions and the following disclaimer.
+//
+// 2. Redistributions in binary form must reproduce the above copyright notice, this list of conditions and the following disclaimer in the documentation and/or other materials provided with the distribution.
+//
+// 3. Neither the name of the copyright holder nor the names of its contributors may be used to endorse or promote products derived from this software without specific prior written permission.
+//
+// THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS" AND ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE ARE DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT HOLDER OR CONTRIBUTORS BE LIABLE FOR ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL DAMAGES (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR SERVICES; LOSS OF USE, DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER CAUSED AND ON ANY THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT LIABILITY, OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE OF THIS SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+//
+package cait
+
+import (
+	"fmt"
+	"time"
+)
+
+// The Agent struct is shaped around people. The typed agent functions here
+// round-trip corporate entities, families and software without losing the
+// fields specific to their names.
+
+// NewAgentCorporateEntity returns a corporate entity agent with an authorized
+// local name, e.g. NewAgentCorporateEntity("Caltech", "Library", "Archives")
+func NewAgentCorporateEntity(primaryName string, subordinateNames ...string) *AgentCorporateEntity {
+	name := &NameCorporateEntity{
+		PrimaryName:          primaryName,
+		Source:               "local",
+		Authorized:           true,
+		IsDisplayName:        true,
+		SortNameAutoGenerate: true,
+	}
+	if len(subordinateNames) > 0 {
+		name.SubordinateName1 = subordinateNames[0]
+	}
+	if len(subordinateNames) > 1 {
+		name.SubordinateName2 = subordinateNames[1]
+	}
+	return &AgentCorporateEntity{
+		Names: []*NameCorporateEntity{name},
+	}
+}
+
+// CreateAgentCorporateEntity creates a corporate entity agent. On success the agent's
+// URI, ID and lock version are updated from the response.
+func (api *ArchivesSpaceAPI) CreateAgentCorporateEntity(agent *AgentCorporateEntity) (*ResponseMsg, error) {
+	agent.JSONModelType = "agent_corporate_entity"
+	agent.LockVersion = "0"
+	responseMsg, err := Create(api, "/agents/corporate_entities", agent)
+	if err != nil {
+		return nil, fmt.Errorf("CreateAgentCorporateEntity() %w", err)
+	}
+	agent.LockVersion = responseMsg.LockVersion
+	return responseMsg, nil
+}
+
+// GetAgentCorporateEntity retrieves a corporate entity agent
+func (api *ArchivesSpaceAPI) GetAgentCorporateEntity(agentID int) (*AgentCorporateEntity, error) {
+	agent, err := Get[AgentCorporateEntity](api, fmt.Sprintf("/agents/corporate_entities/%d", agentID))
+	if err != nil {
+		return nil, fmt.Errorf("GetAgentCorporateEntity(%d) %w", agentID, err)
+	}
+	return agent, nil
+}
+
+// UpdateAgentCorporateEntity updates an existing corporate entity agent
+func (api *ArchivesSpaceAPI) UpdateAgentCorporateEntity(agent *AgentCorporateEntity) (*ResponseMsg, error) {
+	return Update(api, agent)
+}
+
+// DeleteAgentCorporateEntity deletes a corporate entity agent
+func (api *ArchivesSpaceAPI) DeleteAgentCorporateEntity(agent *AgentCorporateEntity) (*ResponseMsg, error) {
+	return Delete(api, agent)
+}
+
+// ListAgentCorporateEntities return a list of corporate entity agent IDs
+// Give modifiedSince to list only the records changed since then.
+func (api *ArchivesSpaceAPI) ListAgentCorporateEntities(modifiedSince ...time.Time) ([]int, error) {
+	return api.ListAgents("corporate_entities", modifiedSince...)
+}
+
+// NewAgentFamily returns a family agent with an authorized local name,
+// e.g. NewAgentFamily("Millikan", "1868-1953")
+func NewAgentFamily(familyName, dates string) *AgentFamily {
+	return &AgentFamily{
+		Names: []*NameFamily{
+			{
+				FamilyName:           familyName,
+				Dates:                dates,
+				Source:               "local",
+				Authorized:           true,
+				IsDisplayName:        true,
+				SortNameAutoGenerate: true,
+			},
+		},
+	}
+}
+
+// CreateAgentFamily creates a family agent. On success the agent's URI, ID and
+// lock version are updated from the response.
+func (api *ArchivesSpaceAPI) CreateAgentFamily(agent *AgentFamily) (*ResponseMsg, error) {
+	agent.JSONModelType = "agent_family"
+	agent.LockVersion = "0"
+	responseMsg, err := Create(api, "/agents/families", agent)
+	if err != nil {
+		return nil, fmt.Errorf("CreateAgentFamily() %w", err)
+	}
+	agent.LockVersion = responseMsg.LockVersion
+	return responseMsg, nil
+}
+
+// GetAgentFamily retrieves a family agent
+func (api *ArchivesSpaceAPI) GetAgentFamily(agentID int) (*AgentFamily, error) {
+	agent, err := Get[AgentFamily](api, fmt.Sprintf("/agents/families/%d", agentID))
+	if err != nil {
+		return nil, fmt.Errorf("GetAgentFamily(%d) %w", agentID, err)
+	}
+	return agent, nil
+}
+
+// UpdateAgentFamily updates an existing family agent
+func (api *ArchivesSpaceAPI) UpdateAgentFamily(agent *AgentFamily) (*ResponseMsg, error) {
+	return Update(api, agent)
+}
+
+// DeleteAgentFamily deletes a family agent
+func (api *ArchivesSpaceAPI) DeleteAgentFamily(agent *AgentFamily) (*ResponseMsg, error) {
+	return Delete(api, agent)
+}
+
+// ListAgentFamilies return a list of family agent IDs
+// Give modifiedSince to list only the records changed since then.
+func (api *ArchivesSpaceAPI) ListAgentFamilies(modifiedSince ...time.Time) ([]int, error) {
+	return api.ListAgents("families", modifiedSince...)
+}
+
+// NewAgentSoftware returns a software agent with an authorized local name, e.g.
+// NewAgentSoftware("ImageMagick", "7.1.1", "ImageMagick Studio LLC") for the
+// application which created a digital object
+func NewAgentSoftware(softwareName, version, manufacturer string) *AgentSoftware {
+	return &AgentSoftware{
+		Names: []*NameSoftware{
+			{
+				SoftwareName:         softwareName,
+				Version:              version,
+				Manufacturer:         manufacturer,
+				Source:               "local",
+				Authorized:           true,
+				IsDisplayName:        true,
+				SortNameAutoGenerate: true,
+			},
+		},
+	}
+}
+
+// CreateAgentSoftware creates a software agent. On success the agent's URI, ID
+// and lock version are updated from the response.
+func (api *ArchivesSpaceAPI) CreateAgentSoftware(agent *AgentSoftware) (*ResponseMsg, error) {
+	agent.JSONModelType = "agent_software"
+	agent.LockVersion = "0"
+	responseMsg, err := Create(api, "/agents/software", agent)
+	if err != nil {
+		return nil, fmt.Errorf("CreateAgentSoftware() %w", err)
+	}
+	agent.LockVersion = responseMsg.LockVersion
+	return responseMsg, nil
+}
+
+// GetAgentSoftware retrieves a software agent
+func (api *ArchivesSpaceAPI) GetAgentSoftware(agentID int) (*AgentSoftware, error) {
+	agent, err := Get[AgentSoftware](api, fmt.Sprintf("/agents/software/%d", agentID))
+	if err != nil {
+		return nil, fmt.Errorf("GetAgentSoftware(%d) %w", agentID, err)
+	}
+	return agent, nil
+}
+
+// UpdateAgentSoftware updates an existing software agent
+func (api *ArchivesSpaceAPI) UpdateAgentSoftware(agent *AgentSoftware) (*ResponseMsg, error) {
+	return Update(api, agent)
+}
+
+// DeleteAgentSoftware deletes a software agent
+func (api *ArchivesSpaceAPI) DeleteAgentSoftware(agent *AgentSoftware) (*ResponseMsg, error) {
+	return Delete(api, agent)
+}
+
+// ListAgentSoftware return a list of software agent IDs
+// Give modifiedSince to list only the records changed since then.
+func (api *ArchivesSpaceAPI) ListAgentSoftware(modifiedSince ...time.Time) ([]int, error) {
+	return api.ListAgents("software", modifiedSince...)
+}
@@ -0,0 +1,356 @@
+//
+// Package cait is a collection of structures and functions
+// for interacting with ArchivesSpace's REST API
+//
+// @author R. S. Doiel, <rsdoiel@caltech.edu>
+//
+// Copyright (c) 2017, Caltech
+// All rights not granted herein are expressly reserved by Caltech.
+//
+// Redistribution and use in source and binary forms, with or without modification, are permitted provided that the following conditions are met:
+//
+// 1. Redistributions of source code must retain the above copyright notice, this list of conditions and the following disclaimer.
+//
+// 2. Redistributions in binary form must reproduce the above copyright notice, this list of conditions and the following disclaimer in the documentation and/or other materials provided with the distribution.
+//
+// 3. Neither the name of the copyright holder nor the names of its contributors may be used to endorse or promote products derived from this software without specific prior written permission.
+//
+// THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS" AND ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE ARE DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT HOLDER OR CONTRIBUTORS BE LIABLE FOR ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL DAMAGES (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR SERVICES; LOSS OF USE, DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER CAUSED AND ON ANY THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT LIABILITY, OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE OF THIS SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+//
+package cait
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Ref returns a JSONModel reference to uri, e.g. for an ArchivalObject's Resource or Parent
+func Ref(uri string) map[string]interface{} {
+	return map[string]interface{}{"ref": uri}
+}
+
+// NewArchivalObject returns an ArchivalObject in the resource given. parentURI is the
+// archival object it is a child of, leave it empty for a top level component.
+func NewArchivalObject(resourceURI, parentURI, title, level string) *ArchivalObject {
+	obj := &ArchivalObject{
+		Title:         title,
+		Level:         level,
+		Resource:      Ref(resourceURI),
+		JSONModelType: "archival_object",
+	}
+	if parentURI != "" {
+		obj.Parent = Ref(parentURI)
+	}
+	return obj
+}
+
+// ConponentID returns the ArchivalObject's ComponentID.
+//
+// Deprecated: the ConponentID field was renamed ComponentID, use the field instead.
+func (obj *ArchivalObject) ConponentID() string {
+	return obj.ComponentID
+}
+
+// SetConponentID sets the ArchivalObject's ComponentID.
+//
+// Deprecated: the ConponentID field was renamed ComponentID, set the field instead.
+func (obj *ArchivalObject) SetConponentID(componentID string) {
+	obj.ComponentID = componentID
+}
+
+// CreateArchivalObject creates an ArchivalObject in a Repository. The object must
+// reference its resource (and its parent archival object if it has one). On success
+// obj's URI, ID and lock version are updated from the response.
+func (api *ArchivesSpaceAPI) CreateArchivalObject(repoID int, obj *ArchivalObject) (*ResponseMsg, error) {
+	if obj.Resource == nil {
+		return nil, fmt.Errorf("CreateArchivalObject(%d) archival object must reference a resource", repoID)
+	}
+	u := api.callURL(fmt.Sprintf("/repositories/%d/archival_objects", repoID))
+	obj.JSONModelType = "archival_object"
+	obj.LockVersion = "0"
+	responseMsg, err := api.CreateAPI(u.String(), obj)
+	if err != nil {
+		return nil, fmt.Errorf("CreateArchivalObject(%d) %w", repoID, err)
+	}
+	obj.SetURI(responseMsg.URI)
+	obj.LockVersion = responseMsg.LockVersion
+	return responseMsg, nil
+}
+
+// GetArchivalObject retrieves an ArchivalObject from a Repository
+func (api *ArchivesSpaceAPI) GetArchivalObject(repoID, objID int) (*ArchivalObject, error) {
+	u := api.callURL(fmt.Sprintf("/repositories/%d/archival_objects/%d", repoID, objID))
+	obj := new(ArchivalObject)
+	if err := api.GetAPI(u.String(), obj); err != nil {
+		return nil, fmt.Errorf("GetArchivalObject(%d, %d) %w", repoID, objID, err)
+	}
+	obj.ID = URIToID(obj.URI)
+	return obj, nil
+}
+
+// GetArchivalObjectByRefID retrieves an ArchivalObject from a Repository by its ref_id
+func (api *ArchivesSpaceAPI) GetArchivalObjectByRefID(repoID int, refID string) (*ArchivalObject, error) {
+	u := api.callURL(fmt.Sprintf("/repositories/%d/find_by_id/archival_objects", repoID))
+	q := u.Query()
+	q.Set("ref_id[]", refID)
+	u.RawQuery = q.Encode()
+	found := struct {
+		ArchivalObjects []map[string]string `json:"archival_objects"`
+	}{}
+	if err := api.GetAPI(u.String(), &found); err != nil {
+		return nil, fmt.Errorf("GetArchivalObjectByRefID(%d, %q) %w", repoID, refID, err)
+	}
+	if len(found.ArchivalObjects) == 0 || found.ArchivalObjects[0]["ref"] == "" {
+		return nil, fmt.Errorf("GetArchivalObjectByRefID(%d, %q) not found", repoID, refID)
+	}
+	return api.GetArchivalObject(repoID, URIToID(found.ArchivalObjects[0]["ref"]))
+}
+
+// UpdateArchivalObject updates an existing ArchivalObject
+func (api *ArchivesSpaceAPI) UpdateArchivalObject(obj *ArchivalObject) (*ResponseMsg, error) {
+	u := api.callURL(obj.URI)
+	return api.UpdateAPI(u.String(), obj)
+}
+
+// DeleteArchivalObject deletes an ArchivalObject, along with its children
+func (api *ArchivesSpaceAPI) DeleteArchivalObject(obj *ArchivalObject) (*ResponseMsg, error) {
+	u := api.callURL(obj.URI)
+	return api.DeleteAPI(u.String(), obj)
+}
+
+// ListArchivalObjects returns the ArchivalObject IDs of a Repository.
+// Give modifiedSince to list only the records changed since then.
+func (api *ArchivesSpaceAPI) ListArchivalObjects(repoID int, modifiedSince ...time.Time) ([]int, error) {
+	u := api.callURL(fmt.Sprintf("/repositories/%d/archival_objects", repoID))
+	q := u.Query()
+	q.Set("all_ids", "true")
+	setModifiedSince(q, modifiedSince)
+	u.RawQuery = q.Encode()
+	return api.ListAPI(u.String())
+}
+
+// ListArchivalObjectsPage returns a page of ArchivalObject records from a Repository
+func (api *ArchivesSpaceAPI) ListArchivalObjectsPage(repoID, page, pageSize int, modifiedSince ...time.Time) (*Page[ArchivalObject], error) {
+	return ListPage[ArchivalObject](api, fmt.Sprintf("/repositories/%d/archival_objects", repoID), page, pageSize, modifiedSince...)
+}
+
+// SetArchivalObjectParent moves obj under the archival object at parentURI at position
+// (counting from 0) among its new siblings, taking its own children with it. An empty
+// parentURI, or the URI of obj's resource, moves it to the top level of its resource.
+// On success obj's Parent and Position are updated, its lock version has changed so
+// fetch it again before saving other changes.
+func (api *ArchivesSpaceAPI) SetArchivalObjectParent(obj *ArchivalObject, parentURI string, position int) (*ResponseMsg, error) {
+	if obj.URI == "" {
+		return nil, fmt.Errorf("SetArchivalObjectParent() archival object has no URI")
+	}
+	responseMsg, err := api.setArchivalObjectParent(obj.URI, parentURI, position)
+	if err != nil {
+		return nil, fmt.Errorf("SetArchivalObjectParent(%q, %q, %d) %w", obj.URI, parentURI, position, err)
+	}
+	if isTopLevel(parentURI) {
+		obj.Parent = nil
+	} else {
+		obj.Parent = Ref(parentURI)
+	}
+	obj.Position = position
+	return responseMsg, nil
+}
+
+// AcceptChildren moves the archival objects at childURIs, in order, under the record at
+// parentURI (an archival object or a resource) starting at position. Children may come
+// from other parts of the tree or from another resource in the same repository, use it
+// to move a series or to merge the contents of two containers.
+func (api *ArchivesSpaceAPI) AcceptChildren(parentURI string, childURIs []string, position int) (*ResponseMsg, error) {
+	if len(childURIs) == 0 {
+		return nil, fmt.Errorf("AcceptChildren(%q) no children to move", parentURI)
+	}
+	u := api.callURL(parentURI + "/accept_children")
+	q := u.Query()
+	for _, uri := range childURIs {
+		q.Add("children[]", uri)
+	}
+	q.Set("position", fmt.Sprintf("%d", position))
+	u.RawQuery = q.Encode()
+	responseMsg, err := api.UpdateAPI(u.String(), nil)
+	if err != nil {
+		return nil, fmt.Errorf("AcceptChildren(%q, %d) %w", parentURI, position, err)
+	}
+	return responseMsg, nil
+}
+
+// isTopLevel returns true if parentURI means the top level of a resource
+func isTopLevel(parentURI string) bool {
+	return parentURI == "" || strings.Contains(parentURI, "/resources/")
+}
+
+// setArchivalObjectParent posts the archival object at uri's new parent and position
+func (api *ArchivesSpaceAPI) setArchivalObjectParent(uri, parentURI string, position int) (*ResponseMsg, error) {
+	u := api.callURL(uri + "/parent")
+	q := u.Query()
+	if isTopLevel(parentURI) == false {
+		q.Set("parent", fmt.Sprintf("%d", URIToID(parentURI)))
+	}
+	q.Set("position", fmt.Sprintf("%d", position))
+	u.RawQuery = q.Encode()
+	return api.UpdateAPI(u.String(), nil)
+}
+
+// MoveArchivalObject moves obj to position (counting from 0) among its siblings, keeping its parent
+func (api *ArchivesSpaceAPI) MoveArchivalObject(obj *ArchivalObject, position int) (*ResponseMsg, error) {
+	parentURI := ""
+	if obj.Parent != nil {
+		parentURI, _ = obj.Parent["ref"].(string)
+	}
+	responseMsg, err := api.SetArchivalObjectParent(obj, parentURI, position)
+	if err != nil {
+		return nil, fmt.Errorf("MoveArchivalObject(%q, %d) %w", obj.URI, position, err)
+	}
+	return responseMsg, nil
+}
+
+// ChildURIs returns the URIs of the children of the archival object or resource at parentURI in order
+func (api *ArchivesSpaceAPI) ChildURIs(parentURI string) ([]string, error) {
+	uris := []string{}
+	if strings.Contains(parentURI, "/archival_objects/") {
+		children := []*ArchivalObject{}
+		u := api.callURL(parentURI + "/children")
+		if err := api.GetAPI(u.String(), &children); err != nil {
+			return nil, fmt.Errorf("ChildURIs(%q) %w", parentURI, err)
+		}
+		for _, child := range children {
+			uris = append(uris, child.URI)
+		}
+		return uris, nil
+	}
+	root := new(TreeNode)
+	u := api.callURL(parentURI + "/tree/root")
+	if err := api.GetAPI(u.String(), root); err != nil {
+		return nil, fmt.Errorf("ChildURIs(%q) %w", parentURI, err)
+	}
+	for offset := 0; offset < root.Waypoints; offset++ {
+		nodes, ok := root.PrecomputedWaypoints[""][fmt.Sprintf("%d", offset)]
+		if ok == false {
+			u := api.callURL(parentURI + "/tree/waypoint")
+			q := u.Query()
+			q.Set("offset", fmt.Sprintf("%d", offset))
+			q.Set("parent_node", "")
+			u.RawQuery = q.Encode()
+			if err := api.GetAPI(u.String(), &nodes); err != nil {
+				return nil, fmt.Errorf("ChildURIs(%q) %w", parentURI, err)
+			}
+		}
+		for _, node := range nodes {
+			uris = append(uris, node.URI)
+		}
+	}
+	return uris, nil
+}
+
+// ReorderChildren puts the children of the archival object or resource at parentURI
+// in the order given by orderedURIs, which must list each child once. Only the children
+// which are out of place are moved, keeping the longest run already in order where it
+// is. It returns the URIs of the children moved.
+func (api *ArchivesSpaceAPI) ReorderChildren(parentURI string, orderedURIs []string) ([]string, error) {
+	current, err := api.ChildURIs(parentURI)
+	if err != nil {
+		return nil, fmt.Errorf("ReorderChildren(%q) %w", parentURI, err)
+	}
+	moves, err := reorderMoves(current, orderedURIs)
+	if err != nil {
+		return nil, fmt.Errorf("ReorderChildren(%q) %w", parentURI, err)
+	}
+	moved := []string{}
+	for _, move := range moves {
+		if _, err := api.setArchivalObjectParent(move.uri, parentURI, move.position); err != nil {
+			return moved, fmt.Errorf("ReorderChildren(%q) %w", parentURI, err)
+		}
+		moved = append(moved, move.uri)
+	}
+	return moved, nil
+}
+
+// childMove is a position update computed by reorderMoves
+type childMove struct {
+	uri      string
+	position int
+}
+
+// reorderMoves returns the fewest moves which turn the order current into ordered.
+// The children forming the longest increasing run of current positions stay put, the
+// others are moved (in ordered's order) to just after the child which precedes them.
+func reorderMoves(current, ordered []string) ([]childMove, error) {
+	index := map[string]int{}
+	for i, uri := range current {
+		index[uri] = i
+	}
+	seen := map[string]bool{}
+	for _, uri := range ordered {
+		if _, ok := index[uri]; ok == false {
+			return nil, fmt.Errorf("%s is not a child", uri)
+		}
+		if seen[uri] {
+			return nil, fmt.Errorf("%s is listed more than once", uri)
+		}
+		seen[uri] = true
+	}
+	if len(ordered) != len(current) {
+		return nil, fmt.Errorf("expected all %d children, got %d", len(current), len(ordered))
+	}
+
+	// Longest increasing subsequence of current positions, in O(n log n)
+	tails, prev := []int{}, make([]int, len(ordered))
+	for i, uri := range ordered {
+		lo, hi := 0, len(tails)
+		for lo < hi {
+			mid := (lo + hi) / 2
+			if index[ordered[tails[mid]]] < index[uri] {
+				lo = mid + 1
+			} else {
+				hi = mid
+			}
+		}
+		prev[i] = -1
+		if lo > 0 {
+			prev[i] = tails[lo-1]
+		}
+		if lo == len(tails) {
+			tails = append(tails, i)
+		} else {
+			tails[lo] = i
+		}
+	}
+	keep := map[string]bool{}
+	if len(tails) > 0 {
+		for i := tails[len(tails)-1]; i >= 0; i = prev[i] {
+			keep[ordered[i]] = true
+		}
+	}
+
+	moves := []childMove{}
+	list := append([]string{}, current...)
+	for i, uri := range ordered {
+		if keep[uri] {
+			continue
+		}
+		// Take uri out then put it after its predecessor in ordered
+		for j, item := range list {
+			if item == uri {
+				list = append(list[:j], list[j+1:]...)
+				break
+			}
+		}
+		position := 0
+		if i > 0 {
+			for j, item := range list {
+				if item == ordered[i-1] {
+					position = j + 1
+					break
+				}
+			}
+		}
+		list = append(list[:position], append([]string{uri}, list[position:]...)...)
+		moves = append(moves, childMove{uri: uri, position: position})
+	}
+	return moves, nil
+}
@@ -0,0 +1,99 @@
+//
+// Package cait is a collection of structures and functions
+// for interacting with ArchivesSpace's REST API
+//
+// @author R. S. Doiel, <rsdoiel@caltech.edu>
+//
+// Copyright (c) 2017, Caltech
+// All rights not granted herein are expressly reserved by Caltech.
+//
+// Redistribution and use in source and binary forms, with or without modification, are permitted provided that the following conditions are met:
+//
+// 1. Redistributions of source code must retain the above copyright notice, this list of conditions and the following disclaimer.
+//
+// 2. Redistributions in binary form must reproduce the above copyright notice, this list of conditions and the following disclaimer in the documentation and/or other materials provided with the distribution.
+//
+// 3. Neither the name of the copyright holder nor the names of its contributors may be used to endorse or promote products derived from this software without specific prior written permission.
+//
+// THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS" AND ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE ARE DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT HOLDER OR CONTRIBUTORS BE LIABLE FOR ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL DAMAGES (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR SERVICES; LOSS OF USE, DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER CAUSED AND ON ANY THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT LIABILITY, OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE OF THIS SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+//
+package cait
+
+import (
+	"fmt"
+	"strings"
+)
+
+// ARKTarget is the record an ARK resolves to
+type ARKTarget struct {
+	// Type is the record's model, "Resource" or "ArchivalObject"
+	Type   string `json:"type"`
+	ID     int    `json:"id"`
+	RepoID int    `json:"repo_id"`
+	URI    string `json:"uri,omitempty"`
+}
+
+// NormalizeARK returns ark in the form ark:/NAAN/Name, dropping any resolver URL
+// in front of it (e.g. "https://n2t.net/ark:/13030/abc" becomes "ark:/13030/abc")
+func NormalizeARK(ark string) (string, error) {
+	i := strings.Index(ark, "ark:")
+	if i < 0 {
+		return "", fmt.Errorf("%q is not an ARK", ark)
+	}
+	ark = strings.TrimSpace(ark[i+len("ark:"):])
+	ark = strings.TrimPrefix(ark, "/")
+	if parts := strings.SplitN(ark, "/", 2); len(parts) != 2 || parts[0] == "" || parts[1] == "" {
+		return "", fmt.Errorf("%q is not an ARK", ark)
+	}
+	return "ark:/" + ark, nil
+}
+
+// ResolveARK returns the record ArchivesSpace minted (or was given) ark for
+func (api *ArchivesSpaceAPI) ResolveARK(ark string) (*ARKTarget, error) {
+	normalized, err := NormalizeARK(ark)
+	if err != nil {
+		return nil, fmt.Errorf("ResolveARK(%q) %w", ark, err)
+	}
+	u := api.callURL("/" + normalized)
+	target := new(ARKTarget)
+	if err := api.GetAPI(u.String(), target); err != nil {
+		return nil, fmt.Errorf("ResolveARK(%q) %w", ark, err)
+	}
+	if target.URI == "" {
+		switch target.Type {
+		case "Resource":
+			target.URI = fmt.Sprintf("/repositories/%d/resources/%d", target.RepoID, target.ID)
+		case "ArchivalObject":
+			target.URI = fmt.Sprintf("/repositories/%d/archival_objects/%d", target.RepoID, target.ID)
+		}
+	}
+	return target, nil
+}
+
+// updateArkName posts arkName to the record at uri
+func (api *ArchivesSpaceAPI) updateArkName(uri string, arkName *ArkName) (*ResponseMsg, error) {
+	arkName.JSONModelType = "ark_name"
+	u := api.callURL(uri + "/ark_name")
+	return api.UpdateAPI(u.String(), arkName)
+}
+
+// UpdateResourceArkName sets a resource's ARKs, e.g. the current ARK minted outside
+// ArchivesSpace along with the previous ones it should still resolve
+func (api *ArchivesSpaceAPI) UpdateResourceArkName(obj *Resource, arkName *ArkName) (*ResponseMsg, error) {
+	responseMsg, err := api.updateArkName(obj.URI, arkName)
+	if err != nil {
+		return nil, fmt.Errorf("UpdateResourceArkName(%q) %w", obj.URI, err)
+	}
+	obj.ArkName = arkName
+	return responseMsg, nil
+}
+
+// UpdateArchivalObjectArkName sets an archival object's ARKs, see UpdateResourceArkName
+func (api *ArchivesSpaceAPI) UpdateArchivalObjectArkName(obj *ArchivalObject, arkName *ArkName) (*ResponseMsg, error) {
+	responseMsg, err := api.updateArkName(obj.URI, arkName)
+	if err != nil {
+		return nil, fmt.Errorf("UpdateArchivalObjectArkName(%q) %w", obj.URI, err)
+	}
+	obj.ArkName = arkName
+	return responseMsg, nil
+}
@@ -0,0 +1,132 @@
+//
+// Package cait is a collection of structures and functions
+// for interacting with ArchivesSpace's REST API
+//
+// @author R. S. Doiel, <rsdoiel@caltech.edu>
+//
+// Copyright (c) 2017, Caltech
+// All rights not granted herein are expressly reserved by Caltech.
+//
+// Redistribution and use in source and binary forms, with or without modification, are permitted provided that the following conditions are met:
+//
+// 1. Redistributions of source code must retain the above copyright notice, this list of conditions and the following disclaimer.
+//
+// 2. Redistributions in binary form must reproduce the above copyright notice, this list of conditions and the following disclaimer in the documentation and/or other materials provided with the distribution.
+//
+// 3. Neither the name of the copyright holder nor the names of its contributors may be used to endorse or promote products derived from this software without specific prior written permission.
+//
+// THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS" AND ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE ARE DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT HOLDER OR CONTRIBUTORS BE LIABLE FOR ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL DAMAGES (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR SERVICES; LOSS OF USE, DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER CAUSED AND ON ANY THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT LIABILITY, OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE OF THIS SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+//
+package cait
+
+import (
+	"fmt"
+	"time"
+)
+
+// NewAssessment returns an assessment of the records at recordURIs surveyed by the agents at surveyorURIs
+func NewAssessment(recordURIs []string, surveyorURIs ...string) *Assessment {
+	assessment := new(Assessment)
+	for _, uri := range recordURIs {
+		assessment.Records = append(assessment.Records, Ref(uri))
+	}
+	for _, uri := range surveyorURIs {
+		assessment.SurveyedBy = append(assessment.SurveyedBy, Ref(uri))
+	}
+	return assessment
+}
+
+// CreateAssessment creates an assessment in a Repository. On success the assessment's
+// URI, ID and lock version are updated from the response.
+func (api *ArchivesSpaceAPI) CreateAssessment(repoID int, assessment *Assessment) (*ResponseMsg, error) {
+	u := api.callURL(fmt.Sprintf("/repositories/%d/assessments", repoID))
+	assessment.JSONModelType = "assessment"
+	assessment.LockVersion = "0"
+	responseMsg, err := api.CreateAPI(u.String(), assessment)
+	if err != nil {
+		return nil, fmt.Errorf("CreateAssessment(%d) %w", repoID, err)
+	}
+	assessment.SetURI(responseMsg.URI)
+	assessment.LockVersion = responseMsg.LockVersion
+	return responseMsg, nil
+}
+
+// GetAssessment retrieves an assessment from a Repository
+func (api *ArchivesSpaceAPI) GetAssessment(repoID, assessmentID int) (*Assessment, error) {
+	u := api.callURL(fmt.Sprintf("/repositories/%d/assessments/%d", repoID, assessmentID))
+	assessment := new(Assessment)
+	if err := api.GetAPI(u.String(), assessment); err != nil {
+		return nil, fmt.Errorf("GetAssessment(%d, %d) %w", repoID, assessmentID, err)
+	}
+	assessment.ID = URIToID(assessment.URI)
+	return assessment, nil
+}
+
+// UpdateAssessment updates an existing assessment
+func (api *ArchivesSpaceAPI) UpdateAssessment(assessment *Assessment) (*ResponseMsg, error) {
+	u := api.callURL(assessment.URI)
+	return api.UpdateAPI(u.String(), assessment)
+}
+
+// DeleteAssessment deletes an assessment
+func (api *ArchivesSpaceAPI) DeleteAssessment(assessment *Assessment) (*ResponseMsg, error) {
+	u := api.callURL(assessment.URI)
+	return api.DeleteAPI(u.String(), assessment)
+}
+
+// ListAssessments return a list of assessment IDs from a Repository
+// Give modifiedSince to list only the records changed since then.
+func (api *ArchivesSpaceAPI) ListAssessments(repoID int, modifiedSince ...time.Time) ([]int, error) {
+	u := api.callURL(fmt.Sprintf("/repositories/%d/assessments", repoID))
+	q := u.Query()
+	q.Set("all_ids", "true")
+	setModifiedSince(q, modifiedSince)
+	u.RawQuery = q.Encode()
+	return api.ListAPI(u.String())
+}
+
+// Find returns the definition of attrType (rating, format or conservation_issue)
+// with label, or nil if there isn't one. Use it to map spreadsheet column names
+// to the definition IDs an AssessmentAttribute needs.
+func (definitions *AssessmentAttributeDefinitions) Find(attrType, label string) *AssessmentAttributeDefinition {
+	for _, definition := range definitions.Definitions {
+		if definition.Type == attrType && definition.Label == label {
+			return definition
+		}
+	}
+	return nil
+}
+
+// GetAssessmentAttributeDefinitions returns the global and repository specific
+// assessment attribute definitions for a Repository
+func (api *ArchivesSpaceAPI) GetAssessmentAttributeDefinitions(repoID int) (*AssessmentAttributeDefinitions, error) {
+	u := api.callURL(fmt.Sprintf("/repositories/%d/assessment_attribute_definitions", repoID))
+	definitions := new(AssessmentAttributeDefinitions)
+	if err := api.GetAPI(u.String(), definitions); err != nil {
+		return nil, fmt.Errorf("GetAssessmentAttributeDefinitions(%d) %w", repoID, err)
+	}
+	return definitions, nil
+}
+
+// UpdateAssessmentAttributeDefinitions replaces a Repository's own definitions with the
+// non-global ones in definitions. Definitions with an ID are updated, those without are
+// added and any the Repository has which aren't listed are removed. Global definitions
+// are left alone.
+func (api *ArchivesSpaceAPI) UpdateAssessmentAttributeDefinitions(repoID int, definitions *AssessmentAttributeDefinitions) (*ResponseMsg, error) {
+	local := &AssessmentAttributeDefinitions{
+		Definitions:   []*AssessmentAttributeDefinition{},
+		LockVersion:   definitions.LockVersion,
+		JSONModelType: "assessment_attribute_definitions",
+	}
+	for _, definition := range definitions.Definitions {
+		if definition.Global == false {
+			local.Definitions = append(local.Definitions, definition)
+		}
+	}
+	u := api.callURL(fmt.Sprintf("/repositories/%d/assessment_attribute_definitions", repoID))
+	responseMsg, err := api.UpdateAPI(u.String(), local)
+	if err != nil {
+		return nil, fmt.Errorf("UpdateAssessmentAttributeDefinitions(%d) %w", repoID, err)
+	}
+	return responseMsg, nil
+}
@@ -0,0 +1,215 @@
+//
+// Package cait is a collection of structures and functions
+// for interacting with ArchivesSpace's REST API
+//
+// @author R. S. Doiel, <rsdoiel@caltech.edu>
+//
+// Copyright (c) 2017, Caltech
+// All rights not granted herein are expressly reserved by Caltech.
+//
+// Redistribution and use in source and binary forms, with or without modification, are permitted provided that the following conditions are met:
+//
+// 1. Redistributions of source code must retain the above copyright notice, this list of conditions and the following disclaimer.
+//
+// 2. Redistributions in binary form must reproduce the above copyright notice, this list of conditions and the following disclaimer in the documentation and/or other materials provided with the distribution.
+//
+// 3. Neither the name of the copyright holder nor the names of its contributors may be used to endorse or promote products derived from this software without specific prior written permission.
+//
+// THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS" AND ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE ARE DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT HOLDER OR CONTRIBUTORS BE LIABLE FOR ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL DAMAGES (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR SERVICES; LOSS OF USE, DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER CAUSED AND ON ANY THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT LIABILITY, OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE OF THIS SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+//
+package cait
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// code39Chars is the Code 39 character set in check value order, used for mod43 checksums
+const code39Chars = "0123456789ABCDEFGHIJKLMNOPQRSTUVWXYZ-. $/+%"
+
+// BarcodeGenerator produces barcodes of the form Prefix + zero padded sequence
+// number + optional check character + Suffix. Checksum can be "" (none),
+// "luhn" (mod 10, digits only) or "mod43" (Code 39).
+type BarcodeGenerator struct {
+	Prefix   string `json:"prefix,omitempty"`
+	Suffix   string `json:"suffix,omitempty"`
+	Width    int    `json:"width"`
+	Next     int    `json:"next"`
+	Checksum string `json:"checksum,omitempty"`
+
+	inUse map[string]bool
+}
+
+// BarcodeAssignment records a barcode assigned to a top container
+type BarcodeAssignment struct {
+	URI           string `json:"uri"`
+	Type          string `json:"type,omitempty"`
+	Indicator     string `json:"indicator,omitempty"`
+	DisplayString string `json:"display_string,omitempty"`
+	Barcode       string `json:"barcode"`
+}
+
+// luhnDigit returns the Luhn (mod 10) check digit for a string of digits
+func luhnDigit(s string) (string, error) {
+	sum := 0
+	double := true
+	for i := len(s) - 1; i >= 0; i-- {
+		if s[i] < '0' || s[i] > '9' {
+			return "", fmt.Errorf("luhn checksum requires digits, %q", s)
+		}
+		d := int(s[i] - '0')
+		if double == true {
+			d = d * 2
+			if d > 9 {
+				d = d - 9
+			}
+		}
+		sum += d
+		double = !double
+	}
+	return fmt.Sprintf("%d", (10-(sum%10))%10), nil
+}
+
+// mod43Char returns the Code 39 mod 43 check character for s
+func mod43Char(s string) (string, error) {
+	sum := 0
+	for _, r := range strings.ToUpper(s) {
+		i := strings.IndexRune(code39Chars, r)
+		if i < 0 {
+			return "", fmt.Errorf("mod43 checksum can't encode %q in %q", r, s)
+		}
+		sum += i
+	}
+	return string(code39Chars[sum%43]), nil
+}
+
+// checkChar returns the check character for body based on the generator's Checksum
+func (gen *BarcodeGenerator) checkChar(body string) (string, error) {
+	switch strings.ToLower(gen.Checksum) {
+	case "":
+		return "", nil
+	case "luhn", "mod10":
+		return luhnDigit(body)
+	case "mod43":
+		return mod43Char(body)
+	}
+	return "", fmt.Errorf("unknown barcode checksum %q", gen.Checksum)
+}
+
+// Reserve marks barcodes as already in use so Generate will not produce them
+func (gen *BarcodeGenerator) Reserve(barcodes ...string) {
+	if gen.inUse == nil {
+		gen.inUse = map[string]bool{}
+	}
+	for _, barcode := range barcodes {
+		gen.inUse[barcode] = true
+	}
+}
+
+// Generate returns the next unused barcode and advances the sequence
+func (gen *BarcodeGenerator) Generate() (string, error) {
+	for {
+		digits := fmt.Sprintf("%0*d", gen.Width, gen.Next)
+		if gen.Width > 0 && len(digits) > gen.Width {
+			return "", fmt.Errorf("barcode sequence %d exceeds %d digits", gen.Next, gen.Width)
+		}
+		gen.Next++
+		body := gen.Prefix + digits
+		check, err := gen.checkChar(body)
+		if err != nil {
+			return "", err
+		}
+		barcode := body + check + gen.Suffix
+		if gen.inUse[barcode] == false {
+			gen.Reserve(barcode)
+			return barcode, nil
+		}
+	}
+}
+
+// Valid returns true if barcode matches the generator's pattern and checksum
+func (gen *BarcodeGenerator) Valid(barcode string) bool {
+	if strings.HasPrefix(barcode, gen.Prefix) == false || strings.HasSuffix(barcode, gen.Suffix) == false {
+		return false
+	}
+	s := strings.TrimSuffix(strings.TrimPrefix(barcode, gen.Prefix), gen.Suffix)
+	check := ""
+	if gen.Checksum != "" {
+		if len(s) < 2 {
+			return false
+		}
+		s, check = s[0:len(s)-1], s[len(s)-1:]
+	}
+	if len(s) == 0 || (gen.Width > 0 && len(s) != gen.Width) {
+		return false
+	}
+	for _, r := range s {
+		if r < '0' || r > '9' {
+			return false
+		}
+	}
+	expected, err := gen.checkChar(gen.Prefix + s)
+	if err != nil {
+		return false
+	}
+	return expected == check
+}
+
+// AssignBarcodes generates barcodes for the top containers in a repository that
+// lack one and saves them in bulk. Existing barcodes are reserved so they are
+// never reissued. If dryRun is true the assignments are returned but not saved.
+func (api *ArchivesSpaceAPI) AssignBarcodes(repoID int, gen *BarcodeGenerator, dryRun bool) ([]*BarcodeAssignment, error) {
+	containers := []*TopContainer{}
+	err := api.EachTopContainer(repoID, func(container *TopContainer) error {
+		if container.Barcode != "" {
+			gen.Reserve(container.Barcode)
+			return nil
+		}
+		containers = append(containers, container)
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("AssignBarcodes(%d) %w", repoID, err)
+	}
+
+	assignments := []*BarcodeAssignment{}
+	barcodes := map[string]string{}
+	for _, container := range containers {
+		barcode, err := gen.Generate()
+		if err != nil {
+			return assignments, fmt.Errorf("AssignBarcodes(%d) %w", repoID, err)
+		}
+		barcodes[container.URI] = barcode
+		assignments = append(assignments, &BarcodeAssignment{
+			URI:           container.URI,
+			Type:          container.Type,
+			Indicator:     container.Indicator,
+			DisplayString: container.DisplayString,
+			Barcode:       barcode,
+		})
+	}
+	if dryRun == true || len(barcodes) == 0 {
+		return assignments, nil
+	}
+	if _, err := api.UpdateTopContainerBarcodes(repoID, barcodes); err != nil {
+		return assignments, err
+	}
+	return assignments, nil
+}
+
+// WriteBarcodeMapping writes barcode assignments as CSV suitable for printing labels
+func WriteBarcodeMapping(w io.Writer, assignments []*BarcodeAssignment) error {
+	out := csv.NewWriter(w)
+	if err := out.Write([]string{"barcode", "uri", "type", "indicator", "display_string"}); err != nil {
+		return err
+	}
+	for _, a := range assignments {
+		if err := out.Write([]string{a.Barcode, a.URI, a.Type, a.Indicator, a.DisplayString}); err != nil {
+			return err
+		}
+	}
+	out.Flush()
+	return out.Error()
+}
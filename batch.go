@@ -0,0 +1,249 @@
+//
+// Package cait is a collection of structures and functions
+// for interacting with ArchivesSpace's REST API
+//
+// @author R. S. Doiel, <rsdoiel@caltech.edu>
+//
+// Copyright (c) 2017, Caltech
+// All rights not granted herein are expressly reserved by Caltech.
+//
+// Redistribution and use in source and binary forms, with or without modification, are permitted provided that the following conditions are met:
+//
+// 1. Redistributions of source code must retain the above copyright notice, this list of conditions and the following disclaimer.
+//
+// 2. Redistributions in binary form must reproduce the above copyright notice, this list of conditions and the following disclaimer in the documentation and/or other materials provided with the distribution.
+//
+// 3. Neither the name of the copyright holder nor the names of its contributors may be used to endorse or promote products derived from this software without specific prior written permission.
+//
+// THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS" AND ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE ARE DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT HOLDER OR CONTRIBUTORS BE LIABLE FOR ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL DAMAGES (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR SERVICES; LOSS OF USE, DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER CAUSED AND ON ANY THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT LIABILITY, OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE OF THIS SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+//
+package cait
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+)
+
+// BatchEditOptions controls how ApplyToSearch processes search hits
+type BatchEditOptions struct {
+	// RepoID limits the search to a repository, zero searches all repositories
+	RepoID int `json:"repo_id,omitempty"`
+	// Types limits the search to the listed record types (e.g. accession, resource)
+	Types []string `json:"types,omitempty"`
+	// DryRun reports the changes that would be made without saving them
+	DryRun bool `json:"dry_run,omitempty"`
+	// MaxRetries is the number of times an update is retried after a lock version conflict,
+	// zero uses the client's ConflictRetries or a single retry if that isn't set
+	MaxRetries int `json:"max_retries,omitempty"`
+}
+
+// errNotSaved stops an update when a batch edit has nothing to save
+var errNotSaved = errors.New("not saved")
+
+// retries returns the number of lock version conflict retries, at least one
+func (opts *BatchEditOptions) retries(api *ArchivesSpaceAPI) int {
+	if opts.MaxRetries > 0 {
+		return opts.MaxRetries
+	}
+	if api.ConflictRetries > 0 {
+		return api.ConflictRetries
+	}
+	return 1
+}
+
+// BatchEditChange records what happened to a single record in a batch edit
+type BatchEditChange struct {
+	URI      string   `json:"uri"`
+	Changed  bool     `json:"changed"`
+	Fields   []string `json:"fields,omitempty"`
+	Saved    bool     `json:"saved"`
+	Attempts int      `json:"attempts,omitempty"`
+	Error    string   `json:"error,omitempty"`
+}
+
+// BatchEditReport summarizes a batch edit run
+type BatchEditReport struct {
+	Query   string             `json:"query,omitempty"`
+	DryRun  bool               `json:"dry_run"`
+	Hits    int                `json:"hits"`
+	Changed int                `json:"changed"`
+	Saved   int                `json:"saved"`
+	Errors  int                `json:"errors"`
+	Changes []*BatchEditChange `json:"changes"`
+}
+
+// TransformFunc mutates a full record in place and returns true if anything was changed
+type TransformFunc func(obj Object) (bool, error)
+
+// ApplyToSearch runs a search, fetches each hit's full record, applies transform
+// and saves the result. Updates that fail on a lock version conflict are refetched
+// and retried. If opts.DryRun is true nothing is saved but the report lists
+// the records and fields that would change.
+func (api *ArchivesSpaceAPI) ApplyToSearch(query string, transform TransformFunc, opts *BatchEditOptions) (*BatchEditReport, error) {
+	if transform == nil {
+		return nil, fmt.Errorf("ApplyToSearch(%q) missing transform function", query)
+	}
+	if opts == nil {
+		opts = new(BatchEditOptions)
+	}
+	report := new(BatchEditReport)
+	report.Query = query
+	report.DryRun = opts.DryRun
+
+	// Collect the URIs first so our own updates can't shift the result pages
+	uris := []string{}
+	req := NewSearchRequest(query).InRepository(opts.RepoID).OfType(opts.Types...)
+	for {
+		results, err := api.SearchRecords(req)
+		if err != nil {
+			return nil, fmt.Errorf("ApplyToSearch(%q) %w", query, err)
+		}
+		for _, hit := range results.Results {
+			if hit.URI != "" {
+				uris = append(uris, hit.URI)
+			}
+		}
+		if len(results.Results) == 0 || results.HasNextPage() == false {
+			break
+		}
+		req.WithPage(results.ThisPage + 1)
+	}
+	report.Hits = len(uris)
+	return api.applyToURIs(report, uris, transform, opts), nil
+}
+
+// ApplyToURIs is like ApplyToSearch but works on an explicit list of record URIs
+func (api *ArchivesSpaceAPI) ApplyToURIs(uris []string, transform TransformFunc, opts *BatchEditOptions) (*BatchEditReport, error) {
+	if transform == nil {
+		return nil, fmt.Errorf("ApplyToURIs() missing transform function")
+	}
+	if opts == nil {
+		opts = new(BatchEditOptions)
+	}
+	report := new(BatchEditReport)
+	report.DryRun = opts.DryRun
+	report.Hits = len(uris)
+	return api.applyToURIs(report, uris, transform, opts), nil
+}
+
+// applyToURIs applies transform to each record adding the results to report
+func (api *ArchivesSpaceAPI) applyToURIs(report *BatchEditReport, uris []string, transform TransformFunc, opts *BatchEditOptions) *BatchEditReport {
+	for _, uri := range uris {
+		change := api.applyToRecord(uri, transform, opts)
+		if change.Changed == true {
+			report.Changed++
+		}
+		if change.Saved == true {
+			report.Saved++
+		}
+		if change.Error != "" {
+			report.Errors++
+		}
+		report.Changes = append(report.Changes, change)
+	}
+	return report
+}
+
+// applyToRecord fetches, transforms and saves a single record retrying on lock conflicts
+func (api *ArchivesSpaceAPI) applyToRecord(uri string, transform TransformFunc, opts *BatchEditOptions) *BatchEditChange {
+	change := &BatchEditChange{URI: uri}
+	apply := func(obj *Object) error {
+		change.Attempts++
+		before := copyObject(*obj)
+		changed, err := transform(*obj)
+		if err != nil {
+			return err
+		}
+		change.Changed = changed
+		change.Fields = changedFields(before, *obj)
+		if changed == false || opts.DryRun == true {
+			return errNotSaved
+		}
+		return nil
+	}
+	responseMsg, err := updateWith[Object](api, uri, opts.retries(api), apply)
+	switch {
+	case errors.Is(err, errNotSaved):
+	case err != nil:
+		change.Error = err.Error()
+	case responseMsg.Error != nil:
+		change.Error = fmt.Sprintf("%v", responseMsg.Error)
+	default:
+		change.Saved = true
+	}
+	return change
+}
+
+// copyObject returns a deep copy of obj
+func copyObject(obj Object) Object {
+	src, _ := json.Marshal(obj)
+	c := Object{}
+	json.Unmarshal(src, &c)
+	return c
+}
+
+// changedFields returns the sorted top level field names which differ between before and after
+func changedFields(before, after Object) []string {
+	fields := []string{}
+	for k, v := range after {
+		src1, _ := json.Marshal(before[k])
+		src2, _ := json.Marshal(v)
+		if string(src1) != string(src2) {
+			fields = append(fields, k)
+		}
+	}
+	for k := range before {
+		if _, ok := after[k]; ok == false {
+			fields = append(fields, k)
+		}
+	}
+	sort.Strings(fields)
+	return fields
+}
+
+// isLockConflict returns true if a response message reports a stale lock_version,
+// ArchivesSpace sends {"error": {"lock_version": ["The record you tried to update ..."]}}
+func isLockConflict(responseMsg *ResponseMsg) bool {
+	if responseMsg == nil {
+		return false
+	}
+	fields, ok := responseMsg.Error.(map[string]interface{})
+	if ok == false {
+		return false
+	}
+	_, ok = fields["lock_version"]
+	return ok
+}
+
+// isLockConflictError returns true if an update error reports a stale lock_version
+func isLockConflictError(err error) bool {
+	e, ok := AsAPIError(err)
+	return ok == true && isLockConflict(&ResponseMsg{Error: e.Message})
+}
+
+// refList returns the list of ref objects held in field of obj (e.g. subjects, classifications)
+func refList(obj Object, field string) []interface{} {
+	list, _ := obj[field].([]interface{})
+	return list
+}
+
+// hasRef returns true if field of obj holds a ref object pointing at ref
+func hasRef(obj Object, field, ref string) bool {
+	for _, item := range refList(obj, field) {
+		if m, ok := item.(map[string]interface{}); ok == true && m["ref"] == ref {
+			return true
+		}
+	}
+	return false
+}
+
+// addRef appends {"ref": ref} to field of obj unless already present, returning true if added
+func addRef(obj Object, field, ref string) bool {
+	if hasRef(obj, field, ref) {
+		return false
+	}
+	obj[field] = append(refList(obj, field), map[string]interface{}{"ref": ref})
+	return true
+}
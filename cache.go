@@ -0,0 +1,161 @@
+//
+// Package cait is a collection of structures and functions
+// for interacting with ArchivesSpace's REST API
+//
+// @author R. S. Doiel, <rsdoiel@caltech.edu>
+//
+// Copyright (c) 2017, Caltech
+// All rights not granted herein are expressly reserved by Caltech.
+//
+// Redistribution and use in source and binary forms, with or without modification, are permitted provided that the following conditions are met:
+//
+// 1. Redistributions of source code must retain the above copyright notice, this list of conditions and the following disclaimer.
+//
+// 2. Redistributions in binary form must reproduce the above copyright notice, this list of conditions and the following disclaimer in the documentation and/or other materials provided with the distribution.
+//
+// 3. Neither the name of the copyright holder nor the names of its contributors may be used to endorse or promote products derived from this software without specific prior written permission.
+//
+// THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS" AND ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE ARE DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT HOLDER OR CONTRIBUTORS BE LIABLE FOR ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL DAMAGES (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR SERVICES; LOSS OF USE, DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER CAUSED AND ON ANY THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT LIABILITY, OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE OF THIS SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+//
+package cait
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// CachedResponse is a stored GET response along with the values used to ask
+// ArchivesSpace (or a caching proxy in front of it) whether it has changed
+type CachedResponse struct {
+	ETag         string `json:"etag,omitempty"`
+	LastModified string `json:"last_modified,omitempty"`
+	SystemMTime  string `json:"system_mtime,omitempty"`
+	Content      []byte `json:"content"`
+}
+
+// ResponseCache stores GET responses. The key is the URL followed by a hash of the
+// user and request headers so clients sharing a cache don't see each other's responses.
+type ResponseCache interface {
+	Get(key string) (*CachedResponse, bool)
+	Put(key string, response *CachedResponse)
+}
+
+// MemoryCache is a ResponseCache held in memory, safe for concurrent use
+type MemoryCache struct {
+	mu        sync.RWMutex
+	responses map[string]*CachedResponse
+}
+
+// NewMemoryCache returns an empty MemoryCache
+func NewMemoryCache() *MemoryCache {
+	return &MemoryCache{responses: map[string]*CachedResponse{}}
+}
+
+// Get returns the cached response for key
+func (cache *MemoryCache) Get(key string) (*CachedResponse, bool) {
+	cache.mu.RLock()
+	defer cache.mu.RUnlock()
+	response, ok := cache.responses[key]
+	return response, ok
+}
+
+// Put stores the response for key
+func (cache *MemoryCache) Put(key string, response *CachedResponse) {
+	cache.mu.Lock()
+	defer cache.mu.Unlock()
+	cache.responses[key] = response
+}
+
+// WithCache makes GET requests conditional, reusing the cached record when
+// the server reports it hasn't changed
+func WithCache(cache ResponseCache) Option {
+	return func(api *ArchivesSpaceAPI) {
+		api.Cache = cache
+	}
+}
+
+// conditionalHeaders adds If-None-Match and If-Modified-Since headers for a cached response.
+// If the server sent no Last-Modified the record's system_mtime is used instead.
+func conditionalHeaders(cached *CachedResponse, headers http.Header) http.Header {
+	h := http.Header{}
+	for key, values := range headers {
+		h[key] = values
+	}
+	if cached.ETag != "" {
+		h.Set("If-None-Match", cached.ETag)
+	}
+	if cached.LastModified != "" {
+		h.Set("If-Modified-Since", cached.LastModified)
+	} else if t, err := time.Parse(time.RFC3339, cached.SystemMTime); err == nil {
+		h.Set("If-Modified-Since", t.UTC().Format(http.TimeFormat))
+	}
+	return h
+}
+
+// cacheKey returns the key a GET of url is cached under. ArchivesSpace only returns
+// what the user may see and a response can vary with the request headers, so the
+// user (or session when there is no username) and headers are part of the key.
+func (api *ArchivesSpaceAPI) cacheKey(url string, headers http.Header) string {
+	h := sha256.New()
+	if api.Username != "" {
+		fmt.Fprintf(h, "user %s\n", api.Username)
+	} else {
+		fmt.Fprintf(h, "session %s\n", api.token())
+	}
+	for _, set := range []http.Header{api.Headers, headers} {
+		keys := []string{}
+		for key := range set {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		for _, key := range keys {
+			fmt.Fprintf(h, "%s: %s\n", http.CanonicalHeaderKey(key), strings.Join(set[key], ", "))
+		}
+	}
+	return url + " " + hex.EncodeToString(h.Sum(nil))
+}
+
+// cachedGet is the GET branch of APIWithHeaders when api.Cache is set
+func (api *ArchivesSpaceAPI) cachedGet(url string, headers http.Header) ([]byte, error) {
+	key := api.cacheKey(url, headers)
+	cached, ok := api.Cache.Get(key)
+	if ok == true {
+		headers = conditionalHeaders(cached, headers)
+	}
+	res, err := api.sendRequest("GET", url, nil, headers)
+	if err != nil {
+		return nil, err
+	}
+	defer res.Body.Close()
+	if ok == true && res.StatusCode == http.StatusNotModified {
+		return cached.Content, nil
+	}
+	if isSuccess(res.StatusCode) == false {
+		body, _ := api.readBody(url, res.Body)
+		return nil, newAPIError("GET", url, res.StatusCode, res.Status, body)
+	}
+	content, err := api.readBody(url, res.Body)
+	if err != nil {
+		return nil, err
+	}
+	response := &CachedResponse{
+		ETag:         res.Header.Get("ETag"),
+		LastModified: res.Header.Get("Last-Modified"),
+		Content:      content,
+	}
+	meta := struct {
+		SystemMTime string `json:"system_mtime"`
+	}{}
+	if json.Unmarshal(content, &meta) == nil {
+		response.SystemMTime = meta.SystemMTime
+	}
+	api.Cache.Put(key, response)
+	return content, nil
+}
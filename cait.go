@@ -20,15 +20,16 @@
 package cait
 
 import (
-	"bytes"
 	"encoding/json"
 	"fmt"
+	"io"
 	"io/ioutil"
 	"net/http"
 	"net/url"
 	"os"
 	"strconv"
 	"strings"
+	"time"
 )
 
 // Version of library
@@ -53,6 +54,36 @@ THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS" AND
 `
 )
 
+// DefaultUserAgent returns the User-Agent sent with requests unless
+// ArchivesSpaceAPI.UserAgent is set, e.g. "gospace/v0.0.16 Go-http-client".
+// Version can be set at build time with -ldflags "-X github.com/caltechlibrary/cait.Version=..."
+func DefaultUserAgent() string {
+	return fmt.Sprintf("gospace/%s Go-http-client", Version)
+}
+
+// userAgent returns the User-Agent header value for requests
+func (api *ArchivesSpaceAPI) userAgent() string {
+	if api.UserAgent != "" {
+		return api.UserAgent
+	}
+	return DefaultUserAgent()
+}
+
+// DefaultMaxResponseSize is the largest response body, in bytes, read into memory by API()
+// unless ArchivesSpaceAPI.MaxResponseSize is changed. Zero or less means no limit.
+const DefaultMaxResponseSize = 64 << 20
+
+// ErrResponseTooLarge is returned when a response body exceeds MaxResponseSize
+type ErrResponseTooLarge struct {
+	URL   string
+	Limit int64
+}
+
+// Error explains the limit and how to retrieve the response anyway
+func (e *ErrResponseTooLarge) Error() string {
+	return fmt.Sprintf("response from %s is larger than %d bytes, use DownloadAPI() to stream it or raise MaxResponseSize", e.URL, e.Limit)
+}
+
 func getenv(envvar, defaultValue string) string {
 	tmp := os.Getenv(envvar)
 	if tmp != "" {
@@ -62,8 +93,11 @@ func getenv(envvar, defaultValue string) string {
 }
 
 // New creates a new ArchivesSpaceAPI object for use with most of the functions
-// in the gas package.
-func New(apiURL, username, password, dataset string) *ArchivesSpaceAPI {
+// in the gas package. Options (e.g. WithRequestTimeout) are applied last.
+//
+// Deprecated: New can't report a bad URL, leaving the program to fail on its
+// first request. Use NewClient or NewFromEnv instead.
+func New(apiURL, username, password, dataset string, options ...Option) *ArchivesSpaceAPI {
 	api := new(ArchivesSpaceAPI)
 	api.BaseURL, _ = url.Parse(getenv("CAIT_API_URL", apiURL))
 	api.CallURL, _ = url.Parse(getenv("CAIT_API_URL", apiURL))
@@ -75,18 +109,61 @@ func New(apiURL, username, password, dataset string) *ArchivesSpaceAPI {
 	api.Htdocs = getenv("CAIT_HTDOCS", "htdocs")
 	api.HtdocsIndex = getenv("CAIT_HTDOCS_INDEX", "htdocs.bleve")
 	api.Templates = getenv("CAIT_TEMPLATES", "templates/default")
+	api.MaxResponseSize = DefaultMaxResponseSize
+	if i, err := strconv.ParseInt(os.Getenv("CAIT_MAX_RESPONSE_SIZE"), 10, 64); err == nil {
+		api.MaxResponseSize = i
+	}
+	for _, option := range options {
+		option(api)
+	}
 	return api
 }
 
+// SetHTTPClient sets the http.Client used for all requests, e.g. to supply
+// custom timeouts, proxies or connection pooling
+func (api *ArchivesSpaceAPI) SetHTTPClient(client *http.Client) {
+	api.Client = client
+}
+
+// httpClient returns api.Client or http.DefaultClient if none has been set
+func (api *ArchivesSpaceAPI) httpClient() *http.Client {
+	if api.Client != nil {
+		return api.Client
+	}
+	return http.DefaultClient
+}
+
 // UpdateCallPath takes the BaseURL Path attribute, copies it into CallURL, applies appends a path for next API call
+//
+// Deprecated: UpdateCallPath changes the shared CallURL so isn't safe to use from
+// more than one goroutine. The package's own functions build a new URL for each request.
 func (api *ArchivesSpaceAPI) UpdateCallPath(p string) string {
 	api.CallURL.Path = api.BaseURL.Path + p
 	return api.CallURL.Path
 }
 
+// callURL returns a new URL for API path p relative to BaseURL. Each request
+// gets its own URL so an ArchivesSpaceAPI can be shared between goroutines.
+func (api *ArchivesSpaceAPI) callURL(p string) *url.URL {
+	u := *api.BaseURL
+	u.Path = api.BaseURL.Path + p
+	u.RawQuery = ""
+	u.Fragment = ""
+	return &u
+}
+
+// buildURL returns the URL string for API path p with the query given
+func (api *ArchivesSpaceAPI) buildURL(p string, query url.Values) string {
+	u := api.callURL(p)
+	if query != nil {
+		u.RawQuery = query.Encode()
+	}
+	return u.String()
+}
+
 // IsAuth returns true if the auth token has been set, false otherwise
 func (api *ArchivesSpaceAPI) IsAuth() bool {
-	if api.AuthToken == "" {
+	if api.token() == "" {
 		return false
 	}
 	return true
@@ -104,54 +181,63 @@ func (api *ArchivesSpaceAPI) Login() error {
 		api.Logout()
 	}
 
-	api.UpdateCallPath(fmt.Sprintf("/users/%s/login", api.Username))
+	u := api.callURL(fmt.Sprintf("/users/%s/login", api.Username))
+	password, err := api.password()
+	if err != nil {
+		return err
+	}
 	form := url.Values{}
-	form.Add("password", api.Password)
+	form.Add("password", password)
 
-	res, err := http.PostForm(api.CallURL.String(), form)
+	headers := http.Header{}
+	headers.Set("Content-Type", "application/x-www-form-urlencoded")
+	res, err := api.doRequest("POST", u.String(), []byte(form.Encode()), headers)
 	if err != nil {
 		return err
 	}
 	defer res.Body.Close()
-	if res.Status != "200 OK" {
-		return fmt.Errorf("ArchivesSpace returned HTTP status %s", res.Status)
+	if isSuccess(res.StatusCode) == false {
+		body, _ := api.readBody(u.String(), res.Body)
+		return newAPIError("POST", u.String(), res.StatusCode, res.Status, body)
 	}
 	content, err := ioutil.ReadAll(res.Body)
 
 	if err != nil {
-		return fmt.Errorf("ArchivesSpace return unreadable body: %s", err)
+		return fmt.Errorf("ArchivesSpace return unreadable body: %w", err)
 	}
 
 	if err = json.Unmarshal(content, &data); err != nil {
-		return fmt.Errorf("Can't process JSON response %s\n\t%s", content, err)
+		return fmt.Errorf("Can't process JSON response %s\n\t%w", content, err)
 	}
-	api.AuthToken = data["session"].(string)
+	token, ok := data["session"].(string)
+	if ok == false {
+		return fmt.Errorf("Can't find session in JSON response %s", content)
+	}
+	api.setToken(token)
 	return nil
 }
 
 // Logout clear the authentication token for the session with the API
 func (api *ArchivesSpaceAPI) Logout() error {
-	// Save the token and invalidate the one in our cait struct.
-	token := api.AuthToken
-	api.AuthToken = ""
-	// Using the copied token try to logout from the service.
-	api.UpdateCallPath(`/logout`)
-	client := &http.Client{}
-	req, err := http.NewRequest("GET", api.CallURL.String(), nil)
-	if err != nil {
-		return err
-	}
-	req.Header.Set("Content-Type", "application/json")
-	req.Header.Add("X-ArchivesSpace-Session", token)
-	_, err = client.Do(req)
+	// The token is cleared even if the service can't be reached
+	defer api.setToken("")
+	u := api.callURL(`/logout`)
+	res, err := api.doRequest("GET", u.String(), nil, nil)
 	if err != nil {
 		return err
 	}
+	res.Body.Close()
 	return nil
 }
 
 // API the common HTTP request processing for interacting with ArchivesSpaceAPI
 func (api *ArchivesSpaceAPI) API(method string, url string, data interface{}) ([]byte, error) {
+	return api.APIWithHeaders(method, url, data, nil)
+}
+
+// APIWithHeaders is like API but adds headers to this request, overriding any
+// of the same name in api.Headers
+func (api *ArchivesSpaceAPI) APIWithHeaders(method string, url string, data interface{}, headers http.Header) ([]byte, error) {
 	var (
 		payload []byte
 		err     error
@@ -159,54 +245,102 @@ func (api *ArchivesSpaceAPI) API(method string, url string, data interface{}) ([
 	if data != nil {
 		payload, err = json.Marshal(data)
 		if err != nil {
-			return nil, fmt.Errorf("API(%q, %q, data), %s", method, url, err)
+			return nil, fmt.Errorf("API(%q, %q, data), %w", method, url, err)
 		}
 	}
-	client := &http.Client{}
-	req, err := http.NewRequest(method, url, bytes.NewReader(payload))
+	if method == "GET" && api.Cache != nil {
+		content, err := api.cachedGet(url, headers)
+		if _, ok := AsAPIError(err); err != nil && ok == false {
+			return nil, fmt.Errorf("Request error: %w", err)
+		}
+		return content, err
+	}
+	res, err := api.sendRequest(method, url, payload, headers)
 	if err != nil {
-		return nil, fmt.Errorf("Can't create request: %s", err)
+		return nil, fmt.Errorf("Request error: %w", err)
+	}
+	defer res.Body.Close()
+	if isSuccess(res.StatusCode) == false {
+		body, _ := api.readBody(url, res.Body)
+		return nil, newAPIError(method, url, res.StatusCode, res.Status, body)
 	}
-	req.Header.Add("X-ArchivesSpace-Session", api.AuthToken)
-	req.Header.Set("Content-Type", "application/json")
+	return api.readBody(url, res.Body)
+}
 
-	if method == "POST" {
-		res, err := client.Do(req)
-		if err != nil {
-			return nil, fmt.Errorf("Request error: %s", err)
-		}
-		defer res.Body.Close()
-		content, err := ioutil.ReadAll(res.Body)
+// readBody reads a response body refusing to read more than api.MaxResponseSize bytes
+func (api *ArchivesSpaceAPI) readBody(url string, body io.Reader) ([]byte, error) {
+	if api.MaxResponseSize <= 0 {
+		content, err := ioutil.ReadAll(body)
 		if err != nil {
-			return nil, fmt.Errorf("Read body error: %s", err)
+			return nil, fmt.Errorf("Read body error: %w", err)
 		}
 		return content, nil
 	}
-	res, err := client.Do(req)
+	content, err := ioutil.ReadAll(io.LimitReader(body, api.MaxResponseSize+1))
 	if err != nil {
-		return nil, fmt.Errorf("Request error: %s", err)
+		return nil, fmt.Errorf("Read body error: %w", err)
+	}
+	if int64(len(content)) > api.MaxResponseSize {
+		return nil, &ErrResponseTooLarge{URL: RedactURL(url), Limit: api.MaxResponseSize}
+	}
+	return content, nil
+}
+
+// DownloadAPI copies the body of a GET request to w without holding it in memory,
+// it is not subject to MaxResponseSize. It returns the number of bytes written.
+func (api *ArchivesSpaceAPI) DownloadAPI(url string, w io.Writer) (int64, error) {
+	res, err := api.sendRequest("GET", url, nil, nil)
+	if err != nil {
+		return 0, fmt.Errorf("Request error: %w", err)
 	}
 	defer res.Body.Close()
-	if res.Status != "200 OK" {
-		return nil, fmt.Errorf("ArchiveSpace API error %s", res.Status)
+	if isSuccess(res.StatusCode) == false {
+		body, _ := api.readBody(url, res.Body)
+		return 0, newAPIError("GET", url, res.StatusCode, res.Status, body)
 	}
-	content, err := ioutil.ReadAll(res.Body)
+	return io.Copy(w, res.Body)
+}
+
+// APIStream sends a request for API path p, which may include a query string, and
+// returns the response body unread so large exports (e.g. EAD) can be piped to disk.
+// It is not subject to MaxResponseSize or the response cache. The caller must close
+// the body.
+func (api *ArchivesSpaceAPI) APIStream(method, p string, payload interface{}) (io.ReadCloser, error) {
+	var src []byte
+	ref, err := url.Parse(p)
 	if err != nil {
-		return nil, fmt.Errorf("Read body error: %s", err)
+		return nil, fmt.Errorf("APIStream(%q, %q, payload), %w", method, p, err)
 	}
-	return content, nil
+	u := api.callURL(ref.Path)
+	u.RawQuery = ref.RawQuery
+	if payload != nil {
+		src, err = json.Marshal(payload)
+		if err != nil {
+			return nil, fmt.Errorf("APIStream(%q, %q, payload), %w", method, p, err)
+		}
+	}
+	res, err := api.sendRequest(method, u.String(), src, nil)
+	if err != nil {
+		return nil, fmt.Errorf("Request error: %w", err)
+	}
+	if isSuccess(res.StatusCode) == false {
+		defer res.Body.Close()
+		body, _ := api.readBody(u.String(), res.Body)
+		return nil, newAPIError(method, u.String(), res.StatusCode, res.Status, body)
+	}
+	return res.Body, nil
 }
 
 // CreateAPI is a generalized call to create an object form an interface.
 func (api *ArchivesSpaceAPI) CreateAPI(url string, obj interface{}) (*ResponseMsg, error) {
 	content, err := api.API("POST", url, obj)
 	if err != nil {
-		return nil, fmt.Errorf("Create API, %s, %s", content, err)
+		return nil, fmt.Errorf("Create API, %w", err)
 	}
 	data := new(ResponseMsg)
 	err = json.Unmarshal(content, data)
 	if err != nil {
-		return nil, fmt.Errorf("Create API, unmarshal response msg, %s", err)
+		return nil, fmt.Errorf("Create API, unmarshal response msg, %w", err)
 	}
 	return data, nil
 }
@@ -220,7 +354,7 @@ func (api *ArchivesSpaceAPI) GetAPI(url string, obj interface{}) error {
 	}
 	err = json.Unmarshal(content, obj)
 	if err != nil {
-		return fmt.Errorf("unmarshal error %s, %s\n", content, err)
+		return fmt.Errorf("unmarshal error %s, %w\n", content, err)
 	}
 	return nil
 }
@@ -229,12 +363,12 @@ func (api *ArchivesSpaceAPI) GetAPI(url string, obj interface{}) error {
 func (api *ArchivesSpaceAPI) UpdateAPI(url string, obj interface{}) (*ResponseMsg, error) {
 	content, err := api.API("POST", url, obj)
 	if err != nil {
-		return nil, fmt.Errorf("UpdateAPI(%q, obj) %s", url, err)
+		return nil, fmt.Errorf("UpdateAPI(%q, obj) %w", RedactURL(url), err)
 	}
 	data := new(ResponseMsg)
 	err = json.Unmarshal(content, data)
 	if err != nil {
-		return nil, fmt.Errorf("Could not unpack UpdateAPI() response [%s] %s", content, err)
+		return nil, fmt.Errorf("Could not unpack UpdateAPI() response [%s] %w", content, err)
 	}
 	return data, nil
 }
@@ -243,13 +377,13 @@ func (api *ArchivesSpaceAPI) UpdateAPI(url string, obj interface{}) (*ResponseMs
 func (api *ArchivesSpaceAPI) DeleteAPI(url string, obj interface{}) (*ResponseMsg, error) {
 	content, err := api.API("DELETE", url, obj)
 	if err != nil {
-		return nil, fmt.Errorf("DeleteAPI(%q, obj) %s", url, err)
+		return nil, fmt.Errorf("DeleteAPI(%q, obj) %w", url, err)
 	}
 
 	data := new(ResponseMsg)
 	err = json.Unmarshal(content, data)
 	if err != nil {
-		return nil, fmt.Errorf("Cannnot decode DeleteAPI() response %s", err)
+		return nil, fmt.Errorf("Cannnot decode DeleteAPI() response %w", err)
 	}
 	return data, nil
 }
@@ -258,7 +392,7 @@ func (api *ArchivesSpaceAPI) DeleteAPI(url string, obj interface{}) (*ResponseMs
 func (api *ArchivesSpaceAPI) ListAPI(url string) ([]int, error) {
 	content, err := api.API("GET", url, nil)
 	if err != nil {
-		return nil, fmt.Errorf("ListAPI(%q) %s", url, err)
+		return nil, fmt.Errorf("ListAPI(%q) %w", url, err)
 	}
 
 	// content should look something like
@@ -266,7 +400,7 @@ func (api *ArchivesSpaceAPI) ListAPI(url string) ([]int, error) {
 	var ids []int
 	err = json.Unmarshal(content, &ids)
 	if err != nil {
-		return nil, fmt.Errorf("ListAPI(%q) %s", url, err)
+		return nil, fmt.Errorf("ListAPI(%q) %w", url, err)
 	}
 	return ids, nil
 }
@@ -275,17 +409,17 @@ func (api *ArchivesSpaceAPI) ListAPI(url string) ([]int, error) {
 // ArchivesSpace defined in the ArchivesSpaceAPI struct.
 // It will return the created record.
 func (api *ArchivesSpaceAPI) CreateRepository(repo *Repository) (*ResponseMsg, error) {
-	api.UpdateCallPath("/repositories")
-	return api.CreateAPI(api.CallURL.String(), repo)
+	u := api.callURL("/repositories")
+	return api.CreateAPI(u.String(), repo)
 }
 
 // GetRepository returns the repository details based on Id
 func (api *ArchivesSpaceAPI) GetRepository(id int) (*Repository, error) {
-	api.UpdateCallPath(fmt.Sprintf(`/repositories/%d`, id))
+	u := api.callURL(fmt.Sprintf(`/repositories/%d`, id))
 	repo := new(Repository)
-	err := api.GetAPI(api.CallURL.String(), repo)
+	err := api.GetAPI(u.String(), repo)
 	if err != nil {
-		return nil, fmt.Errorf("GetRepostiory(%d) %s", id, err)
+		return nil, fmt.Errorf("GetRepostiory(%d) %w", id, err)
 	}
 	repo.ID = URIToID(repo.URI)
 	return repo, nil
@@ -293,14 +427,64 @@ func (api *ArchivesSpaceAPI) GetRepository(id int) (*Repository, error) {
 
 // UpdateRepository takes a repository structure and sends it to the ArchivesSpace REST API
 func (api *ArchivesSpaceAPI) UpdateRepository(repo *Repository) (*ResponseMsg, error) {
-	api.UpdateCallPath(repo.URI)
-	return api.UpdateAPI(api.CallURL.String(), repo)
+	u := api.callURL(repo.URI)
+	return api.UpdateAPI(u.String(), repo)
 }
 
 // DeleteRepository takes a repository structure and sends it to the ArchivesSpace REST API
 func (api *ArchivesSpaceAPI) DeleteRepository(repo *Repository) (*ResponseMsg, error) {
-	api.UpdateCallPath(fmt.Sprintf("/repositories/%d", repo.ID))
-	return api.DeleteAPI(api.CallURL.String(), repo)
+	u := api.callURL(fmt.Sprintf("/repositories/%d", repo.ID))
+	return api.DeleteAPI(u.String(), repo)
+}
+
+// CreateRepositoryWithAgent creates a repository and the corporate entity agent
+// representing it (with its contact details) in one request. On success the
+// repository's URI, ID and lock version are updated from the response.
+func (api *ArchivesSpaceAPI) CreateRepositoryWithAgent(repoWithAgent *RepositoryWithAgent) (*ResponseMsg, error) {
+	if repoWithAgent.Repository == nil {
+		return nil, fmt.Errorf("CreateRepositoryWithAgent() missing repository")
+	}
+	u := api.callURL("/repositories/with_agent")
+	repoWithAgent.JSONModelType = "repository_with_agent"
+	repoWithAgent.Repository.JSONModelType = "repository"
+	if repoWithAgent.AgentRepresentation != nil {
+		repoWithAgent.AgentRepresentation.JSONModelType = "agent_corporate_entity"
+	}
+	responseMsg, err := api.CreateAPI(u.String(), repoWithAgent)
+	if err != nil {
+		return nil, fmt.Errorf("CreateRepositoryWithAgent(%q) %w", repoWithAgent.Repository.RepoCode, err)
+	}
+	repoWithAgent.Repository.URI = responseMsg.URI
+	repoWithAgent.Repository.ID = URIToID(responseMsg.URI)
+	repoWithAgent.Repository.LockVersion = responseMsg.LockVersion
+	return responseMsg, nil
+}
+
+// GetRepositoryWithAgent returns a repository with its agent representation and contact details
+func (api *ArchivesSpaceAPI) GetRepositoryWithAgent(id int) (*RepositoryWithAgent, error) {
+	u := api.callURL(fmt.Sprintf("/repositories/with_agent/%d", id))
+	repoWithAgent := new(RepositoryWithAgent)
+	if err := api.GetAPI(u.String(), repoWithAgent); err != nil {
+		return nil, fmt.Errorf("GetRepositoryWithAgent(%d) %w", id, err)
+	}
+	if repoWithAgent.Repository != nil {
+		repoWithAgent.Repository.ID = URIToID(repoWithAgent.Repository.URI)
+	}
+	return repoWithAgent, nil
+}
+
+// UpdateRepositoryWithAgent updates a repository and its agent representation in one request
+func (api *ArchivesSpaceAPI) UpdateRepositoryWithAgent(repoWithAgent *RepositoryWithAgent) (*ResponseMsg, error) {
+	if repoWithAgent.Repository == nil || URIToID(repoWithAgent.Repository.URI) == 0 {
+		return nil, fmt.Errorf("UpdateRepositoryWithAgent() missing the repository's URI")
+	}
+	id := URIToID(repoWithAgent.Repository.URI)
+	u := api.callURL(fmt.Sprintf("/repositories/with_agent/%d", id))
+	responseMsg, err := api.UpdateAPI(u.String(), repoWithAgent)
+	if err != nil {
+		return nil, fmt.Errorf("UpdateRepositoryWithAgent(%d) %w", id, err)
+	}
+	return responseMsg, nil
 }
 
 // ListRepositoryIDs returns the numeric ids for all respoistories via the ArchivesSpace REST API
@@ -308,14 +492,14 @@ func (api *ArchivesSpaceAPI) ListRepositoryIDs() ([]int, error) {
 	var ids []int
 	var repos []Repository
 
-	api.UpdateCallPath(`/repositories`)
-	content, err := api.API("GET", api.CallURL.String(), nil)
+	u := api.callURL(`/repositories`)
+	content, err := api.API("GET", u.String(), nil)
 	if err != nil {
-		return nil, fmt.Errorf("ListRepositoryIDs() %s", err)
+		return nil, fmt.Errorf("ListRepositoryIDs() %w", err)
 	}
 	err = json.Unmarshal(content, &repos)
 	if err != nil {
-		return nil, fmt.Errorf("ListRepositoryIDs() %s", err)
+		return nil, fmt.Errorf("ListRepositoryIDs() %w", err)
 	}
 	// Now I need to populate out id list
 	for i := range repos {
@@ -328,17 +512,17 @@ func (api *ArchivesSpaceAPI) ListRepositoryIDs() ([]int, error) {
 
 // ListRepositories returns a list of repositories available via the ArchivesSpace REST API
 func (api *ArchivesSpaceAPI) ListRepositories() ([]Repository, error) {
-	api.UpdateCallPath(`/repositories`)
+	u := api.callURL(`/repositories`)
 
-	content, err := api.API("GET", api.CallURL.String(), nil)
+	content, err := api.API("GET", u.String(), nil)
 	if err != nil {
-		return nil, fmt.Errorf("ListRepositories() %s", err)
+		return nil, fmt.Errorf("ListRepositories() %w", err)
 	}
 
 	var repos []Repository
 	err = json.Unmarshal(content, &repos)
 	if err != nil {
-		return nil, fmt.Errorf("ListRepositories() %s", err)
+		return nil, fmt.Errorf("ListRepositories() %w", err)
 	}
 	// Now I need to populate the repos[?].ID fields
 	for i := range repos {
@@ -349,19 +533,19 @@ func (api *ArchivesSpaceAPI) ListRepositories() ([]Repository, error) {
 
 // CreateAgent creates a Agent recod via the ArchivesSpace API
 func (api *ArchivesSpaceAPI) CreateAgent(aType string, agent *Agent) (*ResponseMsg, error) {
-	api.UpdateCallPath(fmt.Sprintf("/agents/%s", aType))
+	u := api.callURL(fmt.Sprintf("/agents/%s", aType))
 	agent.LockVersion = "0"
-	return api.CreateAPI(api.CallURL.String(), agent)
+	return api.CreateAPI(u.String(), agent)
 }
 
 // GetAgent return an Agent via the ArchivesSpace API
 func (api *ArchivesSpaceAPI) GetAgent(agentType string, agentID int) (*Agent, error) {
-	api.UpdateCallPath(fmt.Sprintf(`/agents/%s/%d`, agentType, agentID))
+	u := api.callURL(fmt.Sprintf(`/agents/%s/%d`, agentType, agentID))
 
 	agent := new(Agent)
-	err := api.GetAPI(api.CallURL.String(), agent)
+	err := api.GetAPI(u.String(), agent)
 	if err != nil {
-		return nil, fmt.Errorf("GetAgent(%s, %d) %s", agentType, agentID, err)
+		return nil, fmt.Errorf("GetAgent(%s, %d) %w", agentType, agentID, err)
 	}
 	agent.ID = URIToID(agent.URI)
 	return agent, nil
@@ -369,151 +553,201 @@ func (api *ArchivesSpaceAPI) GetAgent(agentType string, agentID int) (*Agent, er
 
 // UpdateAgent creates a Agent recod via the ArchivesSpace API
 func (api *ArchivesSpaceAPI) UpdateAgent(agent *Agent) (*ResponseMsg, error) {
-	api.UpdateCallPath(agent.URI)
-	return api.UpdateAPI(api.CallURL.String(), agent)
+	u := api.callURL(agent.URI)
+	return api.UpdateAPI(u.String(), agent)
+}
+
+// UpdateAgentWith fetches an Agent, passes it to fn for changes and saves it, refetching
+// and calling fn again after a lock_version conflict (see ConflictRetries)
+func (api *ArchivesSpaceAPI) UpdateAgentWith(agentType string, agentID int, fn func(*Agent) error) (*ResponseMsg, error) {
+	return UpdateWith(api, fmt.Sprintf("/agents/%s/%d", agentType, agentID), fn)
 }
 
 // DeleteAgent creates a Agent record via the ArchivesSpace API
 func (api *ArchivesSpaceAPI) DeleteAgent(agent *Agent) (*ResponseMsg, error) {
-	api.UpdateCallPath(agent.URI)
-	return api.DeleteAPI(api.CallURL.String(), agent)
+	u := api.callURL(agent.URI)
+	return api.DeleteAPI(u.String(), agent)
 }
 
 // ListAgents return an array of Agents via the ArchivesSpace API
-func (api *ArchivesSpaceAPI) ListAgents(agentType string) ([]int, error) {
-	api.UpdateCallPath(fmt.Sprintf(`/agents/%s`, agentType))
-	q := api.CallURL.Query()
+// Give modifiedSince to list only the records changed since then.
+func (api *ArchivesSpaceAPI) ListAgents(agentType string, modifiedSince ...time.Time) ([]int, error) {
+	u := api.callURL(fmt.Sprintf(`/agents/%s`, agentType))
+	q := u.Query()
 	q.Set("all_ids", "true")
-	api.CallURL.RawQuery = q.Encode()
-	return api.ListAPI(api.CallURL.String())
+	setModifiedSince(q, modifiedSince)
+	u.RawQuery = q.Encode()
+	return api.ListAPI(u.String())
 }
 
 // CreateAccession creates a new Accession record in a Repository
 func (api *ArchivesSpaceAPI) CreateAccession(repoID int, accession *Accession) (*ResponseMsg, error) {
-	api.UpdateCallPath(fmt.Sprintf("/repositories/%d/accessions", repoID))
+	u := api.callURL(fmt.Sprintf("/repositories/%d/accessions", repoID))
 	accession.LockVersion = "0"
-	return api.CreateAPI(api.CallURL.String(), accession)
+	return api.CreateAPI(u.String(), accession)
 }
 
 // GetAccession retrieves an Accession record from a Repository
 func (api *ArchivesSpaceAPI) GetAccession(repoID, accessionID int) (*Accession, error) {
-	api.UpdateCallPath(fmt.Sprintf("/repositories/%d/accessions/%d", repoID, accessionID))
+	u := api.callURL(fmt.Sprintf("/repositories/%d/accessions/%d", repoID, accessionID))
 
 	accession := new(Accession)
-	err := api.GetAPI(api.CallURL.String(), accession)
+	err := api.GetAPI(u.String(), accession)
 	if err != nil {
-		return nil, fmt.Errorf("GetAccession(%d, %d) %s", repoID, accessionID, err)
+		return nil, fmt.Errorf("GetAccession(%d, %d) %w", repoID, accessionID, err)
 	}
 	p := strings.Split(accession.URI, "/")
 	accession.ID, err = strconv.Atoi(p[len(p)-1])
 	if err != nil {
-		return accession, fmt.Errorf("Accession ID parse error %d %s", accession.ID, err)
+		return accession, fmt.Errorf("Accession ID parse error %d %w", accession.ID, err)
 	}
 	return accession, nil
 }
 
 // UpdateAccession updates an existing Accession record in a Repository
 func (api *ArchivesSpaceAPI) UpdateAccession(accession *Accession) (*ResponseMsg, error) {
-	api.UpdateCallPath(accession.URI)
-	return api.UpdateAPI(api.CallURL.String(), accession)
+	u := api.callURL(accession.URI)
+	return api.UpdateAPI(u.String(), accession)
+}
+
+// UpdateAccessionWith fetches an Accession, passes it to fn for changes and saves it,
+// refetching and calling fn again after a lock_version conflict (see ConflictRetries).
+// Other record types can use UpdateWith directly.
+func (api *ArchivesSpaceAPI) UpdateAccessionWith(repoID, accessionID int, fn func(*Accession) error) (*ResponseMsg, error) {
+	return UpdateWith(api, fmt.Sprintf("/repositories/%d/accessions/%d", repoID, accessionID), fn)
 }
 
 // DeleteAccession deleted an Accession record from a Repository
 func (api *ArchivesSpaceAPI) DeleteAccession(accession *Accession) (*ResponseMsg, error) {
-	api.UpdateCallPath(accession.URI)
-	return api.DeleteAPI(api.CallURL.String(), accession)
+	u := api.callURL(accession.URI)
+	return api.DeleteAPI(u.String(), accession)
 }
 
 // ListAccessions return a list of Accession IDs from a Repository
-func (api *ArchivesSpaceAPI) ListAccessions(repositoryID int) ([]int, error) {
-	api.UpdateCallPath(fmt.Sprintf(`/repositories/%d/accessions`, repositoryID))
-	q := api.CallURL.Query()
+// Give modifiedSince to list only the records changed since then.
+func (api *ArchivesSpaceAPI) ListAccessions(repositoryID int, modifiedSince ...time.Time) ([]int, error) {
+	u := api.callURL(fmt.Sprintf(`/repositories/%d/accessions`, repositoryID))
+	q := u.Query()
 	q.Set("all_ids", "true")
-	api.CallURL.RawQuery = q.Encode()
-	return api.ListAPI(api.CallURL.String())
+	setModifiedSince(q, modifiedSince)
+	u.RawQuery = q.Encode()
+	return api.ListAPI(u.String())
 }
 
-// CreateSubject creates a new Subject in ArchivesSpace
+// CreateSubject creates a new Subject in ArchivesSpace. On success the subject's URI,
+// ID and lock version are updated from the response.
 func (api *ArchivesSpaceAPI) CreateSubject(subject *Subject) (*ResponseMsg, error) {
-	api.UpdateCallPath("/subjects")
+	u := api.callURL("/subjects")
 	subject.LockVersion = "0"
-	return api.CreateAPI(api.CallURL.String(), subject)
+	responseMsg, err := api.CreateAPI(u.String(), subject)
+	if err != nil {
+		return nil, fmt.Errorf("CreateSubject(%q) %w", subject.Title, err)
+	}
+	subject.SetURI(responseMsg.URI)
+	subject.LockVersion = responseMsg.LockVersion
+	return responseMsg, nil
 }
 
 // GetSubject retrieves a subject record from ArchivesSpace
 func (api *ArchivesSpaceAPI) GetSubject(subjectID int) (*Subject, error) {
-	api.UpdateCallPath(fmt.Sprintf("/subjects/%d", subjectID))
+	u := api.callURL(fmt.Sprintf("/subjects/%d", subjectID))
 
 	subject := new(Subject)
-	err := api.GetAPI(api.CallURL.String(), subject)
-	p := strings.Split(subject.URI, "/")
-	subject.ID, err = strconv.Atoi(p[len(p)-1])
-	if err != nil {
-		return subject, fmt.Errorf("Accession ID parse error %d %s", subject.ID, err)
+	if err := api.GetAPI(u.String(), subject); err != nil {
+		return nil, fmt.Errorf("GetSubject(%d) %w", subjectID, err)
 	}
+	subject.ID = URIToID(subject.URI)
 	return subject, nil
 }
 
 // UpdateSubject updates an existing subject record in ArchivesSpace
 func (api *ArchivesSpaceAPI) UpdateSubject(subject *Subject) (*ResponseMsg, error) {
-	api.UpdateCallPath(subject.URI)
-	return api.UpdateAPI(api.CallURL.String(), subject)
+	u := api.callURL(subject.URI)
+	return api.UpdateAPI(u.String(), subject)
 }
 
 // DeleteSubject deletes a subject from ArchivesSpace
 func (api *ArchivesSpaceAPI) DeleteSubject(subject *Subject) (*ResponseMsg, error) {
-	api.UpdateCallPath(subject.URI)
-	return api.DeleteAPI(api.CallURL.String(), subject)
+	u := api.callURL(subject.URI)
+	return api.DeleteAPI(u.String(), subject)
 }
 
 // ListSubjects return a list of Subject IDs from ArchivesSpace
-func (api *ArchivesSpaceAPI) ListSubjects() ([]int, error) {
-	api.UpdateCallPath(`/subjects`)
-	q := api.CallURL.Query()
+// Give modifiedSince to list only the records changed since then.
+func (api *ArchivesSpaceAPI) ListSubjects(modifiedSince ...time.Time) ([]int, error) {
+	u := api.callURL(`/subjects`)
+	q := u.Query()
 	q.Set("all_ids", "true")
-	api.CallURL.RawQuery = q.Encode()
-	return api.ListAPI(api.CallURL.String())
+	setModifiedSince(q, modifiedSince)
+	u.RawQuery = q.Encode()
+	return api.ListAPI(u.String())
 }
 
-// CreateVocabulary creates a new Vocabulary in ArchivesSpace
+// CreateVocabulary creates a new Vocabulary in ArchivesSpace. On success the
+// vocabulary's URI, ID and lock version are updated from the response.
 func (api *ArchivesSpaceAPI) CreateVocabulary(vocabulary *Vocabulary) (*ResponseMsg, error) {
-	api.UpdateCallPath("/vocabularies")
+	u := api.callURL("/vocabularies")
+	vocabulary.JSONModelType = "vocabulary"
 	vocabulary.LockVersion = "0"
-	return api.CreateAPI(api.CallURL.String(), vocabulary)
+	responseMsg, err := api.CreateAPI(u.String(), vocabulary)
+	if err != nil {
+		return nil, fmt.Errorf("CreateVocabulary(%q) %w", vocabulary.RefID, err)
+	}
+	vocabulary.SetURI(responseMsg.URI)
+	vocabulary.LockVersion = responseMsg.LockVersion
+	return responseMsg, nil
 }
 
 // GetVocabulary retrieves a vocabulary record from ArchivesSpace
 func (api *ArchivesSpaceAPI) GetVocabulary(vocabularyID int) (*Vocabulary, error) {
-	api.UpdateCallPath(fmt.Sprintf("/vocabularies/%d", vocabularyID))
+	u := api.callURL(fmt.Sprintf("/vocabularies/%d", vocabularyID))
 
 	vocabulary := new(Vocabulary)
-	err := api.GetAPI(api.CallURL.String(), vocabulary)
-	p := strings.Split(vocabulary.URI, "/")
-	vocabulary.ID, err = strconv.Atoi(p[len(p)-1])
-	if err != nil {
-		return vocabulary, fmt.Errorf("Accession ID parse error %d %s", vocabulary.ID, err)
+	if err := api.GetAPI(u.String(), vocabulary); err != nil {
+		return nil, fmt.Errorf("GetVocabulary(%d) %w", vocabularyID, err)
 	}
+	vocabulary.ID = URIToID(vocabulary.URI)
 	return vocabulary, nil
 }
 
+// GetVocabularyByRefID retrieves a vocabulary by its ref_id (e.g. "lcsh" or the
+// default "global_vocab") so its URI doesn't need to be hardcoded when creating subjects
+func (api *ArchivesSpaceAPI) GetVocabularyByRefID(refID string) (*Vocabulary, error) {
+	u := api.callURL("/vocabularies")
+	q := u.Query()
+	q.Set("ref_id", refID)
+	u.RawQuery = q.Encode()
+	vocabularies := []*Vocabulary{}
+	if err := api.GetAPI(u.String(), &vocabularies); err != nil {
+		return nil, fmt.Errorf("GetVocabularyByRefID(%q) %w", refID, err)
+	}
+	for _, vocabulary := range vocabularies {
+		if vocabulary.RefID == refID {
+			vocabulary.ID = URIToID(vocabulary.URI)
+			return vocabulary, nil
+		}
+	}
+	return nil, fmt.Errorf("GetVocabularyByRefID(%q) not found", refID)
+}
+
 // UpdateVocabulary updates an existing vocabulary record in ArchivesSpace
 func (api *ArchivesSpaceAPI) UpdateVocabulary(vocabulary *Vocabulary) (*ResponseMsg, error) {
-	api.UpdateCallPath(vocabulary.URI)
-	return api.UpdateAPI(api.CallURL.String(), vocabulary)
+	u := api.callURL(vocabulary.URI)
+	return api.UpdateAPI(u.String(), vocabulary)
 }
 
 // DeleteVocabulary deletes a vocabulary from ArchivesSpace
 func (api *ArchivesSpaceAPI) DeleteVocabulary(vocabulary *Vocabulary) (*ResponseMsg, error) {
-	api.UpdateCallPath(vocabulary.URI)
-	return api.DeleteAPI(api.CallURL.String(), vocabulary)
+	u := api.callURL(vocabulary.URI)
+	return api.DeleteAPI(u.String(), vocabulary)
 }
 
 // ListVocabularies return a list of Vocabulary IDs from ArchivesSpace
 func (api *ArchivesSpaceAPI) ListVocabularies() ([]int, error) {
-	api.UpdateCallPath(`/vocabularies`)
-	content, err := api.API("GET", api.CallURL.String(), nil)
+	u := api.callURL(`/vocabularies`)
+	content, err := api.API("GET", u.String(), nil)
 	if err != nil {
-		return nil, fmt.Errorf("ListVocabularies() %s", err)
+		return nil, fmt.Errorf("ListVocabularies() %w", err)
 	}
 	var (
 		ids          []int
@@ -521,33 +755,42 @@ func (api *ArchivesSpaceAPI) ListVocabularies() ([]int, error) {
 	)
 	err = json.Unmarshal([]byte(content), &vocabularies)
 	if err != nil {
-		return nil, fmt.Errorf("ListVocabularies() %s", err)
+		return nil, fmt.Errorf("ListVocabularies() %w", err)
 	}
 	for _, val := range vocabularies {
 		p := strings.Split(val.URI, "/")
 		id, err := strconv.Atoi(p[len(p)-1])
 		if err != nil {
-			return nil, fmt.Errorf("ListVocabularies() %s", err)
+			return nil, fmt.Errorf("ListVocabularies() %w", err)
 		}
 		ids = append(ids, id)
 	}
 	return ids, nil
 }
 
-// CreateTerm creates a new Term in ArchivesSpace
+// CreateTerm creates a new Term in ArchivesSpace. On success the term's URI,
+// ID and lock version are updated from the response.
 func (api *ArchivesSpaceAPI) CreateTerm(vocabularyID int, term *Term) (*ResponseMsg, error) {
-	api.UpdateCallPath(fmt.Sprintf("/vocabularies/%d/terms", vocabularyID))
+	u := api.callURL(fmt.Sprintf("/vocabularies/%d/terms", vocabularyID))
+	term.JSONModelType = "term"
 	term.LockVersion = "0"
-	return api.CreateAPI(api.CallURL.String(), term)
+	if term.Vocabulary == "" {
+		term.Vocabulary = fmt.Sprintf("/vocabularies/%d", vocabularyID)
+	}
+	responseMsg, err := api.CreateAPI(u.String(), term)
+	if err != nil {
+		return nil, fmt.Errorf("CreateTerm(%d, %q) %w", vocabularyID, term.Term, err)
+	}
+	term.SetURI(responseMsg.URI)
+	term.LockVersion = responseMsg.LockVersion
+	return responseMsg, nil
 }
 
 // GetTerm retrieves a term record from ArchivesSpace
 func (api *ArchivesSpaceAPI) GetTerm(vocabularyID, termID int) (*Term, error) {
-	api.UpdateCallPath(fmt.Sprintf("/vocabularies/%d/terms", vocabularyID))
-
 	terms, err := api.ListTerms(vocabularyID)
 	if err != nil {
-		return nil, fmt.Errorf("GetTerm(%d, %d) %s", vocabularyID, termID, err)
+		return nil, fmt.Errorf("GetTerm(%d, %d) %w", vocabularyID, termID, err)
 	}
 	for _, term := range terms {
 		term.ID = URIToID(term.URI)
@@ -555,36 +798,52 @@ func (api *ArchivesSpaceAPI) GetTerm(vocabularyID, termID int) (*Term, error) {
 			return term, nil
 		}
 	}
-	return nil, nil
+	return nil, fmt.Errorf("GetTerm(%d, %d) not found", vocabularyID, termID)
+}
+
+// SearchTerms returns the terms, across all vocabularies, matching query
+func (api *ArchivesSpaceAPI) SearchTerms(query string) ([]*Term, error) {
+	u := api.callURL("/terms")
+	q := u.Query()
+	q.Set("q", query)
+	u.RawQuery = q.Encode()
+	terms := []*Term{}
+	if err := api.GetAPI(u.String(), &terms); err != nil {
+		return nil, fmt.Errorf("SearchTerms(%q) %w", query, err)
+	}
+	for _, term := range terms {
+		term.ID = URIToID(term.URI)
+	}
+	return terms, nil
 }
 
 // UpdateTerm updates an existing term record in ArchivesSpace
 func (api *ArchivesSpaceAPI) UpdateTerm(term *Term) (*ResponseMsg, error) {
-	api.UpdateCallPath(term.URI)
-	return api.UpdateAPI(api.CallURL.String(), term)
+	u := api.callURL(term.URI)
+	return api.UpdateAPI(u.String(), term)
 }
 
 // DeleteTerm deletes a term from ArchivesSpace
 func (api *ArchivesSpaceAPI) DeleteTerm(term *Term) (*ResponseMsg, error) {
-	api.UpdateCallPath(term.URI)
-	return api.DeleteAPI(api.CallURL.String(), term)
+	u := api.callURL(term.URI)
+	return api.DeleteAPI(u.String(), term)
 }
 
 // ListTermIDs return a list of Term IDs from ArchivesSpace
 func (api *ArchivesSpaceAPI) ListTermIDs(vocabularyID int) ([]int, error) {
-	api.UpdateCallPath(fmt.Sprintf(`/vocabularies/%d/terms`, vocabularyID))
-	q := api.CallURL.Query()
+	u := api.callURL(fmt.Sprintf(`/vocabularies/%d/terms`, vocabularyID))
+	q := u.Query()
 	q.Set("all_ids", "true")
-	api.CallURL.RawQuery = q.Encode()
-	data, err := api.API("GET", api.CallURL.String(), nil)
+	u.RawQuery = q.Encode()
+	data, err := api.API("GET", u.String(), nil)
 	if err != nil {
-		return nil, fmt.Errorf("Can't get Terms for vocabulary %d, %s", vocabularyID, err)
+		return nil, fmt.Errorf("Can't get Terms for vocabulary %d, %w", vocabularyID, err)
 	}
 	// Now Unpack list of terms into a []Term
 	var terms []*Term
 	err = json.Unmarshal(data, &terms)
 	if err != nil {
-		return nil, fmt.Errorf("Can't decode terms for vocabularly %d, %s", vocabularyID, err)
+		return nil, fmt.Errorf("Can't decode terms for vocabularly %d, %w", vocabularyID, err)
 	}
 	var ids []int
 	for _, term := range terms {
@@ -600,18 +859,18 @@ func (api *ArchivesSpaceAPI) ListTermIDs(vocabularyID int) ([]int, error) {
 
 // ListTerms return a list of Term IDs from ArchivesSpace
 func (api *ArchivesSpaceAPI) ListTerms(vocabularyID int) ([]*Term, error) {
-	api.UpdateCallPath(fmt.Sprintf(`/vocabularies/%d/terms`, vocabularyID))
-	q := api.CallURL.Query()
+	u := api.callURL(fmt.Sprintf(`/vocabularies/%d/terms`, vocabularyID))
+	q := u.Query()
 	q.Set("all_ids", "true")
-	api.CallURL.RawQuery = q.Encode()
-	data, err := api.API("GET", api.CallURL.String(), nil)
+	u.RawQuery = q.Encode()
+	data, err := api.API("GET", u.String(), nil)
 	if err != nil {
-		return nil, fmt.Errorf("Can't get Terms for vocabulary %d, %s", vocabularyID, err)
+		return nil, fmt.Errorf("Can't get Terms for vocabulary %d, %w", vocabularyID, err)
 	}
 	// Now Unpack list of terms into a []Term
 	var terms []*Term
 	if err := json.Unmarshal(data, &terms); err != nil {
-		return nil, fmt.Errorf("Can't decode terms for vocabularly %d, %s", vocabularyID, err)
+		return nil, fmt.Errorf("Can't decode terms for vocabularly %d, %w", vocabularyID, err)
 	}
 	for _, term := range terms {
 		//FIXME: Get the Term id and set terms[i].ID to that value.
@@ -621,78 +880,99 @@ func (api *ArchivesSpaceAPI) ListTerms(vocabularyID int) ([]*Term, error) {
 	return terms, nil
 }
 
-// CreateLocation creates a new Location in ArchivesSpace
+// CreateLocation creates a new Location in ArchivesSpace. On success the location's
+// URI, ID and lock version are updated from the response.
 func (api *ArchivesSpaceAPI) CreateLocation(location *Location) (*ResponseMsg, error) {
-	api.UpdateCallPath(fmt.Sprintf("/locations"))
+	u := api.callURL(fmt.Sprintf("/locations"))
+	location.JSONModelType = "location"
 	location.LockVersion = "0"
-	return api.CreateAPI(api.CallURL.String(), location)
+	responseMsg, err := api.CreateAPI(u.String(), location)
+	if err != nil {
+		return nil, fmt.Errorf("CreateLocation(%q) %w", location.Title, err)
+	}
+	location.SetURI(responseMsg.URI)
+	location.LockVersion = responseMsg.LockVersion
+	return responseMsg, nil
 }
 
 // GetLocation retrieves a location record from ArchivesSpace
 func (api *ArchivesSpaceAPI) GetLocation(ID int) (*Location, error) {
-	api.UpdateCallPath(fmt.Sprintf("/locations/%d", ID))
+	u := api.callURL(fmt.Sprintf("/locations/%d", ID))
 
 	location := new(Location)
-	err := api.GetAPI(api.CallURL.String(), location)
-	if err != nil {
-		return nil, fmt.Errorf("GetLocation(%d) %s", ID, err)
-	}
-	p := strings.Split(location.URI, "/")
-	location.ID, err = strconv.Atoi(p[len(p)-1])
+	err := api.GetAPI(u.String(), location)
 	if err != nil {
-		return location, fmt.Errorf("Accession ID parse error %d %s", location.ID, err)
+		return nil, fmt.Errorf("GetLocation(%d) %w", ID, err)
 	}
+	location.ID = URIToID(location.URI)
 	return location, nil
 }
 
 // UpdateLocation updates an existing location record in ArchivesSpace
 func (api *ArchivesSpaceAPI) UpdateLocation(location *Location) (*ResponseMsg, error) {
-	api.UpdateCallPath(location.URI)
-	return api.UpdateAPI(api.CallURL.String(), location)
+	u := api.callURL(location.URI)
+	return api.UpdateAPI(u.String(), location)
 }
 
 // DeleteLocation deletes a location from ArchivesSpace
 func (api *ArchivesSpaceAPI) DeleteLocation(location *Location) (*ResponseMsg, error) {
-	api.UpdateCallPath(location.URI)
-	return api.DeleteAPI(api.CallURL.String(), location)
+	u := api.callURL(location.URI)
+	return api.DeleteAPI(u.String(), location)
 }
 
 // ListLocations return a list of Location IDs from ArchivesSpace
-func (api *ArchivesSpaceAPI) ListLocations() ([]int, error) {
-	api.UpdateCallPath(fmt.Sprintf(`/locations`))
-	q := api.CallURL.Query()
+// Give modifiedSince to list only the records changed since then.
+func (api *ArchivesSpaceAPI) ListLocations(modifiedSince ...time.Time) ([]int, error) {
+	u := api.callURL(fmt.Sprintf(`/locations`))
+	q := u.Query()
 	q.Set("all_ids", "true")
-	api.CallURL.RawQuery = q.Encode()
-	return api.ListAPI(api.CallURL.String())
+	setModifiedSince(q, modifiedSince)
+	u.RawQuery = q.Encode()
+	return api.ListAPI(u.String())
 }
 
-// CreateDigitalObject - return a new digital object
+// CreateDigitalObject - return a new digital object. On success obj's URI, ID and lock
+// version are updated from the response so it can be updated without fetching it again.
+// A digital object is linked to an archival object or accession by adding an instance
+// referencing it to that record, see AddDigitalObjectInstance.
 func (api *ArchivesSpaceAPI) CreateDigitalObject(repoID int, obj *DigitalObject) (*ResponseMsg, error) {
-	// NOTE: attempt extract accession ID for the edge of importing a digital object as opposed to a clean create
-	uriPrefix := fmt.Sprintf("/repositories/%d/digital_objects", repoID)
+	u := api.callURL(fmt.Sprintf("/repositories/%d/digital_objects", repoID))
 	obj.JSONModelType = "digital_object"
 	obj.LockVersion = "0"
-	api.UpdateCallPath(uriPrefix)
-	// We need to create the object
-	responseMsg, responseErr := api.CreateAPI(api.CallURL.String(), obj)
-	if responseErr != nil || responseMsg.Status != "created" {
-		return responseMsg, responseErr
-	}
-	// NOTE: In the case we're importing a digital_object from another ArchivesSpace instance.
-	// We need to correct the URI assignment, lock version info and attach to the accession of necessary
-	obj.URI = responseMsg.URI
+	responseMsg, err := api.CreateAPI(u.String(), obj)
+	if err != nil {
+		return nil, fmt.Errorf("CreateDigitalObject(%d) %w", repoID, err)
+	}
+	obj.SetURI(responseMsg.URI)
 	obj.LockVersion = responseMsg.LockVersion
-	return responseMsg, responseErr
+	return responseMsg, nil
+}
+
+// AddDigitalObjectInstance links the digital object at doURI to the archival object
+// at aoURI by adding a digital object instance to the archival object
+func (api *ArchivesSpaceAPI) AddDigitalObjectInstance(aoURI, doURI string) (*ResponseMsg, error) {
+	return UpdateWith(api, aoURI, func(obj *ArchivalObject) error {
+		for _, instance := range obj.Instances {
+			if instance.DigitalObject["ref"] == doURI {
+				return nil
+			}
+		}
+		obj.Instances = append(obj.Instances, &Instance{
+			InstanceType:  "digital_object",
+			DigitalObject: Ref(doURI),
+		})
+		return nil
+	})
 }
 
 // GetDigitalObject - return a given digital object
 func (api *ArchivesSpaceAPI) GetDigitalObject(repoID, objID int) (*DigitalObject, error) {
-	api.UpdateCallPath(fmt.Sprintf("/repositories/%d/digital_objects/%d", repoID, objID))
+	u := api.callURL(fmt.Sprintf("/repositories/%d/digital_objects/%d", repoID, objID))
 
 	obj := new(DigitalObject)
-	err := api.GetAPI(api.CallURL.String(), obj)
+	err := api.GetAPI(u.String(), obj)
 	if err != nil {
-		return nil, fmt.Errorf("GetDigitalObject() %s, error, %s", api.CallURL.String(), err)
+		return nil, fmt.Errorf("GetDigitalObject() %s, error, %w", u.String(), err)
 	}
 	obj.ID = URIToID(obj.URI)
 	return obj, nil
@@ -700,75 +980,139 @@ func (api *ArchivesSpaceAPI) GetDigitalObject(repoID, objID int) (*DigitalObject
 
 // UpdateDigitalObject - returns an updated digital
 func (api *ArchivesSpaceAPI) UpdateDigitalObject(obj *DigitalObject) (*ResponseMsg, error) {
-	api.UpdateCallPath(obj.URI)
-	return api.UpdateAPI(api.CallURL.String(), obj)
+	u := api.callURL(obj.URI)
+	return api.UpdateAPI(u.String(), obj)
 }
 
 // DeleteDigitalObject - return the results of deleting a digital object
 func (api *ArchivesSpaceAPI) DeleteDigitalObject(obj *DigitalObject) (*ResponseMsg, error) {
-	api.UpdateCallPath(obj.URI)
+	u := api.callURL(obj.URI)
 	//FIXME: If we're Updating we may need to unlink existing accessions
-	return api.DeleteAPI(api.CallURL.String(), obj)
+	return api.DeleteAPI(u.String(), obj)
 }
 
 // ListDigitalObjects - return a list of digital object ids
-func (api *ArchivesSpaceAPI) ListDigitalObjects(repoID int) ([]int, error) {
-	api.UpdateCallPath(fmt.Sprintf(`/repositories/%d/digital_objects`, repoID))
-	q := api.CallURL.Query()
+// Give modifiedSince to list only the records changed since then.
+func (api *ArchivesSpaceAPI) ListDigitalObjects(repoID int, modifiedSince ...time.Time) ([]int, error) {
+	u := api.callURL(fmt.Sprintf(`/repositories/%d/digital_objects`, repoID))
+	q := u.Query()
 	q.Set("all_ids", "true")
-	api.CallURL.RawQuery = q.Encode()
-	return api.ListAPI(api.CallURL.String())
+	setModifiedSince(q, modifiedSince)
+	u.RawQuery = q.Encode()
+	return api.ListAPI(u.String())
 }
 
-// CreateResource - return a new resource
+// CreateResource - return a new resource. On success obj's URI, ID and lock version
+// are updated from the response so it can be updated without fetching it again.
 func (api *ArchivesSpaceAPI) CreateResource(repoID int, obj *Resource) (*ResponseMsg, error) {
-	// NOTE: attempt extract accession ID for the edge of importing a digital object as opposed to a clean create
-	uriPrefix := fmt.Sprintf("/repositories/%d/digital_objects", repoID)
-	obj.JSONModelType = "digital_object"
+	u := api.callURL(fmt.Sprintf("/repositories/%d/resources", repoID))
+	obj.JSONModelType = "resource"
 	obj.LockVersion = "0"
-	api.UpdateCallPath(uriPrefix)
-	// We need to create the object
-	responseMsg, responseErr := api.CreateAPI(api.CallURL.String(), obj)
-	if responseErr != nil || responseMsg.Status != "created" {
-		return responseMsg, responseErr
-	}
-	// NOTE: In the case we're importing a digital_object from another ArchivesSpace instance.
-	// We need to correct the URI assignment, lock version info and attach to the accession of necessary
-	obj.URI = responseMsg.URI
+	responseMsg, err := api.CreateAPI(u.String(), obj)
+	if err != nil {
+		return nil, fmt.Errorf("CreateResource(%d) %w", repoID, err)
+	}
+	obj.SetURI(responseMsg.URI)
 	obj.LockVersion = responseMsg.LockVersion
-	return responseMsg, responseErr
+	return responseMsg, nil
 }
 
 // GetResource - return a given resource
 func (api *ArchivesSpaceAPI) GetResource(repoID, objID int) (*Resource, error) {
-	api.UpdateCallPath(fmt.Sprintf("/repositories/%d/resources/%d", repoID, objID))
+	u := api.callURL(fmt.Sprintf("/repositories/%d/resources/%d", repoID, objID))
 
 	obj := new(Resource)
-	err := api.GetAPI(api.CallURL.String(), obj)
+	err := api.GetAPI(u.String(), obj)
 	if err != nil {
-		return nil, fmt.Errorf("GetResource() %s, error, %s", api.CallURL.String(), err)
+		return nil, fmt.Errorf("GetResource() %s, error, %w", u.String(), err)
 	}
-	//obj.ID = URIToID(obj.URI)
+	obj.ID = URIToID(obj.URI)
 	return obj, nil
 }
 
 // UpdateResource - returns an updated resource
 func (api *ArchivesSpaceAPI) UpdateResource(obj *Resource) (*ResponseMsg, error) {
-	api.UpdateCallPath(obj.URI)
-	return api.UpdateAPI(api.CallURL.String(), obj)
+	u := api.callURL(obj.URI)
+	return api.UpdateAPI(u.String(), obj)
 }
 
 // DeleteResource - return the results of deleting a resource
 func (api *ArchivesSpaceAPI) DeleteResource(obj *Resource) (*ResponseMsg, error) {
-	api.UpdateCallPath(obj.URI)
-	return api.DeleteAPI(api.CallURL.String(), obj)
+	u := api.callURL(obj.URI)
+	return api.DeleteAPI(u.String(), obj)
+}
+
+// PublishResource publishes a resource and all of its components in one request,
+// rather than updating each archival object
+func (api *ArchivesSpaceAPI) PublishResource(obj *Resource) (*ResponseMsg, error) {
+	u := api.callURL(obj.URI + "/publish")
+	responseMsg, err := api.UpdateAPI(u.String(), nil)
+	if err != nil {
+		return nil, fmt.Errorf("PublishResource(%q) %w", obj.URI, err)
+	}
+	obj.Publish = true
+	return responseMsg, nil
 }
 
 // ListResources - return a list of resource ids
-func (api *ArchivesSpaceAPI) ListResources(repoID int) ([]int, error) {
-	api.UpdateCallPath(fmt.Sprintf(`/repositories/%d/resources`, repoID))
-	q := api.CallURL.Query()
+// Give modifiedSince to list only the records changed since then.
+func (api *ArchivesSpaceAPI) ListResources(repoID int, modifiedSince ...time.Time) ([]int, error) {
+	u := api.callURL(fmt.Sprintf(`/repositories/%d/resources`, repoID))
+	q := u.Query()
 	q.Set("all_ids", "true")
-	api.CallURL.RawQuery = q.Encode()
-	return api.ListAPI(api.CallURL.String())
+	setModifiedSince(q, modifiedSince)
+	u.RawQuery = q.Encode()
+	return api.ListAPI(u.String())
+}
+
+// SearchResultsPage is a page of results from the ArchivesSpace search API with each
+// hit as an Object.
+//
+// Deprecated: use SearchRecords which returns SearchResults, each hit's Fields holds
+// the same Object.
+type SearchResultsPage struct {
+	FirstPage   int      `json:"first_page"`
+	LastPage    int      `json:"last_page"`
+	ThisPage    int      `json:"this_page"`
+	OffsetFirst int      `json:"offset_first"`
+	OffsetLast  int      `json:"offset_last"`
+	TotalHits   int      `json:"total_hits"`
+	Results     []Object `json:"results"`
+}
+
+// Search runs a query against the ArchivesSpace search API returning the requested page.
+// If repoID is zero the search is run across all repositories. Types optionally limits
+// the search to the record types listed (e.g. accession, resource).
+//
+// Deprecated: use SearchRecords with NewSearchRequest(q).InRepository(repoID).OfType(types...)
+func (api *ArchivesSpaceAPI) Search(repoID int, q string, types []string, page int) (*SearchResultsPage, error) {
+	return api.SearchWithFilters(repoID, q, types, nil, page)
+}
+
+// SearchWithFilters is like Search but also restricts the results to records whose
+// fields match the filter terms (e.g. {"primary_type": "accession"}).
+//
+// Deprecated: use SearchRecords with a SearchRequest's Filter
+func (api *ArchivesSpaceAPI) SearchWithFilters(repoID int, q string, types []string, filters map[string]string, page int) (*SearchResultsPage, error) {
+	req := NewSearchRequest(q).InRepository(repoID).OfType(types...).WithPage(page)
+	for field, value := range filters {
+		req.Filter(field, value)
+	}
+	results, err := api.SearchRecords(req)
+	if err != nil {
+		return nil, err
+	}
+	p := &SearchResultsPage{
+		FirstPage:   results.FirstPage,
+		LastPage:    results.LastPage,
+		ThisPage:    results.ThisPage,
+		OffsetFirst: results.OffsetFirst,
+		OffsetLast:  results.OffsetLast,
+		TotalHits:   results.TotalHits,
+		Results:     []Object{},
+	}
+	for _, hit := range results.Results {
+		p.Results = append(p.Results, hit.Fields)
+	}
+	return p, nil
 }
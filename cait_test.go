@@ -20,11 +20,25 @@
 package cait
 
 import (
+	"bytes"
+	"compress/gzip"
+	"crypto/sha256"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/json"
 	"fmt"
+	"io"
+	"io/ioutil"
 	"log"
+	"net/http"
+	"net/http/httptest"
 	"net/url"
 	"os"
+	"path"
+	"sort"
+	"strconv"
 	"strings"
+	"sync"
 	"testing"
 	"time"
 )
@@ -579,3 +593,4237 @@ func TestVocabularies(t *testing.T) {
 // }
 
 //FIXME: Needs tests for Subject, Term, Vocalary, User, Search
+
+func TestCancelJob(t *testing.T) {
+	calls := []string{}
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls = append(calls, r.Method+" "+r.URL.Path)
+		if r.URL.Path == "/repositories/2/jobs/7/cancel" {
+			fmt.Fprintf(w, `{"status": "Updated", "id": 7}`)
+			return
+		}
+		w.WriteHeader(http.StatusBadRequest)
+		fmt.Fprintf(w, `{"error": "Job is not running"}`)
+	}))
+	defer ts.Close()
+	t.Setenv("CAIT_API_URL", ts.URL)
+
+	api := New(ts.URL, "", "", "")
+	msg, err := api.CancelJob(2, 7)
+	if err != nil {
+		t.Fatalf("CancelJob(2, 7) %s", err)
+	}
+	if msg.Status != "Updated" || msg.ID != 7 {
+		t.Errorf("CancelJob(2, 7) returned %+v", msg)
+	}
+	if len(calls) != 1 || calls[0] != "POST /repositories/2/jobs/7/cancel" {
+		t.Errorf("CancelJob(2, 7) expected one POST to the cancel endpoint, got %v", calls)
+	}
+	if msg, err := api.CancelJob(2, 8); err == nil && msg.Error == nil {
+		t.Errorf("CancelJob(2, 8) expected the server error, got %+v", msg)
+	}
+}
+
+func TestApplyToSearch(t *testing.T) {
+	posted := map[string]Object{}
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.URL.Path == "/repositories/2/search" && r.URL.Query().Get("page") == "1":
+			fmt.Fprintf(w, `{"first_page": 1, "last_page": 2, "this_page": 1, "total_hits": 3, "results": [
+{"uri": "/repositories/2/accessions/1"}, {"uri": "/repositories/2/accessions/2"}]}`)
+		case r.URL.Path == "/repositories/2/search" && r.URL.Query().Get("page") == "2":
+			fmt.Fprintf(w, `{"first_page": 1, "last_page": 2, "this_page": 2, "total_hits": 3, "results": [
+{"uri": "/repositories/2/accessions/3"}]}`)
+		case r.Method == "GET" && r.URL.Path == "/repositories/2/accessions/1":
+			fmt.Fprintf(w, `{"uri": "/repositories/2/accessions/1", "title": "Papers", "lock_version": 0}`)
+		case r.Method == "GET" && r.URL.Path == "/repositories/2/accessions/2":
+			fmt.Fprintf(w, `{"uri": "/repositories/2/accessions/2", "title": "Papers, 1920-1960", "lock_version": 0}`)
+		case r.Method == "GET" && r.URL.Path == "/repositories/2/accessions/3":
+			fmt.Fprintf(w, `{"uri": "/repositories/2/accessions/3", "lock_version": 0}`)
+		case r.Method == "POST" && r.URL.Path == "/repositories/2/accessions/1":
+			obj := Object{}
+			json.NewDecoder(r.Body).Decode(&obj)
+			posted[r.URL.Path] = obj
+			fmt.Fprintf(w, `{"status": "Updated", "id": 1, "lock_version": 1}`)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer ts.Close()
+	t.Setenv("CAIT_API_URL", ts.URL)
+
+	transform := func(obj Object) (bool, error) {
+		title, ok := obj["title"].(string)
+		if ok == false {
+			return false, fmt.Errorf("%v has no title", obj["uri"])
+		}
+		if title == "Papers, 1920-1960" {
+			return false, nil
+		}
+		obj["title"] = "Papers, 1920-1960"
+		return true, nil
+	}
+	api := New(ts.URL, "", "", "")
+	opts := &BatchEditOptions{RepoID: 2, Types: []string{"accession"}, DryRun: true}
+	report, err := api.ApplyToSearch("Papers", transform, opts)
+	if err != nil || report.DryRun == false || report.Hits != 3 || report.Changed != 1 || report.Saved != 0 || report.Errors != 1 {
+		t.Fatalf("ApplyToSearch() dry run unexpected %+v, %v", report, err)
+	}
+	if len(posted) != 0 {
+		t.Errorf("ApplyToSearch() dry run saved records %v", posted)
+	}
+	if fields := report.Changes[0].Fields; len(fields) != 1 || fields[0] != "title" || report.Changes[1].Changed == true {
+		t.Errorf("ApplyToSearch() dry run unexpected changes %+v, %+v", report.Changes[0], report.Changes[1])
+	}
+	if change := report.Changes[2]; change.URI != "/repositories/2/accessions/3" || strings.Contains(change.Error, "has no title") == false {
+		t.Errorf("ApplyToSearch() expected the transform error to be reported, %+v", change)
+	}
+
+	opts.DryRun = false
+	report, err = api.ApplyToSearch("Papers", transform, opts)
+	if err != nil || report.Changed != 1 || report.Saved != 1 || report.Errors != 1 || report.Changes[0].Saved == false {
+		t.Fatalf("ApplyToSearch() unexpected %+v, %v", report, err)
+	}
+	if obj, ok := posted["/repositories/2/accessions/1"]; ok == false || obj["title"] != "Papers, 1920-1960" || len(posted) != 1 {
+		t.Errorf("ApplyToSearch() expected only the changed record to be saved, %v", posted)
+	}
+	if _, err := api.ApplyToSearch("Papers", nil, nil); err == nil {
+		t.Errorf("ApplyToSearch() expected an error without a transform")
+	}
+}
+
+func TestSavedSearchRender(t *testing.T) {
+	search := &SavedSearch{
+		Name:   "unprocessed",
+		Query:  `primary_type:accession AND accession_date:[{{.fy_start}} TO *]`,
+		Params: map[string]string{"fy_start": "2016-07-01"},
+	}
+	q, err := search.Render(nil)
+	if err != nil {
+		t.Errorf("Render() error: %s", err)
+	}
+	if q != `primary_type:accession AND accession_date:[2016-07-01 TO *]` {
+		t.Errorf("Render() default params, got %q", q)
+	}
+	q, err = search.Render(map[string]string{"fy_start": "2017-07-01"})
+	if err != nil {
+		t.Errorf("Render() error: %s", err)
+	}
+	if q != `primary_type:accession AND accession_date:[2017-07-01 TO *]` {
+		t.Errorf("Render() override params, got %q", q)
+	}
+	search.Query = `title:{{.missing}}`
+	if _, err = search.Render(nil); err == nil {
+		t.Errorf("Render() should fail on a missing param")
+	}
+}
+
+func TestUserDefinedMapping(t *testing.T) {
+	m := UserDefinedMapping{
+		"appraisal_value":   "real_1",
+		"gift_agreement_no": "text_3",
+		"restricted":        "boolean_1",
+	}
+	if err := m.Validate(); err != nil {
+		t.Errorf("Validate() error: %s", err)
+	}
+	accession := new(Accession)
+	if err := accession.SetUserDefined(m, "appraisal_value", 125.5); err != nil {
+		t.Errorf("SetUserDefined() error: %s", err)
+	}
+	if err := accession.SetUserDefined(m, "gift_agreement_no", "GA-2017-01"); err != nil {
+		t.Errorf("SetUserDefined() error: %s", err)
+	}
+	if err := accession.SetUserDefined(m, "restricted", "true"); err != nil {
+		t.Errorf("SetUserDefined() error: %s", err)
+	}
+	if accession.UserDefined.Real1 != "125.5" || accession.UserDefined.Text3 != "GA-2017-01" || accession.UserDefined.Boolean1 != true {
+		t.Errorf("SetUserDefined() stored the wrong values %s", accession.UserDefined)
+	}
+	if val, _ := accession.GetUserDefined(m, "gift_agreement_no"); val != "GA-2017-01" {
+		t.Errorf("GetUserDefined() returned %v", val)
+	}
+	if err := accession.SetUserDefined(m, "restricted", 3); err == nil {
+		t.Errorf("SetUserDefined() should reject an int for a boolean field")
+	}
+	if err := (UserDefinedMapping{"a": "real_1", "b": "real_1"}).Validate(); err == nil {
+		t.Errorf("Validate() should reject two names for one field")
+	}
+	if err := (UserDefinedMapping{"a": "lock_version"}).Validate(); err == nil {
+		t.Errorf("Validate() should reject non user_defined fields")
+	}
+}
+
+func TestExtentConversion(t *testing.T) {
+	if s := NormalizeExtentType("Linear Ft."); s != "linear_feet" {
+		t.Errorf("NormalizeExtentType() returned %q", s)
+	}
+	val, err := ConvertExtent(10, "linear_feet", "linear_meters")
+	if err != nil || val < 3.047 || val > 3.049 {
+		t.Errorf("ConvertExtent() 10 linear_feet -> %f linear_meters, %s", val, err)
+	}
+	if _, err := ConvertExtent(1, "linear_feet", "cubic_feet"); err == nil {
+		t.Errorf("ConvertExtent() should refuse linear to cubic")
+	}
+	extents := []*Extent{
+		{Number: "1,000", ExtentType: "linear_feet"},
+		{Number: "1", ExtentType: "linear_meters"},
+		{Number: "12", ExtentType: "items"},
+	}
+	total, skipped, err := SumExtents(extents, "linear_feet")
+	if err != nil {
+		t.Errorf("SumExtents() error: %s", err)
+	}
+	if total < 1003.28 || total > 1003.29 {
+		t.Errorf("SumExtents() total %f", total)
+	}
+	if len(skipped) != 1 || skipped[0].ExtentType != "items" {
+		t.Errorf("SumExtents() should skip the items extent, %v", skipped)
+	}
+}
+
+func TestBarcodeGenerator(t *testing.T) {
+	if d, _ := luhnDigit("7992739871"); d != "3" {
+		t.Errorf("luhnDigit(7992739871) should be 3, got %s", d)
+	}
+	gen := &BarcodeGenerator{Prefix: "3", Width: 6, Next: 1, Checksum: "luhn"}
+	gen.Reserve("30000012")
+	barcode, err := gen.Generate()
+	if err != nil {
+		t.Errorf("Generate() error: %s", err)
+	}
+	if barcode != "30000020" {
+		t.Errorf("Generate() should skip the reserved barcode, got %s", barcode)
+	}
+	if gen.Valid(barcode) == false {
+		t.Errorf("Valid(%q) should be true", barcode)
+	}
+	if gen.Valid("30000021") == true {
+		t.Errorf("Valid(30000021) should fail the checksum")
+	}
+	gen = &BarcodeGenerator{Prefix: "CIT", Width: 4, Next: 9999, Checksum: "mod43"}
+	barcode, _ = gen.Generate()
+	if gen.Valid(barcode) == false {
+		t.Errorf("Valid(%q) should be true", barcode)
+	}
+	if _, err := gen.Generate(); err == nil {
+		t.Errorf("Generate() should fail once the sequence exceeds Width")
+	}
+	gen = &BarcodeGenerator{Prefix: "CIT"}
+	if gen.Valid("CIT") == true {
+		t.Errorf("Valid(CIT) should need at least one digit when Width is 0")
+	}
+	if gen.Valid("CIT42") == false {
+		t.Errorf("Valid(CIT42) should be true when Width is 0")
+	}
+	gen.Checksum = "luhn"
+	if gen.Valid("CIT4") == true {
+		t.Errorf("Valid(CIT4) should need a digit besides the check character")
+	}
+}
+
+func TestAssignBarcodes(t *testing.T) {
+	var (
+		calls  []string
+		posted map[string]string
+	)
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls = append(calls, r.Method+" "+r.URL.RequestURI())
+		switch {
+		case r.Method == "GET" && r.URL.Path == "/repositories/2/top_containers" && r.URL.Query().Get("page") == "1":
+			fmt.Fprintf(w, `{"first_page": 1, "last_page": 2, "this_page": 1, "total": 3, "results": [
+{"uri": "/repositories/2/top_containers/1", "type": "box", "indicator": "1", "barcode": "30000012"},
+{"uri": "/repositories/2/top_containers/2", "type": "box", "indicator": "2"}]}`)
+		case r.Method == "GET" && r.URL.Path == "/repositories/2/top_containers" && r.URL.Query().Get("page") == "2":
+			fmt.Fprintf(w, `{"first_page": 1, "last_page": 2, "this_page": 2, "total": 3, "results": [
+{"uri": "/repositories/2/top_containers/3", "type": "box", "indicator": "3"}]}`)
+		case r.Method == "POST" && r.URL.Path == "/repositories/2/top_containers/bulk/barcodes":
+			json.NewDecoder(r.Body).Decode(&posted)
+			fmt.Fprintf(w, `{"updated": [2, 3]}`)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+			fmt.Fprintf(w, `{"error": "Repository not found"}`)
+		}
+	}))
+	defer ts.Close()
+
+	api, _ := NewClient(ts.URL)
+	gen := &BarcodeGenerator{Prefix: "3", Width: 6, Next: 1, Checksum: "luhn"}
+	assignments, err := api.AssignBarcodes(2, gen, true)
+	if err != nil || len(assignments) != 2 || assignments[0].URI != "/repositories/2/top_containers/2" || assignments[0].Barcode != "30000020" {
+		t.Fatalf("AssignBarcodes() dry run unexpected %v, %v", assignments, err)
+	}
+	if len(calls) != 2 || posted != nil {
+		t.Errorf("AssignBarcodes() should read two pages and save nothing on a dry run, %v", calls)
+	}
+
+	gen = &BarcodeGenerator{Prefix: "3", Width: 6, Next: 1, Checksum: "luhn"}
+	if _, err := api.AssignBarcodes(2, gen, false); err != nil {
+		t.Fatalf("AssignBarcodes() %s", err)
+	}
+	if len(posted) != 2 || posted["/repositories/2/top_containers/3"] != assignments[1].Barcode {
+		t.Errorf("AssignBarcodes() posted %v", posted)
+	}
+	if _, err := api.AssignBarcodes(5, gen, false); IsNotFound(err) == false {
+		t.Errorf("AssignBarcodes() expected not found, got %v", err)
+	}
+}
+
+func TestIsPlaceholderTitle(t *testing.T) {
+	for _, title := range []string{"", "Untitled", "[untitled]", "1992-04", "MS 12", "ms.12"} {
+		if IsPlaceholderTitle(title, "MS", "12") == false {
+			t.Errorf("IsPlaceholderTitle(%q) should be true", title)
+		}
+	}
+	for _, title := range []string{"Papers of Jane Doe", "MS 12 correspondence"} {
+		if IsPlaceholderTitle(title, "MS", "12") == true {
+			t.Errorf("IsPlaceholderTitle(%q) should be false", title)
+		}
+	}
+}
+
+func TestGiftLetterTemplate(t *testing.T) {
+	tmpl, err := LoadLetterTemplate("templates/default/gift-letter.txt")
+	if err != nil {
+		t.Errorf("LoadLetterTemplate() error: %s", err)
+		t.FailNow()
+	}
+	letter := &GiftLetter{
+		Date:       "July 1, 2017",
+		Repository: &Repository{Name: "Caltech Archives"},
+		Accession:  &Accession{Title: "Papers of Jane Doe", AccessionDate: "2017-06-30"},
+		Identifier: "2017-001",
+		Extents:    []string{"2 linear feet"},
+		Salutation: "Dr. Doe",
+		Address:    contactAddress(&AgentContact{Name: "Jane Doe", Address1: "1200 E California Blvd", City: "Pasadena", Region: "CA", PostCode: "91125"}),
+	}
+	buf := new(strings.Builder)
+	if err := tmpl.Execute(buf, letter); err != nil {
+		t.Errorf("Execute() error: %s", err)
+	}
+	for _, s := range []string{"Dear Dr. Doe,", "Pasadena, CA 91125", "accession 2017-001", "2 linear feet"} {
+		if strings.Contains(buf.String(), s) == false {
+			t.Errorf("letter missing %q\n%s", s, buf.String())
+		}
+	}
+}
+
+func TestReplaceSubjectRefs(t *testing.T) {
+	obj := Object{
+		"uri": "/repositories/2/accessions/1",
+		"subjects": []interface{}{
+			map[string]interface{}{"ref": "/subjects/1"},
+			map[string]interface{}{"ref": "/subjects/2"},
+		},
+	}
+	changed, err := replaceSubjectRefs("/subjects/1", []string{"/subjects/2", "/subjects/3"})(obj)
+	if err != nil || changed == false {
+		t.Errorf("replaceSubjectRefs() changed %t, error %v", changed, err)
+	}
+	if hasRef(obj, "subjects", "/subjects/1") || hasRef(obj, "subjects", "/subjects/3") == false || len(refList(obj, "subjects")) != 2 {
+		t.Errorf("replaceSubjectRefs() unexpected subjects %v", obj["subjects"])
+	}
+	changed, _ = replaceSubjectRefs("/subjects/1", []string{"/subjects/4"})(obj)
+	if changed == true {
+		t.Errorf("replaceSubjectRefs() should not change a record without the old subject")
+	}
+
+	requests := 0
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer ts.Close()
+	api, _ := NewClient(ts.URL)
+	uris := []string{"/repositories/2/accessions/1"}
+	for _, newURIs := range [][]string{{"/subjects/1"}, {"/agents/people/3"}, {"/subjects/2", "/subjects/x"}} {
+		if _, err := api.ReplaceSubject("/subjects/1", newURIs, nil); err == nil {
+			t.Errorf("ReplaceSubject(/subjects/1, %v) should be rejected", newURIs)
+		}
+		if _, err := api.ReplaceSubjectInURIs("/subjects/1", newURIs, uris, nil); err == nil {
+			t.Errorf("ReplaceSubjectInURIs(/subjects/1, %v) should be rejected", newURIs)
+		}
+	}
+	if requests != 0 {
+		t.Errorf("invalid replacements should be rejected before any request, %d made", requests)
+	}
+}
+
+func TestIndicatorOrder(t *testing.T) {
+	indicators := []string{"10", "2", "1a", "1", "B"}
+	sort.SliceStable(indicators, func(i, j int) bool {
+		return indicatorLess(indicators[i], indicators[j])
+	})
+	expected := []string{"B", "1", "1a", "2", "10"}
+	for i, s := range expected {
+		if indicators[i] != s {
+			t.Errorf("indicatorLess() order %v, expected %v", indicators, expected)
+			break
+		}
+	}
+	opts := &RelabelOptions{Prefix: "A-", Width: 3}
+	if s := formatIndicator(7, opts); s != "A-007" {
+		t.Errorf("formatIndicator() %q, expected A-007", s)
+	}
+}
+
+func TestManifestEntry(t *testing.T) {
+	src := []byte(`{"uri":"/subjects/1","system_mtime":"2017-01-02T03:04:05Z","title":"Test"}`)
+	entry := manifestEntry("dataset/subjects.ds/1.json", src)
+	if entry.URI != "/subjects/1" || entry.SystemMTime != "2017-01-02T03:04:05Z" {
+		t.Errorf("manifestEntry() unexpected metadata %s", stringify(entry))
+	}
+	if entry.Path != "dataset/subjects.ds/1.json" {
+		t.Errorf("manifestEntry() path %q", entry.Path)
+	}
+	if len(entry.SHA256) != 64 {
+		t.Errorf("manifestEntry() checksum %q", entry.SHA256)
+	}
+	manifest := NewExportManifest()
+	manifest.Add(entry)
+	manifest.Add(manifestEntry("dataset/agents.ds/people/1.json", []byte(`{}`)))
+	manifest.Finish()
+	if manifest.Finished == "" || manifest.Entries[0].Path != "dataset/agents.ds/people/1.json" {
+		t.Errorf("Finish() unexpected manifest %s", manifest.String())
+	}
+}
+
+func TestEADFilename(t *testing.T) {
+	for _, test := range []struct {
+		eadID    string
+		id       int
+		expected string
+	}{
+		{"CaltechArchives/Smith.xml", 1, "CaltechArchives_Smith.xml"},
+		{" mss-0012 ", 2, "mss-0012.xml"},
+		{"", 3, "resource-3.xml"},
+		{"../", 4, "resource-4.xml"},
+	} {
+		if s := EADFilename(test.eadID, test.id); s != test.expected {
+			t.Errorf("EADFilename(%q, %d) %q, expected %q", test.eadID, test.id, s, test.expected)
+		}
+	}
+}
+
+func TestReadBodyLimit(t *testing.T) {
+	api := &ArchivesSpaceAPI{MaxResponseSize: 4}
+	if _, err := api.readBody("/test", strings.NewReader("1234")); err != nil {
+		t.Errorf("readBody() unexpected error %s", err)
+	}
+	_, err := api.readBody("/test", strings.NewReader("12345"))
+	if _, ok := err.(*ErrResponseTooLarge); ok == false {
+		t.Errorf("readBody() expected ErrResponseTooLarge, got %v", err)
+	}
+	api.MaxResponseSize = 0
+	if src, err := api.readBody("/test", strings.NewReader("12345")); err != nil || len(src) != 5 {
+		t.Errorf("readBody() without limit %q, %v", src, err)
+	}
+}
+
+func TestRegisterEndpoint(t *testing.T) {
+	endpoint := &Endpoint{
+		Name:   "payments.get",
+		Path:   "/repositories/{repo_id}/payments/{id}",
+		Method: "GET",
+	}
+	if err := RegisterEndpoint(endpoint); err != nil {
+		t.Errorf("RegisterEndpoint() %s", err)
+	}
+	defer UnregisterEndpoint(endpoint.Name)
+	if err := RegisterEndpoint(endpoint); err == nil {
+		t.Errorf("RegisterEndpoint() should refuse a duplicate name")
+	}
+	p, query, err := endpoint.expandPath(map[string]string{"repo_id": "2", "id": "a b", "resolve[]": "agent"})
+	if err != nil {
+		t.Errorf("expandPath() %s", err)
+	}
+	if p != "/repositories/2/payments/a%20b" || query.Get("resolve[]") != "agent" {
+		t.Errorf("expandPath() %q %q", p, query.Encode())
+	}
+	if _, _, err := endpoint.expandPath(map[string]string{"repo_id": "2"}); err == nil {
+		t.Errorf("expandPath() should fail on a missing parameter")
+	}
+}
+
+func TestSetHTTPClient(t *testing.T) {
+	api := &ArchivesSpaceAPI{}
+	if api.httpClient() != http.DefaultClient {
+		t.Errorf("httpClient() should default to http.DefaultClient")
+	}
+	client := &http.Client{Timeout: time.Second}
+	api.SetHTTPClient(client)
+	if api.httpClient() != client {
+		t.Errorf("httpClient() should return the client set")
+	}
+}
+
+// TestConcurrentRequests is most useful run with the race detector, go test -race
+func TestConcurrentRequests(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintf(w, `{"uri": %q, "repo_code": "test"}`, r.URL.Path)
+	}))
+	defer ts.Close()
+
+	api := New(ts.URL, "", "", "")
+	api.BaseURL, _ = url.Parse(ts.URL)
+	api.AuthToken = "test"
+	var wg sync.WaitGroup
+	for i := 1; i <= 20; i++ {
+		wg.Add(1)
+		go func(id int) {
+			defer wg.Done()
+			repo, err := api.GetRepository(id)
+			if err != nil {
+				t.Errorf("GetRepository(%d) %s", id, err)
+				return
+			}
+			if repo.ID != id {
+				t.Errorf("GetRepository(%d) returned repository %d", id, repo.ID)
+			}
+		}(i)
+	}
+	wg.Wait()
+}
+
+func TestRetryPolicy(t *testing.T) {
+	failures := 2
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if failures > 0 {
+			failures--
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		fmt.Fprintf(w, `{"uri": "/repositories/2"}`)
+	}))
+	defer ts.Close()
+
+	api := &ArchivesSpaceAPI{}
+	api.BaseURL, _ = url.Parse(ts.URL)
+	if _, err := api.GetRepository(2); err == nil {
+		t.Errorf("GetRepository() should fail without a retry policy")
+	}
+	api.Retry = &RetryPolicy{MaxAttempts: 3, BaseDelay: time.Millisecond}
+	failures = 2
+	if _, err := api.GetRepository(2); err != nil {
+		t.Errorf("GetRepository() should succeed after retries, %s", err)
+	}
+
+	policy := &RetryPolicy{BaseDelay: time.Second, MaxDelay: 5 * time.Second}
+	for attempt, expected := range map[int]time.Duration{1: time.Second, 2: 2 * time.Second, 3: 4 * time.Second, 4: 5 * time.Second} {
+		if d := policy.backoff(attempt); d != expected {
+			t.Errorf("backoff(%d) %s, expected %s", attempt, d, expected)
+		}
+	}
+	if policy.attempts("POST") != 1 {
+		t.Errorf("attempts() should not retry POST by default")
+	}
+}
+
+func TestAPIError(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/repositories/404":
+			w.WriteHeader(http.StatusNotFound)
+			fmt.Fprintf(w, `{"error": "Repository not found"}`)
+		default:
+			w.WriteHeader(http.StatusBadRequest)
+			fmt.Fprintf(w, `{"error": {"repo_code": ["Property is required but was missing"]}}`)
+		}
+	}))
+	defer ts.Close()
+
+	api := &ArchivesSpaceAPI{}
+	api.BaseURL, _ = url.Parse(ts.URL)
+	_, err := api.GetRepository(404)
+	if IsNotFound(err) == false {
+		t.Errorf("GetRepository() expected a not found APIError, got %v", err)
+	}
+	if e, ok := AsAPIError(err); ok == false || e.Message != "Repository not found" {
+		t.Errorf("AsAPIError() unexpected %v", err)
+	}
+	_, err = api.GetRepository(400)
+	if IsValidationError(err) == false || IsNotFound(err) == true {
+		t.Errorf("GetRepository() expected a validation APIError, got %v", err)
+	}
+	if e, _ := AsAPIError(err); len(e.ValidationErrors["repo_code"]) != 1 {
+		t.Errorf("APIError unexpected validation errors %v", e.ValidationErrors)
+	}
+}
+
+func TestSessionRenewal(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.URL.Path == "/users/admin/login":
+			fmt.Fprintf(w, `{"session": "fresh"}`)
+		case r.URL.Path == "/logout":
+			fmt.Fprintf(w, `{"status": "session_logged_out"}`)
+		case r.Header.Get("X-ArchivesSpace-Session") != "fresh":
+			w.WriteHeader(http.StatusPreconditionFailed)
+			fmt.Fprintf(w, `{"code": "SESSION_GONE", "error": "No session found"}`)
+		default:
+			fmt.Fprintf(w, `{"uri": "/repositories/2"}`)
+		}
+	}))
+	defer ts.Close()
+
+	api := &ArchivesSpaceAPI{Username: "admin", Password: "admin", AuthToken: "stale"}
+	api.BaseURL, _ = url.Parse(ts.URL)
+	renewed := 0
+	api.OnReauthenticate = func(err error) {
+		if err != nil {
+			t.Errorf("OnReauthenticate() %s", err)
+		}
+		renewed++
+	}
+	repo, err := api.GetRepository(2)
+	if err != nil || repo.ID != 2 {
+		t.Errorf("GetRepository() should succeed after renewing the session, %v", err)
+	}
+	if renewed != 1 || api.AuthToken != "fresh" {
+		t.Errorf("expected one renewal and a fresh token, %d %q", renewed, api.AuthToken)
+	}
+}
+
+func TestTimeoutOptions(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		time.Sleep(200 * time.Millisecond)
+		fmt.Fprintf(w, `{"uri": "/repositories/2"}`)
+	}))
+	defer ts.Close()
+
+	api := New(ts.URL, "", "", "", WithConnectTimeout(time.Second), WithRequestTimeout(50*time.Millisecond))
+	api.BaseURL, _ = url.Parse(ts.URL)
+	if api.Client == nil || api.Client.Timeout != 50*time.Millisecond {
+		t.Errorf("WithRequestTimeout() did not configure the client")
+	}
+	if _, err := api.GetRepository(2); err == nil {
+		t.Errorf("GetRepository() should time out")
+	}
+
+	api = New(ts.URL, "", "", "", WithDeadline(time.Now().Add(10*time.Second)))
+	api.BaseURL, _ = url.Parse(ts.URL)
+	if _, err := api.GetRepository(2); err != nil {
+		t.Errorf("GetRepository() should succeed before the deadline, %s", err)
+	}
+
+	api = New(ts.URL, "", "", "", WithDeadline(time.Now().Add(50*time.Millisecond)))
+	api.BaseURL, _ = url.Parse(ts.URL)
+	if _, err := api.GetRepository(2); err == nil {
+		t.Errorf("GetRepository() should fail after the deadline")
+	}
+}
+
+func TestTLSConfig(t *testing.T) {
+	ts := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintf(w, `{"uri": "/repositories/2"}`)
+	}))
+	defer ts.Close()
+
+	api := New(ts.URL, "", "", "", WithConnectTimeout(time.Second))
+	api.BaseURL, _ = url.Parse(ts.URL)
+	if _, err := api.GetRepository(2); err == nil {
+		t.Errorf("GetRepository() should reject an unknown certificate authority")
+	}
+	pool := x509.NewCertPool()
+	pool.AddCert(ts.Certificate())
+	api = New(ts.URL, "", "", "", WithTLSConfig(&tls.Config{RootCAs: pool}))
+	api.BaseURL, _ = url.Parse(ts.URL)
+	if _, err := api.GetRepository(2); err != nil {
+		t.Errorf("GetRepository() with custom root CA %s", err)
+	}
+	config, err := LoadTLSConfig("", "", "", true)
+	if err != nil || config.InsecureSkipVerify == false {
+		t.Errorf("LoadTLSConfig() %v", err)
+	}
+}
+
+func TestProxy(t *testing.T) {
+	proxy := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Host != "aspace.example.edu" || r.Header.Get("X-Proxy-Token") != "secret" || r.Header.Get("Proxy-Authorization") == "" {
+			w.WriteHeader(http.StatusProxyAuthRequired)
+			return
+		}
+		fmt.Fprintf(w, `{"uri": %q}`, r.URL.Path)
+	}))
+	defer proxy.Close()
+
+	proxyURL, _ := url.Parse(proxy.URL)
+	proxyURL.User = url.UserPassword("library", "secret")
+	headers := http.Header{}
+	headers.Set("X-Proxy-Token", "secret")
+	api := New("http://aspace.example.edu", "", "", "", WithProxy(proxyURL, headers))
+	api.BaseURL, _ = url.Parse("http://aspace.example.edu")
+	repo, err := api.GetRepository(2)
+	if err != nil || repo.ID != 2 {
+		t.Errorf("GetRepository() through proxy %v", err)
+	}
+}
+
+func TestCustomHeaders(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintf(w, `{"priority": %q, "proxy_auth": %q, "session": %q}`, r.Header.Get("X-ArchivesSpace-Priority"), r.Header.Get("X-Proxy-Auth"), r.Header.Get("X-ArchivesSpace-Session"))
+	}))
+	defer ts.Close()
+
+	headers := http.Header{}
+	headers.Set("X-ArchivesSpace-Priority", "low")
+	headers.Set("X-Proxy-Auth", "library")
+	api := New(ts.URL, "", "", "", WithHeaders(headers))
+	api.BaseURL, _ = url.Parse(ts.URL)
+	api.AuthToken = "token"
+	call := http.Header{}
+	call.Set("X-ArchivesSpace-Priority", "high")
+	call.Set("X-ArchivesSpace-Session", "override")
+	src, err := api.APIWithHeaders("GET", api.buildURL("/version", nil), nil, call)
+	if err != nil {
+		t.Errorf("APIWithHeaders() %s", err)
+	}
+	if string(src) != `{"priority": "high", "proxy_auth": "library", "session": "token"}` {
+		t.Errorf("APIWithHeaders() unexpected headers %s", src)
+	}
+}
+
+func TestLoginHeaders(t *testing.T) {
+	var login, logout http.Header
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/users/admin/login":
+			login = r.Header
+			if r.FormValue("password") != "admin" {
+				w.WriteHeader(http.StatusForbidden)
+				return
+			}
+			fmt.Fprintf(w, `{"session": "abc"}`)
+		case "/logout":
+			logout = r.Header
+			fmt.Fprintf(w, `{"status": "session_logged_out"}`)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer ts.Close()
+
+	headers := http.Header{}
+	headers.Set("X-Proxy-Auth", "library")
+	api, _ := NewClient(ts.URL, WithCredentials("admin", "admin"), WithHeaders(headers))
+	if err := api.Login(); err != nil || api.AuthToken != "abc" {
+		t.Fatalf("Login() unexpected %q, %v", api.AuthToken, err)
+	}
+	if login.Get("X-Proxy-Auth") != "library" || login.Get("Content-Type") != "application/x-www-form-urlencoded" {
+		t.Errorf("Login() unexpected headers %v", login)
+	}
+	if err := api.Logout(); err != nil || api.AuthToken != "" {
+		t.Errorf("Logout() unexpected %q, %v", api.AuthToken, err)
+	}
+	if logout.Get("X-Proxy-Auth") != "library" || logout.Get("X-ArchivesSpace-Session") != "abc" {
+		t.Errorf("Logout() unexpected headers %v", logout)
+	}
+}
+
+func TestGzip(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body io.Reader = r.Body
+		if r.Header.Get("Content-Encoding") == "gzip" {
+			gz, err := gzip.NewReader(r.Body)
+			if err != nil {
+				w.WriteHeader(http.StatusBadRequest)
+				return
+			}
+			body = gz
+		}
+		src, _ := ioutil.ReadAll(body)
+		if r.Header.Get("Accept-Encoding") != "gzip" {
+			w.Write(src)
+			return
+		}
+		w.Header().Set("Content-Encoding", "gzip")
+		gz := gzip.NewWriter(w)
+		gz.Write(src)
+		gz.Close()
+	}))
+	defer ts.Close()
+
+	api := New(ts.URL, "", "", "", WithGzipRequests())
+	api.BaseURL, _ = url.Parse(ts.URL)
+	src, err := api.API("POST", api.buildURL("/echo", nil), map[string]string{"title": "compressed"})
+	if err != nil || string(src) != `{"title":"compressed"}` {
+		t.Errorf("API() gzip round trip %q, %v", src, err)
+	}
+}
+
+func TestResponseCache(t *testing.T) {
+	downloads := 0
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("If-Modified-Since") == "Mon, 02 Jan 2017 03:04:05 GMT" {
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		downloads++
+		fmt.Fprintf(w, `{"uri": "/repositories/2", "system_mtime": "2017-01-02T03:04:05Z"}`)
+	}))
+	defer ts.Close()
+
+	api := New(ts.URL, "", "", "", WithCache(NewMemoryCache()))
+	api.BaseURL, _ = url.Parse(ts.URL)
+	for i := 0; i < 3; i++ {
+		repo, err := api.GetRepository(2)
+		if err != nil || repo.ID != 2 {
+			t.Errorf("GetRepository() with cache %v", err)
+		}
+	}
+	if downloads != 1 {
+		t.Errorf("expected one download, got %d", downloads)
+	}
+}
+
+func TestResponseCacheKey(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// Pretend nothing has changed whenever we're asked
+		if r.Header.Get("If-Modified-Since") != "" {
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		fmt.Fprintf(w, `{"uri": "/repositories/2", "name": "%s %s", "system_mtime": "2017-01-02T03:04:05Z"}`, r.Header.Get("X-ArchivesSpace-Session"), r.Header.Get("X-Priority"))
+	}))
+	defer ts.Close()
+
+	cache := NewMemoryCache()
+	alice, _ := NewClient(ts.URL, WithToken("alice"), WithCache(cache))
+	bob, _ := NewClient(ts.URL, WithToken("bob"), WithCache(cache))
+	u := alice.buildURL("/repositories/2", nil)
+	for i := 0; i < 2; i++ {
+		for _, test := range []struct {
+			api      *ArchivesSpaceAPI
+			priority string
+			expected string
+		}{
+			{alice, "", "alice "},
+			{bob, "", "bob "},
+			{alice, "high", "alice high"},
+		} {
+			headers := http.Header{}
+			if test.priority != "" {
+				headers.Set("X-Priority", test.priority)
+			}
+			src, err := test.api.APIWithHeaders("GET", u, nil, headers)
+			repo := map[string]interface{}{}
+			json.Unmarshal(src, &repo)
+			if err != nil || repo["name"] != test.expected {
+				t.Errorf("cached GET expected %q, got %q, %v", test.expected, repo["name"], err)
+			}
+		}
+	}
+	if len(cache.responses) != 3 {
+		t.Errorf("expected a cache entry per user and headers, got %d", len(cache.responses))
+	}
+	for key := range cache.responses {
+		if strings.Contains(key, "alice") || strings.Contains(key, "bob") {
+			t.Errorf("cache key %q shouldn't include the session token", key)
+		}
+	}
+}
+
+func TestNewClient(t *testing.T) {
+	for _, s := range []string{"", "localhost:8089", "ftp://example.edu", "http://"} {
+		if _, err := NewClient(s); err == nil {
+			t.Errorf("NewClient(%q) should fail", s)
+		}
+	}
+	if _, err := NewClient("http://localhost:8089", WithCredentials("admin", "")); err == nil {
+		t.Errorf("NewClient() should require a password with a username")
+	}
+	policy := &RetryPolicy{MaxAttempts: 3}
+	api, err := NewClient("http://localhost:8089/api", WithCredentials("admin", "admin"), WithRetry(policy), WithRequestTimeout(time.Minute), WithLogger(log.New(ioutil.Discard, "", 0)))
+	if err != nil {
+		t.Errorf("NewClient() %s", err)
+	}
+	if api.Username != "admin" || api.Retry != policy || api.Client.Timeout != time.Minute || api.Logger == nil {
+		t.Errorf("NewClient() options not applied %s", api.String())
+	}
+	if s := api.buildURL("/version", nil); s != "http://localhost:8089/api/version" {
+		t.Errorf("NewClient() base URL gives %q", s)
+	}
+}
+
+func TestNewFromEnv(t *testing.T) {
+	env := map[string]string{
+		"ASPACE_API_URL": "", "ASPACE_USERNAME": "", "ASPACE_PASSWORD": "", "ASPACE_API_TOKEN": "", "ASPACE_REQUEST_TIMEOUT": "",
+		"CAIT_API_URL": "", "CAIT_USERNAME": "", "CAIT_PASSWORD": "", "CAIT_API_TOKEN": "", "CAIT_REQUEST_TIMEOUT": "",
+	}
+	for key := range env {
+		env[key] = os.Getenv(key)
+		os.Unsetenv(key)
+	}
+	defer func() {
+		for key, value := range env {
+			os.Setenv(key, value)
+		}
+	}()
+
+	if _, err := NewFromEnv(); err == nil {
+		t.Errorf("NewFromEnv() should fail without ASPACE_API_URL")
+	}
+	os.Setenv("ASPACE_API_URL", "http://localhost:8089")
+	if _, err := NewFromEnv(); err == nil {
+		t.Errorf("NewFromEnv() should fail without credentials")
+	}
+	os.Setenv("ASPACE_USERNAME", "admin")
+	os.Setenv("ASPACE_PASSWORD", "admin")
+	os.Setenv("ASPACE_REQUEST_TIMEOUT", "soon")
+	if _, err := NewFromEnv(); err == nil {
+		t.Errorf("NewFromEnv() should fail on a bad timeout")
+	}
+	os.Setenv("ASPACE_REQUEST_TIMEOUT", "90")
+	api, err := NewFromEnv()
+	if err != nil {
+		t.Errorf("NewFromEnv() %s", err)
+	} else if api.Username != "admin" || api.RequestTimeout != 90*time.Second {
+		t.Errorf("NewFromEnv() unexpected settings %s", api.String())
+	}
+}
+
+func TestConfigInstances(t *testing.T) {
+	fname := path.Join(t.TempDir(), "aspace.json")
+	src := []byte(`{
+    "default": "sandbox",
+    "instances": {
+        "sandbox": {"api_url": "http://localhost:8089", "username": "admin", "password": "admin", "request_timeout": "30s"},
+        "production": {"api_url": "https://aspace.example.edu/api", "token": "abc", "max_retries": 2}
+    }
+}`)
+	if err := ioutil.WriteFile(fname, src, 0600); err != nil {
+		t.Fatalf("%s", err)
+	}
+	cfg, err := LoadConfig(fname)
+	if err != nil {
+		t.Fatalf("LoadConfig() %s", err)
+	}
+	if names := cfg.Names(); len(names) != 2 || names[0] != "production" {
+		t.Errorf("Names() %v", names)
+	}
+	sandbox, err := cfg.Client("")
+	if err != nil || sandbox.Username != "admin" || sandbox.RequestTimeout != 30*time.Second {
+		t.Errorf("Client(\"\") should return the sandbox, %v", err)
+	}
+	production, err := NewFromConfig(fname, "production")
+	if err != nil || production.AuthToken != "abc" || production.Retry.MaxAttempts != 3 {
+		t.Errorf("NewFromConfig() production %v", err)
+	}
+	if _, err := cfg.Client("staging"); err == nil {
+		t.Errorf("Client(\"staging\") should fail")
+	}
+
+	fname = path.Join(t.TempDir(), "aspace.toml")
+	src = []byte(`default = "staging"
+
+[instances.staging]
+api_url = "https://staging.example.edu/api"
+token = "def"
+request_timeout = "45s"
+
+[[instances.staging.saved_searches]]
+name = "unprocessed"
+q = "processing_status:unprocessed AND accession_date:[{{.fy_start}} TO *]"
+types = ["accession"]
+params = {fy_start = "2026-07-01"}
+`)
+	if err := ioutil.WriteFile(fname, src, 0600); err != nil {
+		t.Fatalf("%s", err)
+	}
+	staging, err := NewFromConfig(fname, "")
+	if err != nil || staging.AuthToken != "def" || staging.RequestTimeout != 45*time.Second {
+		t.Fatalf("NewFromConfig() staging from TOML %v", err)
+	}
+	if names := staging.ListSavedSearches(); len(names) != 1 || names[0] != "unprocessed" {
+		t.Errorf("saved searches from the config %v", names)
+	}
+	if q, err := staging.SavedSearches["unprocessed"].Render(nil); err != nil || q != "processing_status:unprocessed AND accession_date:[2026-07-01 TO *]" {
+		t.Errorf("Render() %q, %v", q, err)
+	}
+	if err := ioutil.WriteFile(fname, []byte(`default = "staging"`+"\n[instances.staging]\napi_url = "), 0600); err != nil {
+		t.Fatalf("%s", err)
+	}
+	if _, err := LoadConfig(fname); err == nil {
+		t.Errorf("LoadConfig() should fail on malformed TOML")
+	}
+}
+
+func TestCredentialProvider(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/users/admin/login" || r.FormValue("password") != "from-keyring" {
+			w.WriteHeader(http.StatusForbidden)
+			fmt.Fprintf(w, `{"error": "Login failed"}`)
+			return
+		}
+		fmt.Fprintf(w, `{"session": "token"}`)
+	}))
+	defer ts.Close()
+
+	provider := CredentialFunc(func(username string) (string, error) {
+		return "from-keyring", nil
+	})
+	api, err := NewClient(ts.URL, WithCredentialProvider("admin", provider))
+	if err != nil {
+		t.Fatalf("NewClient() %s", err)
+	}
+	if err := api.Login(); err != nil || api.AuthToken != "token" {
+		t.Errorf("Login() with credential provider %v", err)
+	}
+	if api.Password != "" {
+		t.Errorf("the password should not be held in the struct")
+	}
+}
+
+func TestSaveSession(t *testing.T) {
+	logins := 0
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.URL.Path == "/users/admin/login":
+			logins++
+			fmt.Fprintf(w, `{"session": "session-%d"}`, logins)
+		case r.URL.Path == "/logout":
+			fmt.Fprintf(w, `{"status": "session_logged_out"}`)
+		case r.Header.Get("X-ArchivesSpace-Session") != fmt.Sprintf("session-%d", logins):
+			w.WriteHeader(http.StatusPreconditionFailed)
+			fmt.Fprintf(w, `{"code": "SESSION_GONE", "error": "No session found"}`)
+		default:
+			fmt.Fprintf(w, `{"username": "admin"}`)
+		}
+	}))
+	defer ts.Close()
+
+	fname := path.Join(t.TempDir(), "session.json")
+	api, _ := NewClient(ts.URL, WithCredentials("admin", "admin"))
+	if err := api.LoginWithSession(fname); err != nil || logins != 1 {
+		t.Errorf("LoginWithSession() should log in when nothing is saved, %v", err)
+	}
+	api2, _ := NewClient(ts.URL, WithCredentials("admin", "admin"))
+	if err := api2.LoginWithSession(fname); err != nil || logins != 1 || api2.AuthToken != "session-1" {
+		t.Errorf("LoginWithSession() should reuse the saved session, %v", err)
+	}
+	// Expire the saved session
+	logins++
+	api3, _ := NewClient(ts.URL, WithCredentials("admin", "admin"))
+	if err := api3.LoginWithSession(fname); err != nil || api3.AuthToken != "session-3" {
+		t.Errorf("LoginWithSession() should log in again when the saved session is rejected, %v %q", err, api3.AuthToken)
+	}
+	other, _ := NewClient(ts.URL, WithCredentials("someone", "else"))
+	if err := other.LoadSession(fname); err == nil {
+		t.Errorf("LoadSession() should refuse another user's session")
+	}
+}
+
+func TestDebugRedaction(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/users/admin/login" {
+			fmt.Fprintf(w, `{"session": "secret-session"}`)
+			return
+		}
+		fmt.Fprintf(w, `{"uri": "/repositories/2"}`)
+	}))
+	defer ts.Close()
+
+	buf := new(strings.Builder)
+	api, _ := NewClient(ts.URL, WithCredentials("admin", "secret-password"), WithDebug(log.New(buf, "", 0)))
+	if err := api.Login(); err != nil {
+		t.Fatalf("Login() %s", err)
+	}
+	api.API("GET", api.buildURL("/repositories/2", url.Values{"token": []string{"secret-token"}}), nil)
+	out := buf.String()
+	if strings.Contains(out, "GET") == false || strings.Contains(out, "200 OK") == false {
+		t.Errorf("expected requests to be logged, %q", out)
+	}
+	for _, secret := range []string{"secret-password", "secret-session", "secret-token"} {
+		if strings.Contains(out, secret) {
+			t.Errorf("debug log contains %q, %s", secret, out)
+		}
+	}
+	if s := RedactURL("https://user:pw@example.edu/x?password=pw&q=a"); strings.Contains(s, "pw@") || strings.Contains(s, "password=pw") {
+		t.Errorf("RedactURL() %s", s)
+	}
+}
+
+func TestStatusCodes(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/created":
+			w.WriteHeader(http.StatusCreated)
+			fmt.Fprintf(w, `{"status": "Created", "id": 3}`)
+		case "/conflict":
+			w.WriteHeader(http.StatusConflict)
+			fmt.Fprintf(w, `{"error": {"lock_version": ["record has been updated"]}}`)
+		default:
+			w.WriteHeader(http.StatusBadGateway)
+			fmt.Fprintf(w, `<html>upstream unavailable</html>`)
+		}
+	}))
+	defer ts.Close()
+
+	api, _ := NewClient(ts.URL)
+	responseMsg, err := api.CreateAPI(api.buildURL("/created", nil), Object{})
+	if err != nil || responseMsg.ID != 3 {
+		t.Errorf("CreateAPI() should accept 201 Created, %v", err)
+	}
+	if _, err := api.UpdateAPI(api.buildURL("/conflict", nil), Object{}); IsConflict(err) == false {
+		t.Errorf("UpdateAPI() should return a conflict APIError, %v", err)
+	}
+	_, err = api.UpdateAPI(api.buildURL("/proxy", nil), Object{})
+	if e, ok := AsAPIError(err); ok == false || e.StatusCode != http.StatusBadGateway || strings.Contains(err.Error(), "upstream unavailable") == false {
+		t.Errorf("UpdateAPI() should include the response body, %v", err)
+	}
+}
+
+func TestThrottling(t *testing.T) {
+	throttle := 2
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if throttle > 0 {
+			throttle--
+			w.Header().Set("Retry-After", "0")
+			w.WriteHeader(http.StatusTooManyRequests)
+			return
+		}
+		fmt.Fprintf(w, `{"status": "Created", "id": 5}`)
+	}))
+	defer ts.Close()
+
+	api, _ := NewClient(ts.URL)
+	if _, err := api.CreateAPI(api.buildURL("/repositories", nil), Object{}); err != nil {
+		t.Errorf("CreateAPI() should succeed after being throttled, %s", err)
+	}
+	if api.ThrottleCount() != 2 {
+		t.Errorf("ThrottleCount() %d, expected 2", api.ThrottleCount())
+	}
+	api.Retry = &RetryPolicy{MaxThrottleRetries: -1}
+	throttle = 1
+	if _, err := api.CreateAPI(api.buildURL("/repositories", nil), Object{}); hasStatus(err, http.StatusTooManyRequests) == false {
+		t.Errorf("CreateAPI() should return the 429 when throttle retries are disabled, %v", err)
+	}
+
+	now := time.Date(2017, 3, 1, 12, 0, 0, 0, time.UTC)
+	res := &http.Response{Header: http.Header{}}
+	for value, expected := range map[string]time.Duration{
+		"120":                           2 * time.Minute,
+		"Wed, 01 Mar 2017 12:00:30 GMT": 30 * time.Second,
+	} {
+		res.Header.Set("Retry-After", value)
+		if d, ok := retryAfter(res, now); ok == false || d != expected {
+			t.Errorf("retryAfter(%q) %s, expected %s", value, d, expected)
+		}
+	}
+	res.Header.Set("Retry-After", "soon")
+	if _, ok := retryAfter(res, now); ok == true {
+		t.Errorf("retryAfter() should reject an invalid header")
+	}
+
+	// A Retry-After of an hour is cut down to the policy's MaxDelay
+	ts2 := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if throttle > 0 {
+			throttle--
+			w.Header().Set("Retry-After", "3600")
+			w.WriteHeader(http.StatusTooManyRequests)
+			return
+		}
+		fmt.Fprintf(w, `{"status": "Created", "id": 5}`)
+	}))
+	defer ts2.Close()
+	api, _ = NewClient(ts2.URL, WithRetry(&RetryPolicy{MaxDelay: 10 * time.Millisecond}))
+	throttle = 1
+	start := time.Now()
+	if _, err := api.CreateAPI(api.buildURL("/repositories", nil), Object{}); err != nil || time.Since(start) > 5*time.Second {
+		t.Errorf("CreateAPI() should retry after MaxDelay, %v after %s", err, time.Since(start))
+	}
+	for policy, expected := range map[*RetryPolicy]time.Duration{
+		nil:                                     DefaultMaxThrottleDelay,
+		&RetryPolicy{}:                          DefaultMaxThrottleDelay,
+		&RetryPolicy{MaxDelay: 2 * time.Second}: 2 * time.Second,
+	} {
+		if d := policy.throttleDelay(time.Hour); d != expected {
+			t.Errorf("throttleDelay() %s, expected %s", d, expected)
+		}
+	}
+	if d := (&RetryPolicy{MaxDelay: time.Minute}).throttleDelay(time.Second); d != time.Second {
+		t.Errorf("throttleDelay() shouldn't lengthen a short wait, %s", d)
+	}
+}
+
+func TestAPIStream(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/repositories/2/resource_descriptions/5.xml" {
+			w.WriteHeader(http.StatusNotFound)
+			fmt.Fprintf(w, `{"error": "Resource not found"}`)
+			return
+		}
+		fmt.Fprintf(w, `<ead numbered_cs="%s">`, r.URL.Query().Get("numbered_cs"))
+		for i := 0; i < 1000; i++ {
+			fmt.Fprintf(w, "<c>%d</c>", i)
+		}
+		fmt.Fprintf(w, "</ead>")
+	}))
+	defer ts.Close()
+
+	api, _ := NewClient(ts.URL, WithMaxResponseSize(64))
+	body, err := api.APIStream("GET", "/repositories/2/resource_descriptions/5.xml?numbered_cs=true", nil)
+	if err != nil {
+		t.Fatalf("APIStream() %s", err)
+	}
+	src, err := ioutil.ReadAll(body)
+	body.Close()
+	if err != nil || bytes.HasPrefix(src, []byte(`<ead numbered_cs="true">`)) == false || bytes.HasSuffix(src, []byte("</ead>")) == false {
+		t.Errorf("APIStream() unexpected body %d bytes, %v", len(src), err)
+	}
+	if _, err := api.APIStream("GET", "/repositories/2/resource_descriptions/6.xml", nil); IsNotFound(err) == false {
+		t.Errorf("APIStream() expected a not found APIError, got %v", err)
+	}
+}
+
+func TestGenericCRUD(t *testing.T) {
+	subjects := map[string]string{"/subjects/1": "Seismology"}
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.Method == "GET" && r.URL.Path == "/subjects":
+			fmt.Fprintf(w, "[1]")
+		case r.Method == "GET" && subjects[r.URL.Path] != "":
+			fmt.Fprintf(w, `{"uri": %q, "title": %q, "jsonmodel_type": "subject"}`, r.URL.Path, subjects[r.URL.Path])
+		case r.Method == "POST" && r.URL.Path == "/subjects":
+			w.WriteHeader(http.StatusCreated)
+			fmt.Fprintf(w, `{"status": "Created", "id": 2, "uri": "/subjects/2"}`)
+		case r.Method == "POST" || r.Method == "DELETE":
+			fmt.Fprintf(w, `{"status": "Updated", "uri": %q}`, r.URL.Path)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer ts.Close()
+
+	api, _ := NewClient(ts.URL)
+	subject, err := Get[Subject](api, "/subjects/1")
+	if err != nil || subject.ID != 1 || subject.Title != "Seismology" {
+		t.Fatalf("Get() unexpected %+v, %v", subject, err)
+	}
+	created := &Subject{Title: "Geophysics"}
+	if _, err := Create(api, "/subjects", created); err != nil || created.URI != "/subjects/2" || created.ID != 2 {
+		t.Errorf("Create() should set the URI and ID, %+v, %v", created, err)
+	}
+	if _, err := Update(api, subject); err != nil {
+		t.Errorf("Update() %s", err)
+	}
+	if _, err := Delete(api, &Subject{}); err == nil {
+		t.Errorf("Delete() should fail without a URI")
+	}
+	list, err := List[Subject](api, "/subjects")
+	if err != nil || len(list) != 1 || list[0].URI != "/subjects/1" {
+		t.Errorf("List() unexpected %v, %v", list, err)
+	}
+	obj, err := Get[Object](api, "/subjects/1")
+	if err != nil || obj.GetURI() != "/subjects/1" {
+		t.Errorf("Get[Object]() unexpected %v, %v", obj, err)
+	}
+}
+
+func TestDecodeJSONModel(t *testing.T) {
+	obj, err := DecodeJSONModel([]byte(`{"jsonmodel_type": "accession", "uri": "/repositories/2/accessions/7", "title": "Papers"}`))
+	if accession, ok := obj.(*Accession); err != nil || ok == false || accession.ID != 7 || accession.Title != "Papers" {
+		t.Errorf("DecodeJSONModel() expected an *Accession, got %T %+v, %v", obj, obj, err)
+	}
+	obj, err = DecodeJSONModel([]byte(`{"jsonmodel_type": "agent_person", "uri": "/agents/people/3", "related_agents": [{"ref": "/agents/people/4", "relator": "is_associated_with", "jsonmodel_type": "agent_relationship_associative"}]}`))
+	if person, ok := obj.(*AgentPerson); err != nil || ok == false || len(person.RelatedAgents) != 1 {
+		t.Errorf("DecodeJSONModel() expected an *AgentPerson with a related agent, got %T, %v", obj, err)
+	}
+	obj, err = DecodeJSONModel([]byte(`{"jsonmodel_type": "agent_person", "uri": "/agents/people/5", "names": [{"primary_name": "Millikan", "rest_of_name": "Robert A."}], "notes": [{"jsonmodel_type": "note_bioghist", "label": "Biography", "subnotes": [{"jsonmodel_type": "note_text", "content": "Physicist"}]}]}`))
+	if person, ok := obj.(*AgentPerson); err != nil || ok == false || person.ID != 5 || len(person.Notes) != 1 || person.Notes[0]["label"] != "Biography" {
+		t.Errorf("DecodeJSONModel() expected an *AgentPerson with notes, got %T %+v, %v", obj, obj, err)
+	}
+	obj, err = DecodeJSONModel([]byte(`{"jsonmodel_type": "payment", "amount": 5}`))
+	if _, ok := obj.(Object); err != nil || ok == false {
+		t.Errorf("DecodeJSONModel() expected an Object for an unregistered type, got %T, %v", obj, err)
+	}
+
+	type Payment struct {
+		Amount int `json:"amount"`
+	}
+	if err := RegisterJSONModel("payment", func() interface{} { return new(Payment) }); err != nil {
+		t.Fatalf("RegisterJSONModel() %s", err)
+	}
+	defer UnregisterJSONModel("payment")
+	if err := RegisterJSONModel("payment", func() interface{} { return new(Payment) }); err == nil {
+		t.Errorf("RegisterJSONModel() should refuse a duplicate type")
+	}
+	obj, err = DecodeJSONModel([]byte(`{"jsonmodel_type": "payment", "amount": 5}`))
+	if payment, ok := obj.(*Payment); err != nil || ok == false || payment.Amount != 5 {
+		t.Errorf("DecodeJSONModel() expected a *Payment, got %T, %v", obj, err)
+	}
+}
+
+func TestUserAgent(t *testing.T) {
+	userAgents := []string{}
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		userAgents = append(userAgents, r.Header.Get("User-Agent"))
+		fmt.Fprintf(w, `{"session": "abc", "uri": "/repositories/2"}`)
+	}))
+	defer ts.Close()
+
+	api, _ := NewClient(ts.URL, WithCredentials("admin", "admin"))
+	api.Login()
+	api.GetRepository(2)
+	api.UserAgent = "harvester/1.0"
+	api.GetRepository(2)
+	expected := []string{DefaultUserAgent(), DefaultUserAgent(), "harvester/1.0"}
+	if strings.Join(userAgents, ", ") != strings.Join(expected, ", ") {
+		t.Errorf("User-Agent %q, expected %q", userAgents, expected)
+	}
+	if strings.HasPrefix(DefaultUserAgent(), "gospace/"+Version) == false {
+		t.Errorf("DefaultUserAgent() %q should include the version", DefaultUserAgent())
+	}
+}
+
+func TestListPage(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		q := r.URL.Query()
+		if r.URL.Path != "/repositories/2/accessions" || q.Get("all_ids") != "" || q.Get("page_size") != "2" {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		page, _ := strconv.Atoi(q.Get("page"))
+		fmt.Fprintf(w, `{"first_page": 1, "last_page": 2, "this_page": %d, "total": 3, "results": [`, page)
+		if page == 1 {
+			fmt.Fprintf(w, `{"uri": "/repositories/2/accessions/1", "title": "One"}, {"uri": "/repositories/2/accessions/2", "title": "Two"}`)
+		} else {
+			fmt.Fprintf(w, `{"uri": "/repositories/2/accessions/3", "title": "Three"}`)
+		}
+		fmt.Fprintf(w, "]}")
+	}))
+	defer ts.Close()
+
+	api, _ := NewClient(ts.URL)
+	results, err := api.ListAccessionsPage(2, 2, 2)
+	if err != nil {
+		t.Fatalf("ListAccessionsPage() %s", err)
+	}
+	if results.ThisPage != 2 || results.LastPage != 2 || results.Total != 3 || len(results.Results) != 1 {
+		t.Errorf("ListAccessionsPage() unexpected page %+v", results)
+	}
+	if accession := results.Results[0]; accession.ID != 3 || accession.Title != "Three" {
+		t.Errorf("ListAccessionsPage() unexpected record %+v", accession)
+	}
+}
+
+func TestEachAccession(t *testing.T) {
+	pages := 0
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		pages++
+		page, _ := strconv.Atoi(r.URL.Query().Get("page"))
+		fmt.Fprintf(w, `{"first_page": 1, "last_page": 3, "this_page": %d, "total": 5, "results": [`, page)
+		for i := 1; i <= 2 && (page-1)*2+i <= 5; i++ {
+			if i > 1 {
+				fmt.Fprintf(w, ",")
+			}
+			fmt.Fprintf(w, `{"uri": "/repositories/2/accessions/%d"}`, (page-1)*2+i)
+		}
+		fmt.Fprintf(w, "]}")
+	}))
+	defer ts.Close()
+
+	api, _ := NewClient(ts.URL)
+	ids := []int{}
+	it := NewIterator[Accession](api, "/repositories/2/accessions", 2)
+	for it.Next() {
+		ids = append(ids, it.Record().ID)
+	}
+	if it.Err() != nil || fmt.Sprintf("%v", ids) != "[1 2 3 4 5]" || it.Total() != 5 || pages != 3 {
+		t.Errorf("Iterator unexpected %v after %d pages, %v", ids, pages, it.Err())
+	}
+
+	pages, ids = 0, []int{}
+	err := api.EachAccession(2, func(accession *Accession) error {
+		ids = append(ids, accession.ID)
+		if len(ids) == 3 {
+			return ErrStopIteration
+		}
+		return nil
+	})
+	if err != nil || len(ids) != 3 {
+		t.Errorf("EachAccession() should stop early, %v, %v", ids, err)
+	}
+
+	pages, ids = 0, []int{}
+	err = api.EachAccession(2, func(accession *Accession) error {
+		ids = append(ids, accession.ID)
+		return fmt.Errorf("accession %d, %w", accession.ID, ErrStopIteration)
+	})
+	if err != nil || len(ids) != 1 {
+		t.Errorf("EachAccession() should stop on a wrapped ErrStopIteration, %v, %v", ids, err)
+	}
+}
+
+func TestModifiedSince(t *testing.T) {
+	queries := []string{}
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		queries = append(queries, r.URL.Query().Get("modified_since"))
+		if r.URL.Query().Get("all_ids") == "true" {
+			fmt.Fprintf(w, "[3]")
+			return
+		}
+		fmt.Fprintf(w, `{"first_page": 1, "last_page": 1, "this_page": 1, "total": 0, "results": []}`)
+	}))
+	defer ts.Close()
+
+	api, _ := NewClient(ts.URL)
+	since := time.Unix(1488326400, 0)
+	api.ListAccessions(2, since)
+	api.ListAgents("people")
+	api.ListSubjectsPage(1, 10, time.Time{}, since)
+	if strings.Join(queries, ",") != "1488326400,,1488326400" {
+		t.Errorf("modified_since unexpected %q", queries)
+	}
+}
+
+func TestGetDeleteFeed(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/delete-feed" || r.URL.Query().Get("page") != "2" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		fmt.Fprintf(w, `{"first_page": 1, "last_page": 2, "this_page": 2, "total": 11, "results": ["/repositories/2/accessions/5"]}`)
+	}))
+	defer ts.Close()
+
+	api, _ := NewClient(ts.URL)
+	feed, err := api.GetDeleteFeed(2, 10)
+	if err != nil || feed.Total != 11 || len(feed.Results) != 1 || feed.Results[0] != "/repositories/2/accessions/5" {
+		t.Errorf("GetDeleteFeed() unexpected %+v, %v", feed, err)
+	}
+}
+
+func TestUpdateFeeds(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/update-feed":
+			fmt.Fprintf(w, `[{"sequence": %s1, "uri": "/repositories/2/accessions/1", "record": {"title": "Papers"}}]`, r.URL.Query().Get("last_sequence"))
+		case "/repositories":
+			fmt.Fprintf(w, `[{"uri": "/repositories/2"}, {"uri": "/repositories/3"}]`)
+		case "/repositories/2/search":
+			if r.URL.Query().Get("q") != "system_mtime:[2017-03-01T00:00:00Z TO *]" {
+				w.WriteHeader(http.StatusBadRequest)
+				return
+			}
+			fmt.Fprintf(w, `{"total_hits": 4, "results": []}`)
+		default:
+			fmt.Fprintf(w, `{"total_hits": 0, "results": []}`)
+		}
+	}))
+	defer ts.Close()
+
+	api, _ := NewClient(ts.URL)
+	entries, err := api.GetUpdateFeed(4)
+	if err != nil || len(entries) != 1 || entries[0].Sequence != 41 || entries[0].Record["title"] != "Papers" {
+		t.Errorf("GetUpdateFeed() unexpected %v, %v", entries, err)
+	}
+	repoIDs, err := api.ChangedRepositories(time.Date(2017, 3, 1, 0, 0, 0, 0, time.UTC))
+	if err != nil || fmt.Sprintf("%v", repoIDs) != "[2]" {
+		t.Errorf("ChangedRepositories() unexpected %v, %v", repoIDs, err)
+	}
+}
+
+func TestGetVersion(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintf(w, "ArchivesSpace (v2.0.1)")
+	}))
+	defer ts.Close()
+
+	api, _ := NewClient(ts.URL)
+	version, err := api.GetVersion()
+	if err != nil || version.String() != "v2.0.1" {
+		t.Fatalf("GetVersion() unexpected %v, %v", version, err)
+	}
+	if version.AtLeast(2, 0, 0) == false || version.AtLeast(2, 1, 0) == true || version.Compare(1, 9, 9) != 1 {
+		t.Errorf("ServerVersion comparisons wrong for %s", version)
+	}
+	if version, err := ParseServerVersion("2.8"); err != nil || version.Compare(2, 8, 0) != 0 {
+		t.Errorf("ParseServerVersion(\"2.8\") unexpected %v, %v", version, err)
+	}
+	if _, err := ParseServerVersion("ArchivesSpace"); err == nil {
+		t.Errorf("ParseServerVersion() should fail without a version number")
+	}
+}
+
+func TestUpdateWith(t *testing.T) {
+	lockVersion, saves := 1, 0
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == "GET" {
+			fmt.Fprintf(w, `{"uri": "/repositories/2/accessions/7", "title": "Papers", "lock_version": %d}`, lockVersion)
+			return
+		}
+		obj := Object{}
+		json.NewDecoder(r.Body).Decode(&obj)
+		saves++
+		// Someone else saves the record between our first GET and POST
+		if saves == 1 {
+			lockVersion++
+		}
+		if fmt.Sprintf("%v", obj["lock_version"]) != fmt.Sprintf("%d", lockVersion) {
+			w.WriteHeader(http.StatusConflict)
+			fmt.Fprintf(w, `{"error": {"lock_version": ["The record you tried to update has been modified since you fetched it."]}}`)
+			return
+		}
+		fmt.Fprintf(w, `{"status": "Updated", "uri": "/repositories/2/accessions/7"}`)
+	}))
+	defer ts.Close()
+
+	mutate := func(accession *Accession) error {
+		accession.Title = "Papers, 1920-1960"
+		return nil
+	}
+	api, _ := NewClient(ts.URL)
+	if _, err := UpdateWith(api, "/repositories/2/accessions/7", mutate); IsConflict(err) == false {
+		t.Errorf("UpdateWith() should return the conflict without ConflictRetries, %v", err)
+	}
+	lockVersion, saves = 1, 0
+	api.ConflictRetries = 2
+	if _, err := UpdateWith(api, "/repositories/2/accessions/7", mutate); err != nil || saves != 2 {
+		t.Errorf("UpdateWith() should refetch and retry, %d saves, %v", saves, err)
+	}
+}
+
+func TestUpdateAgentWith(t *testing.T) {
+	var saved Object
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == "GET" {
+			fmt.Fprintf(w, `{"uri": %q, "title": "Doiel, R.", "lock_version": 3}`, r.URL.Path)
+			return
+		}
+		saved = Object{}
+		json.NewDecoder(r.Body).Decode(&saved)
+		fmt.Fprintf(w, `{"status": "Updated", "uri": %q}`, r.URL.Path)
+	}))
+	defer ts.Close()
+
+	api, _ := NewClient(ts.URL)
+	_, err := api.UpdateAgentWith("people", 4, func(agent *Agent) error {
+		agent.Title = "Doiel, R. S."
+		return nil
+	})
+	if err != nil || saved["title"] != "Doiel, R. S." || saved["uri"] != "/agents/people/4" || fmt.Sprintf("%v", saved["lock_version"]) != "3" {
+		t.Errorf("UpdateAgentWith() saved %v, %v", saved, err)
+	}
+	_, err = api.UpdateAccessionWith(2, 7, func(accession *Accession) error {
+		return fmt.Errorf("no changes")
+	})
+	if err == nil || strings.Contains(err.Error(), "no changes") == false {
+		t.Errorf("UpdateAccessionWith() should return the callback's error, %v", err)
+	}
+}
+
+func TestResourceCRUD(t *testing.T) {
+	var created Object
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.Method == "POST" && r.URL.Path == "/repositories/2/resources":
+			created = Object{}
+			json.NewDecoder(r.Body).Decode(&created)
+			fmt.Fprintf(w, `{"status": "Created", "id": 9, "lock_version": 0, "uri": "/repositories/2/resources/9"}`)
+		case r.Method == "GET" && r.URL.Path == "/repositories/2/resources/9":
+			fmt.Fprintf(w, `{"uri": "/repositories/2/resources/9", "title": "Papers", "finding_aid_status": "completed", "restrictions": true}`)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer ts.Close()
+
+	api, _ := NewClient(ts.URL)
+	resource := &Resource{Title: "Papers", ID0: "MSS-01", Level: "collection"}
+	if _, err := api.CreateResource(2, resource); err != nil || resource.ID != 9 || resource.URI != "/repositories/2/resources/9" {
+		t.Fatalf("CreateResource() unexpected %+v, %v", resource, err)
+	}
+	if created["jsonmodel_type"] != "resource" || created["id_0"] != "MSS-01" {
+		t.Errorf("CreateResource() posted %v", created)
+	}
+	resource, err := api.GetResource(2, 9)
+	if err != nil || resource.ID != 9 || resource.FindingAidStatus != "completed" || resource.Restrictions == false {
+		t.Errorf("GetResource() unexpected %+v, %v", resource, err)
+	}
+}
+
+// missingJSON returns the path of the first value in want that isn't in got. False,
+// empty strings and empty lists may be left out, ArchivesSpace defaults them.
+func missingJSON(prefix string, want, got interface{}) string {
+	if got == nil && isZeroJSON(want) == true {
+		return ""
+	}
+	switch want := want.(type) {
+	case map[string]interface{}:
+		members, ok := got.(map[string]interface{})
+		if ok == false {
+			return prefix
+		}
+		for key, value := range want {
+			if missing := missingJSON(prefix+"."+key, value, members[key]); missing != "" {
+				return missing
+			}
+		}
+	case []interface{}:
+		items, ok := got.([]interface{})
+		if ok == false || len(items) != len(want) {
+			return prefix
+		}
+		for i, value := range want {
+			if missing := missingJSON(fmt.Sprintf("%s[%d]", prefix, i), value, items[i]); missing != "" {
+				return missing
+			}
+		}
+	default:
+		if want != got {
+			return prefix
+		}
+	}
+	return ""
+}
+
+// isZeroJSON reports if value is a JSON false, empty string or empty list
+func isZeroJSON(value interface{}) bool {
+	switch value := value.(type) {
+	case bool:
+		return value == false
+	case string:
+		return value == ""
+	case []interface{}:
+		return len(value) == 0
+	}
+	return false
+}
+
+func TestResourceRoundTrip(t *testing.T) {
+	src, err := ioutil.ReadFile(path.Join("testdata", "resource.json"))
+	if err != nil {
+		t.Fatalf("Can't read resource fixture, %s", err)
+	}
+	var updated []byte
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.Method == "GET" && r.URL.Path == "/repositories/2/resources/237":
+			w.Write(src)
+		case r.Method == "POST" && r.URL.Path == "/repositories/2/resources/237":
+			updated, _ = ioutil.ReadAll(r.Body)
+			fmt.Fprintf(w, `{"status": "Updated", "id": 237, "lock_version": 5}`)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer ts.Close()
+
+	api, _ := NewClient(ts.URL)
+	resource, err := api.GetResource(2, 237)
+	if err != nil {
+		t.Fatalf("GetResource() %s", err)
+	}
+	if len(resource.LangMaterials) != 1 || resource.FindingAidScript != "Latn" || len(resource.Extras) != 1 {
+		t.Errorf("GetResource() unexpected %+v", resource)
+	}
+	if _, err := api.UpdateResource(resource); err != nil {
+		t.Fatalf("UpdateResource() %s", err)
+	}
+	var want, got interface{}
+	json.Unmarshal(src, &want)
+	if err := json.Unmarshal(updated, &got); err != nil {
+		t.Fatalf("UpdateResource() posted %s, %s", updated, err)
+	}
+	if missing := missingJSON("resource", want, got); missing != "" {
+		t.Errorf("UpdateResource() lost %s from the record, posted %s", missing, updated)
+	}
+}
+
+type crudCase struct {
+	name     string
+	path     string
+	model    string
+	record   string
+	posted   map[string]interface{}
+	required []string
+	absent   []string
+	create   func(api *ArchivesSpaceAPI) (JSONModel, error)
+	get      func(api *ArchivesSpaceAPI, id int) (JSONModel, error)
+	list     func(api *ArchivesSpaceAPI) ([]int, error)
+	update   func(api *ArchivesSpaceAPI, obj JSONModel) (*ResponseMsg, error)
+	delete   func(api *ArchivesSpaceAPI, obj JSONModel) (*ResponseMsg, error)
+}
+
+var crudCases = []*crudCase{
+	{
+		name:   "ArchivalObject",
+		path:   "/repositories/2/archival_objects",
+		model:  "archival_object",
+		record: `{"title": "Correspondence", "level": "file", "component_id": "B1F3", "ref_id": "aspace_f3a1", "resource": {"ref": "/repositories/2/resources/9"}, "parent": {"ref": "/repositories/2/archival_objects/4"}}`,
+		posted: map[string]interface{}{"title": "Correspondence", "component_id": "B1F3", "parent": map[string]interface{}{"ref": "/repositories/2/archival_objects/4"}},
+		create: func(api *ArchivesSpaceAPI) (JSONModel, error) {
+			obj := NewArchivalObject("/repositories/2/resources/9", "/repositories/2/archival_objects/4", "Correspondence", "file")
+			obj.ComponentID = "B1F3"
+			_, err := api.CreateArchivalObject(2, obj)
+			return obj, err
+		},
+		get:  func(api *ArchivesSpaceAPI, id int) (JSONModel, error) { return api.GetArchivalObject(2, id) },
+		list: func(api *ArchivesSpaceAPI) ([]int, error) { return api.ListArchivalObjects(2) },
+		update: func(api *ArchivesSpaceAPI, obj JSONModel) (*ResponseMsg, error) {
+			return api.UpdateArchivalObject(obj.(*ArchivalObject))
+		},
+		delete: func(api *ArchivesSpaceAPI, obj JSONModel) (*ResponseMsg, error) {
+			return api.DeleteArchivalObject(obj.(*ArchivalObject))
+		},
+	},
+	{
+		name:   "DigitalObject",
+		path:   "/repositories/2/digital_objects",
+		model:  "digital_object",
+		record: `{"title": "Letter, 1921", "digital_object_id": "caltech:2017-001", "file_versions": [{"file_uri": "https://example.edu/letter.pdf", "publish": true}], "external_ids": [{"external_id": "42", "source": "islandora"}]}`,
+		posted: map[string]interface{}{"digital_object_id": "caltech:2017-001", "file_versions": []interface{}{map[string]interface{}{"file_uri": "https://example.edu/letter.pdf", "publish": true}}},
+		create: func(api *ArchivesSpaceAPI) (JSONModel, error) {
+			obj := &DigitalObject{
+				Title:           "Letter, 1921",
+				DigitalObjectID: "caltech:2017-001",
+				FileVersions:    []*FileVersion{{FileURI: "https://example.edu/letter.pdf", Publish: true}},
+			}
+			_, err := api.CreateDigitalObject(2, obj)
+			return obj, err
+		},
+		get:  func(api *ArchivesSpaceAPI, id int) (JSONModel, error) { return api.GetDigitalObject(2, id) },
+		list: func(api *ArchivesSpaceAPI) ([]int, error) { return api.ListDigitalObjects(2) },
+		update: func(api *ArchivesSpaceAPI, obj JSONModel) (*ResponseMsg, error) {
+			return api.UpdateDigitalObject(obj.(*DigitalObject))
+		},
+		delete: func(api *ArchivesSpaceAPI, obj JSONModel) (*ResponseMsg, error) {
+			return api.DeleteDigitalObject(obj.(*DigitalObject))
+		},
+	},
+	{
+		name:   "DigitalObjectComponent",
+		path:   "/repositories/2/digital_object_components",
+		model:  "digital_object_component",
+		record: `{"title": "Page 2", "position": 1, "digital_object": {"ref": "/repositories/2/digital_objects/21"}, "parent": {"ref": "/repositories/2/digital_object_components/30"}}`,
+		posted: map[string]interface{}{"title": "Page 2", "digital_object": map[string]interface{}{"ref": "/repositories/2/digital_objects/21"}},
+		create: func(api *ArchivesSpaceAPI) (JSONModel, error) {
+			obj := NewDigitalObjectComponent("/repositories/2/digital_objects/21", "/repositories/2/digital_object_components/30", "Page 2")
+			_, err := api.CreateDigitalObjectComponent(2, obj)
+			return obj, err
+		},
+		get:  func(api *ArchivesSpaceAPI, id int) (JSONModel, error) { return api.GetDigitalObjectComponent(2, id) },
+		list: func(api *ArchivesSpaceAPI) ([]int, error) { return api.ListDigitalObjectComponents(2) },
+		update: func(api *ArchivesSpaceAPI, obj JSONModel) (*ResponseMsg, error) {
+			return api.UpdateDigitalObjectComponent(obj.(*DigitalObjectComponent))
+		},
+		delete: func(api *ArchivesSpaceAPI, obj JSONModel) (*ResponseMsg, error) {
+			return api.DeleteDigitalObjectComponent(obj.(*DigitalObjectComponent))
+		},
+	},
+	{
+		name:   "Subject",
+		path:   "/subjects",
+		model:  "subject",
+		record: `{"title": "Seismology", "source": "local", "vocabulary": "/vocabularies/1", "terms": [{"term": "Seismology", "term_type": "topical", "vocabulary": "/vocabularies/1"}]}`,
+		posted: map[string]interface{}{"source": "local", "terms": []interface{}{map[string]interface{}{"term": "Seismology", "term_type": "topical"}}},
+		create: func(api *ArchivesSpaceAPI) (JSONModel, error) {
+			subject := NewSubject("/vocabularies/1", "local", SubjectTerm("Seismology", "topical", "/vocabularies/1"))
+			_, err := api.CreateSubject(subject)
+			return subject, err
+		},
+		get:  func(api *ArchivesSpaceAPI, id int) (JSONModel, error) { return api.GetSubject(id) },
+		list: func(api *ArchivesSpaceAPI) ([]int, error) { return api.ListSubjects() },
+		update: func(api *ArchivesSpaceAPI, obj JSONModel) (*ResponseMsg, error) {
+			return api.UpdateSubject(obj.(*Subject))
+		},
+		delete: func(api *ArchivesSpaceAPI, obj JSONModel) (*ResponseMsg, error) {
+			return api.DeleteSubject(obj.(*Subject))
+		},
+	},
+	{
+		name:   "Classification",
+		path:   "/repositories/2/classifications",
+		model:  "classification",
+		record: `{"identifier": "GEO", "title": "Geology", "publish": true, "linked_records": [{"ref": "/repositories/2/resources/9"}]}`,
+		posted: map[string]interface{}{"identifier": "GEO", "title": "Geology", "publish": true},
+		create: func(api *ArchivesSpaceAPI) (JSONModel, error) {
+			classification := &Classification{Identifier: "GEO", Title: "Geology", Publish: true}
+			_, err := api.CreateClassification(2, classification)
+			return classification, err
+		},
+		get:  func(api *ArchivesSpaceAPI, id int) (JSONModel, error) { return api.GetClassification(2, id) },
+		list: func(api *ArchivesSpaceAPI) ([]int, error) { return api.ListClassifications(2) },
+		update: func(api *ArchivesSpaceAPI, obj JSONModel) (*ResponseMsg, error) {
+			return api.UpdateClassification(obj.(*Classification))
+		},
+		delete: func(api *ArchivesSpaceAPI, obj JSONModel) (*ResponseMsg, error) {
+			return api.DeleteClassification(obj.(*Classification))
+		},
+	},
+	{
+		name:   "ClassificationTerm",
+		path:   "/repositories/2/classification_terms",
+		model:  "classification_term",
+		record: `{"identifier": "GEO.1", "title": "Seismology", "classification": {"ref": "/repositories/2/classifications/1"}}`,
+		posted: map[string]interface{}{"identifier": "GEO.1", "classification": map[string]interface{}{"ref": "/repositories/2/classifications/1"}},
+		absent: []string{"parent"},
+		create: func(api *ArchivesSpaceAPI) (JSONModel, error) {
+			term := NewClassificationTerm("/repositories/2/classifications/1", "", "GEO.1", "Seismology")
+			_, err := api.CreateClassificationTerm(2, term)
+			return term, err
+		},
+		get:  func(api *ArchivesSpaceAPI, id int) (JSONModel, error) { return api.GetClassificationTerm(2, id) },
+		list: func(api *ArchivesSpaceAPI) ([]int, error) { return api.ListClassificationTerms(2) },
+		update: func(api *ArchivesSpaceAPI, obj JSONModel) (*ResponseMsg, error) {
+			return api.UpdateClassificationTerm(obj.(*ClassificationTerm))
+		},
+		delete: func(api *ArchivesSpaceAPI, obj JSONModel) (*ResponseMsg, error) {
+			return api.DeleteClassificationTerm(obj.(*ClassificationTerm))
+		},
+	},
+	{
+		name:   "TopContainer",
+		path:   "/repositories/2/top_containers",
+		model:  "top_container",
+		record: `{"type": "box", "indicator": "1", "barcode": "3901", "container_locations": [{"ref": "/locations/12", "status": "current", "start_date": "2026-01-05"}]}`,
+		posted: map[string]interface{}{"type": "box", "indicator": "1", "barcode": "3901"},
+		create: func(api *ArchivesSpaceAPI) (JSONModel, error) {
+			container := &TopContainer{Type: "box", Indicator: "1", Barcode: "3901"}
+			_, err := api.CreateTopContainer(2, container)
+			return container, err
+		},
+		get:  func(api *ArchivesSpaceAPI, id int) (JSONModel, error) { return api.GetTopContainer(2, id) },
+		list: func(api *ArchivesSpaceAPI) ([]int, error) { return api.ListTopContainers(2) },
+		update: func(api *ArchivesSpaceAPI, obj JSONModel) (*ResponseMsg, error) {
+			return api.UpdateTopContainer(obj.(*TopContainer))
+		},
+		delete: func(api *ArchivesSpaceAPI, obj JSONModel) (*ResponseMsg, error) {
+			return api.DeleteTopContainer(obj.(*TopContainer))
+		},
+	},
+	{
+		name:   "ContainerProfile",
+		path:   "/container_profiles",
+		model:  "container_profile",
+		record: `{"name": "Hollinger box", "dimension_units": "inches", "extent_dimension": "width", "depth": "5", "height": "10.25", "width": "15.5"}`,
+		posted: map[string]interface{}{"name": "Hollinger box", "depth": "5", "height": "10.25", "width": "15.5"},
+		create: func(api *ArchivesSpaceAPI) (JSONModel, error) {
+			profile := NewContainerProfile("Hollinger box", "inches", "width", "5", "10.25", "15.5")
+			_, err := api.CreateContainerProfile(profile)
+			return profile, err
+		},
+		get:  func(api *ArchivesSpaceAPI, id int) (JSONModel, error) { return api.GetContainerProfile(id) },
+		list: func(api *ArchivesSpaceAPI) ([]int, error) { return api.ListContainerProfiles() },
+		update: func(api *ArchivesSpaceAPI, obj JSONModel) (*ResponseMsg, error) {
+			return api.UpdateContainerProfile(obj.(*ContainerProfile))
+		},
+		delete: func(api *ArchivesSpaceAPI, obj JSONModel) (*ResponseMsg, error) {
+			return api.DeleteContainerProfile(obj.(*ContainerProfile))
+		},
+	},
+	{
+		name:   "Location",
+		path:   "/locations",
+		model:  "location",
+		record: `{"building": "Beckman", "floor": "B1", "room": "12", "classification": "A", "coordinate_1_label": "Range", "coordinate_1_indicator": "1"}`,
+		posted: map[string]interface{}{"building": "Beckman", "classification": "A", "coordinate_1_indicator": "1"},
+		create: func(api *ArchivesSpaceAPI) (JSONModel, error) {
+			location := &Location{Building: "Beckman", Floor: "B1", Room: "12", Classification: "A", Coordinate1Label: "Range", Coordinate1Indicator: "1"}
+			_, err := api.CreateLocation(location)
+			return location, err
+		},
+		get:  func(api *ArchivesSpaceAPI, id int) (JSONModel, error) { return api.GetLocation(id) },
+		list: func(api *ArchivesSpaceAPI) ([]int, error) { return api.ListLocations() },
+		update: func(api *ArchivesSpaceAPI, obj JSONModel) (*ResponseMsg, error) {
+			return api.UpdateLocation(obj.(*Location))
+		},
+		delete: func(api *ArchivesSpaceAPI, obj JSONModel) (*ResponseMsg, error) {
+			return api.DeleteLocation(obj.(*Location))
+		},
+	},
+	{
+		name:   "LocationProfile",
+		path:   "/location_profiles",
+		model:  "location_profile",
+		record: `{"name": "Compact shelf", "dimension_units": "inches", "depth": "12"}`,
+		posted: map[string]interface{}{"name": "Compact shelf", "dimension_units": "inches", "depth": "12"},
+		create: func(api *ArchivesSpaceAPI) (JSONModel, error) {
+			profile := &LocationProfile{Name: "Compact shelf", DimensionUnits: "inches", Depth: "12"}
+			_, err := api.CreateLocationProfile(profile)
+			return profile, err
+		},
+		get:  func(api *ArchivesSpaceAPI, id int) (JSONModel, error) { return api.GetLocationProfile(id) },
+		list: func(api *ArchivesSpaceAPI) ([]int, error) { return api.ListLocationProfiles() },
+		update: func(api *ArchivesSpaceAPI, obj JSONModel) (*ResponseMsg, error) {
+			return api.UpdateLocationProfile(obj.(*LocationProfile))
+		},
+		delete: func(api *ArchivesSpaceAPI, obj JSONModel) (*ResponseMsg, error) {
+			return api.DeleteLocationProfile(obj.(*LocationProfile))
+		},
+	},
+	{
+		name:   "Event",
+		path:   "/repositories/2/events",
+		model:  "event",
+		record: `{"event_type": "digitization", "outcome": "pass", "timestamp": "2026-01-05T10:00:00Z", "linked_agents": [{"ref": "/agents/software/1", "role": "executing_program"}], "linked_records": [{"ref": "/repositories/2/digital_objects/5", "role": "outcome"}]}`,
+		posted: map[string]interface{}{
+			"event_type":     "digitization",
+			"linked_agents":  []interface{}{map[string]interface{}{"ref": "/agents/software/1", "role": "executing_program"}},
+			"linked_records": []interface{}{map[string]interface{}{"ref": "/repositories/2/digital_objects/5", "role": "outcome"}},
+		},
+		required: []string{"timestamp"},
+		create: func(api *ArchivesSpaceAPI) (JSONModel, error) {
+			event := NewEvent("digitization", "pass")
+			event.LinkAgent("/agents/software/1", "executing_program")
+			event.LinkRecord("/repositories/2/digital_objects/5", "outcome")
+			_, err := api.CreateEvent(2, event)
+			return event, err
+		},
+		get:  func(api *ArchivesSpaceAPI, id int) (JSONModel, error) { return api.GetEvent(2, id) },
+		list: func(api *ArchivesSpaceAPI) ([]int, error) { return api.ListEvents(2) },
+		update: func(api *ArchivesSpaceAPI, obj JSONModel) (*ResponseMsg, error) {
+			return api.UpdateEvent(obj.(*Event))
+		},
+		delete: func(api *ArchivesSpaceAPI, obj JSONModel) (*ResponseMsg, error) {
+			return api.DeleteEvent(obj.(*Event))
+		},
+	},
+	{
+		name:   "Assessment",
+		path:   "/repositories/2/assessments",
+		model:  "assessment",
+		record: `{"survey_begin": "2026-01-05", "records": [{"ref": "/repositories/2/resources/9"}], "surveyed_by": [{"ref": "/agents/people/4"}], "ratings": [{"definition_id": 1, "label": "Housing Quality", "value": "3", "global": true}]}`,
+		posted: map[string]interface{}{
+			"records":             []interface{}{map[string]interface{}{"ref": "/repositories/2/resources/9"}},
+			"surveyed_by":         []interface{}{map[string]interface{}{"ref": "/agents/people/4"}},
+			"conservation_issues": []interface{}{map[string]interface{}{"definition_id": float64(30), "value": "true"}},
+		},
+		create: func(api *ArchivesSpaceAPI) (JSONModel, error) {
+			assessment := NewAssessment([]string{"/repositories/2/resources/9"}, "/agents/people/4")
+			assessment.SurveyBegin = "2026-01-05"
+			assessment.ConservationIssues = append(assessment.ConservationIssues, &AssessmentAttribute{DefinitionID: 30, Value: "true"})
+			_, err := api.CreateAssessment(2, assessment)
+			return assessment, err
+		},
+		get:  func(api *ArchivesSpaceAPI, id int) (JSONModel, error) { return api.GetAssessment(2, id) },
+		list: func(api *ArchivesSpaceAPI) ([]int, error) { return api.ListAssessments(2) },
+		update: func(api *ArchivesSpaceAPI, obj JSONModel) (*ResponseMsg, error) {
+			return api.UpdateAssessment(obj.(*Assessment))
+		},
+		delete: func(api *ArchivesSpaceAPI, obj JSONModel) (*ResponseMsg, error) {
+			return api.DeleteAssessment(obj.(*Assessment))
+		},
+	},
+	{
+		name:   "AgentCorporateEntity",
+		path:   "/agents/corporate_entities",
+		model:  "agent_corporate_entity",
+		record: `{"names": [{"primary_name": "Caltech", "subordinate_name_1": "Library", "number": "1"}], "notes": [{"jsonmodel_type": "note_bioghist", "label": "History"}], "related_agents": [{"ref": "/agents/people/4", "relator": "is_associated_with", "jsonmodel_type": "agent_relationship_associative"}]}`,
+		posted: map[string]interface{}{"names": []interface{}{map[string]interface{}{"primary_name": "Caltech", "subordinate_name_1": "Library", "subordinate_name_2": "Archives"}}},
+		create: func(api *ArchivesSpaceAPI) (JSONModel, error) {
+			agent := NewAgentCorporateEntity("Caltech", "Library", "Archives")
+			_, err := api.CreateAgentCorporateEntity(agent)
+			return agent, err
+		},
+		get:  func(api *ArchivesSpaceAPI, id int) (JSONModel, error) { return api.GetAgentCorporateEntity(id) },
+		list: func(api *ArchivesSpaceAPI) ([]int, error) { return api.ListAgentCorporateEntities() },
+		update: func(api *ArchivesSpaceAPI, obj JSONModel) (*ResponseMsg, error) {
+			return api.UpdateAgentCorporateEntity(obj.(*AgentCorporateEntity))
+		},
+		delete: func(api *ArchivesSpaceAPI, obj JSONModel) (*ResponseMsg, error) {
+			return api.DeleteAgentCorporateEntity(obj.(*AgentCorporateEntity))
+		},
+	},
+	{
+		name:   "AgentFamily",
+		path:   "/agents/families",
+		model:  "agent_family",
+		record: `{"publish": true, "names": [{"family_name": "Millikan", "prefix": "The", "dates": "1868-1953", "authorized": true}], "dates_of_existence": [{"date_type": "range", "label": "existence", "begin": "1868"}], "related_agents": [{"ref": "/agents/people/4", "relator": "is_associated_with", "jsonmodel_type": "agent_relationship_associative"}]}`,
+		posted: map[string]interface{}{"names": []interface{}{map[string]interface{}{"family_name": "Millikan", "dates": "1868-1953"}}},
+		create: func(api *ArchivesSpaceAPI) (JSONModel, error) {
+			agent := NewAgentFamily("Millikan", "1868-1953")
+			_, err := api.CreateAgentFamily(agent)
+			return agent, err
+		},
+		get:  func(api *ArchivesSpaceAPI, id int) (JSONModel, error) { return api.GetAgentFamily(id) },
+		list: func(api *ArchivesSpaceAPI) ([]int, error) { return api.ListAgentFamilies() },
+		update: func(api *ArchivesSpaceAPI, obj JSONModel) (*ResponseMsg, error) {
+			return api.UpdateAgentFamily(obj.(*AgentFamily))
+		},
+		delete: func(api *ArchivesSpaceAPI, obj JSONModel) (*ResponseMsg, error) {
+			return api.DeleteAgentFamily(obj.(*AgentFamily))
+		},
+	},
+	{
+		name:   "AgentSoftware",
+		path:   "/agents/software",
+		model:  "agent_software",
+		record: `{"linked_agent_roles": ["creator"], "names": [{"software_name": "ImageMagick", "version": "7.1.1", "manufacturer": "ImageMagick Studio LLC"}], "related_agents": [{"ref": "/agents/people/4", "relator": "is_associated_with", "jsonmodel_type": "agent_relationship_associative"}]}`,
+		posted: map[string]interface{}{"names": []interface{}{map[string]interface{}{"software_name": "ImageMagick", "version": "7.1.1", "manufacturer": "ImageMagick Studio LLC"}}},
+		absent: []string{"agent_contacts"},
+		create: func(api *ArchivesSpaceAPI) (JSONModel, error) {
+			agent := NewAgentSoftware("ImageMagick", "7.1.1", "ImageMagick Studio LLC")
+			_, err := api.CreateAgentSoftware(agent)
+			return agent, err
+		},
+		get:  func(api *ArchivesSpaceAPI, id int) (JSONModel, error) { return api.GetAgentSoftware(id) },
+		list: func(api *ArchivesSpaceAPI) ([]int, error) { return api.ListAgentSoftware() },
+		update: func(api *ArchivesSpaceAPI, obj JSONModel) (*ResponseMsg, error) {
+			return api.UpdateAgentSoftware(obj.(*AgentSoftware))
+		},
+		delete: func(api *ArchivesSpaceAPI, obj JSONModel) (*ResponseMsg, error) {
+			return api.DeleteAgentSoftware(obj.(*AgentSoftware))
+		},
+	},
+	{
+		name:   "CustomReportTemplate",
+		path:   "/repositories/2/custom_report_templates",
+		model:  "custom_report_template",
+		record: `{"name": "Accessions by donor", "description": "Accessions with their donors", "data": "{\"custom_record_type\": \"accession\"}"}`,
+		posted: map[string]interface{}{"name": "Accessions by donor"},
+		create: func(api *ArchivesSpaceAPI) (JSONModel, error) {
+			tmpl := &CustomReportTemplate{Name: "Accessions by donor", Description: "Accessions with their donors", Data: `{"custom_record_type": "accession"}`}
+			_, err := api.CreateCustomReportTemplate(2, tmpl)
+			return tmpl, err
+		},
+		get:  func(api *ArchivesSpaceAPI, id int) (JSONModel, error) { return api.GetCustomReportTemplate(2, id) },
+		list: func(api *ArchivesSpaceAPI) ([]int, error) { return api.ListCustomReportTemplates(2) },
+		update: func(api *ArchivesSpaceAPI, obj JSONModel) (*ResponseMsg, error) {
+			return api.UpdateCustomReportTemplate(obj.(*CustomReportTemplate))
+		},
+		delete: func(api *ArchivesSpaceAPI, obj JSONModel) (*ResponseMsg, error) {
+			return api.DeleteCustomReportTemplate(obj.(*CustomReportTemplate))
+		},
+	},
+}
+
+// TestTypedCRUD runs create, get, list, update and delete for each typed record
+// against one server, then checks a server error is surfaced.
+func TestTypedCRUD(t *testing.T) {
+	for _, c := range crudCases {
+		t.Run(c.name, func(t *testing.T) {
+			var (
+				failing bool
+				calls   []string
+				posted  = map[string]Object{}
+			)
+			uri := c.path + "/7"
+			ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				calls = append(calls, r.Method+" "+r.URL.Path)
+				switch {
+				case failing == true:
+					w.WriteHeader(http.StatusInternalServerError)
+					fmt.Fprintf(w, `{"error": "Database is unavailable"}`)
+				case r.Method == "POST" && r.URL.Path == c.path:
+					obj := Object{}
+					json.NewDecoder(r.Body).Decode(&obj)
+					posted[r.URL.Path] = obj
+					fmt.Fprintf(w, `{"status": "Created", "id": 7, "lock_version": 0, "uri": %q}`, uri)
+				case r.Method == "GET" && r.URL.Path == c.path:
+					fmt.Fprintf(w, `[7]`)
+				case r.Method == "GET" && r.URL.Path == uri:
+					obj := Object{}
+					json.Unmarshal([]byte(c.record), &obj)
+					obj["uri"], obj["lock_version"], obj["jsonmodel_type"] = uri, 4, c.model
+					json.NewEncoder(w).Encode(obj)
+				case r.Method == "POST" && r.URL.Path == uri:
+					obj := Object{}
+					json.NewDecoder(r.Body).Decode(&obj)
+					posted[r.URL.Path] = obj
+					fmt.Fprintf(w, `{"status": "Updated", "id": 7, "lock_version": 5, "uri": %q}`, uri)
+				case r.Method == "DELETE" && r.URL.Path == uri:
+					fmt.Fprintf(w, `{"status": "Deleted", "id": 7}`)
+				default:
+					w.WriteHeader(http.StatusNotFound)
+					fmt.Fprintf(w, `{"error": "Record not found"}`)
+				}
+			}))
+			defer ts.Close()
+
+			api, _ := NewClient(ts.URL)
+			obj, err := c.create(api)
+			if err != nil || obj.GetURI() != uri {
+				t.Fatalf("create unexpected %+v, %v", obj, err)
+			}
+			created := posted[c.path]
+			if created["jsonmodel_type"] != c.model || created["lock_version"] != float64(0) {
+				t.Errorf("create posted jsonmodel_type %v, lock_version %v", created["jsonmodel_type"], created["lock_version"])
+			}
+			if missing := missingJSON(c.model, c.posted, map[string]interface{}(created)); missing != "" {
+				t.Errorf("create didn't post %s, %v", missing, created)
+			}
+			for _, key := range c.required {
+				if created[key] == nil {
+					t.Errorf("create should post %s, %v", key, created)
+				}
+			}
+			for _, key := range c.absent {
+				if _, ok := created[key]; ok == true {
+					t.Errorf("create shouldn't post %s, %v", key, created[key])
+				}
+			}
+
+			obj, err = c.get(api, 7)
+			if err != nil || obj.GetURI() != uri {
+				t.Fatalf("get unexpected %+v, %v", obj, err)
+			}
+			if ids, err := c.list(api); err != nil || len(ids) != 1 || ids[0] != 7 {
+				t.Errorf("list unexpected %v, %v", ids, err)
+			}
+
+			// Saving what was fetched must send back every field and the lock_version
+			if _, err := c.update(api, obj); err != nil {
+				t.Fatalf("update %s", err)
+			}
+			updated := posted[uri]
+			if updated["lock_version"] != float64(4) || updated["uri"] != uri {
+				t.Errorf("update posted uri %v, lock_version %v", updated["uri"], updated["lock_version"])
+			}
+			record := map[string]interface{}{}
+			json.Unmarshal([]byte(c.record), &record)
+			if missing := missingJSON(c.model, record, map[string]interface{}(updated)); missing != "" {
+				t.Errorf("update lost %s, %v", missing, updated)
+			}
+
+			if _, err := c.delete(api, obj); err != nil {
+				t.Errorf("delete %s", err)
+			}
+			if last := calls[len(calls)-1]; last != "DELETE "+uri {
+				t.Errorf("delete requested %q", last)
+			}
+
+			failing = true
+			if _, err := c.create(api); hasStatus(err, http.StatusInternalServerError) == false || strings.Contains(err.Error(), "Database is unavailable") == false {
+				t.Errorf("create expected the server error, got %v", err)
+			}
+			if _, err := c.get(api, 7); hasStatus(err, http.StatusInternalServerError) == false {
+				t.Errorf("get expected the server error, got %v", err)
+			}
+			if _, err := c.update(api, obj); hasStatus(err, http.StatusInternalServerError) == false {
+				t.Errorf("update expected the server error, got %v", err)
+			}
+		})
+	}
+}
+
+func TestGetArchivalObjectByRefID(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.URL.Path == "/repositories/2/find_by_id/archival_objects" && r.URL.Query().Get("ref_id[]") == "aspace_f3a1":
+			fmt.Fprintf(w, `{"archival_objects": [{"ref": "/repositories/2/archival_objects/12"}]}`)
+		case r.URL.Path == "/repositories/2/archival_objects/12":
+			fmt.Fprintf(w, `{"uri": "/repositories/2/archival_objects/12", "ref_id": "aspace_f3a1", "component_id": "B1F3", "level": "file",
+"parent": {"ref": "/repositories/2/archival_objects/4"}, "notes": [{"jsonmodel_type": "note_multipart", "type": "scopecontent"}]}`)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+			fmt.Fprintf(w, `{"archival_objects": []}`)
+		}
+	}))
+	defer ts.Close()
+
+	api, _ := NewClient(ts.URL)
+	if _, err := api.CreateArchivalObject(2, &ArchivalObject{Title: "Orphan"}); err == nil {
+		t.Errorf("CreateArchivalObject() should require a resource")
+	}
+	obj, err := api.GetArchivalObjectByRefID(2, "aspace_f3a1")
+	if err != nil || obj.ID != 12 || obj.Level != "file" || obj.Notes[0]["type"] != "scopecontent" {
+		t.Errorf("GetArchivalObjectByRefID() unexpected %+v, %v", obj, err)
+	}
+	obj.SetConponentID("B2F1")
+	if obj.ComponentID != "B2F1" || obj.ConponentID() != "B2F1" {
+		t.Errorf("SetConponentID() should set ComponentID, %q", obj.ComponentID)
+	}
+	if _, err := api.GetArchivalObjectByRefID(2, "missing"); err == nil {
+		t.Errorf("GetArchivalObjectByRefID() should fail for an unknown ref_id")
+	}
+}
+
+func TestAddDigitalObjectInstance(t *testing.T) {
+	var linked Object
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.Method == "GET" && r.URL.Path == "/repositories/2/archival_objects/12":
+			fmt.Fprintf(w, `{"uri": "/repositories/2/archival_objects/12", "lock_version": 1, "instances": []}`)
+		case r.Method == "POST" && r.URL.Path == "/repositories/2/archival_objects/12":
+			linked = Object{}
+			json.NewDecoder(r.Body).Decode(&linked)
+			fmt.Fprintf(w, `{"status": "Updated", "uri": "/repositories/2/archival_objects/12"}`)
+		case r.Method == "GET" && r.URL.Path == "/repositories/2/digital_objects/21":
+			fmt.Fprintf(w, `{"uri": "/repositories/2/digital_objects/21", "digital_object_id": "caltech:2017-001",
+"external_ids": [{"external_id": "42", "source": "islandora"}], "linked_instances": [{"ref": "/repositories/2/archival_objects/12"}]}`)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer ts.Close()
+
+	api, _ := NewClient(ts.URL)
+	if _, err := api.AddDigitalObjectInstance("/repositories/2/archival_objects/12", "/repositories/2/digital_objects/21"); err != nil {
+		t.Errorf("AddDigitalObjectInstance() %s", err)
+	}
+	if instances, _ := linked["instances"].([]interface{}); len(instances) != 1 {
+		t.Errorf("AddDigitalObjectInstance() saved %v", linked)
+	}
+	obj, err := api.GetDigitalObject(2, 21)
+	if err != nil || obj.ExternalIDs[0].ExternalID != "42" || len(obj.LinkedInstances) != 1 {
+		t.Errorf("GetDigitalObject() unexpected %+v, %v", obj, err)
+	}
+}
+
+func TestSetDigitalObjectComponentParent(t *testing.T) {
+	requests := []string{}
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests = append(requests, r.Method+" "+r.URL.RequestURI())
+		fmt.Fprintf(w, `{"status": "Updated", "id": 31}`)
+	}))
+	defer ts.Close()
+
+	api, _ := NewClient(ts.URL)
+	if _, err := api.SetDigitalObjectComponentParent(2, 31, 0, 3); err != nil {
+		t.Errorf("SetDigitalObjectComponentParent() %s", err)
+	}
+	if len(requests) != 1 || requests[0] != "POST /repositories/2/digital_object_components/31/parent?position=3" {
+		t.Errorf("SetDigitalObjectComponentParent() requested %v", requests)
+	}
+}
+
+func TestAttachSubjectToAccession(t *testing.T) {
+	var saved Object
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.Method == "GET" && r.URL.Path == "/subjects/404":
+			w.WriteHeader(http.StatusNotFound)
+			fmt.Fprintf(w, `{"error": "Subject not found"}`)
+		case r.Method == "GET":
+			fmt.Fprintf(w, `{"uri": %q, "title": "Papers", "subjects": [{"ref": "/subjects/2"}]}`, r.URL.Path)
+		default:
+			saved = Object{}
+			json.NewDecoder(r.Body).Decode(&saved)
+			fmt.Fprintf(w, `{"status": "Updated", "uri": %q}`, r.URL.Path)
+		}
+	}))
+	defer ts.Close()
+
+	api, _ := NewClient(ts.URL)
+	if _, err := api.GetSubject(404); IsNotFound(err) == false {
+		t.Errorf("GetSubject() should return not found, %v", err)
+	}
+	if _, err := api.AttachSubjectToAccession(2, 7, "/subjects/8"); err != nil {
+		t.Fatalf("AttachSubjectToAccession() %s", err)
+	}
+	if subjects, _ := saved["subjects"].([]interface{}); len(subjects) != 2 || saved["title"] != "Papers" {
+		t.Errorf("AttachSubjectToAccession() saved %v", saved)
+	}
+}
+
+func TestGetClassificationTree(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/repositories/2/classifications/1/tree" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		fmt.Fprintf(w, `{"title": "Geology", "identifier": "GEO", "children": [{"title": "Seismology", "identifier": "GEO.1", "record_uri": "/repositories/2/classification_terms/5"}]}`)
+	}))
+	defer ts.Close()
+
+	api, _ := NewClient(ts.URL)
+	tree, err := api.GetClassificationTree(2, 1)
+	if err != nil || len(tree.Children) != 1 || tree.Children[0].Identifier != "GEO.1" {
+		t.Errorf("GetClassificationTree() unexpected %+v, %v", tree, err)
+	}
+}
+
+func TestSearchTopContainers(t *testing.T) {
+	var query url.Values
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/repositories/2/top_containers/search" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		query = r.URL.Query()
+		fmt.Fprintf(w, `{"response": {"numFound": 1, "docs": [{"uri": "/repositories/2/top_containers/40", "json": "{\"uri\": \"/repositories/2/top_containers/40\", \"indicator\": \"1\", \"barcode\": \"3901\"}"}]}}`)
+	}))
+	defer ts.Close()
+
+	api, _ := NewClient(ts.URL)
+	empty := false
+	containers, err := api.SearchTopContainers(2, &TopContainerSearch{Resources: []string{"/repositories/2/resources/9"}, Empty: &empty})
+	if err != nil || len(containers) != 1 || containers[0].ID != 40 || containers[0].Barcode != "3901" {
+		t.Errorf("SearchTopContainers() unexpected %v, %v", containers, err)
+	}
+	if query.Get("collection_resource[]") != "/repositories/2/resources/9" || query.Get("empty") != "false" || query.Get("exported") != "" {
+		t.Errorf("SearchTopContainers() query %v", query)
+	}
+}
+
+func TestLocationBatch(t *testing.T) {
+	var batch map[string]interface{}
+	dryRun := ""
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/locations/batch":
+			batch = map[string]interface{}{}
+			json.NewDecoder(r.Body).Decode(&batch)
+			dryRun = r.URL.Query().Get("dry_run")
+			if dryRun == "true" {
+				fmt.Fprintf(w, `[{"building": "Beckman", "coordinate_1_label": "Shelf", "coordinate_1_indicator": "1"}, {"building": "Beckman", "coordinate_1_label": "Shelf", "coordinate_1_indicator": "2"}]`)
+				return
+			}
+			fmt.Fprintf(w, `["/locations/20", "/locations/21"]`)
+		case "/locations/batch_update":
+			batch = map[string]interface{}{}
+			json.NewDecoder(r.Body).Decode(&batch)
+			fmt.Fprintf(w, `{"status": "Updated", "id": 20}`)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer ts.Close()
+
+	api, _ := NewClient(ts.URL)
+	locationBatch := &LocationBatch{
+		Building:         "Beckman",
+		Coordinate1Range: &CoordinateRange{Label: "Shelf", Start: "1", End: "2"},
+	}
+	locations, err := api.PreviewLocationBatch(locationBatch)
+	if err != nil || len(locations) != 2 || locations[1].Coordinate1Indicator != "2" || dryRun != "true" {
+		t.Errorf("PreviewLocationBatch() unexpected %v, %v, dry_run %q", locations, err, dryRun)
+	}
+	uris, err := api.CreateLocationBatch(locationBatch)
+	if err != nil || len(uris) != 2 || uris[0] != "/locations/20" || dryRun != "" {
+		t.Errorf("CreateLocationBatch() unexpected %v, %v, dry_run %q", uris, err, dryRun)
+	}
+	if r, ok := batch["coordinate_1_range"].(map[string]interface{}); ok == false || r["end"] != "2" || batch["jsonmodel_type"] != "location_batch" {
+		t.Errorf("CreateLocationBatch() posted %v", batch)
+	}
+	if _, err := api.UpdateLocationBatch(&LocationBatchUpdate{Room: "B12"}); err == nil {
+		t.Errorf("UpdateLocationBatch() expected an error without record_uris")
+	}
+	if _, err := api.UpdateLocationBatch(&LocationBatchUpdate{Room: "B12", RecordURIs: uris}); err != nil {
+		t.Errorf("UpdateLocationBatch() %s", err)
+	}
+	if batch["room"] != "B12" || len(batch["record_uris"].([]interface{})) != 2 {
+		t.Errorf("UpdateLocationBatch() posted %v", batch)
+	}
+}
+
+func TestUserCRUD(t *testing.T) {
+	var (
+		password string
+		posted   map[string]interface{}
+	)
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.Method == "POST" && r.URL.Path == "/users":
+			password = r.URL.Query().Get("password")
+			json.NewDecoder(r.Body).Decode(&posted)
+			fmt.Fprintf(w, `{"status": "Created", "id": 6, "lock_version": 0, "uri": "/users/6"}`)
+		case r.Method == "POST" && r.URL.Path == "/users/6":
+			password = r.URL.Query().Get("password")
+			w.WriteHeader(http.StatusForbidden)
+			fmt.Fprintf(w, `{"error": "Access denied"}`)
+		case r.Method == "GET" && r.URL.Path == "/users/6":
+			fmt.Fprintf(w, `{"uri": "/users/6", "username": "jdoe", "is_admin": true, "groups": ["/repositories/2/groups/3"], "permissions": {"/repositories/2": ["view_repository"]}}`)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer ts.Close()
+
+	api, _ := NewClient(ts.URL)
+	user := &User{Username: "jdoe", Name: "J. Doe", IsAdmin: true}
+	if _, err := api.CreateUser(&User{Username: "nopass"}, ""); err == nil {
+		t.Errorf("CreateUser() expected an error without a password")
+	}
+	if _, err := api.CreateUser(user, "s3cret"); err != nil || user.ID != 6 {
+		t.Fatalf("CreateUser() unexpected %+v, %v", user, err)
+	}
+	if password != "s3cret" || posted["is_admin"] != true {
+		t.Errorf("CreateUser() sent password %q, %v", password, posted)
+	}
+	user, err := api.GetUser(6)
+	if err != nil || user.ID != 6 || len(user.Groups) != 1 || user.Permissions["/repositories/2"][0] != "view_repository" {
+		t.Errorf("GetUser() unexpected %+v, %v", user, err)
+	}
+	_, err = api.UpdateUser(user, "n3w")
+	if password != "n3w" || err == nil || strings.Contains(err.Error(), "n3w") {
+		t.Errorf("UpdateUser() sent password %q, error should not include it %v", password, err)
+	}
+}
+
+func TestCreateUserNetworkErrorRedacted(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	ts.Close()
+
+	api, _ := NewClient(ts.URL)
+	_, err := api.CreateUser(&User{Username: "jdoe"}, "s3cret")
+	if err == nil || strings.Contains(err.Error(), "s3cret") {
+		t.Errorf("CreateUser() expected a network error without the password, got %v", err)
+	}
+	api.MaxResponseSize = 4
+	_, err = api.readBody(ts.URL+"/users?password=s3cret", strings.NewReader("12345"))
+	if err == nil || strings.Contains(err.Error(), "s3cret") {
+		t.Errorf("readBody() expected ErrResponseTooLarge without the password, got %v", err)
+	}
+}
+
+func TestApplyGroup(t *testing.T) {
+	var (
+		updated url.Values
+		posted  map[string]interface{}
+		created bool
+	)
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.Method == "GET" && r.URL.Path == "/repositories/2/groups":
+			fmt.Fprintf(w, `[{"uri": "/repositories/2/groups/5", "group_code": "repository-viewers", "lock_version": 3}]`)
+		case r.Method == "GET" && r.URL.Path == "/repositories/3/groups":
+			fmt.Fprintf(w, `[]`)
+		case r.Method == "POST" && r.URL.Path == "/repositories/2/groups/5":
+			updated = r.URL.Query()
+			json.NewDecoder(r.Body).Decode(&posted)
+			fmt.Fprintf(w, `{"status": "Updated", "id": 5, "lock_version": 4}`)
+		case r.Method == "POST" && r.URL.Path == "/repositories/3/groups":
+			created = true
+			fmt.Fprintf(w, `{"status": "Created", "id": 9, "uri": "/repositories/3/groups/9"}`)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer ts.Close()
+
+	api, _ := NewClient(ts.URL)
+	template := &Group{GroupCode: "repository-viewers", Description: "Viewers", MemberUsernames: []string{"jdoe"}, GrantsPermissions: []string{"view_repository"}}
+	group, err := api.ApplyGroup(2, template)
+	if err != nil || group.ID != 5 || updated.Get("with_members") != "true" || posted["lock_version"] != float64(3) {
+		t.Errorf("ApplyGroup(2) unexpected %+v, %v, %v, %v", group, err, updated, posted)
+	}
+	group, err = api.ApplyGroup(3, template)
+	if err != nil || created == false || group.ID != 9 || template.ID != 0 {
+		t.Errorf("ApplyGroup(3) unexpected %+v, %v", group, err)
+	}
+	if _, err := api.ApplyGroup(2, &Group{}); err == nil {
+		t.Errorf("ApplyGroup() expected an error without a group_code")
+	}
+}
+
+func TestListPermissions(t *testing.T) {
+	level := ""
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		level = r.URL.Query().Get("level")
+		fmt.Fprintf(w, `[{"permission_code": "view_repository", "description": "view the records in this repository", "level": "repository"}, {"permission_code": "administer_system", "level": "global"}]`)
+	}))
+	defer ts.Close()
+
+	api, _ := NewClient(ts.URL)
+	permissions, err := api.ListPermissions("")
+	if err != nil || len(permissions) != 2 || permissions[0].PermissionCode != "view_repository" || level != "all" {
+		t.Errorf("ListPermissions() unexpected %v, %v, level %q", permissions, err, level)
+	}
+	if err := api.ValidateGrants([]string{"view_repository"}); err != nil {
+		t.Errorf("ValidateGrants() %s", err)
+	}
+	if err := api.ValidateGrants([]string{"view_repository", "view_everything"}); err == nil || strings.Contains(err.Error(), "view_everything") == false {
+		t.Errorf("ValidateGrants() expected an error naming view_everything, got %v", err)
+	}
+}
+
+func TestVocabularyAndTerms(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.Method == "POST" && r.URL.Path == "/vocabularies":
+			fmt.Fprintf(w, `{"status": "Created", "id": 2, "lock_version": 0, "uri": "/vocabularies/2"}`)
+		case r.Method == "GET" && r.URL.Path == "/vocabularies" && r.URL.Query().Get("ref_id") == "lcsh":
+			fmt.Fprintf(w, `[{"uri": "/vocabularies/2", "ref_id": "lcsh", "name": "Library of Congress Subject Headings"}]`)
+		case r.Method == "GET" && r.URL.Path == "/vocabularies":
+			fmt.Fprintf(w, `[]`)
+		case r.Method == "GET" && r.URL.Path == "/vocabularies/2/terms":
+			fmt.Fprintf(w, `[{"uri": "/terms/7", "term": "Astronomy", "term_type": "topical", "vocabulary": "/vocabularies/2"}]`)
+		case r.Method == "GET" && r.URL.Path == "/terms" && r.URL.Query().Get("q") == "Astro":
+			fmt.Fprintf(w, `[{"uri": "/terms/7", "term": "Astronomy", "term_type": "topical", "vocabulary": "/vocabularies/2"}]`)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer ts.Close()
+
+	api, _ := NewClient(ts.URL)
+	vocabulary := &Vocabulary{Name: "Library of Congress Subject Headings", RefID: "lcsh"}
+	if _, err := api.CreateVocabulary(vocabulary); err != nil || vocabulary.ID != 2 {
+		t.Fatalf("CreateVocabulary() unexpected %+v, %v", vocabulary, err)
+	}
+	if _, err := api.GetVocabulary(3); err == nil {
+		t.Errorf("GetVocabulary(3) expected a not found error")
+	}
+	vocabulary, err := api.GetVocabularyByRefID("lcsh")
+	if err != nil || vocabulary.URI != "/vocabularies/2" || vocabulary.ID != 2 {
+		t.Errorf("GetVocabularyByRefID() unexpected %+v, %v", vocabulary, err)
+	}
+	if _, err := api.GetVocabularyByRefID("aat"); err == nil {
+		t.Errorf("GetVocabularyByRefID(aat) expected an error")
+	}
+	if term, err := api.GetTerm(2, 7); err != nil || term.Term != "Astronomy" {
+		t.Errorf("GetTerm() unexpected %+v, %v", term, err)
+	}
+	if term, err := api.GetTerm(2, 8); err == nil || term != nil {
+		t.Errorf("GetTerm(2, 8) expected a not found error, got %+v", term)
+	}
+	terms, err := api.SearchTerms("Astro")
+	if err != nil || len(terms) != 1 || terms[0].ID != 7 {
+		t.Errorf("SearchTerms() unexpected %v, %v", terms, err)
+	}
+}
+
+func TestEnumerations(t *testing.T) {
+	var (
+		posted map[string]interface{}
+		query  url.Values
+	)
+	values := `["cubic_feet", "linear_feet"]`
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.Method == "GET" && r.URL.Path == "/config/enumerations":
+			fmt.Fprintf(w, `[{"uri": "/config/enumerations/14", "name": "extent_extent_type", "values": %s}]`, values)
+		case r.Method == "GET" && r.URL.Path == "/config/enumerations/14":
+			fmt.Fprintf(w, `{"uri": "/config/enumerations/14", "name": "extent_extent_type", "values": %s, "enumeration_values": [{"uri": "/config/enumeration_values/90", "value": "cubic_feet", "position": 0}]}`, values)
+		case r.Method == "POST" && r.URL.Path == "/config/enumerations/14":
+			posted = map[string]interface{}{}
+			json.NewDecoder(r.Body).Decode(&posted)
+			values = `["cubic_feet", "linear_feet", "boxes"]`
+			fmt.Fprintf(w, `{"status": "Updated", "id": 14}`)
+		case r.Method == "POST" && r.URL.Path == "/config/enumeration_values/90/suppressed":
+			query = r.URL.Query()
+			fmt.Fprintf(w, `{"status": "Suppressed", "id": 90}`)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer ts.Close()
+
+	api, _ := NewClient(ts.URL)
+	enumeration, err := api.GetEnumerationByName("extent_extent_type")
+	if err != nil || enumeration.ID != 14 {
+		t.Fatalf("GetEnumerationByName() unexpected %+v, %v", enumeration, err)
+	}
+	if _, err := api.GetEnumerationByName("no_such_list"); err == nil {
+		t.Errorf("GetEnumerationByName() expected an error for an unknown name")
+	}
+	enumeration, err = api.AddEnumerationValues(14, "linear_feet", "boxes")
+	if err != nil || len(enumeration.Values) != 3 || enumeration.EnumerationValues[0].ID != 90 {
+		t.Errorf("AddEnumerationValues() unexpected %+v, %v", enumeration, err)
+	}
+	if v, _ := posted["values"].([]interface{}); len(v) != 3 || posted["enumeration_values"] != nil {
+		t.Errorf("AddEnumerationValues() posted %v", posted)
+	}
+	if _, err := api.SuppressEnumerationValue(90, true); err != nil || query.Get("suppressed") != "true" {
+		t.Errorf("SuppressEnumerationValue() %v, %v", err, query)
+	}
+}
+
+func TestCollectionManagement(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/repositories/2/collection_management":
+			page := r.URL.Query().Get("page")
+			if page == "1" {
+				fmt.Fprintf(w, `{"first_page": 1, "last_page": 2, "this_page": 1, "total": 2, "results": [{"uri": "/repositories/2/collection_management/1", "processing_priority": "high", "processing_status": "new"}]}`)
+				return
+			}
+			fmt.Fprintf(w, `{"first_page": 1, "last_page": 2, "this_page": 2, "total": 2, "results": [{"uri": "/repositories/2/collection_management/2", "processing_priority": "low", "processing_status": "in_progress", "processors": "J. Doe"}]}`)
+		case "/repositories/2/collection_management/2":
+			fmt.Fprintf(w, `{"uri": "/repositories/2/collection_management/2", "processing_status": "in_progress"}`)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer ts.Close()
+
+	api, _ := NewClient(ts.URL)
+	queue := map[string]int{}
+	err := api.EachCollectionManagement(2, func(cm *CollectionManagement) error {
+		queue[cm.ProcessingStatus]++
+		return nil
+	})
+	if err != nil || queue["new"] != 1 || queue["in_progress"] != 1 {
+		t.Errorf("EachCollectionManagement() unexpected %v, %v", queue, err)
+	}
+	cm, err := api.GetCollectionManagement(2, 2)
+	if err != nil || cm.ID != 2 || cm.ProcessingStatus != "in_progress" {
+		t.Errorf("GetCollectionManagement() unexpected %+v, %v", cm, err)
+	}
+}
+
+func TestAssessmentAttributeDefinitions(t *testing.T) {
+	var posted AssessmentAttributeDefinitions
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/repositories/2/assessment_attribute_definitions" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		if r.Method == "POST" {
+			json.NewDecoder(r.Body).Decode(&posted)
+			fmt.Fprintf(w, `{"status": "Updated", "id": 2}`)
+			return
+		}
+		fmt.Fprintf(w, `{"definitions": [{"id": 1, "label": "Housing Quality", "type": "rating", "global": true, "position": 0}, {"id": 40, "label": "Glass plate negatives", "type": "format", "position": 1}]}`)
+	}))
+	defer ts.Close()
+
+	api, _ := NewClient(ts.URL)
+	definitions, err := api.GetAssessmentAttributeDefinitions(2)
+	if err != nil || len(definitions.Definitions) != 2 {
+		t.Fatalf("GetAssessmentAttributeDefinitions() unexpected %+v, %v", definitions, err)
+	}
+	if d := definitions.Find("rating", "Housing Quality"); d == nil || d.ID != 1 {
+		t.Errorf("Find() unexpected %+v", d)
+	}
+	if d := definitions.Find("format", "Housing Quality"); d != nil {
+		t.Errorf("Find() should match on type, got %+v", d)
+	}
+	definitions.Definitions = append(definitions.Definitions, &AssessmentAttributeDefinition{Label: "Lantern slides", Type: "format"})
+	if _, err := api.UpdateAssessmentAttributeDefinitions(2, definitions); err != nil {
+		t.Fatalf("UpdateAssessmentAttributeDefinitions() %s", err)
+	}
+	if len(posted.Definitions) != 2 || posted.Definitions[0].ID != 40 || posted.Definitions[1].Label != "Lantern slides" {
+		t.Errorf("UpdateAssessmentAttributeDefinitions() posted %+v", posted.Definitions)
+	}
+}
+
+func TestRepositoryWithAgent(t *testing.T) {
+	var posted map[string]interface{}
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.Method == "POST" && r.URL.Path == "/repositories/with_agent":
+			json.NewDecoder(r.Body).Decode(&posted)
+			fmt.Fprintf(w, `{"status": "Created", "id": 4, "lock_version": 0, "uri": "/repositories/4"}`)
+		case r.Method == "GET" && r.URL.Path == "/repositories/with_agent/4":
+			fmt.Fprintf(w, `{"repository": {"uri": "/repositories/4", "repo_code": "CLA", "name": "Caltech Archives", "lock_version": 0}, "agent_representation": {"agent_contacts": [{"name": "Reference Desk", "email": "archives@example.edu"}]}}`)
+		case r.Method == "POST" && r.URL.Path == "/repositories/with_agent/4":
+			json.NewDecoder(r.Body).Decode(&posted)
+			fmt.Fprintf(w, `{"status": "Updated", "id": 4, "lock_version": 1}`)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer ts.Close()
+
+	api, _ := NewClient(ts.URL)
+	repoWithAgent := &RepositoryWithAgent{
+		Repository: &Repository{RepoCode: "CLA", Name: "Caltech Archives"},
+		AgentRepresentation: &AgentCorporateEntity{
+			AgentContacts: []*AgentContact{{Name: "Reference Desk", EMail: "archives@example.edu"}},
+		},
+	}
+	if _, err := api.CreateRepositoryWithAgent(repoWithAgent); err != nil || repoWithAgent.Repository.ID != 4 {
+		t.Fatalf("CreateRepositoryWithAgent() unexpected %+v, %v", repoWithAgent.Repository, err)
+	}
+	agent, _ := posted["agent_representation"].(map[string]interface{})
+	if repo, _ := posted["repository"].(map[string]interface{}); repo["repo_code"] != "CLA" || agent["jsonmodel_type"] != "agent_corporate_entity" {
+		t.Errorf("CreateRepositoryWithAgent() posted %v", posted)
+	}
+	repoWithAgent, err := api.GetRepositoryWithAgent(4)
+	if err != nil || repoWithAgent.Repository.ID != 4 || repoWithAgent.AgentRepresentation.AgentContacts[0].EMail != "archives@example.edu" {
+		t.Fatalf("GetRepositoryWithAgent() unexpected %+v, %v", repoWithAgent, err)
+	}
+	repoWithAgent.AgentRepresentation.AgentContacts[0].City = "Pasadena"
+	if _, err := api.UpdateRepositoryWithAgent(repoWithAgent); err != nil {
+		t.Errorf("UpdateRepositoryWithAgent() %s", err)
+	}
+	if _, err := api.UpdateRepositoryWithAgent(&RepositoryWithAgent{Repository: &Repository{}}); err == nil {
+		t.Errorf("UpdateRepositoryWithAgent() expected an error without a repository URI")
+	}
+}
+
+func TestGetResourceTree(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/repositories/2/resources/9/tree" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		fmt.Fprintf(w, `{"title": "Papers", "id": 9, "record_uri": "/repositories/2/resources/9", "node_type": "resource", "level": "collection", "children": [
+			{"title": "Series 1", "id": 1, "record_uri": "/repositories/2/archival_objects/1", "level": "series", "has_children": true, "children": [
+				{"title": "Folder 1", "id": 3, "record_uri": "/repositories/2/archival_objects/3", "level": "file", "containers": [{"type_1": "box", "indicator_1": "1"}]}]},
+			{"title": "Series 2", "id": 2, "record_uri": "/repositories/2/archival_objects/2", "level": "series"}]}`)
+	}))
+	defer ts.Close()
+
+	api, _ := NewClient(ts.URL)
+	tree, err := api.GetResourceTree(2, 9)
+	if err != nil {
+		t.Fatalf("GetResourceTree() %s", err)
+	}
+	titles := []string{}
+	err = tree.Walk(func(node *ResourceTree, depth int) error {
+		titles = append(titles, fmt.Sprintf("%d %s", depth, node.Title))
+		return nil
+	})
+	if err != nil || strings.Join(titles, ", ") != "0 Papers, 1 Series 1, 2 Folder 1, 1 Series 2" {
+		t.Errorf("Walk() unexpected %v, %v", titles, err)
+	}
+	if tree.Children[0].Children[0].Containers[0]["indicator_1"] != "1" {
+		t.Errorf("GetResourceTree() containers %v", tree.Children[0].Children[0].Containers)
+	}
+	count := 0
+	err = tree.Walk(func(node *ResourceTree, depth int) error {
+		count++
+		if node.Level == "file" {
+			return fmt.Errorf("%s, %w", node.Title, ErrStopIteration)
+		}
+		return nil
+	})
+	if err != nil || count != 3 {
+		t.Errorf("Walk() should stop at the file, visited %d, %v", count, err)
+	}
+}
+
+func TestGetFullResourceTree(t *testing.T) {
+	requests := []string{}
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		q := r.URL.Query()
+		requests = append(requests, r.URL.Path+" "+q.Get("parent_node")+" "+q.Get("offset"))
+		switch r.URL.Path {
+		case "/repositories/2/resources/9/tree/root":
+			fmt.Fprintf(w, `{"uri": "/repositories/2/resources/9", "title": "Papers", "child_count": 3, "waypoints": 2, "waypoint_size": 2,
+				"precomputed_waypoints": {"": {"0": [
+					{"uri": "/repositories/2/archival_objects/1", "title": "Series 1", "position": 0, "child_count": 1, "waypoints": 1, "waypoint_size": 2},
+					{"uri": "/repositories/2/archival_objects/2", "title": "Series 2", "position": 1, "child_count": 0, "waypoints": 0, "waypoint_size": 2}]}}}`)
+		case "/repositories/2/resources/9/tree/waypoint":
+			if q.Get("parent_node") == "" && q.Get("offset") == "1" {
+				fmt.Fprintf(w, `[{"uri": "/repositories/2/archival_objects/4", "title": "Series 3", "position": 2, "child_count": 0, "waypoints": 0, "waypoint_size": 2}]`)
+				return
+			}
+			if q.Get("parent_node") == "/repositories/2/archival_objects/1" && q.Get("offset") == "0" {
+				fmt.Fprintf(w, `[{"uri": "/repositories/2/archival_objects/3", "title": "Folder 1", "position": 0, "child_count": 0, "waypoints": 0, "waypoint_size": 2}]`)
+				return
+			}
+			w.WriteHeader(http.StatusNotFound)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer ts.Close()
+
+	api, _ := NewClient(ts.URL)
+	tree, err := api.GetFullResourceTree(2, 9)
+	if err != nil {
+		t.Fatalf("GetFullResourceTree() %s", err)
+	}
+	if len(tree.Children) != 3 || tree.Children[2].Title != "Series 3" || len(tree.Children[0].Children) != 1 || tree.Children[0].Children[0].Title != "Folder 1" {
+		t.Errorf("GetFullResourceTree() unexpected tree %+v", tree)
+	}
+	// The precomputed first waypoint of the root isn't fetched again
+	if len(requests) != 3 {
+		t.Errorf("GetFullResourceTree() made %d requests %v", len(requests), requests)
+	}
+}
+
+func TestReparentArchivalObjects(t *testing.T) {
+	queries := map[string]url.Values{}
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != "POST" {
+			w.WriteHeader(http.StatusMethodNotAllowed)
+			return
+		}
+		queries[r.URL.Path] = r.URL.Query()
+		fmt.Fprintf(w, `{"status": "Updated", "id": %d}`, URIToID(strings.TrimSuffix(strings.TrimSuffix(r.URL.Path, "/parent"), "/accept_children")))
+	}))
+	defer ts.Close()
+
+	api, _ := NewClient(ts.URL)
+	obj := &ArchivalObject{URI: "/repositories/2/archival_objects/7", Parent: Ref("/repositories/2/archival_objects/1")}
+	if _, err := api.SetArchivalObjectParent(obj, "/repositories/2/archival_objects/5", 2); err != nil {
+		t.Fatalf("SetArchivalObjectParent() %s", err)
+	}
+	q := queries["/repositories/2/archival_objects/7/parent"]
+	if q.Get("parent") != "5" || q.Get("position") != "2" || obj.Parent["ref"] != "/repositories/2/archival_objects/5" || obj.Position != 2 {
+		t.Errorf("SetArchivalObjectParent() sent %v, obj %+v", q, obj)
+	}
+	if _, err := api.SetArchivalObjectParent(obj, "", 0); err != nil || obj.Parent != nil {
+		t.Errorf("SetArchivalObjectParent() to top level %v, parent %v", err, obj.Parent)
+	}
+	if q := queries["/repositories/2/archival_objects/7/parent"]; q.Get("parent") != "" {
+		t.Errorf("SetArchivalObjectParent() to top level sent %v", q)
+	}
+
+	children := []string{"/repositories/2/archival_objects/8", "/repositories/2/archival_objects/9"}
+	if _, err := api.AcceptChildren("/repositories/2/resources/3", children, 1); err != nil {
+		t.Fatalf("AcceptChildren() %s", err)
+	}
+	q = queries["/repositories/2/resources/3/accept_children"]
+	if strings.Join(q["children[]"], ",") != strings.Join(children, ",") || q.Get("position") != "1" {
+		t.Errorf("AcceptChildren() sent %v", q)
+	}
+	if _, err := api.AcceptChildren("/repositories/2/resources/3", nil, 0); err == nil {
+		t.Errorf("AcceptChildren() expected an error without children")
+	}
+}
+
+func TestReorderMoves(t *testing.T) {
+	for _, test := range []struct {
+		current, ordered string
+		moves            int
+	}{
+		{"a b c d", "a b c d", 0},
+		{"a b c", "b c a", 1},
+		{"a b c d e", "e a b c d", 1},
+		{"a b c d e", "e d c b a", 4},
+		{"a b c d e f", "b a d c f e", 3},
+		{"a", "a", 0},
+	} {
+		current, ordered := strings.Fields(test.current), strings.Fields(test.ordered)
+		moves, err := reorderMoves(current, ordered)
+		if err != nil {
+			t.Errorf("reorderMoves(%v, %v) %s", current, ordered, err)
+			continue
+		}
+		if len(moves) != test.moves {
+			t.Errorf("reorderMoves(%v, %v) expected %d moves, got %v", current, ordered, test.moves, moves)
+		}
+		// Apply the moves as ArchivesSpace would and check the result
+		list := append([]string{}, current...)
+		for _, move := range moves {
+			for j, item := range list {
+				if item == move.uri {
+					list = append(list[:j], list[j+1:]...)
+					break
+				}
+			}
+			list = append(list[:move.position], append([]string{move.uri}, list[move.position:]...)...)
+		}
+		if strings.Join(list, " ") != test.ordered {
+			t.Errorf("reorderMoves(%v, %v) produced %v", current, ordered, list)
+		}
+	}
+	if _, err := reorderMoves([]string{"a", "b"}, []string{"a"}); err == nil {
+		t.Errorf("reorderMoves() expected an error for a missing child")
+	}
+	if _, err := reorderMoves([]string{"a", "b"}, []string{"a", "c"}); err == nil {
+		t.Errorf("reorderMoves() expected an error for an unknown child")
+	}
+}
+
+func TestReorderChildren(t *testing.T) {
+	moves := []string{}
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.Method == "GET" && r.URL.Path == "/repositories/2/archival_objects/1/children":
+			fmt.Fprintf(w, `[{"uri": "/repositories/2/archival_objects/11"}, {"uri": "/repositories/2/archival_objects/12"}, {"uri": "/repositories/2/archival_objects/13"}]`)
+		case r.Method == "POST" && strings.HasSuffix(r.URL.Path, "/parent"):
+			moves = append(moves, fmt.Sprintf("%s %s %s", r.URL.Path, r.URL.Query().Get("parent"), r.URL.Query().Get("position")))
+			fmt.Fprintf(w, `{"status": "Updated"}`)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer ts.Close()
+
+	api, _ := NewClient(ts.URL)
+	moved, err := api.ReorderChildren("/repositories/2/archival_objects/1", []string{
+		"/repositories/2/archival_objects/13",
+		"/repositories/2/archival_objects/11",
+		"/repositories/2/archival_objects/12",
+	})
+	if err != nil || len(moved) != 1 || moved[0] != "/repositories/2/archival_objects/13" {
+		t.Fatalf("ReorderChildren() unexpected %v, %v", moved, err)
+	}
+	if len(moves) != 1 || moves[0] != "/repositories/2/archival_objects/13/parent 1 0" {
+		t.Errorf("ReorderChildren() posted %v", moves)
+	}
+	obj := &ArchivalObject{URI: "/repositories/2/archival_objects/12", Parent: Ref("/repositories/2/archival_objects/1")}
+	if _, err := api.MoveArchivalObject(obj, 0); err != nil || moves[1] != "/repositories/2/archival_objects/12/parent 1 0" {
+		t.Errorf("MoveArchivalObject() %v, posted %v", err, moves)
+	}
+}
+
+func TestSuppress(t *testing.T) {
+	posted := []string{}
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != "POST" || strings.HasSuffix(r.URL.Path, "/suppressed") == false {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		posted = append(posted, r.URL.Path+"?"+r.URL.RawQuery)
+		fmt.Fprintf(w, `{"status": "Suppressed", "id": 3, "suppressed_state": %s}`, r.URL.Query().Get("suppressed"))
+	}))
+	defer ts.Close()
+
+	api, _ := NewClient(ts.URL)
+	accession := &Accession{URI: "/repositories/2/accessions/3"}
+	if _, err := api.SuppressAccession(accession); err != nil || accession.Suppressed == false {
+		t.Errorf("SuppressAccession() %v, suppressed %t", err, accession.Suppressed)
+	}
+	obj := &DigitalObject{URI: "/repositories/2/digital_objects/3", Suppressed: true}
+	if _, err := api.UnsuppressDigitalObject(obj); err != nil || obj.Suppressed {
+		t.Errorf("UnsuppressDigitalObject() %v, suppressed %t", err, obj.Suppressed)
+	}
+	expected := []string{
+		"/repositories/2/accessions/3/suppressed?suppressed=true",
+		"/repositories/2/digital_objects/3/suppressed?suppressed=false",
+	}
+	if strings.Join(posted, " ") != strings.Join(expected, " ") {
+		t.Errorf("expected %v, got %v", expected, posted)
+	}
+	if _, err := api.SetSuppressed("", true); err == nil {
+		t.Errorf("SetSuppressed() expected an error for an empty URI")
+	}
+}
+
+func TestPublishResource(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != "POST" || r.URL.Path != "/repositories/2/resources/5/publish" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		fmt.Fprintf(w, `{"status": "Updated", "id": 5, "lock_version": 3}`)
+	}))
+	defer ts.Close()
+
+	api, _ := NewClient(ts.URL)
+	obj := &Resource{URI: "/repositories/2/resources/5"}
+	if _, err := api.PublishResource(obj); err != nil || obj.Publish == false {
+		t.Errorf("PublishResource() %v, publish %t", err, obj.Publish)
+	}
+	if _, err := api.PublishResource(&Resource{URI: "/repositories/2/resources/6"}); err == nil {
+		t.Errorf("PublishResource() expected an error for a missing resource")
+	}
+}
+
+func TestTransfer(t *testing.T) {
+	posted := []string{}
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != "POST" || strings.HasSuffix(r.URL.Path, "/transfer") == false {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		posted = append(posted, r.URL.Path+" "+r.URL.Query().Get("target_repo"))
+		fmt.Fprintf(w, `{"status": "Updated", "id": 9}`)
+	}))
+	defer ts.Close()
+
+	api, _ := NewClient(ts.URL)
+	obj := &Resource{URI: "/repositories/2/resources/9"}
+	if _, err := api.TransferResource(obj, 4); err != nil {
+		t.Fatalf("TransferResource() %s", err)
+	}
+	if obj.URI != "/repositories/4/resources/9" || obj.ID != 9 {
+		t.Errorf("TransferResource() expected the new URI, got %q (%d)", obj.URI, obj.ID)
+	}
+	if _, err := api.TransferRepository(3, 4); err != nil {
+		t.Errorf("TransferRepository() %s", err)
+	}
+	expected := "/repositories/2/resources/9/transfer /repositories/4|/repositories/3/transfer /repositories/4"
+	if strings.Join(posted, "|") != expected {
+		t.Errorf("expected %q, got %q", expected, strings.Join(posted, "|"))
+	}
+}
+
+func TestSpawnResourceFromAccession(t *testing.T) {
+	var (
+		posted  *Resource
+		payload map[string]interface{}
+	)
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != "POST" || r.URL.Path != "/repositories/2/resources" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		src, _ := ioutil.ReadAll(r.Body)
+		posted = new(Resource)
+		if err := json.Unmarshal(src, posted); err != nil {
+			t.Errorf("can't decode resource, %s", err)
+		}
+		json.Unmarshal(src, &payload)
+		fmt.Fprintf(w, `{"status": "Created", "id": 12, "lock_version": 0, "uri": "/repositories/2/resources/12"}`)
+	}))
+	defer ts.Close()
+
+	api, _ := NewClient(ts.URL)
+	accession := &Accession{
+		URI:                "/repositories/2/accessions/7",
+		Title:              "Papers of A. Person",
+		ContentDescription: "Letters and notebooks",
+		Extents:            []*Extent{{Portion: "whole", Number: "2", ExtentType: "linear_feet"}},
+		Subjects:           []map[string]interface{}{Ref("/subjects/3")},
+		LinkedAgents:       []map[string]interface{}{{"ref": "/agents/people/4", "role": "creator"}},
+		LangMaterials: []map[string]interface{}{{
+			"jsonmodel_type":      "lang_material",
+			"language_and_script": map[string]interface{}{"jsonmodel_type": "language_and_script", "language": "eng", "script": "Latn"},
+		}},
+	}
+	obj, err := api.SpawnResourceFromAccession(accession, &Resource{ID0: "MSS-7", Level: "fonds"})
+	if err != nil {
+		t.Fatalf("SpawnResourceFromAccession() %s", err)
+	}
+	if obj.URI != "/repositories/2/resources/12" || obj.ID != 12 {
+		t.Errorf("expected the new resource's URI, got %q", obj.URI)
+	}
+	if posted.Title != accession.Title || posted.ID0 != "MSS-7" || posted.Level != "fonds" {
+		t.Errorf("unexpected title, identifier or level %q, %q, %q", posted.Title, posted.ID0, posted.Level)
+	}
+	if len(posted.Extents) != 1 || len(posted.Subjects) != 1 || len(posted.LinkedAgents) != 1 || len(posted.Notes) != 1 {
+		t.Errorf("expected the extents, subjects, agents and note to be copied, %+v", posted)
+	}
+	if languages, _ := payload["lang_materials"].([]interface{}); len(languages) != 1 {
+		t.Errorf("expected lang_materials to be copied, got %v", payload["lang_materials"])
+	} else if language, _ := languages[0].(map[string]interface{})["language_and_script"].(map[string]interface{}); language["language"] != "eng" {
+		t.Errorf("expected the language and script to be copied, got %v", languages[0])
+	}
+	if len(posted.ReleatedAccessions) != 1 || posted.ReleatedAccessions[0]["ref"] != accession.URI {
+		t.Errorf("expected a related accession, got %v", posted.ReleatedAccessions)
+	}
+	if len(accession.RelatedResources) != 1 || accession.RelatedResources[0]["ref"] != obj.URI {
+		t.Errorf("expected the accession's related resources to be updated, got %v", accession.RelatedResources)
+	}
+}
+
+func TestMergeRequests(t *testing.T) {
+	posted := []string{}
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != "POST" || strings.HasPrefix(r.URL.Path, "/merge_requests/") == false {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		merge := new(MergeRequest)
+		if err := json.NewDecoder(r.Body).Decode(merge); err != nil {
+			t.Errorf("can't decode merge request, %s", err)
+		}
+		victims := []string{}
+		for _, victim := range merge.Victims {
+			victims = append(victims, victim["ref"].(string))
+		}
+		posted = append(posted, fmt.Sprintf("%s?%s %s <- %s", r.URL.Path, r.URL.RawQuery, merge.Target["ref"], strings.Join(victims, ",")))
+		fmt.Fprintf(w, `{"status": "OK"}`)
+	}))
+	defer ts.Close()
+
+	api, _ := NewClient(ts.URL)
+	if _, err := api.MergeAgents("/agents/people/1", "/agents/people/2", "/agents/people/3"); err != nil {
+		t.Errorf("MergeAgents() %s", err)
+	}
+	if _, err := api.MergeSubjects("/subjects/1", "/subjects/2"); err != nil {
+		t.Errorf("MergeSubjects() %s", err)
+	}
+	if _, err := api.MergeTopContainers(2, "/repositories/2/top_containers/1", "/repositories/2/top_containers/5"); err != nil {
+		t.Errorf("MergeTopContainers() %s", err)
+	}
+	expected := []string{
+		"/merge_requests/agent? /agents/people/1 <- /agents/people/2,/agents/people/3",
+		"/merge_requests/subject? /subjects/1 <- /subjects/2",
+		"/merge_requests/top_container?repo_id=2 /repositories/2/top_containers/1 <- /repositories/2/top_containers/5",
+	}
+	if strings.Join(posted, "\n") != strings.Join(expected, "\n") {
+		t.Errorf("expected\n%s\ngot\n%s", strings.Join(expected, "\n"), strings.Join(posted, "\n"))
+	}
+
+	// These are refused before anything is posted
+	if _, err := api.MergeSubjects("/subjects/1"); err == nil {
+		t.Errorf("MergeSubjects() expected an error without victims")
+	}
+	if _, err := api.MergeAgents("/agents/people/1", "/subjects/2"); err == nil {
+		t.Errorf("MergeAgents() expected an error merging a subject")
+	}
+	if _, err := api.MergeSubjects("/subjects/1", "/subjects/1"); err == nil {
+		t.Errorf("MergeSubjects() expected an error merging a subject into itself")
+	}
+	if len(posted) != len(expected) {
+		t.Errorf("expected nothing more to be posted, got %v", posted[len(expected):])
+	}
+}
+
+func TestJobs(t *testing.T) {
+	polls := 0
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.Method == "POST" && r.URL.Path == "/repositories/2/jobs":
+			job := new(Job)
+			json.NewDecoder(r.Body).Decode(job)
+			if job.Job["jsonmodel_type"] != "print_to_pdf_job" || job.Job["source"] != "/repositories/2/resources/1" {
+				t.Errorf("unexpected job %v", job.Job)
+			}
+			fmt.Fprintf(w, `{"status": "Created", "id": 8, "lock_version": 0, "uri": "/repositories/2/jobs/8"}`)
+		case r.Method == "GET" && r.URL.Path == "/repositories/2/jobs/8":
+			polls++
+			status := "running"
+			if polls > 2 {
+				status = "completed"
+			}
+			fmt.Fprintf(w, `{"uri": "/repositories/2/jobs/8", "job_type": "print_to_pdf_job", "status": %q}`, status)
+		case r.Method == "GET" && r.URL.Path == "/repositories/2/jobs/8/log":
+			log := "Starting\nDone\n"
+			offset, _ := strconv.Atoi(r.URL.Query().Get("offset"))
+			fmt.Fprintf(w, "%s", log[offset:])
+		case r.Method == "GET" && r.URL.Path == "/repositories/2/jobs/8/records":
+			if r.URL.Query().Get("page") == "1" {
+				fmt.Fprintf(w, `{"last_page": 2, "results": [{"record": {"ref": "/repositories/2/resources/4"}}]}`)
+			} else {
+				fmt.Fprintf(w, `{"last_page": 2, "results": [{"record": {"ref": "/repositories/2/archival_objects/5"}}]}`)
+			}
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer ts.Close()
+
+	api, _ := NewClient(ts.URL)
+	job := NewJob("print_to_pdf_job", map[string]interface{}{"source": "/repositories/2/resources/1"})
+	if _, err := api.CreateJob(2, job); err != nil || job.ID != 8 {
+		t.Fatalf("CreateJob() %v, ID %d", err, job.ID)
+	}
+	if _, err := api.WaitForJob(2, 8, time.Millisecond, time.Millisecond); err == nil {
+		t.Errorf("WaitForJob() expected a timeout")
+	}
+	job, err := api.WaitForJob(2, 8, time.Millisecond, 0)
+	if err != nil || job.Status != JobCompleted || job.IsFinished() == false {
+		t.Errorf("WaitForJob() %v, %+v", err, job)
+	}
+	if log, err := api.GetJobLog(2, 8, 9); err != nil || log != "Done\n" {
+		t.Errorf("GetJobLog() %v, %q", err, log)
+	}
+	uris, err := api.ListJobRecords(2, 8)
+	if err != nil || strings.Join(uris, " ") != "/repositories/2/resources/4 /repositories/2/archival_objects/5" {
+		t.Errorf("ListJobRecords() %v, %v", err, uris)
+	}
+}
+
+func TestCreateImportJobWithFiles(t *testing.T) {
+	dname := t.TempDir()
+	fname := path.Join(dname, "finding-aid.xml")
+	if err := ioutil.WriteFile(fname, []byte("<ead></ead>"), 0644); err != nil {
+		t.Fatalf("%s", err)
+	}
+	var uploaded []string
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != "POST" || r.URL.Path != "/repositories/2/jobs_with_files" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		if err := r.ParseMultipartForm(1 << 20); err != nil {
+			t.Errorf("expected a multipart form, %s", err)
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		job := new(Job)
+		if err := json.Unmarshal([]byte(r.FormValue("job")), job); err != nil {
+			t.Errorf("can't decode job, %s", err)
+		}
+		if job.Job["jsonmodel_type"] != "import_job" || job.Job["import_type"] != "ead_xml" {
+			t.Errorf("unexpected job %v", job.Job)
+		}
+		files := r.MultipartForm.File["files[]"]
+		uploaded = []string{}
+		for _, file := range files {
+			uploaded = append(uploaded, file.Filename)
+		}
+		if len(files) != 1 {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		fp, _ := files[0].Open()
+		src, _ := ioutil.ReadAll(fp)
+		fp.Close()
+		if string(src) != "<ead></ead>" {
+			t.Errorf("unexpected file content %q", src)
+		}
+		fmt.Fprintf(w, `{"status": "Created", "id": 9, "lock_version": 0, "uri": "/repositories/2/jobs/9"}`)
+	}))
+	defer ts.Close()
+
+	api, _ := NewClient(ts.URL)
+	job, err := api.CreateImportJobWithFiles(2, "ead_xml", fname)
+	if err != nil || job.URI != "/repositories/2/jobs/9" || job.ID != 9 {
+		t.Errorf("CreateImportJobWithFiles() %v, %+v", err, job)
+	}
+	if len(uploaded) != 1 || uploaded[0] != "finding-aid.xml" {
+		t.Errorf("CreateImportJobWithFiles() expected finding-aid.xml to be uploaded, got %v", uploaded)
+	}
+	if _, err := api.CreateImportJobWithFiles(2, "ead_xml", path.Join(dname, "missing.xml")); err == nil {
+		t.Errorf("CreateImportJobWithFiles() expected an error for a missing file")
+	}
+	if _, err := api.CreateImportJobWithFiles(2, "ead_xml"); err == nil {
+		t.Errorf("CreateImportJobWithFiles() expected an error without files")
+	}
+}
+
+func TestReports(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.Method == "GET" && r.URL.Path == "/reports":
+			fmt.Fprintf(w, `{"accession_report": {"uri_suffix": "accession_report", "description": "Displays accessions"}}`)
+		case r.Method == "GET" && r.URL.Path == "/reports/static/css/report.css":
+			fmt.Fprintf(w, "body { margin: 0 }")
+		case r.Method == "POST" && r.URL.Path == "/repositories/2/jobs":
+			job := new(Job)
+			json.NewDecoder(r.Body).Decode(job)
+			if job.Job["jsonmodel_type"] != "report_job" || job.Job["report_type"] != "accession_report" || job.Job["format"] != "csv" {
+				t.Errorf("unexpected job %v", job.Job)
+			}
+			fmt.Fprintf(w, `{"status": "Created", "id": 4, "lock_version": 0, "uri": "/repositories/2/jobs/4"}`)
+		case r.Method == "GET" && r.URL.Path == "/repositories/2/jobs/4/output_files":
+			fmt.Fprintf(w, `[11]`)
+		case r.Method == "GET" && r.URL.Path == "/repositories/2/jobs/4/output_files/11":
+			fmt.Fprintf(w, "title,date\nPapers,1901\n")
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer ts.Close()
+
+	api, _ := NewClient(ts.URL)
+	reports, err := api.ListReports()
+	if err != nil || reports["accession_report"] == nil || reports["accession_report"].Code != "accession_report" {
+		t.Errorf("ListReports() %v, %+v", err, reports)
+	}
+	buf := new(bytes.Buffer)
+	if _, err := api.GetReportStatic("css/report.css", buf); err != nil || buf.String() != "body { margin: 0 }" {
+		t.Errorf("GetReportStatic() %v, %q", err, buf.String())
+	}
+	job, err := api.RunReport(2, "accession_report", "csv", nil)
+	if err != nil || job.ID != 4 {
+		t.Fatalf("RunReport() %v, %+v", err, job)
+	}
+	buf.Reset()
+	if n, err := api.WriteReport(2, job.ID, buf); err != nil || n != int64(buf.Len()) || buf.String() != "title,date\nPapers,1901\n" {
+		t.Errorf("WriteReport() %v, %d, %q", err, n, buf.String())
+	}
+	if _, err := api.WriteReport(2, 5, buf); err == nil {
+		t.Errorf("WriteReport() expected an error for a missing job")
+	}
+}
+
+func TestSpaceCalculator(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		q := r.URL.Query()
+		if q.Get("container_profile_uri") != "/container_profiles/1" {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		switch {
+		case r.URL.Path == "/space_calculator/by_building" && q.Get("building") == "Archives" && q.Get("floor") == "2" && q.Get("room") == "":
+			fmt.Fprintf(w, `{"jsonmodel_type": "space_calculator_results", "total_spaces_available": 7,
+"locations_with_space": [{"ref": "/locations/1", "count": 7}],
+"locations_without_space": [{"ref": "/locations/2"}],
+"uncalculatable_locations": [{"ref": "/locations/3", "reason": "location_lacks_dimensions"}]}`)
+		case r.URL.Path == "/space_calculator/by_location" && strings.Join(q["location_uris[]"], " ") == "/locations/1 /locations/2":
+			fmt.Fprintf(w, `{"total_spaces_available": 3, "locations_with_space": [{"ref": "/locations/1", "count": 3}]}`)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer ts.Close()
+
+	api, _ := NewClient(ts.URL)
+	results, err := api.SpaceByBuilding("/container_profiles/1", "Archives", "2", "", "")
+	if err != nil {
+		t.Fatalf("SpaceByBuilding() %s", err)
+	}
+	if results.TotalSpacesAvailable != 7 || len(results.LocationsWithSpace) != 1 || results.LocationsWithSpace[0].Count != 7 ||
+		len(results.LocationsWithoutSpace) != 1 || results.UncalculatableLocations[0].Reason != "location_lacks_dimensions" {
+		t.Errorf("SpaceByBuilding() unexpected %+v", results)
+	}
+	results, err = api.SpaceByLocation("/container_profiles/1", "/locations/1", "/locations/2")
+	if err != nil || results.TotalSpacesAvailable != 3 {
+		t.Errorf("SpaceByLocation() %v, %+v", err, results)
+	}
+	if _, err := api.SpaceByLocation("/container_profiles/1"); err == nil {
+		t.Errorf("SpaceByLocation() expected an error without locations")
+	}
+}
+
+func TestCalculateExtent(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		q := r.URL.Query()
+		if r.URL.Path != "/extent_calculator" || q.Get("record_uri") != "/repositories/2/resources/1" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		fmt.Fprintf(w, `{"object": {"uri": "/repositories/2/resources/1"}, "units": %q, "total_extent": 2.5, "container_count": 6,
+"containers": {"Document box": {"count": 6, "extent": 2.5}}}`, q.Get("unit"))
+	}))
+	defer ts.Close()
+
+	api, _ := NewClient(ts.URL)
+	calc, err := api.CalculateExtent("/repositories/2/resources/1", "feet")
+	if err != nil {
+		t.Fatalf("CalculateExtent() %s", err)
+	}
+	if calc.Units != "feet" || calc.TotalExtent.String() != "2.5" || calc.ContainerCount != 6 || calc.Containers == nil {
+		t.Errorf("CalculateExtent() unexpected %+v", calc)
+	}
+	if _, err := api.CalculateExtent("/repositories/2/resources/9", ""); err == nil {
+		t.Errorf("CalculateExtent() expected an error for a missing record")
+	}
+}
+
+func TestCalculateDates(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		q := r.URL.Query()
+		if r.URL.Path != "/date_calculator" || q.Get("record_uri") != "/repositories/2/resources/1" || q.Get("label") != "creation" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		fmt.Fprintf(w, `{"object": {"uri": "/repositories/2/resources/1"}, "label": "creation",
+"min_begin": "1901", "min_begin_date": "1901-01-01", "max_end": "1955-06", "max_end_date": "1955-06-30"}`)
+	}))
+	defer ts.Close()
+
+	api, _ := NewClient(ts.URL)
+	calc, err := api.CalculateDates("/repositories/2/resources/1", "creation")
+	if err != nil {
+		t.Fatalf("CalculateDates() %s", err)
+	}
+	if calc.MinBeginDate != "1901-01-01" || calc.MaxEndDate != "1955-06-30" {
+		t.Errorf("CalculateDates() unexpected %+v", calc)
+	}
+	date := calc.Date()
+	if date.DateType != "inclusive" || date.Label != "creation" || date.Begin != "1901" || date.End != "1955-06" {
+		t.Errorf("Date() unexpected %+v", date)
+	}
+}
+
+func TestTopContainerBulkAndBatch(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		q := r.URL.Query()
+		switch {
+		case r.Method == "POST" && r.URL.Path == "/repositories/2/top_containers/bulk/barcodes":
+			barcodes := map[string]string{}
+			json.NewDecoder(r.Body).Decode(&barcodes)
+			if barcodes["/repositories/2/top_containers/1"] != "3900001" {
+				t.Errorf("unexpected barcodes %v", barcodes)
+			}
+			fmt.Fprintf(w, `{"updated": [1]}`)
+		case r.Method == "POST" && r.URL.Path == "/repositories/2/top_containers/batch/ils_holding_id":
+			if strings.Join(q["ids[]"], ",") != "1,2" || q.Get("ils_holding_id") != "h42" {
+				t.Errorf("unexpected query %v", q)
+			}
+			fmt.Fprintf(w, `{"records_updated": 2}`)
+		case r.Method == "POST" && r.URL.Path == "/repositories/2/top_containers/batch/location":
+			if q.Get("location_uri") != "/locations/5" {
+				t.Errorf("unexpected query %v", q)
+			}
+			fmt.Fprintf(w, `{"error": "location not found"}`)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer ts.Close()
+
+	api, _ := NewClient(ts.URL)
+	ids, err := api.UpdateTopContainerBarcodes(2, map[string]string{"/repositories/2/top_containers/1": "3900001"})
+	if err != nil || len(ids) != 1 || ids[0] != 1 {
+		t.Errorf("UpdateTopContainerBarcodes() %v, %v", err, ids)
+	}
+	if n, err := api.UpdateTopContainerILSHoldingIDs(2, []int{1, 2}, "h42"); err != nil || n != 2 {
+		t.Errorf("UpdateTopContainerILSHoldingIDs() %v, %d", err, n)
+	}
+	if _, err := api.MoveTopContainers(2, []int{1}, "/locations/5"); err == nil || strings.Contains(err.Error(), "location not found") == false {
+		t.Errorf("MoveTopContainers() expected the server's error, got %v", err)
+	}
+	if _, err := api.UpdateTopContainerProfiles(2, nil, "/container_profiles/1"); err == nil {
+		t.Errorf("UpdateTopContainerProfiles() expected an error without containers")
+	}
+}
+
+func TestContainerMoves(t *testing.T) {
+	var posted []map[string]string
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.Method == "GET" && r.URL.Path == "/locations":
+			fmt.Fprintf(w, `[1, 2]`)
+		case r.Method == "GET" && r.URL.Path == "/locations/1":
+			fmt.Fprintf(w, `{"uri": "/locations/1", "title": "Beckman B1 [1]", "barcode": "LOC-1", "building": "Beckman", "room": "B1", "coordinate_1_indicator": "1"}`)
+		case r.Method == "GET" && r.URL.Path == "/locations/2":
+			fmt.Fprintf(w, `{"uri": "/locations/2", "title": "Beckman B2 [1]", "barcode": "LOC-2", "building": "Beckman", "room": "B2", "coordinate_1_indicator": "1"}`)
+		case r.Method == "GET" && r.URL.Path == "/repositories/2/top_containers/by_barcode/BOX-1":
+			fmt.Fprintf(w, `{"uri": "/repositories/2/top_containers/5", "display_string": "Box 1", "container_locations": [{"ref": "/locations/1", "status": "current", "start_date": "2020-01-02", "jsonmodel_type": "container_location"}]}`)
+		case r.Method == "GET" && r.URL.Path == "/repositories/2/top_containers/by_barcode/BOX-2":
+			fmt.Fprintf(w, `{"uri": "/repositories/2/top_containers/6", "display_string": "Box 2"}`)
+		case r.Method == "POST" && r.URL.Path == "/repositories/2/top_containers/bulk/locations":
+			locations := map[string]string{}
+			json.NewDecoder(r.Body).Decode(&locations)
+			posted = append(posted, locations)
+			fmt.Fprintf(w, `{"updated": [5, 6]}`)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer ts.Close()
+
+	api, _ := NewClient(ts.URL)
+	plan, err := api.PlanContainerMoves(2, []*ContainerMove{
+		{Container: "BOX-1", Location: "LOC-2"},
+		{Container: "BOX-1", Location: "LOC-1"},
+		{Container: "BOX-2", Location: "LOC-9"},
+		{Container: "BOX-3", Location: "LOC-1"},
+		{Container: "/repositories/3/top_containers/1", Location: "LOC-1"},
+	})
+	if err != nil {
+		t.Fatalf("PlanContainerMoves() %s", err)
+	}
+	expected := []string{"", "container listed more than once", "no location matches", "404", "container is not in repository 2"}
+	for i, move := range plan.Moves {
+		if (expected[i] == "" && move.Error != "") || strings.Contains(move.Error, expected[i]) == false {
+			t.Errorf("PlanContainerMoves() move %d error %q, expected %q", i, move.Error, expected[i])
+		}
+	}
+	if plan.Errors != 4 {
+		t.Errorf("PlanContainerMoves() expected 4 errors, got %d", plan.Errors)
+	}
+	if _, err := api.ApplyContainerMoves(plan); err == nil || len(posted) != 0 {
+		t.Errorf("ApplyContainerMoves() should refuse a plan with errors, %v, %v", err, posted)
+	}
+
+	// Planning is a dry run, nothing is posted until the plan is applied
+	plan, err = api.PlanContainerMoves(2, []*ContainerMove{
+		{Container: "BOX-1", Location: "LOC-2"},
+		{Container: "BOX-2", Coordinate: &LocationCoordinate{Building: "beckman", Room: "B1"}},
+	})
+	if err != nil || plan.Errors != 0 || len(posted) != 0 {
+		t.Fatalf("PlanContainerMoves() unexpected %s, %v, %v", plan, err, posted)
+	}
+	if move := plan.Moves[0]; move.ContainerURI != "/repositories/2/top_containers/5" || len(move.FromLocations) != 1 || move.FromLocations[0] != "/locations/1" || move.LocationURI != "/locations/2" {
+		t.Errorf("PlanContainerMoves() unexpected move %+v", move)
+	}
+	if move := plan.Moves[1]; move.ContainerURI != "/repositories/2/top_containers/6" || move.LocationURI != "/locations/1" {
+		t.Errorf("PlanContainerMoves() unexpected move by coordinate %+v", move)
+	}
+	ids, err := api.ApplyContainerMoves(plan)
+	if err != nil || len(ids) != 2 || len(posted) != 1 {
+		t.Fatalf("ApplyContainerMoves() unexpected %v, %v, %v", ids, err, posted)
+	}
+	if posted[0]["/repositories/2/top_containers/5"] != "/locations/2" || posted[0]["/repositories/2/top_containers/6"] != "/locations/1" {
+		t.Errorf("ApplyContainerMoves() posted %v", posted[0])
+	}
+}
+
+func TestListJobs(t *testing.T) {
+	requested := []string{}
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requested = append(requested, r.URL.Path+"?page="+r.URL.Query().Get("page"))
+		switch {
+		case r.URL.Path == "/repositories/2/jobs" && r.URL.Query().Get("page") == "1":
+			fmt.Fprintf(w, `{"first_page": 1, "last_page": 2, "this_page": 1, "total": 3, "results": [
+{"uri": "/repositories/2/jobs/1", "job_type": "import_job", "status": "completed"},
+{"uri": "/repositories/2/jobs/2", "job_type": "print_to_pdf_job", "status": "running"}]}`)
+		case r.URL.Path == "/repositories/2/jobs" && r.URL.Query().Get("page") == "2":
+			fmt.Fprintf(w, `{"first_page": 1, "last_page": 2, "this_page": 2, "total": 3, "results": [
+{"uri": "/repositories/2/jobs/3", "job_type": "import_job", "status": "queued"}]}`)
+		case r.URL.Path == "/repositories/2/jobs/active":
+			fmt.Fprintf(w, `[{"uri": "/repositories/2/jobs/2", "job_type": "print_to_pdf_job", "status": "running"},
+{"uri": "/repositories/2/jobs/3", "job_type": "import_job", "status": "queued"}]`)
+		case r.URL.Path == "/repositories/2/jobs/archived":
+			fmt.Fprintf(w, `{"first_page": 1, "last_page": 1, "this_page": 1, "total": 1, "results": [
+{"uri": "/repositories/2/jobs/1", "job_type": "import_job", "status": "completed"}]}`)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer ts.Close()
+
+	api, _ := NewClient(ts.URL)
+	jobs, err := api.ListJobs(2, nil)
+	if err != nil || jobs.Total != 3 || len(jobs.Results) != 2 || jobs.Results[1].ID != 2 {
+		t.Errorf("ListJobs(2, nil) unexpected %+v, %v", jobs, err)
+	}
+	requested = []string{}
+	jobs, err = api.ListJobs(2, &JobListOptions{Status: []string{JobQueued, JobRunning}, JobTypes: []string{"import_job"}})
+	if err != nil || jobs.Total != 1 || len(jobs.Results) != 1 || jobs.Results[0].ID != 3 {
+		t.Errorf("ListJobs() active import jobs unexpected %+v, %v", jobs, err)
+	}
+	if strings.Join(requested, ",") != "/repositories/2/jobs/active?page=" {
+		t.Errorf("ListJobs() active jobs should come from jobs/active, requested %v", requested)
+	}
+	requested = []string{}
+	jobs, err = api.ListJobs(2, &JobListOptions{Status: []string{JobCompleted}})
+	if err != nil || jobs.Total != 1 || jobs.Results[0].ID != 1 || strings.Join(requested, ",") != "/repositories/2/jobs/archived?page=1" {
+		t.Errorf("ListJobs() completed jobs unexpected %+v, %v, requested %v", jobs, err, requested)
+	}
+	// A job type filter alone has to look at every page, Total counts only the matches
+	requested = []string{}
+	jobs, err = api.ListJobs(2, &JobListOptions{JobTypes: []string{"import_job"}, PageSize: 1, Page: 2})
+	if err != nil || jobs.Total != 2 || jobs.LastPage != 2 || jobs.ThisPage != 2 || len(jobs.Results) != 1 || jobs.Results[0].ID != 3 {
+		t.Errorf("ListJobs() import jobs page 2 unexpected %+v, %v", jobs, err)
+	}
+	if len(requested) != 2 {
+		t.Errorf("ListJobs() expected both pages to be read, requested %v", requested)
+	}
+	jobs, err = api.ListJobs(2, &JobListOptions{JobTypes: []string{"import_job"}, Page: 5})
+	if err != nil || jobs.Total != 2 || len(jobs.Results) != 0 {
+		t.Errorf("ListJobs() past the last page unexpected %+v, %v", jobs, err)
+	}
+	if _, err := api.ListJobs(3, &JobListOptions{Status: []string{JobFailed}}); err == nil {
+		t.Errorf("ListJobs(3) expected an error")
+	}
+}
+
+func TestApplyToURIs(t *testing.T) {
+	var (
+		mu          sync.Mutex
+		saves       = map[string]int{}
+		lockVersion = 1
+	)
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		switch {
+		case r.Method == "GET" && r.URL.Path == "/repositories/2/accessions/1":
+			fmt.Fprintf(w, `{"uri": "/repositories/2/accessions/1", "title": "Papers", "lock_version": %d}`, lockVersion)
+		case r.Method == "GET" && r.URL.Path == "/repositories/2/accessions/2":
+			fmt.Fprintf(w, `{"uri": "/repositories/2/accessions/2", "title": "Papers, 1920-1960", "lock_version": 0}`)
+		case r.Method == "GET" && r.URL.Path == "/repositories/2/accessions/3":
+			fmt.Fprintf(w, `{"uri": "/repositories/2/accessions/3", "title": "Letters", "lock_version": 0}`)
+		case r.Method == "POST" && r.URL.Path == "/repositories/2/accessions/1":
+			obj := Object{}
+			json.NewDecoder(r.Body).Decode(&obj)
+			saves[r.URL.Path]++
+			// Someone else saves the record between our first GET and POST
+			if saves[r.URL.Path] == 1 {
+				lockVersion++
+			}
+			if fmt.Sprintf("%v", obj["lock_version"]) != fmt.Sprintf("%d", lockVersion) {
+				w.WriteHeader(http.StatusConflict)
+				fmt.Fprintf(w, `{"error": {"lock_version": ["The record you tried to update has been modified since you fetched it."]}}`)
+				return
+			}
+			fmt.Fprintf(w, `{"status": "Updated", "id": 1, "lock_version": %d}`, lockVersion+1)
+		case r.Method == "POST" && r.URL.Path == "/repositories/2/accessions/3":
+			saves[r.URL.Path]++
+			w.WriteHeader(http.StatusConflict)
+			fmt.Fprintf(w, `{"error": {"id_0": ["That ID is already in use"]}}`)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer ts.Close()
+
+	transform := func(obj Object) (bool, error) {
+		if obj["title"] == "Papers, 1920-1960" {
+			return false, nil
+		}
+		obj["title"] = "Papers, 1920-1960"
+		return true, nil
+	}
+	uris := []string{"/repositories/2/accessions/1", "/repositories/2/accessions/2", "/repositories/2/accessions/3"}
+	api, _ := NewClient(ts.URL)
+
+	report, err := api.ApplyToURIs(uris, transform, &BatchEditOptions{DryRun: true})
+	if err != nil || report.DryRun == false || report.Hits != 3 || report.Changed != 2 || report.Saved != 0 || report.Errors != 0 {
+		t.Fatalf("ApplyToURIs() dry run unexpected %+v, %v", report, err)
+	}
+	if len(saves) != 0 {
+		t.Errorf("ApplyToURIs() dry run saved records %v", saves)
+	}
+	if fields := report.Changes[0].Fields; len(fields) != 1 || fields[0] != "title" || report.Changes[1].Changed == true {
+		t.Errorf("ApplyToURIs() dry run unexpected changes %+v, %+v", report.Changes[0], report.Changes[1])
+	}
+
+	// Without MaxRetries the lock_version conflict on the first record is still retried once
+	report, err = api.ApplyToURIs(uris, transform, nil)
+	if err != nil || report.Changed != 2 || report.Saved != 1 || report.Errors != 1 {
+		t.Fatalf("ApplyToURIs() unexpected %+v, %v", report, err)
+	}
+	if change := report.Changes[0]; change.Saved == false || change.Attempts != 2 || change.Error != "" {
+		t.Errorf("ApplyToURIs() expected the conflict to be retried, %+v", change)
+	}
+	if change := report.Changes[2]; change.Saved == true || change.Attempts != 1 || saves["/repositories/2/accessions/3"] != 1 || strings.Contains(change.Error, "id_0") == false {
+		t.Errorf("ApplyToURIs() a conflict other than lock_version shouldn't be retried, %+v", change)
+	}
+	if _, err := api.ApplyToURIs(uris, nil, nil); err == nil {
+		t.Errorf("ApplyToURIs() expected an error without a transform")
+	}
+}
+
+func TestScanStacks(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/repositories":
+			fmt.Fprintf(w, `[{"uri": "/repositories/2"}, {"uri": "/repositories/3"}]`)
+		case "/repositories/2/top_containers":
+			fmt.Fprintf(w, `[1, 2]`)
+		case "/repositories/2/top_containers/1":
+			fmt.Fprintf(w, `{"uri": "/repositories/2/top_containers/1", "display_string": "Box 1", "collection": [{"ref": "/repositories/2/resources/1"}],
+"container_locations": [{"ref": "/locations/1", "status": "current"}]}`)
+		case "/repositories/2/top_containers/2":
+			fmt.Fprintf(w, `{"uri": "/repositories/2/top_containers/2", "display_string": "Box 2", "container_locations": [{"ref": "/locations/2", "status": "previous"}]}`)
+		case "/repositories/3/top_containers":
+			fmt.Fprintf(w, `[7]`)
+		case "/repositories/3/top_containers/7":
+			fmt.Fprintf(w, `{"uri": "/repositories/3/top_containers/7", "display_string": "Box 7", "collection": [{"ref": "/repositories/3/resources/4"}],
+"container_locations": [{"ref": "/locations/2", "status": "current"}]}`)
+		case "/locations":
+			fmt.Fprintf(w, `[1, 2, 3]`)
+		case "/locations/1", "/locations/2", "/locations/3":
+			fmt.Fprintf(w, `{"uri": %q, "title": "Shelf"}`, r.URL.Path)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer ts.Close()
+
+	api, _ := NewClient(ts.URL)
+	report, err := api.ScanStacks(2)
+	if err != nil {
+		t.Fatalf("ScanStacks(2) %s", err)
+	}
+	if len(report.Homeless) != 1 || report.Homeless[0].URI != "/repositories/2/top_containers/2" || len(report.Unlinked) != 1 || report.Unlinked[0].Title != "Box 2" {
+		t.Errorf("ScanStacks(2) unexpected %s", report)
+	}
+	// Repository 3's container occupies /locations/2 so it can't be reported empty
+	if report.EmptyLocations != nil {
+		t.Errorf("ScanStacks(2) shouldn't report empty locations for one repository, %s", report)
+	}
+	report, err = api.ScanStacks()
+	if err != nil {
+		t.Fatalf("ScanStacks() %s", err)
+	}
+	if len(report.Homeless) != 1 || len(report.EmptyLocations) != 1 || report.EmptyLocations[0].URI != "/locations/3" {
+		t.Errorf("ScanStacks() unexpected %s", report)
+	}
+	if report, err = api.ScanStacks(3, 2); err != nil || len(report.EmptyLocations) != 1 {
+		t.Errorf("ScanStacks(3, 2) should check locations when every repository is listed, %v, %v", report, err)
+	}
+}
+
+func TestLinkClassification(t *testing.T) {
+	const term = "/repositories/2/classification_terms/4"
+	var mu sync.Mutex
+	records := map[string]string{
+		"/repositories/2/accessions/1":      `{"uri": "/repositories/2/accessions/1", "title": "Letters", "lock_version": 0, "classifications": []}`,
+		"/repositories/2/accessions/5":      `{"uri": "/repositories/2/accessions/5", "title": "Notebooks", "lock_version": 0}`,
+		"/repositories/2/resources/2":       `{"uri": "/repositories/2/resources/2", "title": "Papers", "lock_version": 3, "classifications": [{"ref": "/repositories/2/classification_terms/4"}]}`,
+		"/repositories/2/digital_objects/3": `{"uri": "/repositories/2/digital_objects/3", "title": "Scan", "lock_version": 0}`,
+	}
+	posts := map[string]int{}
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		switch {
+		case r.URL.Path == "/search":
+			if strings.Join(r.URL.Query()["type[]"], ",") != "accession,resource" {
+				t.Errorf("unexpected search types %v", r.URL.Query())
+			}
+			fmt.Fprintf(w, `{"first_page": 1, "last_page": 1, "this_page": 1, "total_hits": 3, "results": [
+{"uri": "/repositories/2/accessions/1"}, {"uri": "/repositories/2/accessions/5"}, {"uri": "/repositories/2/resources/2"}]}`)
+		case r.Method == "GET" && records[r.URL.Path] != "":
+			fmt.Fprint(w, records[r.URL.Path])
+		case r.Method == "POST" && records[r.URL.Path] != "":
+			src, _ := ioutil.ReadAll(r.Body)
+			posts[r.URL.Path]++
+			obj := Object{}
+			json.Unmarshal(src, &obj)
+			count := 0
+			for _, item := range refList(obj, "classifications") {
+				if item.(map[string]interface{})["ref"] == term {
+					count++
+				}
+			}
+			if count != 1 {
+				t.Errorf("%s posted with the classification %d times, %s", r.URL.Path, count, src)
+			}
+			records[r.URL.Path] = string(src)
+			fmt.Fprintf(w, `{"status": "Updated", "lock_version": 1}`)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer ts.Close()
+
+	api, _ := NewClient(ts.URL)
+	if _, err := api.LinkClassification("/subjects/1", []string{"/repositories/2/accessions/1"}, nil); err == nil {
+		t.Errorf("LinkClassification() expected an error for a subject URI")
+	}
+	uris := []string{"/repositories/2/accessions/1", "/repositories/2/resources/2", "/repositories/2/digital_objects/3"}
+	report, err := api.LinkClassification(term, uris, nil)
+	if err != nil || report.Changed != 1 || report.Saved != 1 || report.Errors != 1 {
+		t.Fatalf("LinkClassification() unexpected %+v, %v", report, err)
+	}
+	if posts["/repositories/2/accessions/1"] != 1 || posts["/repositories/2/resources/2"] != 0 || posts["/repositories/2/digital_objects/3"] != 0 {
+		t.Errorf("LinkClassification() unexpected saves %v", posts)
+	}
+	// Running it again finds every record already linked
+	report, err = api.LinkClassification(term, uris, nil)
+	if err != nil || report.Changed != 0 || report.Saved != 0 || posts["/repositories/2/accessions/1"] != 1 {
+		t.Errorf("LinkClassification() should be idempotent, %+v, %v, %v", report, err, posts)
+	}
+
+	report, err = api.LinkClassificationToSearch(term, "title:*", nil)
+	if err != nil || report.Hits != 3 || report.Changed != 1 || report.Saved != 1 || posts["/repositories/2/accessions/5"] != 1 {
+		t.Fatalf("LinkClassificationToSearch() unexpected %+v, %v, %v", report, err, posts)
+	}
+	report, err = api.LinkClassificationToSearch(term, "title:*", nil)
+	if err != nil || report.Changed != 0 || report.Saved != 0 || posts["/repositories/2/accessions/5"] != 1 {
+		t.Errorf("LinkClassificationToSearch() should be idempotent, %+v, %v, %v", report, err, posts)
+	}
+}
+
+func TestSetRepresentative(t *testing.T) {
+	posted := map[string]Object{}
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.Method == "GET" && r.URL.Path == "/repositories/2/digital_objects/3":
+			fmt.Fprintf(w, `{"uri": "/repositories/2/digital_objects/3", "title": "Scan", "lock_version": 1, "file_versions": [
+{"file_uri": "https://example.edu/a.jpg", "publish": true},
+{"file_uri": "https://example.edu/b.jpg", "publish": false},
+{"file_uri": "https://example.edu/c.jpg", "publish": true, "is_representative": true}]}`)
+		case r.Method == "GET" && r.URL.Path == "/repositories/2/resources/1":
+			fmt.Fprintf(w, `{"uri": "/repositories/2/resources/1", "title": "Papers", "lock_version": 2, "instances": [
+{"instance_type": "mixed_materials", "sub_container": {"top_container": {"ref": "/repositories/2/top_containers/1"}}},
+{"instance_type": "digital_object", "digital_object": {"ref": "/repositories/2/digital_objects/3"}}]}`)
+		case r.Method == "POST":
+			obj := Object{}
+			json.NewDecoder(r.Body).Decode(&obj)
+			posted[r.URL.Path] = obj
+			fmt.Fprintf(w, `{"status": "Updated", "lock_version": 3}`)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+			fmt.Fprintf(w, `{"error": "Record not found"}`)
+		}
+	}))
+	defer ts.Close()
+
+	api, _ := NewClient(ts.URL)
+	if _, err := api.SetDigitalObjectRepresentativeFileVersion(2, 3, "https://example.edu/a.jpg"); err != nil {
+		t.Fatalf("SetDigitalObjectRepresentativeFileVersion() %s", err)
+	}
+	versions := refList(posted["/repositories/2/digital_objects/3"], "file_versions")
+	if len(versions) != 3 || versions[0].(map[string]interface{})["is_representative"] != true || versions[2].(map[string]interface{})["is_representative"] == true {
+		t.Errorf("SetDigitalObjectRepresentativeFileVersion() posted %v", versions)
+	}
+	posted = map[string]Object{}
+	for fileURI, expected := range map[string]string{
+		"https://example.edu/b.jpg":       "must be published",
+		"https://example.edu/missing.jpg": "has no file version",
+	} {
+		if _, err := api.SetDigitalObjectRepresentativeFileVersion(2, 3, fileURI); err == nil || strings.Contains(err.Error(), expected) == false {
+			t.Errorf("SetDigitalObjectRepresentativeFileVersion(%q) expected %q, got %v", fileURI, expected, err)
+		}
+	}
+	if _, err := api.SetDigitalObjectRepresentativeFileVersion(2, 4, "https://example.edu/a.jpg"); IsNotFound(err) == false {
+		t.Errorf("SetDigitalObjectRepresentativeFileVersion() expected not found, got %v", err)
+	}
+
+	if _, err := api.SetResourceRepresentativeInstance(2, 1, "/repositories/2/digital_objects/3"); err != nil {
+		t.Fatalf("SetResourceRepresentativeInstance() %s", err)
+	}
+	instances := refList(posted["/repositories/2/resources/1"], "instances")
+	if len(instances) != 2 || instances[1].(map[string]interface{})["is_representative"] != true || instances[0].(map[string]interface{})["is_representative"] == true {
+		t.Errorf("SetResourceRepresentativeInstance() posted %v", instances)
+	}
+	delete(posted, "/repositories/2/resources/1")
+	if _, err := api.SetResourceRepresentativeInstance(2, 1, "/repositories/2/digital_objects/9"); err == nil || strings.Contains(err.Error(), "has no digital object instance") == false {
+		t.Errorf("SetResourceRepresentativeInstance() expected an error for an unlinked digital object, got %v", err)
+	}
+	if len(posted) != 0 {
+		t.Errorf("nothing should be saved when the URI isn't on the record, posted %v", posted)
+	}
+}
+
+func TestExportAllEADResume(t *testing.T) {
+	downloads := 0
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/repositories/2/resources":
+			fmt.Fprintf(w, `[1, 2]`)
+		case "/repositories/2/resources/1", "/repositories/2/resources/2":
+			id := path.Base(r.URL.Path)
+			fmt.Fprintf(w, `{"uri": %q, "ead_id": "mss%s", "system_mtime": "2026-01-05T10:00:00Z"}`, r.URL.Path, id)
+		case "/repositories/2/resource_descriptions/1.xml", "/repositories/2/resource_descriptions/2.xml":
+			downloads++
+			fmt.Fprintf(w, `<ead><eadheader><eadid>%s</eadid></eadheader></ead>`, path.Base(r.URL.Path))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer ts.Close()
+
+	dir := t.TempDir()
+	existing := []byte(`<ead><eadheader><eadid>exported earlier</eadid></eadheader></ead>`)
+	if err := ioutil.WriteFile(path.Join(dir, "mss1.xml"), existing, 0664); err != nil {
+		t.Fatalf("%s", err)
+	}
+	api, _ := NewClient(ts.URL)
+	api.Manifest = NewExportManifest()
+	report, err := api.ExportAllEAD(2, dir, &EADExportOptions{Workers: 1})
+	if err != nil || report.Exported != 1 || report.Skipped != 1 || downloads != 1 {
+		t.Fatalf("ExportAllEAD() unexpected %+v, %v, %d downloads", report, err, downloads)
+	}
+	if len(api.Manifest.Entries) != 2 {
+		t.Fatalf("ExportAllEAD() should record skipped finding aids in the manifest, %d entries", len(api.Manifest.Entries))
+	}
+	checksums := map[string]string{}
+	for _, entry := range api.Manifest.Entries {
+		checksums[entry.URI] = entry.SHA256
+	}
+	if expected := fmt.Sprintf("%x", sha256.Sum256(existing)); checksums["/repositories/2/resources/1"] != expected {
+		t.Errorf("skipped finding aid checksum %q, expected %q", checksums["/repositories/2/resources/1"], expected)
+	}
+}
+
+// failingReader returns err once its content has been read
+type failingReader struct {
+	content io.Reader
+	err     error
+}
+
+func (r *failingReader) Read(p []byte) (int, error) {
+	n, err := r.content.Read(p)
+	if err == io.EOF {
+		return n, r.err
+	}
+	return n, err
+}
+
+func TestSessionExpiredBody(t *testing.T) {
+	api, _ := NewClient("http://localhost:8089")
+	payload := `{"error": "Access denied", "code": "SESSION_EXPIRED"}` + strings.Repeat(" ", 2*sessionErrorSize)
+	res := &http.Response{StatusCode: http.StatusForbidden, Body: ioutil.NopCloser(strings.NewReader(payload))}
+	if api.sessionExpired(res) == false {
+		t.Errorf("sessionExpired() should spot SESSION_EXPIRED")
+	}
+	if body, err := ioutil.ReadAll(res.Body); err != nil || string(body) != payload {
+		t.Errorf("sessionExpired() should leave the whole body readable, read %d of %d bytes, %v", len(body), len(payload), err)
+	}
+
+	res = &http.Response{StatusCode: http.StatusForbidden, Body: ioutil.NopCloser(&failingReader{strings.NewReader(`{"code": "SESSION_`), io.ErrUnexpectedEOF})}
+	if api.sessionExpired(res) == true {
+		t.Errorf("sessionExpired() should not renew the session after a failed read")
+	}
+	if _, err := ioutil.ReadAll(res.Body); err != io.ErrUnexpectedEOF {
+		t.Errorf("the failed read should be left for the caller, got %v", err)
+	}
+}
+
+func TestPreferences(t *testing.T) {
+	var updated *Preference
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.Method == "GET" && r.URL.Path == "/current_global_preferences":
+			fmt.Fprintf(w, `{"defaults": {"publish": true, "note_order": "scopecontent"}}`)
+		case r.Method == "GET" && r.URL.Path == "/repositories/2/preferences/defaults" && r.URL.Query().Get("username") == "jane":
+			fmt.Fprintf(w, `{"show_suppressed": true, "publish": false}`)
+		case r.Method == "GET" && r.URL.Path == "/repositories/1/preferences":
+			fmt.Fprintf(w, `[{"uri": "/repositories/1/preferences/3", "user_id": 5, "defaults": {}},
+{"uri": "/repositories/1/preferences/1", "defaults": {"publish": true}, "lock_version": 2}]`)
+		case r.Method == "POST" && r.URL.Path == "/repositories/1/preferences/1":
+			updated = new(Preference)
+			json.NewDecoder(r.Body).Decode(updated)
+			fmt.Fprintf(w, `{"status": "Updated", "id": 1, "lock_version": 3}`)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer ts.Close()
+
+	api, _ := NewClient(ts.URL)
+	defaults, err := api.GetCurrentGlobalPreferences()
+	if err != nil || defaults.Publish == false || defaults.NoteOrder != "scopecontent" {
+		t.Errorf("GetCurrentGlobalPreferences() %v, %+v", err, defaults)
+	}
+	defaults, err = api.GetPreferenceDefaults(2, "jane")
+	if err != nil || defaults.ShowSuppressed == false {
+		t.Errorf("GetPreferenceDefaults() %v, %+v", err, defaults)
+	}
+	pref, err := api.GetGlobalPreference()
+	if err != nil || pref == nil || pref.ID != 1 {
+		t.Fatalf("GetGlobalPreference() %v, %+v", err, pref)
+	}
+	// Turning publish off has to reach the server
+	pref.Defaults.Publish = false
+	if _, err := api.UpdatePreference(pref); err != nil {
+		t.Fatalf("UpdatePreference() %s", err)
+	}
+	src, _ := json.Marshal(updated.Defaults)
+	if strings.Contains(string(src), `"publish":false`) == false {
+		t.Errorf("expected publish false to be sent, got %s", src)
+	}
+}
+
+func TestCopyDefaultValues(t *testing.T) {
+	staging := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == "GET" && r.URL.Path == "/repositories/2/default_values/resource" {
+			fmt.Fprintf(w, `{"uri": "/repositories/2/default_values/resource", "record_type": "resource", "lock_version": 4,
+"defaults": {"publish": true, "lang_materials": [{"language_and_script": {"language": "eng"}}]}}`)
+			return
+		}
+		w.WriteHeader(http.StatusNotFound)
+	}))
+	defer staging.Close()
+	var saved *DefaultValues
+	production := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.Method == "GET" && r.URL.Path == "/repositories/3/default_values/resource":
+			fmt.Fprintf(w, `{"uri": "/repositories/3/default_values/resource", "record_type": "resource", "lock_version": 1, "defaults": {}}`)
+		case r.Method == "POST" && r.URL.Path == "/repositories/3/default_values/resource":
+			saved = new(DefaultValues)
+			json.NewDecoder(r.Body).Decode(saved)
+			fmt.Fprintf(w, `{"status": "Updated", "lock_version": 2}`)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer production.Close()
+
+	src, _ := NewClient(staging.URL)
+	dst, _ := NewClient(production.URL)
+	copied, err := src.CopyDefaultValues(dst, 2, 3, "resource", "accession")
+	if err != nil || strings.Join(copied, ",") != "resource" {
+		t.Fatalf("CopyDefaultValues() %v, %v", err, copied)
+	}
+	if saved == nil || saved.LockVersion.String() != "1" || saved.Defaults["publish"] != true || saved.RecordType != "resource" {
+		t.Errorf("expected the staging defaults with production's lock version, got %+v", saved)
+	}
+	if _, err := dst.SaveDefaultValues(3, &DefaultValues{}); err == nil {
+		t.Errorf("SaveDefaultValues() expected an error without a record type")
+	}
+}
+
+func TestApplyRequiredFields(t *testing.T) {
+	saved := map[string]*RequiredFields{}
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.Method == "GET" && r.URL.Path == "/repositories":
+			fmt.Fprintf(w, `[{"uri": "/repositories/2"}, {"uri": "/repositories/3"}]`)
+		case r.Method == "GET" && r.URL.Path == "/repositories/2/required_fields/resource":
+			fmt.Fprintf(w, `{"uri": "/repositories/2/required_fields/resource", "record_type": "resource", "lock_version": 5, "subrecord_requirements": []}`)
+		case r.Method == "POST" && strings.HasSuffix(r.URL.Path, "/required_fields/resource"):
+			rf := new(RequiredFields)
+			json.NewDecoder(r.Body).Decode(rf)
+			saved[r.URL.Path] = rf
+			fmt.Fprintf(w, `{"status": "Updated", "lock_version": 1}`)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer ts.Close()
+
+	api, _ := NewClient(ts.URL)
+	policy := &RequiredFields{
+		RecordType: "resource",
+		SubrecordRequirements: []map[string]interface{}{
+			{"property": "dates", "record_type": "date", "required_fields": []string{"begin"}},
+		},
+	}
+	updated, err := api.ApplyRequiredFields(policy)
+	if err != nil || len(updated) != 2 {
+		t.Fatalf("ApplyRequiredFields() %v, %v", err, updated)
+	}
+	if rf := saved["/repositories/2/required_fields/resource"]; rf == nil || rf.LockVersion.String() != "5" || len(rf.SubrecordRequirements) != 1 {
+		t.Errorf("expected repository 2's lock version to be used, got %+v", rf)
+	}
+	if rf := saved["/repositories/3/required_fields/resource"]; rf == nil || rf.LockVersion.String() != "0" {
+		t.Errorf("expected a new policy for repository 3, got %+v", rf)
+	}
+	if policy.LockVersion != "" {
+		t.Errorf("expected the policy passed in to be left alone, got lock version %q", policy.LockVersion)
+	}
+}
+
+func TestImportCustomReportTemplate(t *testing.T) {
+	posted := []string{}
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.Method == "GET" && r.URL.Path == "/repositories/3/custom_report_templates":
+			fmt.Fprintf(w, `[4]`)
+		case r.Method == "GET" && r.URL.Path == "/repositories/3/custom_report_templates/4":
+			fmt.Fprintf(w, `{"uri": "/repositories/3/custom_report_templates/4", "name": "Accessions by year", "data": "{}", "lock_version": 2}`)
+		case r.Method == "POST":
+			tmpl := new(CustomReportTemplate)
+			json.NewDecoder(r.Body).Decode(tmpl)
+			posted = append(posted, fmt.Sprintf("%s %s %s", r.URL.Path, tmpl.Name, tmpl.LockVersion))
+			if r.URL.Path == "/repositories/3/custom_report_templates" {
+				fmt.Fprintf(w, `{"status": "Created", "id": 5, "lock_version": 0, "uri": "/repositories/3/custom_report_templates/5"}`)
+			} else {
+				fmt.Fprintf(w, `{"status": "Updated", "id": 4, "lock_version": 3}`)
+			}
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer ts.Close()
+
+	api, _ := NewClient(ts.URL)
+	// Templates exported from staging carry staging's URIs
+	update := &CustomReportTemplate{URI: "/repositories/2/custom_report_templates/9", Name: "Accessions by year", Data: `{"fields": {}}`}
+	if _, err := api.ImportCustomReportTemplate(3, update); err != nil || update.URI != "/repositories/3/custom_report_templates/4" {
+		t.Errorf("ImportCustomReportTemplate() %v, %q", err, update.URI)
+	}
+	create := &CustomReportTemplate{URI: "/repositories/2/custom_report_templates/10", Name: "Unprocessed", Data: "{}"}
+	if _, err := api.ImportCustomReportTemplate(3, create); err != nil || create.ID != 5 {
+		t.Errorf("ImportCustomReportTemplate() %v, %d", err, create.ID)
+	}
+	expected := "/repositories/3/custom_report_templates/4 Accessions by year 2|/repositories/3/custom_report_templates Unprocessed 0"
+	if strings.Join(posted, "|") != expected {
+		t.Errorf("expected %q, got %q", expected, strings.Join(posted, "|"))
+	}
+}
+
+func TestOAIConfig(t *testing.T) {
+	var saved *OAIConfig
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.Method == "GET" && r.URL.Path == "/oai_config":
+			fmt.Fprintf(w, `{"oai_record_prefix": "oai:archives", "oai_repository_name": "Archives", "repo_set_codes": "[\"MSS\"]", "lock_version": 1}`)
+		case r.Method == "POST" && r.URL.Path == "/oai_config":
+			saved = new(OAIConfig)
+			json.NewDecoder(r.Body).Decode(saved)
+			fmt.Fprintf(w, `{"status": "Updated", "id": 1, "lock_version": 2}`)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer ts.Close()
+
+	api, _ := NewClient(ts.URL)
+	config, err := api.GetOAIConfig()
+	if err != nil {
+		t.Fatalf("GetOAIConfig() %s", err)
+	}
+	codes, err := config.GetRepoSetCodes()
+	if err != nil || strings.Join(codes, ",") != "MSS" {
+		t.Errorf("GetRepoSetCodes() %v, %v", err, codes)
+	}
+	config.SetRepoSetCodes(append(codes, "UA"))
+	config.OAIAdminEmail = "archives@example.edu"
+	if _, err := api.UpdateOAIConfig(config); err != nil || config.LockVersion.String() != "2" {
+		t.Fatalf("UpdateOAIConfig() %v, lock version %s", err, config.LockVersion)
+	}
+	if saved.RepoSetCodes != `["MSS","UA"]` || saved.OAIAdminEmail != "archives@example.edu" || saved.LockVersion.String() != "1" {
+		t.Errorf("unexpected config saved %+v", saved)
+	}
+	if names, err := config.GetSponsorSetNames(); err != nil || len(names) != 0 {
+		t.Errorf("GetSponsorSetNames() %v, %v", err, names)
+	}
+}
+
+func TestGetCurrentUser(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != "GET" || r.URL.Path != "/users/current-user" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		fmt.Fprintf(w, `{"uri": "/users/7", "username": "archivist", "is_admin": false, "permissions": {
+"/repositories/2": ["view_repository", "update_resource_record", "delete_archival_record"],
+"_archivesspace": ["view_all_records"]}}`)
+	}))
+	defer ts.Close()
+
+	api, _ := NewClient(ts.URL)
+	user, err := api.GetCurrentUser()
+	if err != nil || user.ID != 7 || user.Username != "archivist" {
+		t.Fatalf("GetCurrentUser() %v, %+v", err, user)
+	}
+	if user.HasPermission(2, "delete_archival_record") == false || user.HasPermission(3, "view_all_records") == false {
+		t.Errorf("HasPermission() expected repository and global permissions")
+	}
+	if user.HasPermission(3, "delete_archival_record") {
+		t.Errorf("HasPermission() expected no delete permission in repository 3")
+	}
+	if err := api.RequirePermissions(2, "update_resource_record", "delete_archival_record"); err != nil {
+		t.Errorf("RequirePermissions() %s", err)
+	}
+	err = api.RequirePermissions(3, "view_all_records", "delete_archival_record")
+	if err == nil || strings.Contains(err.Error(), "delete_archival_record") == false || strings.Contains(err.Error(), "view_all_records") {
+		t.Errorf("RequirePermissions() expected delete_archival_record to be missing, got %v", err)
+	}
+}
+
+func TestARKs(t *testing.T) {
+	for _, test := range []struct{ in, out string }{
+		{"ark:/13030/abc123", "ark:/13030/abc123"},
+		{"https://n2t.net/ark:/13030/abc123", "ark:/13030/abc123"},
+		{"ark:13030/abc123", "ark:/13030/abc123"},
+		{"13030/abc123", ""},
+		{"ark:/13030", ""},
+	} {
+		out, err := NormalizeARK(test.in)
+		if out != test.out || (err != nil) != (test.out == "") {
+			t.Errorf("NormalizeARK(%q) expected %q, got %q, %v", test.in, test.out, out, err)
+		}
+	}
+
+	var posted *ArkName
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.Method == "GET" && r.URL.Path == "/ark:/13030/abc123":
+			fmt.Fprintf(w, `{"type": "ArchivalObject", "id": 44, "repo_id": 2}`)
+		case r.Method == "POST" && r.URL.Path == "/repositories/2/resources/5/ark_name":
+			posted = new(ArkName)
+			json.NewDecoder(r.Body).Decode(posted)
+			fmt.Fprintf(w, `{"status": "Updated", "id": 5}`)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer ts.Close()
+
+	api, _ := NewClient(ts.URL)
+	target, err := api.ResolveARK("https://n2t.net/ark:/13030/abc123")
+	if err != nil || target.URI != "/repositories/2/archival_objects/44" {
+		t.Errorf("ResolveARK() %v, %+v", err, target)
+	}
+	obj := &Resource{URI: "/repositories/2/resources/5"}
+	arkName := &ArkName{Current: "ark:/13030/xyz", CurrentIsExternal: true, Previous: []string{"ark:/13030/old"}}
+	if _, err := api.UpdateResourceArkName(obj, arkName); err != nil || obj.ArkName != arkName {
+		t.Fatalf("UpdateResourceArkName() %v", err)
+	}
+	if posted.Current != "ark:/13030/xyz" || posted.JSONModelType != "ark_name" || len(posted.Previous) != 1 {
+		t.Errorf("unexpected ark name posted %+v", posted)
+	}
+}
+
+func TestSearchRecords(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		q := r.URL.Query()
+		if r.URL.Path != "/repositories/2/search" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		if q.Get("q") != "title:papers" || q.Get("page") != "2" || q.Get("page_size") != "5" || q.Get("sort") != "title_sort asc" ||
+			strings.Join(q["type[]"], ",") != "resource" || strings.Join(q["filter_term[]"], ",") != `{"publish":"true"}` ||
+			strings.Join(q["filter_query[]"], ",") != "system_mtime:[2020-01-01T00:00:00Z TO *]" || strings.Join(q["facet[]"], ",") != "subjects" {
+			t.Errorf("unexpected query %v", q)
+		}
+		fmt.Fprintf(w, `{"first_page": 1, "last_page": 3, "this_page": 2, "total_hits": 12,
+"results": [{"id": "/repositories/2/resources/1", "uri": "/repositories/2/resources/1", "title": "Papers of A. Person",
+  "primary_type": "resource", "identifier": "MSS 1", "json": "{\"jsonmodel_type\": \"resource\", \"title\": \"Papers of A. Person\"}"}],
+"facets": {"facet_queries": {}, "facet_fields": {"subjects": ["Physics", 7, "Chemistry", 2]}}}`)
+	}))
+	defer ts.Close()
+
+	api, _ := NewClient(ts.URL)
+	req := NewSearchRequest("title:papers").InRepository(2).OfType("resource").Filter("publish", "true").
+		DateRange("system_mtime", time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC), time.Time{}).
+		Facet("subjects").SortBy("title_sort asc").WithPage(2).WithPageSize(5)
+	results, err := api.SearchRecords(req)
+	if err != nil {
+		t.Fatalf("SearchRecords() %s", err)
+	}
+	if results.TotalHits != 12 || results.HasNextPage() == false || len(results.Results) != 1 || results.Results[0].Identifier != "MSS 1" || results.Results[0].Fields["identifier"] != "MSS 1" {
+		t.Errorf("SearchRecords() unexpected %+v", results)
+	}
+	facets := results.Facets["subjects"]
+	if len(facets) != 2 || facets[0].Value != "Physics" || facets[0].Count != 7 {
+		t.Errorf("SearchRecords() unexpected facets %+v", results.Facets)
+	}
+}
+
+func TestSearchCallers(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		q := r.URL.Query()
+		switch {
+		case r.URL.Path == "/repositories/2/search":
+			if q.Get("q") != "papers" || strings.Join(q["type[]"], ",") != "resource" || strings.Join(q["filter_term[]"], ",") != `{"level":"collection"}` {
+				t.Errorf("unexpected query %v", q)
+			}
+			fmt.Fprintf(w, `{"first_page": 1, "last_page": 2, "this_page": %s, "total_hits": 2, "results": [
+{"id": "/repositories/2/resources/%s", "uri": "/repositories/2/resources/%s", "primary_type": "resource", "level": "collection"}]}`, q.Get("page"), q.Get("page"), q.Get("page"))
+		case r.Method == "GET" && strings.HasPrefix(r.URL.Path, "/repositories/2/resources/"):
+			fmt.Fprintf(w, `{"uri": %q, "title": "Papers", "level": "collection"}`, r.URL.Path)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer ts.Close()
+
+	api, _ := NewClient(ts.URL)
+	p, err := api.SearchWithFilters(2, "papers", []string{"resource"}, map[string]string{"level": "collection"}, 2)
+	if err != nil || p.ThisPage != 2 || p.TotalHits != 2 || len(p.Results) != 1 || p.Results[0]["level"] != "collection" || p.Results[0]["uri"] != "/repositories/2/resources/2" {
+		t.Errorf("SearchWithFilters() unexpected %+v, %v", p, err)
+	}
+	api.AddSavedSearch(&SavedSearch{Name: "collections", RepoID: 2, Query: "papers", Types: []string{"resource"}, Filters: map[string]string{"level": "collection"}})
+	objects, err := api.RunSavedSearch("collections", nil)
+	if err != nil || len(objects) != 2 || objects[1].GetURI() != "/repositories/2/resources/2" || objects[1]["level"] != "collection" {
+		t.Errorf("RunSavedSearch() unexpected %v, %v", objects, err)
+	}
+}
+
+func TestAdvancedQuery(t *testing.T) {
+	term := And(
+		Or(Field("title", "papers"), Literal("identifier", "MSS 12")),
+		AndNot(Literal("primary_type", "resource"), BoolField("publish", false)),
+		DateAfter("create_time", time.Date(2020, 1, 2, 0, 0, 0, 0, time.UTC)),
+		Empty("finding_aid_status").Negate(),
+	)
+	aq := NewAdvancedQuery(term)
+	decoded := map[string]interface{}{}
+	if err := json.Unmarshal([]byte(aq.String()), &decoded); err != nil {
+		t.Fatalf("can't decode %s, %s", aq, err)
+	}
+	query := decoded["query"].(map[string]interface{})
+	subqueries := query["subqueries"].([]interface{})
+	if decoded["jsonmodel_type"] != "advanced_query" || query["op"] != "AND" || len(subqueries) != 4 {
+		t.Fatalf("unexpected advanced query %s", aq)
+	}
+	or := subqueries[0].(map[string]interface{})
+	literal := or["subqueries"].([]interface{})[1].(map[string]interface{})
+	if or["op"] != "OR" || literal["literal"] != true || literal["field"] != "identifier" {
+		t.Errorf("unexpected OR group %v", or)
+	}
+	not := subqueries[1].(map[string]interface{})
+	boolField := not["subqueries"].([]interface{})[1].(map[string]interface{})
+	if not["op"] != "NOT" || boolField["jsonmodel_type"] != "boolean_field_query" || boolField["value"] != false {
+		t.Errorf("unexpected NOT group %v", not)
+	}
+	date := subqueries[2].(map[string]interface{})
+	if date["value"] != "2020-01-02" || date["comparator"] != "greater_than" {
+		t.Errorf("unexpected date query %v", date)
+	}
+	if empty := subqueries[3].(map[string]interface{}); empty["negated"] != true || empty["comparator"] != "empty" {
+		t.Errorf("unexpected empty query %v", empty)
+	}
+
+	v, err := NewSearchRequest("").Advanced(term).Values()
+	if err != nil || v.Get("aq") != aq.String() || v.Get("q") != "" {
+		t.Errorf("Values() %v, %v", err, v)
+	}
+	if _, err := NewSearchRequest("").Advanced(And()).Values(); err == nil {
+		t.Errorf("Values() expected an error for an empty AND")
+	}
+	if _, err := NewSearchRequest("").Advanced(DateField("create_time", "equal", "last week")).Values(); err == nil {
+		t.Errorf("Values() expected an error for a bad date")
+	}
+}
+
+func TestSearchAll(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		page := r.URL.Query().Get("page")
+		switch page {
+		case "1":
+			fmt.Fprintf(w, `{"first_page": 1, "last_page": 2, "this_page": 1, "total_hits": 3, "results": [
+{"uri": "/repositories/2/accessions/1", "primary_type": "accession", "json": "{\"jsonmodel_type\": \"accession\", \"uri\": \"/repositories/2/accessions/1\", \"title\": \"First\"}"},
+{"uri": "/repositories/2/resources/2", "primary_type": "resource", "json": "{\"jsonmodel_type\": \"resource\", \"uri\": \"/repositories/2/resources/2\", \"title\": \"Second\"}"}]}`)
+		case "2":
+			fmt.Fprintf(w, `{"first_page": 1, "last_page": 2, "this_page": 2, "total_hits": 3, "results": [
+{"uri": "/widgets/3", "primary_type": "widget", "json": "{\"jsonmodel_type\": \"widget\", \"uri\": \"/widgets/3\"}"}]}`)
+		default:
+			t.Errorf("unexpected page %q", page)
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer ts.Close()
+
+	api, _ := NewClient(ts.URL)
+	req := NewSearchRequest("papers")
+	seen := []string{}
+	err := api.SearchAll(req, func(hit SearchHit) error {
+		switch record := hit.Record.(type) {
+		case *Accession:
+			seen = append(seen, fmt.Sprintf("accession %d %s", record.ID, record.Title))
+		case *Resource:
+			seen = append(seen, fmt.Sprintf("resource %d %s", record.ID, record.Title))
+		case Object:
+			seen = append(seen, fmt.Sprintf("object %s", record.GetURI()))
+		default:
+			t.Errorf("unexpected record %T", hit.Record)
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("SearchAll() %s", err)
+	}
+	if strings.Join(seen, "|") != "accession 1 First|resource 2 Second|object /widgets/3" {
+		t.Errorf("SearchAll() unexpected %v", seen)
+	}
+	if req.Page != 1 {
+		t.Errorf("SearchAll() changed the request's page to %d", req.Page)
+	}
+	count := 0
+	err = api.SearchAll(req, func(hit SearchHit) error {
+		count++
+		return fmt.Errorf("%s, %w", hit.URI, ErrStopIteration)
+	})
+	if err != nil || count != 1 {
+		t.Errorf("SearchAll() expected to stop after one hit, %v, %d", err, count)
+	}
+}
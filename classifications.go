@@ -0,0 +1,198 @@
+//
+// Package cait is a collection of structures and functions
+// for interacting with ArchivesSpace's REST API
+//
+// @author R. S. Doiel, <rsdoiel@caltech.edu>
+//
+// Copyright (c) 2017, Caltech
+// All rights not granted herein are expressly reserved by Caltech.
+//
+// Redistribution and use in source and binary forms, with or without modification, are permitted provided that the following conditions are met:
+//
+// 1. Redistributions of source code must retain the above copyright notice, this list of conditions and the following disclaimer.
+//
+// 2. Redistributions in binary form must reproduce the above copyright notice, this list of conditions and the following disclaimer in the documentation and/or other materials provided with the distribution.
+//
+// 3. Neither the name of the copyright holder nor the names of its contributors may be used to endorse or promote products derived from this software without specific prior written permission.
+//
+// THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS" AND ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE ARE DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT HOLDER OR CONTRIBUTORS BE LIABLE FOR ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL DAMAGES (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR SERVICES; LOSS OF USE, DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER CAUSED AND ON ANY THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT LIABILITY, OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE OF THIS SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+//
+package cait
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// classificationTransform returns a TransformFunc linking an accession or resource to classificationURI
+func classificationTransform(classificationURI string) TransformFunc {
+	return func(obj Object) (bool, error) {
+		uri, _ := obj["uri"].(string)
+		if strings.Contains(uri, "/accessions/") == false && strings.Contains(uri, "/resources/") == false {
+			return false, fmt.Errorf("%s is not an accession or resource", uri)
+		}
+		return addRef(obj, "classifications", classificationURI), nil
+	}
+}
+
+// checkClassificationURI makes sure uri refers to a classification or classification term
+func checkClassificationURI(uri string) error {
+	if strings.Contains(uri, "/classifications/") == false && strings.Contains(uri, "/classification_terms/") == false {
+		return fmt.Errorf("%q is not a classification or classification term URI", uri)
+	}
+	return nil
+}
+
+// LinkClassification links a classification or classification term to each accession
+// or resource in uris. Records already linked are left unchanged.
+func (api *ArchivesSpaceAPI) LinkClassification(classificationURI string, uris []string, opts *BatchEditOptions) (*BatchEditReport, error) {
+	if err := checkClassificationURI(classificationURI); err != nil {
+		return nil, err
+	}
+	return api.ApplyToURIs(uris, classificationTransform(classificationURI), opts)
+}
+
+// LinkClassificationToSearch links a classification or classification term to the
+// accessions and resources matching query
+func (api *ArchivesSpaceAPI) LinkClassificationToSearch(classificationURI, query string, opts *BatchEditOptions) (*BatchEditReport, error) {
+	if err := checkClassificationURI(classificationURI); err != nil {
+		return nil, err
+	}
+	o := BatchEditOptions{}
+	if opts != nil {
+		o = *opts
+	}
+	if len(o.Types) == 0 {
+		o.Types = []string{"accession", "resource"}
+	}
+	return api.ApplyToSearch(query, classificationTransform(classificationURI), &o)
+}
+
+// CreateClassification creates a Classification in a Repository. On success the
+// classification's URI, ID and lock version are updated from the response.
+func (api *ArchivesSpaceAPI) CreateClassification(repoID int, classification *Classification) (*ResponseMsg, error) {
+	u := api.callURL(fmt.Sprintf("/repositories/%d/classifications", repoID))
+	classification.JSONModelType = "classification"
+	classification.LockVersion = "0"
+	responseMsg, err := api.CreateAPI(u.String(), classification)
+	if err != nil {
+		return nil, fmt.Errorf("CreateClassification(%d) %w", repoID, err)
+	}
+	classification.SetURI(responseMsg.URI)
+	classification.LockVersion = responseMsg.LockVersion
+	return responseMsg, nil
+}
+
+// GetClassification retrieves a Classification from a Repository
+func (api *ArchivesSpaceAPI) GetClassification(repoID, classificationID int) (*Classification, error) {
+	u := api.callURL(fmt.Sprintf("/repositories/%d/classifications/%d", repoID, classificationID))
+	classification := new(Classification)
+	if err := api.GetAPI(u.String(), classification); err != nil {
+		return nil, fmt.Errorf("GetClassification(%d, %d) %w", repoID, classificationID, err)
+	}
+	classification.ID = URIToID(classification.URI)
+	return classification, nil
+}
+
+// UpdateClassification updates an existing Classification
+func (api *ArchivesSpaceAPI) UpdateClassification(classification *Classification) (*ResponseMsg, error) {
+	u := api.callURL(classification.URI)
+	return api.UpdateAPI(u.String(), classification)
+}
+
+// DeleteClassification deletes a Classification and its terms
+func (api *ArchivesSpaceAPI) DeleteClassification(classification *Classification) (*ResponseMsg, error) {
+	u := api.callURL(classification.URI)
+	return api.DeleteAPI(u.String(), classification)
+}
+
+// ListClassifications returns the Classification IDs of a Repository.
+// Give modifiedSince to list only the records changed since then.
+func (api *ArchivesSpaceAPI) ListClassifications(repoID int, modifiedSince ...time.Time) ([]int, error) {
+	u := api.callURL(fmt.Sprintf("/repositories/%d/classifications", repoID))
+	q := u.Query()
+	q.Set("all_ids", "true")
+	setModifiedSince(q, modifiedSince)
+	u.RawQuery = q.Encode()
+	return api.ListAPI(u.String())
+}
+
+// GetClassificationTree returns a Classification with its terms nested as children
+func (api *ArchivesSpaceAPI) GetClassificationTree(repoID, classificationID int) (*ClassificationTree, error) {
+	u := api.callURL(fmt.Sprintf("/repositories/%d/classifications/%d/tree", repoID, classificationID))
+	tree := new(ClassificationTree)
+	if err := api.GetAPI(u.String(), tree); err != nil {
+		return nil, fmt.Errorf("GetClassificationTree(%d, %d) %w", repoID, classificationID, err)
+	}
+	return tree, nil
+}
+
+// NewClassificationTerm returns a ClassificationTerm in the classification given.
+// parentURI is the term it is nested under, leave it empty for a top level term.
+func NewClassificationTerm(classificationURI, parentURI, identifier, title string) *ClassificationTerm {
+	term := &ClassificationTerm{
+		Identifier:     identifier,
+		Title:          title,
+		Publish:        true,
+		Classification: Ref(classificationURI),
+		JSONModelType:  "classification_term",
+	}
+	if parentURI != "" {
+		term.Parent = Ref(parentURI)
+	}
+	return term
+}
+
+// CreateClassificationTerm creates a ClassificationTerm in a Repository. The term must
+// reference its classification. On success the term's URI, ID and lock version are
+// updated from the response.
+func (api *ArchivesSpaceAPI) CreateClassificationTerm(repoID int, term *ClassificationTerm) (*ResponseMsg, error) {
+	if term.Classification == nil {
+		return nil, fmt.Errorf("CreateClassificationTerm(%d) term must reference a classification", repoID)
+	}
+	u := api.callURL(fmt.Sprintf("/repositories/%d/classification_terms", repoID))
+	term.JSONModelType = "classification_term"
+	term.LockVersion = "0"
+	responseMsg, err := api.CreateAPI(u.String(), term)
+	if err != nil {
+		return nil, fmt.Errorf("CreateClassificationTerm(%d) %w", repoID, err)
+	}
+	term.SetURI(responseMsg.URI)
+	term.LockVersion = responseMsg.LockVersion
+	return responseMsg, nil
+}
+
+// GetClassificationTerm retrieves a ClassificationTerm from a Repository
+func (api *ArchivesSpaceAPI) GetClassificationTerm(repoID, termID int) (*ClassificationTerm, error) {
+	u := api.callURL(fmt.Sprintf("/repositories/%d/classification_terms/%d", repoID, termID))
+	term := new(ClassificationTerm)
+	if err := api.GetAPI(u.String(), term); err != nil {
+		return nil, fmt.Errorf("GetClassificationTerm(%d, %d) %w", repoID, termID, err)
+	}
+	term.ID = URIToID(term.URI)
+	return term, nil
+}
+
+// UpdateClassificationTerm updates an existing ClassificationTerm
+func (api *ArchivesSpaceAPI) UpdateClassificationTerm(term *ClassificationTerm) (*ResponseMsg, error) {
+	u := api.callURL(term.URI)
+	return api.UpdateAPI(u.String(), term)
+}
+
+// DeleteClassificationTerm deletes a ClassificationTerm and the terms nested under it
+func (api *ArchivesSpaceAPI) DeleteClassificationTerm(term *ClassificationTerm) (*ResponseMsg, error) {
+	u := api.callURL(term.URI)
+	return api.DeleteAPI(u.String(), term)
+}
+
+// ListClassificationTerms returns the ClassificationTerm IDs of a Repository.
+// Give modifiedSince to list only the records changed since then.
+func (api *ArchivesSpaceAPI) ListClassificationTerms(repoID int, modifiedSince ...time.Time) ([]int, error) {
+	u := api.callURL(fmt.Sprintf("/repositories/%d/classification_terms", repoID))
+	q := u.Query()
+	q.Set("all_ids", "true")
+	setModifiedSince(q, modifiedSince)
+	u.RawQuery = q.Encode()
+	return api.ListAPI(u.String())
+}
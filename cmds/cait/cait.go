@@ -59,6 +59,7 @@ var (
 		"term",
 		"location",
 		"digital_object",
+		"search",
 		// "resource",
 	}
 	actions = []string{
@@ -67,6 +68,7 @@ var (
 		"update",
 		"delete",
 		"export",
+		"run",
 	}
 )
 
@@ -98,6 +100,26 @@ to ArchivesSpace. The following shell variables are used
 If CAIT_API_TOKEN is not set then CAIT_USERNAME and CAIT_PASSWORD
 are used.
 
+Set CAIT_DEBUG to true to log each request (with passwords and session
+tokens redacted) to standard error.
+
+If CAIT_SESSION_FILE is set (e.g. $HOME/.cait-session.json) the session
+token is saved there and reused by later commands until ArchivesSpace
+rejects it.
+
+Responses larger than CAIT_MAX_RESPONSE_SIZE bytes (default 64 MiB) are
+refused rather than read into memory, set it to 0 to remove the limit.
+
+If CAIT_CONFIG names a configuration file (JSON, or TOML if it ends in
+.toml) the connection settings come from the instance named by
+CAIT_INSTANCE (or the file's default instance) rather than the variables
+above.
+
+Saved searches are read from the instance's saved_searches in CAIT_CONFIG
+and can be run by name with the "search run" command. Searches in the JSON
+file named by CAIT_SAVED_SEARCHES (e.g. saved-searches.json) override those
+with the same name.
+
 `
 
 	examples = `
@@ -120,6 +142,12 @@ Or for a specific repository by ID with
 
     %s repository list '{"uri": "/repositories/2"}'
 
+Saved searches are listed and run by name, params are optional values
+for the search's query template
+
+    %s search list
+    %s search run '{"name": "unprocessed-accessions", "params": {"fy_start": "2017-07-01"}}'
+
 Other SUBJECTS and ACTIONS work in a similar fashion.
 
 `
@@ -144,10 +172,18 @@ func containsElement(src []string, elem string) bool {
 	return false
 }
 
+// login authenticates, reusing the session saved in CAIT_SESSION_FILE when set
+func login(api *cait.ArchivesSpaceAPI) error {
+	if fname := os.Getenv("CAIT_SESSION_FILE"); fname != "" {
+		return api.LoginWithSession(fname)
+	}
+	return api.Login()
+}
+
 func exportArchivesSpace(api *cait.ArchivesSpaceAPI) error {
 	log.Println("Logging into ", api.BaseURL)
 	log.Printf("Exporting to %s\n", api.Dataset)
-	err := api.Login()
+	err := login(api)
 	if err != nil {
 		return fmt.Errorf("%s, error %s", api.BaseURL, err)
 	}
@@ -182,7 +218,7 @@ func parseCmd(args []string) (*command, error) {
 }
 
 func runArchivesSpaceCmd(api *cait.ArchivesSpaceAPI, cmd *command) (string, error) {
-	if err := api.Login(); err != nil {
+	if err := login(api); err != nil {
 		return "", err
 	}
 	switch cmd.Action {
@@ -193,7 +229,7 @@ func runArchivesSpaceCmd(api *cait.ArchivesSpaceAPI, cmd *command) (string, erro
 }
 
 func runRepoCmd(api *cait.ArchivesSpaceAPI, cmd *command) (string, error) {
-	if err := api.Login(); err != nil {
+	if err := login(api); err != nil {
 		return "", err
 	}
 	repoID := 0
@@ -272,7 +308,7 @@ func runRepoCmd(api *cait.ArchivesSpaceAPI, cmd *command) (string, error) {
 }
 
 func runAgentCmd(api *cait.ArchivesSpaceAPI, cmd *command) (string, error) {
-	if err := api.Login(); err != nil {
+	if err := login(api); err != nil {
 		return "", err
 	}
 	//Agent Type Payload as JSON encoded objects
@@ -355,7 +391,7 @@ func runAgentCmd(api *cait.ArchivesSpaceAPI, cmd *command) (string, error) {
 }
 
 func runAccessionCmd(api *cait.ArchivesSpaceAPI, cmd *command) (string, error) {
-	if err := api.Login(); err != nil {
+	if err := login(api); err != nil {
 		return "", err
 	}
 	// Repo ID is passed as a JSON object
@@ -435,7 +471,7 @@ func runAccessionCmd(api *cait.ArchivesSpaceAPI, cmd *command) (string, error) {
 }
 
 func runSubjectCmd(api *cait.ArchivesSpaceAPI, cmd *command) (string, error) {
-	if err := api.Login(); err != nil {
+	if err := login(api); err != nil {
 		return "", err
 	}
 	subject := new(cait.Subject)
@@ -510,7 +546,7 @@ func runSubjectCmd(api *cait.ArchivesSpaceAPI, cmd *command) (string, error) {
 }
 
 func runLocationCmd(api *cait.ArchivesSpaceAPI, cmd *command) (string, error) {
-	if err := api.Login(); err != nil {
+	if err := login(api); err != nil {
 		return "", err
 	}
 	location := new(cait.Location)
@@ -585,7 +621,7 @@ func runLocationCmd(api *cait.ArchivesSpaceAPI, cmd *command) (string, error) {
 }
 
 func runVocabularyCmd(api *cait.ArchivesSpaceAPI, cmd *command) (string, error) {
-	if err := api.Login(); err != nil {
+	if err := login(api); err != nil {
 		return "", err
 	}
 	vocabulary := new(cait.Vocabulary)
@@ -661,7 +697,7 @@ func runVocabularyCmd(api *cait.ArchivesSpaceAPI, cmd *command) (string, error)
 }
 
 func runTermCmd(api *cait.ArchivesSpaceAPI, cmd *command) (string, error) {
-	if err := api.Login(); err != nil {
+	if err := login(api); err != nil {
 		return "", err
 	}
 	term := new(cait.Term)
@@ -743,7 +779,7 @@ func runTermCmd(api *cait.ArchivesSpaceAPI, cmd *command) (string, error) {
 }
 
 func runDigitalObjectCmd(api *cait.ArchivesSpaceAPI, cmd *command) (string, error) {
-	if err := api.Login(); err != nil {
+	if err := login(api); err != nil {
 		return "", err
 	}
 	obj := new(cait.DigitalObject)
@@ -822,7 +858,7 @@ func runDigitalObjectCmd(api *cait.ArchivesSpaceAPI, cmd *command) (string, erro
 }
 
 func runResourceCmd(api *cait.ArchivesSpaceAPI, cmd *command) (string, error) {
-	if err := api.Login(); err != nil {
+	if err := login(api); err != nil {
 		return "", err
 	}
 	obj := new(cait.Resource)
@@ -900,6 +936,37 @@ func runResourceCmd(api *cait.ArchivesSpaceAPI, cmd *command) (string, error) {
 	return "", fmt.Errorf("runResourceCMd() action %s not implemented for %s", cmd.Action, cmd.Subject)
 }
 
+func runSearchCmd(api *cait.ArchivesSpaceAPI, cmd *command) (string, error) {
+	if fname := os.Getenv("CAIT_SAVED_SEARCHES"); fname != "" {
+		if err := api.LoadSavedSearches(fname); err != nil {
+			return "", err
+		}
+	}
+	switch cmd.Action {
+	case "list":
+		src, err := json.Marshal(api.ListSavedSearches())
+		return string(src), err
+	case "run":
+		req := struct {
+			Name   string            `json:"name"`
+			Params map[string]string `json:"params,omitempty"`
+		}{}
+		if err := json.Unmarshal([]byte(cmd.Payload), &req); err != nil {
+			return "", fmt.Errorf("Could not decode %s, error: %s", cmd.Payload, err)
+		}
+		if err := login(api); err != nil {
+			return "", err
+		}
+		results, err := api.RunSavedSearch(req.Name, req.Params)
+		if err != nil {
+			return "", err
+		}
+		src, err := json.Marshal(results)
+		return string(src), err
+	}
+	return "", fmt.Errorf("action %s not implemented for %s", cmd.Action, cmd.Subject)
+}
+
 func runCmd(api *cait.ArchivesSpaceAPI, cmd *command) (string, error) {
 	switch cmd.Subject {
 	case "archivesspace":
@@ -920,6 +987,8 @@ func runCmd(api *cait.ArchivesSpaceAPI, cmd *command) (string, error) {
 		return runTermCmd(api, cmd)
 	case "digital_object":
 		return runDigitalObjectCmd(api, cmd)
+	case "search":
+		return runSearchCmd(api, cmd)
 		// case "resource":
 		// 	return runResourceCmd(api, cmd)
 	}
@@ -958,7 +1027,7 @@ func main() {
 	cfg.LicenseText = fmt.Sprintf(cait.LicenseText, appName, cait.Version)
 	cfg.UsageText = fmt.Sprintf(usage, appName)
 	cfg.DescriptionText = fmt.Sprintf(description, appName, strings.Join(subjects, ", "), strings.Join(actions, ", "), appName)
-	cfg.ExampleText = fmt.Sprintf(examples, appName, appName, appName)
+	cfg.ExampleText = fmt.Sprintf(examples, appName, appName, appName, appName, appName)
 	cfg.OptionText = "OPTIONS\n\n"
 
 	if showHelp == true {
@@ -1015,7 +1084,22 @@ func main() {
 		log.SetOutput(os.Stderr)
 	}
 
-	api := cait.New(caitAPIURL, caitUsername, caitPassword, caitDataset)
+	var api *cait.ArchivesSpaceAPI
+	if fname := os.Getenv("CAIT_CONFIG"); fname != "" {
+		api, err = cait.NewFromConfig(fname, os.Getenv("CAIT_INSTANCE"))
+		if err != nil {
+			log.Fatalf("%s", err)
+		}
+		if api.Dataset == "" {
+			api.Dataset = caitDataset
+		}
+	} else {
+		api = cait.New(caitAPIURL, caitUsername, caitPassword, caitDataset)
+	}
+	if os.Getenv("CAIT_DEBUG") == "true" {
+		api.Logger = log.New(os.Stderr, "", log.LstdFlags)
+		api.Debug = true
+	}
 	src, err := runCmd(api, cmd)
 	if err != nil {
 		fmt.Println(err)
@@ -0,0 +1,62 @@
+//
+// Package cait is a collection of structures and functions
+// for interacting with ArchivesSpace's REST API
+//
+// @author R. S. Doiel, <rsdoiel@caltech.edu>
+//
+// Copyright (c) 2017, Caltech
+// All rights not granted herein are expressly reserved by Caltech.
+//
+// Redistribution and use in source and binary forms, with or without modification, are permitted provided that the following conditions are met:
+//
+// 1. Redistributions of source code must retain the above copyright notice, this list of conditions and the following disclaimer.
+//
+// 2. Redistributions in binary form must reproduce the above copyright notice, this list of conditions and the following disclaimer in the documentation and/or other materials provided with the distribution.
+//
+// 3. Neither the name of the copyright holder nor the names of its contributors may be used to endorse or promote products derived from this software without specific prior written permission.
+//
+// THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS" AND ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE ARE DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT HOLDER OR CONTRIBUTORS BE LIABLE FOR ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL DAMAGES (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR SERVICES; LOSS OF USE, DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER CAUSED AND ON ANY THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT LIABILITY, OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE OF THIS SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+//
+package cait
+
+import (
+	"fmt"
+	"time"
+)
+
+// Collection management records hold processing information (priority, status,
+// processors, estimates) and are saved as part of their accession, resource or
+// digital object, e.g. accession.CollectionManagement. The functions here read
+// them across a Repository for processing queue reports.
+
+// GetCollectionManagement retrieves a collection management record from a Repository
+func (api *ArchivesSpaceAPI) GetCollectionManagement(repoID, collectionManagementID int) (*CollectionManagement, error) {
+	u := api.callURL(fmt.Sprintf("/repositories/%d/collection_management/%d", repoID, collectionManagementID))
+	cm := new(CollectionManagement)
+	if err := api.GetAPI(u.String(), cm); err != nil {
+		return nil, fmt.Errorf("GetCollectionManagement(%d, %d) %w", repoID, collectionManagementID, err)
+	}
+	cm.ID = URIToID(cm.URI)
+	return cm, nil
+}
+
+// ListCollectionManagement return a list of collection management record IDs from a Repository
+// Give modifiedSince to list only the records changed since then.
+func (api *ArchivesSpaceAPI) ListCollectionManagement(repoID int, modifiedSince ...time.Time) ([]int, error) {
+	u := api.callURL(fmt.Sprintf("/repositories/%d/collection_management", repoID))
+	q := u.Query()
+	q.Set("all_ids", "true")
+	setModifiedSince(q, modifiedSince)
+	u.RawQuery = q.Encode()
+	return api.ListAPI(u.String())
+}
+
+// ListCollectionManagementPage returns a page of collection management records from a Repository
+func (api *ArchivesSpaceAPI) ListCollectionManagementPage(repoID, page, pageSize int, modifiedSince ...time.Time) (*Page[CollectionManagement], error) {
+	return ListPage[CollectionManagement](api, fmt.Sprintf("/repositories/%d/collection_management", repoID), page, pageSize, modifiedSince...)
+}
+
+// EachCollectionManagement calls fn with every collection management record in a Repository
+func (api *ArchivesSpaceAPI) EachCollectionManagement(repoID int, fn func(*CollectionManagement) error) error {
+	return Each[CollectionManagement](api, fmt.Sprintf("/repositories/%d/collection_management", repoID), eachPageSize, fn)
+}
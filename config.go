@@ -0,0 +1,182 @@
+//
+// Package cait is a collection of structures and functions
+// for interacting with ArchivesSpace's REST API
+//
+// @author R. S. Doiel, <rsdoiel@caltech.edu>
+//
+// Copyright (c) 2017, Caltech
+// All rights not granted herein are expressly reserved by Caltech.
+//
+// Redistribution and use in source and binary forms, with or without modification, are permitted provided that the following conditions are met:
+//
+// 1. Redistributions of source code must retain the above copyright notice, this list of conditions and the following disclaimer.
+//
+// 2. Redistributions in binary form must reproduce the above copyright notice, this list of conditions and the following disclaimer in the documentation and/or other materials provided with the distribution.
+//
+// 3. Neither the name of the copyright holder nor the names of its contributors may be used to endorse or promote products derived from this software without specific prior written permission.
+//
+// THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS" AND ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE ARE DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT HOLDER OR CONTRIBUTORS BE LIABLE FOR ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL DAMAGES (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR SERVICES; LOSS OF USE, DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER CAUSED AND ON ANY THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT LIABILITY, OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE OF THIS SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+//
+package cait
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"path"
+	"sort"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+)
+
+// InstanceConfig holds the settings for one ArchivesSpace instance in a Config file.
+// Timeouts are durations such as "30s" or a whole number of seconds. Setting
+// keyring_service reads the password from the OS keyring rather than the file.
+// SavedSearches are added to the client so they can be run by name.
+type InstanceConfig struct {
+	APIURL          string         `json:"api_url" toml:"api_url"`
+	Username        string         `json:"username,omitempty" toml:"username"`
+	Password        string         `json:"password,omitempty" toml:"password"`
+	KeyringService  string         `json:"keyring_service,omitempty" toml:"keyring_service"`
+	Token           string         `json:"token,omitempty" toml:"token"`
+	Dataset         string         `json:"dataset,omitempty" toml:"dataset"`
+	ConnectTimeout  string         `json:"connect_timeout,omitempty" toml:"connect_timeout"`
+	RequestTimeout  string         `json:"request_timeout,omitempty" toml:"request_timeout"`
+	MaxRetries      int            `json:"max_retries,omitempty" toml:"max_retries"`
+	MaxResponseSize int64          `json:"max_response_size,omitempty" toml:"max_response_size"`
+	SavedSearches   []*SavedSearch `json:"saved_searches,omitempty" toml:"saved_searches"`
+}
+
+// Config is a configuration file describing one or more named ArchivesSpace
+// instances (e.g. sandbox, staging, production), for example
+//
+//	{
+//	    "default": "sandbox",
+//	    "instances": {
+//	        "sandbox": {"api_url": "http://localhost:8089", "username": "admin", "password": "admin"},
+//	        "production": {"api_url": "https://aspace.example.edu/api", "token": "..."}
+//	    }
+//	}
+//
+// or the same settings in TOML
+//
+//	default = "sandbox"
+//
+//	[instances.sandbox]
+//	api_url = "http://localhost:8089"
+//	username = "admin"
+//	password = "admin"
+//
+//	[[instances.sandbox.saved_searches]]
+//	name = "unprocessed"
+//	q = "processing_status:unprocessed"
+type Config struct {
+	Default   string                     `json:"default,omitempty" toml:"default"`
+	Instances map[string]*InstanceConfig `json:"instances" toml:"instances"`
+}
+
+// LoadConfig reads a configuration file, files ending in .toml are decoded
+// as TOML and anything else as JSON
+func LoadConfig(fname string) (*Config, error) {
+	src, err := ioutil.ReadFile(fname)
+	if err != nil {
+		return nil, fmt.Errorf("LoadConfig(%q) %w", fname, err)
+	}
+	cfg := new(Config)
+	if strings.ToLower(path.Ext(fname)) == ".toml" {
+		err = toml.Unmarshal(src, cfg)
+	} else {
+		err = json.Unmarshal(src, cfg)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("LoadConfig(%q) %w", fname, err)
+	}
+	if len(cfg.Instances) == 0 {
+		return nil, fmt.Errorf("LoadConfig(%q) no instances defined", fname)
+	}
+	if cfg.Default != "" {
+		if _, ok := cfg.Instances[cfg.Default]; ok == false {
+			return nil, fmt.Errorf("LoadConfig(%q) default instance %q is not defined", fname, cfg.Default)
+		}
+	}
+	return cfg, nil
+}
+
+// Names returns the sorted instance names
+func (cfg *Config) Names() []string {
+	names := []string{}
+	for name := range cfg.Instances {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Client returns a client for the named instance. An empty name selects the
+// default instance, or the only instance if there is just one. Options are
+// applied after the instance's settings.
+func (cfg *Config) Client(name string, options ...Option) (*ArchivesSpaceAPI, error) {
+	if name == "" {
+		name = cfg.Default
+	}
+	if name == "" && len(cfg.Instances) == 1 {
+		name = cfg.Names()[0]
+	}
+	instance, ok := cfg.Instances[name]
+	if ok == false || instance == nil {
+		return nil, fmt.Errorf("instance %q not found, expected one of %v", name, cfg.Names())
+	}
+	opts := []Option{}
+	if instance.Username != "" || instance.Password != "" {
+		opts = append(opts, WithCredentials(instance.Username, instance.Password))
+	}
+	if instance.KeyringService != "" {
+		opts = append(opts, WithCredentialProvider(instance.Username, &KeyringProvider{Service: instance.KeyringService}))
+	}
+	if instance.Token != "" {
+		opts = append(opts, WithToken(instance.Token))
+	}
+	if instance.Dataset != "" {
+		opts = append(opts, WithDataset(instance.Dataset))
+	}
+	if instance.ConnectTimeout != "" {
+		d, err := parseTimeout("connect_timeout", instance.ConnectTimeout)
+		if err != nil {
+			return nil, fmt.Errorf("instance %q %w", name, err)
+		}
+		opts = append(opts, WithConnectTimeout(d))
+	}
+	if instance.RequestTimeout != "" {
+		d, err := parseTimeout("request_timeout", instance.RequestTimeout)
+		if err != nil {
+			return nil, fmt.Errorf("instance %q %w", name, err)
+		}
+		opts = append(opts, WithRequestTimeout(d))
+	}
+	if instance.MaxRetries > 0 {
+		opts = append(opts, WithRetry(&RetryPolicy{MaxAttempts: instance.MaxRetries + 1}))
+	}
+	if instance.MaxResponseSize != 0 {
+		opts = append(opts, WithMaxResponseSize(instance.MaxResponseSize))
+	}
+	api, err := NewClient(instance.APIURL, append(opts, options...)...)
+	if err != nil {
+		return nil, fmt.Errorf("instance %q %w", name, err)
+	}
+	for _, search := range instance.SavedSearches {
+		if err := api.AddSavedSearch(search); err != nil {
+			return nil, fmt.Errorf("instance %q %w", name, err)
+		}
+	}
+	return api, nil
+}
+
+// NewFromConfig loads a configuration file and returns a client for the named instance
+func NewFromConfig(fname, name string, options ...Option) (*ArchivesSpaceAPI, error) {
+	cfg, err := LoadConfig(fname)
+	if err != nil {
+		return nil, err
+	}
+	return cfg.Client(name, options...)
+}
@@ -0,0 +1,215 @@
+//
+// Package cait is a collection of structures and functions
+// for interacting with ArchivesSpace's REST API
+//
+// @author R. S. Doiel, <rsdoiel@caltech.edu>
+//
+// Copyright (c) 2017, Caltech
+// All rights not granted herein are expressly reserved by Caltech.
+//
+// Redistribution and use in source and binary forms, with or without modification, are permitted provided that the following conditions are met:
+//
+// 1. Redistributions of source code must retain the above copyright notice, this list of conditions and the following disclaimer.
+//
+// 2. Redistributions in binary form must reproduce the above copyright notice, this list of conditions and the following disclaimer in the documentation and/or other materials provided with the distribution.
+//
+// 3. Neither the name of the copyright holder nor the names of its contributors may be used to endorse or promote products derived from this software without specific prior written permission.
+//
+// THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS" AND ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE ARE DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT HOLDER OR CONTRIBUTORS BE LIABLE FOR ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL DAMAGES (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR SERVICES; LOSS OF USE, DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER CAUSED AND ON ANY THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT LIABILITY, OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE OF THIS SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+//
+package cait
+
+import (
+	"fmt"
+	"strings"
+)
+
+// LocationCoordinate identifies a location by its building, room and coordinate
+// indicators. Empty fields are ignored when matching.
+type LocationCoordinate struct {
+	Building    string `json:"building,omitempty"`
+	Floor       string `json:"floor,omitempty"`
+	Room        string `json:"room,omitempty"`
+	Area        string `json:"area,omitempty"`
+	Coordinate1 string `json:"coordinate_1,omitempty"`
+	Coordinate2 string `json:"coordinate_2,omitempty"`
+	Coordinate3 string `json:"coordinate_3,omitempty"`
+}
+
+// ContainerMove asks for a top container, given by URI or barcode, to be
+// assigned to a location, given by URI, location barcode or coordinate.
+type ContainerMove struct {
+	Container  string              `json:"container"`
+	Location   string              `json:"location,omitempty"`
+	Coordinate *LocationCoordinate `json:"coordinate,omitempty"`
+}
+
+// ContainerMovePreview is a resolved ContainerMove, Error is set if it can't be applied
+type ContainerMovePreview struct {
+	Container        string   `json:"container"`
+	ContainerURI     string   `json:"container_uri,omitempty"`
+	ContainerDisplay string   `json:"container_display,omitempty"`
+	FromLocations    []string `json:"from_locations,omitempty"`
+	LocationURI      string   `json:"location_uri,omitempty"`
+	LocationTitle    string   `json:"location_title,omitempty"`
+	Error            string   `json:"error,omitempty"`
+}
+
+// ContainerMovePlan holds the validated moves for review before ApplyContainerMoves
+type ContainerMovePlan struct {
+	RepoID int                     `json:"repo_id"`
+	Moves  []*ContainerMovePreview `json:"moves"`
+	Errors int                     `json:"errors"`
+}
+
+// Match returns true if every non-empty field of the coordinate matches the location
+func (coord *LocationCoordinate) Match(location *Location) bool {
+	pairs := [][2]string{
+		{coord.Building, location.Building},
+		{coord.Floor, location.Floor},
+		{coord.Room, location.Room},
+		{coord.Area, location.Area},
+		{coord.Coordinate1, location.Coordinate1Indicator},
+		{coord.Coordinate2, location.Coordinate2Indicator},
+		{coord.Coordinate3, location.Coordinate3Indicator},
+	}
+	matched := false
+	for _, p := range pairs {
+		want := strings.TrimSpace(p[0])
+		if want == "" {
+			continue
+		}
+		if strings.EqualFold(want, strings.TrimSpace(p[1])) == false {
+			return false
+		}
+		matched = true
+	}
+	return matched
+}
+
+// allLocations fetches every location record
+func (api *ArchivesSpaceAPI) allLocations() ([]*Location, error) {
+	ids, err := api.ListLocations()
+	if err != nil {
+		return nil, err
+	}
+	locations := []*Location{}
+	for _, id := range ids {
+		location, err := api.GetLocation(id)
+		if err != nil {
+			return nil, err
+		}
+		locations = append(locations, location)
+	}
+	return locations, nil
+}
+
+// resolveMoveLocation finds the single location a move refers to
+func resolveMoveLocation(move *ContainerMove, locations []*Location) (*Location, error) {
+	matches := []*Location{}
+	for _, location := range locations {
+		switch {
+		case strings.HasPrefix(move.Location, "/locations/"):
+			if location.URI == move.Location {
+				matches = append(matches, location)
+			}
+		case move.Location != "":
+			if location.Barcode == move.Location {
+				matches = append(matches, location)
+			}
+		case move.Coordinate != nil:
+			if move.Coordinate.Match(location) {
+				matches = append(matches, location)
+			}
+		}
+	}
+	if move.Location == "" && move.Coordinate == nil {
+		return nil, fmt.Errorf("no location given")
+	}
+	if len(matches) == 0 {
+		return nil, fmt.Errorf("no location matches")
+	}
+	if len(matches) > 1 {
+		return nil, fmt.Errorf("ambiguous location, %d locations match", len(matches))
+	}
+	return matches[0], nil
+}
+
+// PlanContainerMoves resolves and validates a list of container moves without changing
+// anything. Review the returned plan (e.g. plan.String()) then pass it to ApplyContainerMoves.
+func (api *ArchivesSpaceAPI) PlanContainerMoves(repoID int, moves []*ContainerMove) (*ContainerMovePlan, error) {
+	locations, err := api.allLocations()
+	if err != nil {
+		return nil, fmt.Errorf("PlanContainerMoves(%d) can't read locations, %w", repoID, err)
+	}
+	plan := &ContainerMovePlan{RepoID: repoID}
+	seen := map[string]bool{}
+	for _, move := range moves {
+		preview := &ContainerMovePreview{Container: move.Container}
+		plan.Moves = append(plan.Moves, preview)
+
+		var (
+			container *TopContainer
+			err       error
+		)
+		if strings.HasPrefix(move.Container, "/repositories/") {
+			if URIToRepoID(move.Container) != repoID {
+				preview.Error = fmt.Sprintf("container is not in repository %d", repoID)
+			} else {
+				container, err = api.GetTopContainer(repoID, URIToID(move.Container))
+			}
+		} else {
+			container, err = api.GetTopContainerByBarcode(repoID, move.Container)
+		}
+		if preview.Error == "" && err != nil {
+			preview.Error = err.Error()
+		}
+		if container != nil {
+			preview.ContainerURI = container.URI
+			preview.ContainerDisplay = container.DisplayString
+			for _, cl := range container.ContainerLocations {
+				if cl.Status == "current" {
+					preview.FromLocations = append(preview.FromLocations, cl.Ref)
+				}
+			}
+			if seen[container.URI] == true && preview.Error == "" {
+				preview.Error = "container listed more than once"
+			}
+			seen[container.URI] = true
+		}
+
+		location, err2 := resolveMoveLocation(move, locations)
+		if err2 != nil {
+			if preview.Error == "" {
+				preview.Error = err2.Error()
+			}
+		} else {
+			preview.LocationURI = location.URI
+			preview.LocationTitle = location.Title
+		}
+		if preview.Error != "" {
+			plan.Errors++
+		}
+	}
+	return plan, nil
+}
+
+// ApplyContainerMoves assigns the planned locations in bulk. A plan with errors is refused.
+func (api *ArchivesSpaceAPI) ApplyContainerMoves(plan *ContainerMovePlan) ([]int, error) {
+	if plan.Errors > 0 {
+		return nil, fmt.Errorf("container move plan has %d errors", plan.Errors)
+	}
+	locations := map[string]string{}
+	for _, move := range plan.Moves {
+		locations[move.ContainerURI] = move.LocationURI
+	}
+	if len(locations) == 0 {
+		return nil, nil
+	}
+	return api.UpdateTopContainerLocations(plan.RepoID, locations)
+}
+
+// String return a ContainerMovePlan
+func (plan *ContainerMovePlan) String() string {
+	return stringify(plan)
+}
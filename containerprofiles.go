@@ -0,0 +1,88 @@
+//
+// Package cait is a collection of structures and functions
+// for interacting with ArchivesSpace's REST API
+//
+// @author R. S. Doiel, <rsdoiel@caltech.edu>
+//
+// Copyright (c) 2017, Caltech
+// All rights not granted herein are expressly reserved by Caltech.
+//
+// Redistribution and use in source and binary forms, with or without modification, are permitted provided that the following conditions are met:
+//
+// 1. Redistributions of source code must retain the above copyright notice, this list of conditions and the following disclaimer.
+//
+// 2. Redistributions in binary form must reproduce the above copyright notice, this list of conditions and the following disclaimer in the documentation and/or other materials provided with the distribution.
+//
+// 3. Neither the name of the copyright holder nor the names of its contributors may be used to endorse or promote products derived from this software without specific prior written permission.
+//
+// THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS" AND ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE ARE DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT HOLDER OR CONTRIBUTORS BE LIABLE FOR ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL DAMAGES (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR SERVICES; LOSS OF USE, DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER CAUSED AND ON ANY THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT LIABILITY, OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE OF THIS SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+//
+package cait
+
+import (
+	"fmt"
+	"time"
+)
+
+// NewContainerProfile returns a container profile for a box type, e.g.
+// NewContainerProfile("Hollinger box", "inches", "width", "5", "10.25", "15.5").
+// extentDimension names the dimension used when calculating extents.
+func NewContainerProfile(name, dimensionUnits, extentDimension, depth, height, width string) *ContainerProfile {
+	return &ContainerProfile{
+		Name:            name,
+		DimensionUnits:  dimensionUnits,
+		ExtentDimension: extentDimension,
+		Depth:           depth,
+		Height:          height,
+		Width:           width,
+	}
+}
+
+// CreateContainerProfile creates a container profile. On success the profile's
+// URI, ID and lock version are updated from the response.
+func (api *ArchivesSpaceAPI) CreateContainerProfile(profile *ContainerProfile) (*ResponseMsg, error) {
+	u := api.callURL("/container_profiles")
+	profile.JSONModelType = "container_profile"
+	profile.LockVersion = "0"
+	responseMsg, err := api.CreateAPI(u.String(), profile)
+	if err != nil {
+		return nil, fmt.Errorf("CreateContainerProfile(%q) %w", profile.Name, err)
+	}
+	profile.SetURI(responseMsg.URI)
+	profile.LockVersion = responseMsg.LockVersion
+	return responseMsg, nil
+}
+
+// GetContainerProfile retrieves a container profile
+func (api *ArchivesSpaceAPI) GetContainerProfile(profileID int) (*ContainerProfile, error) {
+	u := api.callURL(fmt.Sprintf("/container_profiles/%d", profileID))
+	profile := new(ContainerProfile)
+	if err := api.GetAPI(u.String(), profile); err != nil {
+		return nil, fmt.Errorf("GetContainerProfile(%d) %w", profileID, err)
+	}
+	profile.ID = URIToID(profile.URI)
+	return profile, nil
+}
+
+// UpdateContainerProfile updates an existing container profile
+func (api *ArchivesSpaceAPI) UpdateContainerProfile(profile *ContainerProfile) (*ResponseMsg, error) {
+	u := api.callURL(profile.URI)
+	return api.UpdateAPI(u.String(), profile)
+}
+
+// DeleteContainerProfile deletes a container profile
+func (api *ArchivesSpaceAPI) DeleteContainerProfile(profile *ContainerProfile) (*ResponseMsg, error) {
+	u := api.callURL(profile.URI)
+	return api.DeleteAPI(u.String(), profile)
+}
+
+// ListContainerProfiles return a list of container profile IDs
+// Give modifiedSince to list only the records changed since then.
+func (api *ArchivesSpaceAPI) ListContainerProfiles(modifiedSince ...time.Time) ([]int, error) {
+	u := api.callURL("/container_profiles")
+	q := u.Query()
+	q.Set("all_ids", "true")
+	setModifiedSince(q, modifiedSince)
+	u.RawQuery = q.Encode()
+	return api.ListAPI(u.String())
+}
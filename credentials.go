@@ -0,0 +1,97 @@
+//
+// Package cait is a collection of structures and functions
+// for interacting with ArchivesSpace's REST API
+//
+// @author R. S. Doiel, <rsdoiel@caltech.edu>
+//
+// Copyright (c) 2017, Caltech
+// All rights not granted herein are expressly reserved by Caltech.
+//
+// Redistribution and use in source and binary forms, with or without modification, are permitted provided that the following conditions are met:
+//
+// 1. Redistributions of source code must retain the above copyright notice, this list of conditions and the following disclaimer.
+//
+// 2. Redistributions in binary form must reproduce the above copyright notice, this list of conditions and the following disclaimer in the documentation and/or other materials provided with the distribution.
+//
+// 3. Neither the name of the copyright holder nor the names of its contributors may be used to endorse or promote products derived from this software without specific prior written permission.
+//
+// THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS" AND ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE ARE DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT HOLDER OR CONTRIBUTORS BE LIABLE FOR ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL DAMAGES (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR SERVICES; LOSS OF USE, DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER CAUSED AND ON ANY THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT LIABILITY, OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE OF THIS SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+//
+package cait
+
+import (
+	"fmt"
+	"os/exec"
+	"runtime"
+	"strings"
+)
+
+// CredentialProvider supplies the password for a user when Login is called,
+// so the password needn't be held in the ArchivesSpaceAPI struct
+type CredentialProvider interface {
+	Password(username string) (string, error)
+}
+
+// CredentialFunc adapts a function to a CredentialProvider
+type CredentialFunc func(username string) (string, error)
+
+// Password calls fn
+func (fn CredentialFunc) Password(username string) (string, error) {
+	return fn(username)
+}
+
+// KeyringProvider reads passwords from the operating system's keyring, the
+// macOS keychain (via security) or the freedesktop Secret Service on Linux
+// (via secret-tool from libsecret). Store the password with the account set
+// to the ArchivesSpace username, e.g.
+//
+//	security add-generic-password -s archivesspace -a admin -w
+//	secret-tool store --label=ArchivesSpace service archivesspace account admin
+type KeyringProvider struct {
+	// Service is the name the password is stored under, e.g. "archivesspace"
+	Service string `json:"service"`
+}
+
+// Password looks up the password for username in the keyring
+func (keyring *KeyringProvider) Password(username string) (string, error) {
+	var cmd *exec.Cmd
+	switch runtime.GOOS {
+	case "darwin":
+		cmd = exec.Command("security", "find-generic-password", "-s", keyring.Service, "-a", username, "-w")
+	case "linux", "freebsd", "openbsd":
+		cmd = exec.Command("secret-tool", "lookup", "service", keyring.Service, "account", username)
+	default:
+		return "", fmt.Errorf("keyring not supported on %s", runtime.GOOS)
+	}
+	out, err := cmd.Output()
+	if err != nil {
+		return "", fmt.Errorf("can't read %s password for %s from keyring, %w", keyring.Service, username, err)
+	}
+	password := strings.TrimRight(string(out), "\r\n")
+	if password == "" {
+		return "", fmt.Errorf("no %s password for %s in keyring", keyring.Service, username)
+	}
+	return password, nil
+}
+
+// WithCredentialProvider sets the username and where Login gets its password from
+func WithCredentialProvider(username string, provider CredentialProvider) Option {
+	return func(api *ArchivesSpaceAPI) {
+		api.Username = username
+		api.Password = ""
+		api.Credentials = provider
+	}
+}
+
+// hasCredentials returns true if Login has a username and a way to get a password
+func (api *ArchivesSpaceAPI) hasCredentials() bool {
+	return api.Username != "" && (api.Password != "" || api.Credentials != nil)
+}
+
+// password returns api.Password or asks api.Credentials for it
+func (api *ArchivesSpaceAPI) password() (string, error) {
+	if api.Credentials == nil {
+		return api.Password, nil
+	}
+	return api.Credentials.Password(api.Username)
+}
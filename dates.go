@@ -0,0 +1,65 @@
+//
+// Package cait is a collection of structures and functions
+// for interacting with ArchivesSpace's REST API
+//
+// @author R. S. Doiel, <rsdoiel@caltech.edu>
+//
+// Copyright (c) 2017, Caltech
+// All rights not granted herein are expressly reserved by Caltech.
+//
+// Redistribution and use in source and binary forms, with or without modification, are permitted provided that the following conditions are met:
+//
+// 1. Redistributions of source code must retain the above copyright notice, this list of conditions and the following disclaimer.
+//
+// 2. Redistributions in binary form must reproduce the above copyright notice, this list of conditions and the following disclaimer in the documentation and/or other materials provided with the distribution.
+//
+// 3. Neither the name of the copyright holder nor the names of its contributors may be used to endorse or promote products derived from this software without specific prior written permission.
+//
+// THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS" AND ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE ARE DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT HOLDER OR CONTRIBUTORS BE LIABLE FOR ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL DAMAGES (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR SERVICES; LOSS OF USE, DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER CAUSED AND ON ANY THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT LIABILITY, OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE OF THIS SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+//
+package cait
+
+import (
+	"fmt"
+)
+
+// DateCalculation is the date range ArchivesSpace computes for a record and its components.
+// MinBegin and MaxEnd are as entered, MinBeginDate and MaxEndDate are full dates.
+type DateCalculation struct {
+	Object       map[string]interface{} `json:"object,omitempty"`
+	Resource     map[string]interface{} `json:"resource,omitempty"`
+	Label        string                 `json:"label,omitempty"`
+	MinBegin     string                 `json:"min_begin,omitempty"`
+	MinBeginDate string                 `json:"min_begin_date,omitempty"`
+	MaxEnd       string                 `json:"max_end,omitempty"`
+	MaxEndDate   string                 `json:"max_end_date,omitempty"`
+}
+
+// Date returns the calculated range as an inclusive Date, e.g. to replace a resource's
+// dates after an import
+func (calc *DateCalculation) Date() *Date {
+	return &Date{
+		JSONModelType: "date",
+		DateType:      "inclusive",
+		Label:         calc.Label,
+		Begin:         calc.MinBegin,
+		End:           calc.MaxEnd,
+	}
+}
+
+// CalculateDates asks ArchivesSpace for the range of the dates labeled label (e.g. "creation",
+// empty for any) of the resource or archival object at recordURI and its components
+func (api *ArchivesSpaceAPI) CalculateDates(recordURI, label string) (*DateCalculation, error) {
+	u := api.callURL("/date_calculator")
+	q := u.Query()
+	q.Set("record_uri", recordURI)
+	if label != "" {
+		q.Set("label", label)
+	}
+	u.RawQuery = q.Encode()
+	calc := new(DateCalculation)
+	if err := api.GetAPI(u.String(), calc); err != nil {
+		return nil, fmt.Errorf("CalculateDates(%q, %q) %w", recordURI, label, err)
+	}
+	return calc, nil
+}
@@ -0,0 +1,90 @@
+//
+// Package cait is a collection of structures and functions
+// for interacting with ArchivesSpace's REST API
+//
+// @author R. S. Doiel, <rsdoiel@caltech.edu>
+//
+// Copyright (c) 2017, Caltech
+// All rights not granted herein are expressly reserved by Caltech.
+//
+// Redistribution and use in source and binary forms, with or without modification, are permitted provided that the following conditions are met:
+//
+// 1. Redistributions of source code must retain the above copyright notice, this list of conditions and the following disclaimer.
+//
+// 2. Redistributions in binary form must reproduce the above copyright notice, this list of conditions and the following disclaimer in the documentation and/or other materials provided with the distribution.
+//
+// 3. Neither the name of the copyright holder nor the names of its contributors may be used to endorse or promote products derived from this software without specific prior written permission.
+//
+// THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS" AND ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE ARE DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT HOLDER OR CONTRIBUTORS BE LIABLE FOR ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL DAMAGES (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR SERVICES; LOSS OF USE, DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER CAUSED AND ON ANY THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT LIABILITY, OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE OF THIS SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+//
+package cait
+
+import (
+	"errors"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// redactedParams are query parameters whose values are never logged
+var redactedParams = []string{"password", "token", "session", "expiring"}
+
+// WithDebug logs the method, URL, status and timing of every request to logger.
+// Passwords and session tokens are never logged.
+func WithDebug(logger Logger) Option {
+	return func(api *ArchivesSpaceAPI) {
+		api.Logger = logger
+		api.Debug = true
+	}
+}
+
+// RedactURL returns rawURL with any password or session token replaced by REDACTED
+func RedactURL(rawURL string) string {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return "REDACTED"
+	}
+	if _, ok := u.User.Password(); ok == true {
+		u.User = url.UserPassword(u.User.Username(), "REDACTED")
+	}
+	q := u.Query()
+	changed := false
+	for key := range q {
+		for _, name := range redactedParams {
+			if strings.Contains(strings.ToLower(key), name) {
+				q.Set(key, "REDACTED")
+				changed = true
+			}
+		}
+	}
+	if changed == true {
+		u.RawQuery = q.Encode()
+	}
+	return u.String()
+}
+
+// requestError describes a failed request without repeating its (unredacted) URL
+func requestError(err error) string {
+	var e *url.Error
+	if errors.As(err, &e) == true {
+		return "error " + e.Err.Error()
+	}
+	return "error " + err.Error()
+}
+
+// redactError removes any password or session token from the URL of a failed
+// request so it can't leak through err.Error()
+func redactError(err error) error {
+	var e *url.Error
+	if errors.As(err, &e) == true {
+		e.URL = RedactURL(e.URL)
+	}
+	return err
+}
+
+// debugf logs a request when api.Debug is set
+func (api *ArchivesSpaceAPI) debugf(method, rawURL, status string, start time.Time) {
+	if api.Debug == true {
+		api.logf("%s %s %s %s", method, RedactURL(rawURL), status, time.Since(start).Round(time.Millisecond))
+	}
+}
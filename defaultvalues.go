@@ -0,0 +1,86 @@
+//
+// Package cait is a collection of structures and functions
+// for interacting with ArchivesSpace's REST API
+//
+// @author R. S. Doiel, <rsdoiel@caltech.edu>
+//
+// Copyright (c) 2017, Caltech
+// All rights not granted herein are expressly reserved by Caltech.
+//
+// Redistribution and use in source and binary forms, with or without modification, are permitted provided that the following conditions are met:
+//
+// 1. Redistributions of source code must retain the above copyright notice, this list of conditions and the following disclaimer.
+//
+// 2. Redistributions in binary form must reproduce the above copyright notice, this list of conditions and the following disclaimer in the documentation and/or other materials provided with the distribution.
+//
+// 3. Neither the name of the copyright holder nor the names of its contributors may be used to endorse or promote products derived from this software without specific prior written permission.
+//
+// THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS" AND ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE ARE DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT HOLDER OR CONTRIBUTORS BE LIABLE FOR ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL DAMAGES (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR SERVICES; LOSS OF USE, DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER CAUSED AND ON ANY THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT LIABILITY, OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE OF THIS SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+//
+package cait
+
+import (
+	"fmt"
+)
+
+// GetDefaultValues returns the default values a repository uses for new records of
+// recordType (e.g. "resource" or "archival_object")
+func (api *ArchivesSpaceAPI) GetDefaultValues(repoID int, recordType string) (*DefaultValues, error) {
+	u := api.callURL(fmt.Sprintf("/repositories/%d/default_values/%s", repoID, recordType))
+	dv := new(DefaultValues)
+	if err := api.GetAPI(u.String(), dv); err != nil {
+		return nil, fmt.Errorf("GetDefaultValues(%d, %q) %w", repoID, recordType, err)
+	}
+	return dv, nil
+}
+
+// SaveDefaultValues saves the default values for dv.RecordType in a repository,
+// replacing any already saved. The lock version is updated on success.
+func (api *ArchivesSpaceAPI) SaveDefaultValues(repoID int, dv *DefaultValues) (*ResponseMsg, error) {
+	if dv.RecordType == "" {
+		return nil, fmt.Errorf("SaveDefaultValues(%d) missing record type", repoID)
+	}
+	u := api.callURL(fmt.Sprintf("/repositories/%d/default_values/%s", repoID, dv.RecordType))
+	dv.JSONModelType = "default_values"
+	if dv.LockVersion == "" {
+		dv.LockVersion = "0"
+	}
+	responseMsg, err := api.UpdateAPI(u.String(), dv)
+	if err != nil {
+		return nil, fmt.Errorf("SaveDefaultValues(%d, %q) %w", repoID, dv.RecordType, err)
+	}
+	if responseMsg.URI != "" {
+		dv.SetURI(responseMsg.URI)
+	}
+	dv.LockVersion = responseMsg.LockVersion
+	return responseMsg, nil
+}
+
+// CopyDefaultValues copies the default values of each record type from the repository
+// srcRepoID to the repository dstRepoID of dst, e.g. from staging to production.
+// Record types without default values in the source are skipped. It returns the
+// record types copied.
+func (api *ArchivesSpaceAPI) CopyDefaultValues(dst *ArchivesSpaceAPI, srcRepoID, dstRepoID int, recordTypes ...string) ([]string, error) {
+	copied := []string{}
+	for _, recordType := range recordTypes {
+		dv, err := api.GetDefaultValues(srcRepoID, recordType)
+		if IsNotFound(err) {
+			continue
+		}
+		if err != nil {
+			return copied, fmt.Errorf("CopyDefaultValues() %w", err)
+		}
+		// Saving over existing default values needs their lock version
+		dv.URI, dv.LockVersion, dv.RecordType = "", "0", recordType
+		if existing, err := dst.GetDefaultValues(dstRepoID, recordType); err == nil {
+			dv.LockVersion = existing.LockVersion
+		} else if IsNotFound(err) == false {
+			return copied, fmt.Errorf("CopyDefaultValues() %w", err)
+		}
+		if _, err := dst.SaveDefaultValues(dstRepoID, dv); err != nil {
+			return copied, fmt.Errorf("CopyDefaultValues() %w", err)
+		}
+		copied = append(copied, recordType)
+	}
+	return copied, nil
+}
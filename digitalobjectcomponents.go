@@ -0,0 +1,110 @@
+//
+// Package cait is a collection of structures and functions
+// for interacting with ArchivesSpace's REST API
+//
+// @author R. S. Doiel, <rsdoiel@caltech.edu>
+//
+// Copyright (c) 2017, Caltech
+// All rights not granted herein are expressly reserved by Caltech.
+//
+// Redistribution and use in source and binary forms, with or without modification, are permitted provided that the following conditions are met:
+//
+// 1. Redistributions of source code must retain the above copyright notice, this list of conditions and the following disclaimer.
+//
+// 2. Redistributions in binary form must reproduce the above copyright notice, this list of conditions and the following disclaimer in the documentation and/or other materials provided with the distribution.
+//
+// 3. Neither the name of the copyright holder nor the names of its contributors may be used to endorse or promote products derived from this software without specific prior written permission.
+//
+// THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS" AND ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE ARE DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT HOLDER OR CONTRIBUTORS BE LIABLE FOR ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL DAMAGES (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR SERVICES; LOSS OF USE, DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER CAUSED AND ON ANY THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT LIABILITY, OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE OF THIS SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+//
+package cait
+
+import (
+	"fmt"
+	"time"
+)
+
+// NewDigitalObjectComponent returns a DigitalObjectComponent of the digital object given.
+// parentURI is the component it is a child of, leave it empty for a top level component.
+func NewDigitalObjectComponent(digitalObjectURI, parentURI, title string) *DigitalObjectComponent {
+	obj := &DigitalObjectComponent{
+		Title:         title,
+		DigitalObject: Ref(digitalObjectURI),
+		JSONModelType: "digital_object_component",
+	}
+	if parentURI != "" {
+		obj.Parent = Ref(parentURI)
+	}
+	return obj
+}
+
+// CreateDigitalObjectComponent creates a DigitalObjectComponent in a Repository. The
+// component must reference its digital object (and its parent component if it has one).
+// On success obj's URI, ID and lock version are updated from the response.
+func (api *ArchivesSpaceAPI) CreateDigitalObjectComponent(repoID int, obj *DigitalObjectComponent) (*ResponseMsg, error) {
+	if obj.DigitalObject == nil {
+		return nil, fmt.Errorf("CreateDigitalObjectComponent(%d) component must reference a digital object", repoID)
+	}
+	u := api.callURL(fmt.Sprintf("/repositories/%d/digital_object_components", repoID))
+	obj.JSONModelType = "digital_object_component"
+	obj.LockVersion = "0"
+	responseMsg, err := api.CreateAPI(u.String(), obj)
+	if err != nil {
+		return nil, fmt.Errorf("CreateDigitalObjectComponent(%d) %w", repoID, err)
+	}
+	obj.SetURI(responseMsg.URI)
+	obj.LockVersion = responseMsg.LockVersion
+	return responseMsg, nil
+}
+
+// GetDigitalObjectComponent retrieves a DigitalObjectComponent from a Repository
+func (api *ArchivesSpaceAPI) GetDigitalObjectComponent(repoID, componentID int) (*DigitalObjectComponent, error) {
+	u := api.callURL(fmt.Sprintf("/repositories/%d/digital_object_components/%d", repoID, componentID))
+	obj := new(DigitalObjectComponent)
+	if err := api.GetAPI(u.String(), obj); err != nil {
+		return nil, fmt.Errorf("GetDigitalObjectComponent(%d, %d) %w", repoID, componentID, err)
+	}
+	obj.ID = URIToID(obj.URI)
+	return obj, nil
+}
+
+// UpdateDigitalObjectComponent updates an existing DigitalObjectComponent
+func (api *ArchivesSpaceAPI) UpdateDigitalObjectComponent(obj *DigitalObjectComponent) (*ResponseMsg, error) {
+	u := api.callURL(obj.URI)
+	return api.UpdateAPI(u.String(), obj)
+}
+
+// DeleteDigitalObjectComponent deletes a DigitalObjectComponent, along with its children
+func (api *ArchivesSpaceAPI) DeleteDigitalObjectComponent(obj *DigitalObjectComponent) (*ResponseMsg, error) {
+	u := api.callURL(obj.URI)
+	return api.DeleteAPI(u.String(), obj)
+}
+
+// ListDigitalObjectComponents returns the DigitalObjectComponent IDs of a Repository.
+// Give modifiedSince to list only the records changed since then.
+func (api *ArchivesSpaceAPI) ListDigitalObjectComponents(repoID int, modifiedSince ...time.Time) ([]int, error) {
+	u := api.callURL(fmt.Sprintf("/repositories/%d/digital_object_components", repoID))
+	q := u.Query()
+	q.Set("all_ids", "true")
+	setModifiedSince(q, modifiedSince)
+	u.RawQuery = q.Encode()
+	return api.ListAPI(u.String())
+}
+
+// SetDigitalObjectComponentParent moves a component under the component parentID at
+// position (counting from 0) among its siblings. A parentID of zero moves the component
+// to the top level of its digital object.
+func (api *ArchivesSpaceAPI) SetDigitalObjectComponentParent(repoID, componentID, parentID, position int) (*ResponseMsg, error) {
+	u := api.callURL(fmt.Sprintf("/repositories/%d/digital_object_components/%d/parent", repoID, componentID))
+	q := u.Query()
+	if parentID > 0 {
+		q.Set("parent", fmt.Sprintf("%d", parentID))
+	}
+	q.Set("position", fmt.Sprintf("%d", position))
+	u.RawQuery = q.Encode()
+	responseMsg, err := api.UpdateAPI(u.String(), nil)
+	if err != nil {
+		return nil, fmt.Errorf("SetDigitalObjectComponentParent(%d, %d, %d, %d) %w", repoID, componentID, parentID, position, err)
+	}
+	return responseMsg, nil
+}
@@ -0,0 +1,243 @@
+//
+// Package cait is a collection of structures and functions
+// for interacting with ArchivesSpace's REST API
+//
+// @author R. S. Doiel, <rsdoiel@caltech.edu>
+//
+// Copyright (c) 2017, Caltech
+// All rights not granted herein are expressly reserved by Caltech.
+//
+// Redistribution and use in source and binary forms, with or without modification, are permitted provided that the following conditions are met:
+//
+// 1. Redistributions of source code must retain the above copyright notice, this list of conditions and the following disclaimer.
+//
+// 2. Redistributions in binary form must reproduce the above copyright notice, this list of conditions and the following disclaimer in the documentation and/or other materials provided with the distribution.
+//
+// 3. Neither the name of the copyright holder nor the names of its contributors may be used to endorse or promote products derived from this software without specific prior written permission.
+//
+// THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS" AND ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE ARE DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT HOLDER OR CONTRIBUTORS BE LIABLE FOR ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL DAMAGES (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR SERVICES; LOSS OF USE, DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER CAUSED AND ON ANY THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT LIABILITY, OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE OF THIS SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+//
+package cait
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/url"
+	"os"
+	"path"
+	"strings"
+	"sync"
+	"time"
+)
+
+// EADExportOptions controls ExportAllEAD
+type EADExportOptions struct {
+	// Workers is the number of finding aids exported at once, defaults to 4
+	Workers int `json:"workers,omitempty"`
+	// RequestsPerSecond limits the rate requests are made to ArchivesSpace, zero means no limit
+	RequestsPerSecond int `json:"requests_per_second,omitempty"`
+	// Overwrite re-exports finding aids already in dir, otherwise they are skipped
+	Overwrite bool `json:"overwrite,omitempty"`
+	// IncludeUnpublished includes unpublished components in the EAD
+	IncludeUnpublished bool `json:"include_unpublished,omitempty"`
+	// IncludeDAOs includes digital object links in the EAD
+	IncludeDAOs bool `json:"include_daos,omitempty"`
+	// NumberedCs uses numbered <c01>..<c12> components instead of <c>
+	NumberedCs bool `json:"numbered_cs,omitempty"`
+}
+
+// EADExportResult is the outcome of exporting a single finding aid
+type EADExportResult struct {
+	ResourceID int    `json:"resource_id"`
+	EADID      string `json:"ead_id,omitempty"`
+	Path       string `json:"path,omitempty"`
+	Skipped    bool   `json:"skipped,omitempty"`
+	Error      string `json:"error,omitempty"`
+}
+
+// EADExportReport summarizes an ExportAllEAD run
+type EADExportReport struct {
+	Exported int                `json:"exported"`
+	Skipped  int                `json:"skipped"`
+	Errors   int                `json:"errors"`
+	Results  []*EADExportResult `json:"results"`
+}
+
+// EADFilename returns a safe file name for a finding aid based on its EAD ID,
+// falling back to the resource ID when no EAD ID is set
+func EADFilename(eadID string, resourceID int) string {
+	name := strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9', r == '-', r == '_', r == '.':
+			return r
+		}
+		return '_'
+	}, strings.TrimSpace(eadID))
+	name = strings.Trim(name, "._")
+	if name == "" {
+		return fmt.Sprintf("resource-%d.xml", resourceID)
+	}
+	if strings.HasSuffix(strings.ToLower(name), ".xml") == false {
+		name += ".xml"
+	}
+	return name
+}
+
+// exportEAD fetches a resource and its finding aid, writing it to dir
+func (api *ArchivesSpaceAPI) exportEAD(repoID, resourceID int, dir string, query url.Values, opts *EADExportOptions, wait func()) *EADExportResult {
+	result := &EADExportResult{ResourceID: resourceID}
+
+	wait()
+	src, err := api.API("GET", api.buildURL(fmt.Sprintf("/repositories/%d/resources/%d", repoID, resourceID), nil), nil)
+	if err != nil {
+		result.Error = err.Error()
+		return result
+	}
+	resource := new(Resource)
+	if err := json.Unmarshal(src, resource); err != nil {
+		result.Error = err.Error()
+		return result
+	}
+	result.EADID = resource.EADID
+	fname := EADFilename(resource.EADID, resourceID)
+	result.Path = path.Join(dir, fname)
+	if opts.Overwrite == false {
+		if info, err := os.Stat(result.Path); err == nil && info.Size() > 0 {
+			// A resumed run still records the finding aids it skips so the
+			// manifest covers the whole export
+			if api.Manifest != nil {
+				checksum, err := fileChecksum(result.Path)
+				if err != nil {
+					result.Error = err.Error()
+					return result
+				}
+				api.Manifest.Add(&ManifestEntry{
+					URI:         resource.URI,
+					Path:        result.Path,
+					SHA256:      checksum,
+					SystemMTime: resource.SystemMTime,
+					ExportedAt:  info.ModTime().UTC().Format(time.RFC3339),
+				})
+			}
+			result.Skipped = true
+			return result
+		}
+	}
+
+	wait()
+	// Stream to a temporary file first, finding aids can be very large and an
+	// interrupted run must never leave a partial finding aid behind
+	tmp := result.Path + ".tmp"
+	fp, err := os.Create(tmp)
+	if err != nil {
+		result.Error = err.Error()
+		return result
+	}
+	checksum := sha256.New()
+	_, err = api.DownloadAPI(api.buildURL(fmt.Sprintf("/repositories/%d/resource_descriptions/%d.xml", repoID, resourceID), query), io.MultiWriter(fp, checksum))
+	fp.Close()
+	if err != nil {
+		os.Remove(tmp)
+		result.Error = err.Error()
+		return result
+	}
+	if err := os.Rename(tmp, result.Path); err != nil {
+		result.Error = err.Error()
+		return result
+	}
+	if api.Manifest != nil {
+		api.Manifest.Add(&ManifestEntry{
+			URI:         resource.URI,
+			Path:        result.Path,
+			SHA256:      hex.EncodeToString(checksum.Sum(nil)),
+			SystemMTime: resource.SystemMTime,
+			ExportedAt:  time.Now().UTC().Format(time.RFC3339),
+		})
+	}
+	return result
+}
+
+// fileChecksum returns the hex encoded SHA-256 of the file fname
+func fileChecksum(fname string) (string, error) {
+	fp, err := os.Open(fname)
+	if err != nil {
+		return "", err
+	}
+	defer fp.Close()
+	checksum := sha256.New()
+	if _, err := io.Copy(checksum, fp); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(checksum.Sum(nil)), nil
+}
+
+// ExportAllEAD exports the finding aid of every resource in a repository to dir
+// naming each file from its EAD ID. Finding aids already in dir are skipped unless
+// opts.Overwrite is set so an interrupted run can be resumed.
+func (api *ArchivesSpaceAPI) ExportAllEAD(repoID int, dir string, opts *EADExportOptions) (*EADExportReport, error) {
+	if opts == nil {
+		opts = new(EADExportOptions)
+	}
+	workers := opts.Workers
+	if workers < 1 {
+		workers = 4
+	}
+	if err := os.MkdirAll(dir, 0775); err != nil {
+		return nil, fmt.Errorf("ExportAllEAD(%d, %q) %w", repoID, dir, err)
+	}
+	ids, err := api.ListResources(repoID)
+	if err != nil {
+		return nil, fmt.Errorf("ExportAllEAD(%d, %q) %w", repoID, dir, err)
+	}
+
+	query := url.Values{}
+	query.Set("include_unpublished", fmt.Sprintf("%t", opts.IncludeUnpublished))
+	query.Set("include_daos", fmt.Sprintf("%t", opts.IncludeDAOs))
+	query.Set("numbered_cs", fmt.Sprintf("%t", opts.NumberedCs))
+
+	wait := func() {}
+	if opts.RequestsPerSecond > 0 {
+		ticker := time.NewTicker(time.Second / time.Duration(opts.RequestsPerSecond))
+		defer ticker.Stop()
+		wait = func() { <-ticker.C }
+	}
+
+	results := make([]*EADExportResult, len(ids))
+	jobs := make(chan int)
+	var wg sync.WaitGroup
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := range jobs {
+				results[j] = api.exportEAD(repoID, ids[j], dir, query, opts, wait)
+			}
+		}()
+	}
+	for j := range ids {
+		jobs <- j
+	}
+	close(jobs)
+	wg.Wait()
+
+	report := &EADExportReport{Results: results}
+	for _, result := range results {
+		switch {
+		case result.Error != "":
+			report.Errors++
+		case result.Skipped == true:
+			report.Skipped++
+		default:
+			report.Exported++
+		}
+	}
+	return report, nil
+}
+
+// String return an EADExportReport
+func (report *EADExportReport) String() string {
+	return stringify(report)
+}
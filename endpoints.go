@@ -0,0 +1,189 @@
+//
+// Package cait is a collection of structures and functions
+// for interacting with ArchivesSpace's REST API
+//
+// @author R. S. Doiel, <rsdoiel@caltech.edu>
+//
+// Copyright (c) 2017, Caltech
+// All rights not granted herein are expressly reserved by Caltech.
+//
+// Redistribution and use in source and binary forms, with or without modification, are permitted provided that the following conditions are met:
+//
+// 1. Redistributions of source code must retain the above copyright notice, this list of conditions and the following disclaimer.
+//
+// 2. Redistributions in binary form must reproduce the above copyright notice, this list of conditions and the following disclaimer in the documentation and/or other materials provided with the distribution.
+//
+// 3. Neither the name of the copyright holder nor the names of its contributors may be used to endorse or promote products derived from this software without specific prior written permission.
+//
+// THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS" AND ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE ARE DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT HOLDER OR CONTRIBUTORS BE LIABLE FOR ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL DAMAGES (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR SERVICES; LOSS OF USE, DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER CAUSED AND ON ANY THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT LIABILITY, OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE OF THIS SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+//
+package cait
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// Endpoint describes a custom ArchivesSpace endpoint, e.g. one added by a site plugin
+type Endpoint struct {
+	// Name identifies the endpoint, e.g. "payments.get"
+	Name string `json:"name"`
+	// Method is the HTTP method, defaults to GET
+	Method string `json:"method,omitempty"`
+	// Path is a template with {param} placeholders, e.g. /repositories/{repo_id}/payments/{id}
+	Path string `json:"path"`
+	// NewRequest, if set, returns a pointer to the value a request payload must decode into
+	NewRequest func() interface{} `json:"-"`
+	// NewResponse, if set, returns a pointer to the value the response is decoded into,
+	// otherwise responses are decoded into an Object
+	NewResponse func() interface{} `json:"-"`
+}
+
+var (
+	endpointsMu sync.RWMutex
+	endpoints   = map[string]*Endpoint{}
+
+	pathParam = regexp.MustCompile(`\{([a-zA-Z0-9_]+)\}`)
+)
+
+// RegisterEndpoint adds a custom endpoint so it can be called with CallEndpoint.
+// It is an error to register the same name twice.
+func RegisterEndpoint(endpoint *Endpoint) error {
+	if endpoint == nil || endpoint.Name == "" {
+		return fmt.Errorf("RegisterEndpoint() endpoint must have a name")
+	}
+	if strings.HasPrefix(endpoint.Path, "/") == false {
+		return fmt.Errorf("RegisterEndpoint(%q) path %q must start with /", endpoint.Name, endpoint.Path)
+	}
+	endpointsMu.Lock()
+	defer endpointsMu.Unlock()
+	if _, ok := endpoints[endpoint.Name]; ok == true {
+		return fmt.Errorf("RegisterEndpoint(%q) already registered", endpoint.Name)
+	}
+	endpoints[endpoint.Name] = endpoint
+	return nil
+}
+
+// UnregisterEndpoint removes a custom endpoint
+func UnregisterEndpoint(name string) {
+	endpointsMu.Lock()
+	defer endpointsMu.Unlock()
+	delete(endpoints, name)
+}
+
+// Endpoints returns the sorted names of the registered custom endpoints
+func Endpoints() []string {
+	endpointsMu.RLock()
+	defer endpointsMu.RUnlock()
+	names := []string{}
+	for name := range endpoints {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// lookupEndpoint returns the registered endpoint called name
+func lookupEndpoint(name string) (*Endpoint, error) {
+	endpointsMu.RLock()
+	defer endpointsMu.RUnlock()
+	endpoint, ok := endpoints[name]
+	if ok == false {
+		return nil, fmt.Errorf("endpoint %q not registered", name)
+	}
+	return endpoint, nil
+}
+
+// expandPath fills the {param} placeholders of the endpoint's path. Params
+// not used in the path are returned as query parameters.
+func (endpoint *Endpoint) expandPath(params map[string]string) (string, url.Values, error) {
+	used := map[string]bool{}
+	missing := []string{}
+	p := pathParam.ReplaceAllStringFunc(endpoint.Path, func(s string) string {
+		key := s[1 : len(s)-1]
+		value, ok := params[key]
+		if ok == false {
+			missing = append(missing, key)
+			return s
+		}
+		used[key] = true
+		return url.PathEscape(value)
+	})
+	if len(missing) > 0 {
+		return "", nil, fmt.Errorf("missing path parameters %s", strings.Join(missing, ", "))
+	}
+	query := url.Values{}
+	for key, value := range params {
+		if used[key] == false {
+			query.Set(key, value)
+		}
+	}
+	return p, query, nil
+}
+
+// CallEndpoint calls a registered custom endpoint decoding the response into the
+// value returned by the endpoint's NewResponse (or an Object if not set)
+func (api *ArchivesSpaceAPI) CallEndpoint(name string, params map[string]string, payload interface{}) (interface{}, error) {
+	endpoint, err := lookupEndpoint(name)
+	if err != nil {
+		return nil, fmt.Errorf("CallEndpoint(%q) %w", name, err)
+	}
+	var response interface{}
+	if endpoint.NewResponse != nil {
+		response = endpoint.NewResponse()
+	} else {
+		response = &Object{}
+	}
+	if err := api.callEndpoint(endpoint, params, payload, response); err != nil {
+		return nil, err
+	}
+	return response, nil
+}
+
+// CallEndpointInto is like CallEndpoint but decodes the response into the value
+// response points to, making it easy to write typed wrapper functions
+func (api *ArchivesSpaceAPI) CallEndpointInto(name string, params map[string]string, payload, response interface{}) error {
+	endpoint, err := lookupEndpoint(name)
+	if err != nil {
+		return fmt.Errorf("CallEndpointInto(%q) %w", name, err)
+	}
+	return api.callEndpoint(endpoint, params, payload, response)
+}
+
+// callEndpoint makes the request described by endpoint
+func (api *ArchivesSpaceAPI) callEndpoint(endpoint *Endpoint, params map[string]string, payload, response interface{}) error {
+	method := strings.ToUpper(endpoint.Method)
+	if method == "" {
+		method = "GET"
+	}
+	p, query, err := endpoint.expandPath(params)
+	if err != nil {
+		return fmt.Errorf("CallEndpoint(%q) %w", endpoint.Name, err)
+	}
+	if payload != nil && endpoint.NewRequest != nil {
+		// Check the payload has the shape the endpoint expects before sending it
+		src, err := json.Marshal(payload)
+		if err != nil {
+			return fmt.Errorf("CallEndpoint(%q) %w", endpoint.Name, err)
+		}
+		if err := json.Unmarshal(src, endpoint.NewRequest()); err != nil {
+			return fmt.Errorf("CallEndpoint(%q) invalid request payload, %w", endpoint.Name, err)
+		}
+	}
+	content, err := api.API(method, api.buildURL(p, query), payload)
+	if err != nil {
+		return fmt.Errorf("CallEndpoint(%q) %w", endpoint.Name, err)
+	}
+	if response == nil || len(content) == 0 {
+		return nil
+	}
+	if err := json.Unmarshal(content, response); err != nil {
+		return fmt.Errorf("CallEndpoint(%q) can't decode response %s, %w", endpoint.Name, content, err)
+	}
+	return nil
+}
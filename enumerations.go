@@ -0,0 +1,127 @@
+//
+// Package cait is a collection of structures and functions
+// for interacting with ArchivesSpace's REST API
+//
+// @author R. S. Doiel, <rsdoiel@caltech.edu>
+//
+// Copyright (c) 2017, Caltech
+// All rights not granted herein are expressly reserved by Caltech.
+//
+// Redistribution and use in source and binary forms, with or without modification, are permitted provided that the following conditions are met:
+//
+// 1. Redistributions of source code must retain the above copyright notice, this list of conditions and the following disclaimer.
+//
+// 2. Redistributions in binary form must reproduce the above copyright notice, this list of conditions and the following disclaimer in the documentation and/or other materials provided with the distribution.
+//
+// 3. Neither the name of the copyright holder nor the names of its contributors may be used to endorse or promote products derived from this software without specific prior written permission.
+//
+// THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS" AND ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE ARE DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT HOLDER OR CONTRIBUTORS BE LIABLE FOR ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL DAMAGES (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR SERVICES; LOSS OF USE, DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER CAUSED AND ON ANY THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT LIABILITY, OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE OF THIS SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+//
+package cait
+
+import (
+	"fmt"
+)
+
+// ListEnumerations returns the controlled value lists, e.g. extent_extent_type
+func (api *ArchivesSpaceAPI) ListEnumerations() ([]*Enumeration, error) {
+	u := api.callURL("/config/enumerations")
+	enumerations := []*Enumeration{}
+	if err := api.GetAPI(u.String(), &enumerations); err != nil {
+		return nil, fmt.Errorf("ListEnumerations() %w", err)
+	}
+	for _, enumeration := range enumerations {
+		setEnumerationIDs(enumeration)
+	}
+	return enumerations, nil
+}
+
+// GetEnumeration retrieves a controlled value list with its values
+func (api *ArchivesSpaceAPI) GetEnumeration(enumID int) (*Enumeration, error) {
+	u := api.callURL(fmt.Sprintf("/config/enumerations/%d", enumID))
+	enumeration := new(Enumeration)
+	if err := api.GetAPI(u.String(), enumeration); err != nil {
+		return nil, fmt.Errorf("GetEnumeration(%d) %w", enumID, err)
+	}
+	setEnumerationIDs(enumeration)
+	return enumeration, nil
+}
+
+// GetEnumerationByName retrieves a controlled value list by name, e.g. "subject_source"
+func (api *ArchivesSpaceAPI) GetEnumerationByName(name string) (*Enumeration, error) {
+	enumerations, err := api.ListEnumerations()
+	if err != nil {
+		return nil, fmt.Errorf("GetEnumerationByName(%q) %w", name, err)
+	}
+	for _, enumeration := range enumerations {
+		if enumeration.Name == name {
+			return enumeration, nil
+		}
+	}
+	return nil, fmt.Errorf("GetEnumerationByName(%q) not found", name)
+}
+
+// AddEnumerationValues adds values to a controlled value list, values already
+// in the list are skipped. It returns the updated list.
+func (api *ArchivesSpaceAPI) AddEnumerationValues(enumID int, values ...string) (*Enumeration, error) {
+	enumeration, err := api.GetEnumeration(enumID)
+	if err != nil {
+		return nil, fmt.Errorf("AddEnumerationValues(%d) %w", enumID, err)
+	}
+	known := map[string]bool{}
+	for _, value := range enumeration.Values {
+		known[value] = true
+	}
+	added := false
+	for _, value := range values {
+		if known[value] == false {
+			enumeration.Values = append(enumeration.Values, value)
+			known[value] = true
+			added = true
+		}
+	}
+	if added == false {
+		return enumeration, nil
+	}
+	// ArchivesSpace builds the list from values, the value records would be read as a replacement
+	enumeration.EnumerationValues = nil
+	u := api.callURL(enumeration.URI)
+	if _, err := api.UpdateAPI(u.String(), enumeration); err != nil {
+		return nil, fmt.Errorf("AddEnumerationValues(%d) %w", enumID, err)
+	}
+	return api.GetEnumeration(enumID)
+}
+
+// SuppressEnumerationValue hides (or, with suppressed false, restores) a value so it is no longer offered
+func (api *ArchivesSpaceAPI) SuppressEnumerationValue(valueID int, suppressed bool) (*ResponseMsg, error) {
+	u := api.callURL(fmt.Sprintf("/config/enumeration_values/%d/suppressed", valueID))
+	q := u.Query()
+	q.Set("suppressed", fmt.Sprintf("%t", suppressed))
+	u.RawQuery = q.Encode()
+	responseMsg, err := api.UpdateAPI(u.String(), nil)
+	if err != nil {
+		return nil, fmt.Errorf("SuppressEnumerationValue(%d, %t) %w", valueID, suppressed, err)
+	}
+	return responseMsg, nil
+}
+
+// SetEnumerationValuePosition moves a value to position (starting at zero) in its list
+func (api *ArchivesSpaceAPI) SetEnumerationValuePosition(valueID, position int) (*ResponseMsg, error) {
+	u := api.callURL(fmt.Sprintf("/config/enumeration_values/%d/position", valueID))
+	q := u.Query()
+	q.Set("position", fmt.Sprintf("%d", position))
+	u.RawQuery = q.Encode()
+	responseMsg, err := api.UpdateAPI(u.String(), nil)
+	if err != nil {
+		return nil, fmt.Errorf("SetEnumerationValuePosition(%d, %d) %w", valueID, position, err)
+	}
+	return responseMsg, nil
+}
+
+// setEnumerationIDs sets the ID of an enumeration and its values from their URIs
+func setEnumerationIDs(enumeration *Enumeration) {
+	enumeration.ID = URIToID(enumeration.URI)
+	for _, value := range enumeration.EnumerationValues {
+		value.ID = URIToID(value.URI)
+	}
+}
@@ -0,0 +1,108 @@
+//
+// Package cait is a collection of structures and functions
+// for interacting with ArchivesSpace's REST API
+//
+// @author R. S. Doiel, <rsdoiel@caltech.edu>
+//
+// Copyright (c) 2017, Caltech
+// All rights not granted herein are expressly reserved by Caltech.
+//
+// Redistribution and use in source and binary forms, with or without modification, are permitted provided that the following conditions are met:
+//
+// 1. Redistributions of source code must retain the above copyright notice, this list of conditions and the following disclaimer.
+//
+// 2. Redistributions in binary form must reproduce the above copyright notice, this list of conditions and the following disclaimer in the documentation and/or other materials provided with the distribution.
+//
+// 3. Neither the name of the copyright holder nor the names of its contributors may be used to endorse or promote products derived from this software without specific prior written permission.
+//
+// THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS" AND ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE ARE DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT HOLDER OR CONTRIBUTORS BE LIABLE FOR ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL DAMAGES (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR SERVICES; LOSS OF USE, DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER CAUSED AND ON ANY THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT LIABILITY, OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE OF THIS SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+//
+package cait
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// lookupEnv returns the first environment variable set from names
+func lookupEnv(names ...string) (string, string) {
+	for _, name := range names {
+		if value := os.Getenv(name); value != "" {
+			return name, value
+		}
+	}
+	return "", ""
+}
+
+// parseTimeout reads a duration (e.g. 30s, 2m) or a whole number of seconds
+func parseTimeout(name, value string) (time.Duration, error) {
+	if i, err := strconv.Atoi(value); err == nil {
+		return time.Duration(i) * time.Second, nil
+	}
+	d, err := time.ParseDuration(value)
+	if err != nil || d < 0 {
+		return 0, fmt.Errorf("%s=%q is not a duration (e.g. 30s) or number of seconds", name, value)
+	}
+	return d, nil
+}
+
+// NewFromEnv creates a client from the environment. It reads ASPACE_API_URL,
+// ASPACE_USERNAME, ASPACE_PASSWORD, ASPACE_API_TOKEN, ASPACE_CONNECT_TIMEOUT,
+// ASPACE_REQUEST_TIMEOUT and ASPACE_MAX_RESPONSE_SIZE, falling back to the
+// CAIT_* variable of the same name. Either a token or a username and password
+// are required. If ASPACE_KEYRING_SERVICE is set the password is read from the
+// OS keyring at login instead. Options are applied after the environment.
+func NewFromEnv(options ...Option) (*ArchivesSpaceAPI, error) {
+	opts := []Option{}
+	_, apiURL := lookupEnv("ASPACE_API_URL", "CAIT_API_URL")
+	if apiURL == "" {
+		return nil, fmt.Errorf("NewFromEnv() ASPACE_API_URL is not set")
+	}
+	_, username := lookupEnv("ASPACE_USERNAME", "CAIT_USERNAME")
+	_, password := lookupEnv("ASPACE_PASSWORD", "CAIT_PASSWORD")
+	_, token := lookupEnv("ASPACE_API_TOKEN", "CAIT_API_TOKEN")
+	_, keyring := lookupEnv("ASPACE_KEYRING_SERVICE", "CAIT_KEYRING_SERVICE")
+	if token == "" && (username == "" || (password == "" && keyring == "")) {
+		return nil, fmt.Errorf("NewFromEnv() set ASPACE_API_TOKEN or both ASPACE_USERNAME and ASPACE_PASSWORD (or ASPACE_KEYRING_SERVICE)")
+	}
+	if keyring != "" {
+		opts = append(opts, WithCredentialProvider(username, &KeyringProvider{Service: keyring}))
+	} else if username != "" || password != "" {
+		opts = append(opts, WithCredentials(username, password))
+	}
+	if token != "" {
+		opts = append(opts, WithToken(token))
+	}
+	if _, dataset := lookupEnv("ASPACE_DATASET", "CAIT_DATASET"); dataset != "" {
+		opts = append(opts, WithDataset(dataset))
+	}
+	if name, value := lookupEnv("ASPACE_CONNECT_TIMEOUT", "CAIT_CONNECT_TIMEOUT"); value != "" {
+		d, err := parseTimeout(name, value)
+		if err != nil {
+			return nil, fmt.Errorf("NewFromEnv() %w", err)
+		}
+		opts = append(opts, WithConnectTimeout(d))
+	}
+	if name, value := lookupEnv("ASPACE_REQUEST_TIMEOUT", "CAIT_REQUEST_TIMEOUT"); value != "" {
+		d, err := parseTimeout(name, value)
+		if err != nil {
+			return nil, fmt.Errorf("NewFromEnv() %w", err)
+		}
+		opts = append(opts, WithRequestTimeout(d))
+	}
+	if name, value := lookupEnv("ASPACE_MAX_RESPONSE_SIZE", "CAIT_MAX_RESPONSE_SIZE"); value != "" {
+		size, err := strconv.ParseInt(strings.TrimSpace(value), 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("NewFromEnv() %s=%q is not a number of bytes", name, value)
+		}
+		opts = append(opts, WithMaxResponseSize(size))
+	}
+	api, err := NewClient(apiURL, append(opts, options...)...)
+	if err != nil {
+		return nil, fmt.Errorf("NewFromEnv() %w", err)
+	}
+	return api, nil
+}
@@ -0,0 +1,144 @@
+//
+// Package cait is a collection of structures and functions
+// for interacting with ArchivesSpace's REST API
+//
+// @author R. S. Doiel, <rsdoiel@caltech.edu>
+//
+// Copyright (c) 2017, Caltech
+// All rights not granted herein are expressly reserved by Caltech.
+//
+// Redistribution and use in source and binary forms, with or without modification, are permitted provided that the following conditions are met:
+//
+// 1. Redistributions of source code must retain the above copyright notice, this list of conditions and the following disclaimer.
+//
+// 2. Redistributions in binary form must reproduce the above copyright notice, this list of conditions and the following disclaimer in the documentation and/or other materials provided with the distribution.
+//
+// 3. Neither the name of the copyright holder nor the names of its contributors may be used to endorse or promote products derived from this software without specific prior written permission.
+//
+// THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS" AND ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE ARE DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT HOLDER OR CONTRIBUTORS BE LIABLE FOR ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL DAMAGES (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR SERVICES; LOSS OF USE, DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER CAUSED AND ON ANY THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT LIABILITY, OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE OF THIS SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+//
+package cait
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+)
+
+// APIError is returned when ArchivesSpace responds with an error status. Message holds
+// the decoded "error" value of the response when there is one and ValidationErrors
+// the per-field messages ArchivesSpace returns when a record fails validation.
+type APIError struct {
+	Method           string              `json:"method"`
+	URL              string              `json:"url"`
+	StatusCode       int                 `json:"status_code"`
+	Status           string              `json:"status"`
+	Message          interface{}         `json:"error,omitempty"`
+	ValidationErrors map[string][]string `json:"validation_errors,omitempty"`
+	Body             []byte              `json:"-"`
+}
+
+// isSuccess returns true for 2xx status codes
+func isSuccess(statusCode int) bool {
+	return statusCode >= 200 && statusCode < 300
+}
+
+// newAPIError builds an APIError from a response status and body
+func newAPIError(method, url string, statusCode int, status string, body []byte) *APIError {
+	e := &APIError{
+		Method:     method,
+		URL:        RedactURL(url),
+		StatusCode: statusCode,
+		Status:     status,
+		Body:       body,
+	}
+	msg := struct {
+		Error interface{} `json:"error"`
+	}{}
+	if err := json.Unmarshal(body, &msg); err != nil {
+		return e
+	}
+	e.Message = msg.Error
+	// Validation failures have the form {"error": {"field": ["message", ...]}}
+	if fields, ok := msg.Error.(map[string]interface{}); ok == true {
+		e.ValidationErrors = map[string][]string{}
+		for field, value := range fields {
+			switch v := value.(type) {
+			case []interface{}:
+				for _, item := range v {
+					e.ValidationErrors[field] = append(e.ValidationErrors[field], fmt.Sprintf("%v", item))
+				}
+			default:
+				e.ValidationErrors[field] = append(e.ValidationErrors[field], fmt.Sprintf("%v", v))
+			}
+		}
+	}
+	return e
+}
+
+// Error returns the status along with the ArchivesSpace error message
+func (e *APIError) Error() string {
+	s := fmt.Sprintf("ArchiveSpace API error %s", e.Status)
+	if len(e.ValidationErrors) > 0 {
+		fields := []string{}
+		for field := range e.ValidationErrors {
+			fields = append(fields, field)
+		}
+		sort.Strings(fields)
+		parts := []string{}
+		for _, field := range fields {
+			parts = append(parts, fmt.Sprintf("%s: %s", field, strings.Join(e.ValidationErrors[field], ", ")))
+		}
+		return fmt.Sprintf("%s, %s", s, strings.Join(parts, "; "))
+	}
+	if e.Message != nil {
+		return fmt.Sprintf("%s, %v", s, e.Message)
+	}
+	if body := strings.TrimSpace(string(e.Body)); body != "" {
+		if len(body) > 200 {
+			body = body[:200] + "..."
+		}
+		return fmt.Sprintf("%s, %s", s, body)
+	}
+	return s
+}
+
+// AsAPIError returns the APIError held in err if there is one
+func AsAPIError(err error) (*APIError, bool) {
+	var e *APIError
+	if errors.As(err, &e) == true {
+		return e, true
+	}
+	return nil, false
+}
+
+// hasStatus returns true if err is an APIError with the status code given
+func hasStatus(err error, statusCode int) bool {
+	e, ok := AsAPIError(err)
+	return ok == true && e.StatusCode == statusCode
+}
+
+// IsNotFound returns true if err is an APIError for a 404 Not Found response
+func IsNotFound(err error) bool {
+	return hasStatus(err, http.StatusNotFound)
+}
+
+// IsForbidden returns true if err is an APIError for a 403 Forbidden response
+func IsForbidden(err error) bool {
+	return hasStatus(err, http.StatusForbidden)
+}
+
+// IsConflict returns true if err is an APIError for a 409 Conflict response,
+// e.g. saving a record with a stale lock_version
+func IsConflict(err error) bool {
+	return hasStatus(err, http.StatusConflict)
+}
+
+// IsValidationError returns true if err is an APIError carrying per-field validation messages
+func IsValidationError(err error) bool {
+	e, ok := AsAPIError(err)
+	return ok == true && len(e.ValidationErrors) > 0
+}
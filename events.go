@@ -0,0 +1,95 @@
+//
+// Package cait is a collection of structures and functions
+// for interacting with ArchivesSpace's REST API
+//
+// @author R. S. Doiel, <rsdoiel@caltech.edu>
+//
+// Copyright (c) 2017, Caltech
+// All rights not granted herein are expressly reserved by Caltech.
+//
+// Redistribution and use in source and binary forms, with or without modification, are permitted provided that the following conditions are met:
+//
+// 1. Redistributions of source code must retain the above copyright notice, this list of conditions and the following disclaimer.
+//
+// 2. Redistributions in binary form must reproduce the above copyright notice, this list of conditions and the following disclaimer in the documentation and/or other materials provided with the distribution.
+//
+// 3. Neither the name of the copyright holder nor the names of its contributors may be used to endorse or promote products derived from this software without specific prior written permission.
+//
+// THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS" AND ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE ARE DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT HOLDER OR CONTRIBUTORS BE LIABLE FOR ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL DAMAGES (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR SERVICES; LOSS OF USE, DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER CAUSED AND ON ANY THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT LIABILITY, OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE OF THIS SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+//
+package cait
+
+import (
+	"fmt"
+	"time"
+)
+
+// NewEvent returns an event of eventType (e.g. "digitization", "processed") with
+// outcome (e.g. "pass", may be empty) timestamped now. Use LinkAgent and LinkRecord
+// to say who was involved and what the event was about before creating it.
+func NewEvent(eventType, outcome string) *Event {
+	return &Event{
+		EventType: eventType,
+		Outcome:   outcome,
+		Timestamp: time.Now().UTC().Format(time.RFC3339),
+	}
+}
+
+// LinkAgent adds the agent at agentURI to the event with role (e.g. "implementer", "executing_program")
+func (event *Event) LinkAgent(agentURI, role string) {
+	event.LinkedAgents = append(event.LinkedAgents, map[string]interface{}{"ref": agentURI, "role": role})
+}
+
+// LinkRecord adds the record at recordURI to the event with role (e.g. "source", "outcome")
+func (event *Event) LinkRecord(recordURI, role string) {
+	event.LinkedRecords = append(event.LinkedRecords, map[string]interface{}{"ref": recordURI, "role": role})
+}
+
+// CreateEvent creates an event in a Repository. On success the event's URI, ID
+// and lock version are updated from the response.
+func (api *ArchivesSpaceAPI) CreateEvent(repoID int, event *Event) (*ResponseMsg, error) {
+	u := api.callURL(fmt.Sprintf("/repositories/%d/events", repoID))
+	event.JSONModelType = "event"
+	event.LockVersion = "0"
+	responseMsg, err := api.CreateAPI(u.String(), event)
+	if err != nil {
+		return nil, fmt.Errorf("CreateEvent(%d) %w", repoID, err)
+	}
+	event.SetURI(responseMsg.URI)
+	event.LockVersion = responseMsg.LockVersion
+	return responseMsg, nil
+}
+
+// GetEvent retrieves an event from a Repository
+func (api *ArchivesSpaceAPI) GetEvent(repoID, eventID int) (*Event, error) {
+	u := api.callURL(fmt.Sprintf("/repositories/%d/events/%d", repoID, eventID))
+	event := new(Event)
+	if err := api.GetAPI(u.String(), event); err != nil {
+		return nil, fmt.Errorf("GetEvent(%d, %d) %w", repoID, eventID, err)
+	}
+	event.ID = URIToID(event.URI)
+	return event, nil
+}
+
+// UpdateEvent updates an existing event
+func (api *ArchivesSpaceAPI) UpdateEvent(event *Event) (*ResponseMsg, error) {
+	u := api.callURL(event.URI)
+	return api.UpdateAPI(u.String(), event)
+}
+
+// DeleteEvent deletes an event
+func (api *ArchivesSpaceAPI) DeleteEvent(event *Event) (*ResponseMsg, error) {
+	u := api.callURL(event.URI)
+	return api.DeleteAPI(u.String(), event)
+}
+
+// ListEvents return a list of event IDs from a Repository
+// Give modifiedSince to list only the records changed since then.
+func (api *ArchivesSpaceAPI) ListEvents(repoID int, modifiedSince ...time.Time) ([]int, error) {
+	u := api.callURL(fmt.Sprintf("/repositories/%d/events", repoID))
+	q := u.Query()
+	q.Set("all_ids", "true")
+	setModifiedSince(q, modifiedSince)
+	u.RawQuery = q.Encode()
+	return api.ListAPI(u.String())
+}
@@ -30,17 +30,17 @@ func (api *ArchivesSpaceAPI) ExportRepository(id int, fname string) error {
 	dir := "repository.ds"
 	c, err := CreateCollection(api, dir)
 	if err != nil {
-		return fmt.Errorf("Can't open collection %s/%s, %s", api.Dataset, dir, err)
+		return fmt.Errorf("Can't open collection %s/%s, %w", api.Dataset, dir, err)
 	}
 	defer c.Close()
 
 	data, err := api.GetRepository(id)
 	if err != nil {
-		return fmt.Errorf("Can't get repository %d data, %s", id, err)
+		return fmt.Errorf("Can't get repository %d data, %w", id, err)
 	}
-	err = WriteJSON(c, fname, data)
+	err = api.exportJSON(c, dir, fname, data)
 	if err != nil {
-		return fmt.Errorf("Can't write repository %d data, %s", id, err)
+		return fmt.Errorf("Can't write repository %d data, %w", id, err)
 	}
 	return nil
 }
@@ -49,13 +49,13 @@ func (api *ArchivesSpaceAPI) ExportRepository(id int, fname string) error {
 func (api *ArchivesSpaceAPI) ExportRepositories(verbose bool) error {
 	ids, err := api.ListRepositoryIDs()
 	if err != nil {
-		return fmt.Errorf("Can't get list of repository ids, %s", err)
+		return fmt.Errorf("Can't get list of repository ids, %w", err)
 	}
 	for i, id := range ids {
 		fname := fmt.Sprintf("%d.json", id)
 		err = api.ExportRepository(id, fname)
 		if err != nil {
-			return fmt.Errorf("Can't export repository %d data, %s", id, err)
+			return fmt.Errorf("Can't export repository %d data, %w", id, err)
 		}
 		if verbose == true && i > 0 && (i%100) == 0 {
 			log.Printf("%d repository definitions exported\n", i)
@@ -69,7 +69,7 @@ func (api *ArchivesSpaceAPI) ExportAgents(agentType string, verbose bool) error
 	dir := path.Join("agents.ds", agentType)
 	c, err := CreateCollection(api, dir)
 	if err != nil {
-		return fmt.Errorf("Can't open collection %s, %s", api.Dataset, err)
+		return fmt.Errorf("Can't open collection %s, %w", api.Dataset, err)
 	}
 	defer c.Close()
 	ids, err := api.ListAgents(agentType)
@@ -79,12 +79,12 @@ func (api *ArchivesSpaceAPI) ExportAgents(agentType string, verbose bool) error
 	for i, id := range ids {
 		data, err := api.GetAgent(agentType, id)
 		if err != nil {
-			return fmt.Errorf("Can't get %s/%d, %s", dir, id, err)
+			return fmt.Errorf("Can't get %s/%d, %w", dir, id, err)
 		}
 		fname := fmt.Sprintf("%d.json", id)
-		err = WriteJSON(c, fname, &data)
+		err = api.exportJSON(c, dir, fname, &data)
 		if err != nil {
-			return fmt.Errorf("Can't write %s/%s/%d.json, %s", dir, agentType, id, err)
+			return fmt.Errorf("Can't write %s/%s/%d.json, %w", dir, agentType, id, err)
 		}
 		if verbose == true && i > 0 && (i%100) == 0 {
 			log.Printf("%d agents/%s exported\n", i, agentType)
@@ -98,13 +98,13 @@ func (api *ArchivesSpaceAPI) ExportAccessions(repoID int, verbose bool) error {
 	dir := fmt.Sprintf("repository-%d/accessions.ds", repoID)
 	c, err := CreateCollection(api, dir)
 	if err != nil {
-		return fmt.Errorf("Can't open collection %s, %s", api.Dataset, err)
+		return fmt.Errorf("Can't open collection %s, %w", api.Dataset, err)
 	}
 	defer c.Close()
 
 	ids, err := api.ListAccessions(repoID)
 	if err != nil {
-		return fmt.Errorf("Can't list accession ids from repository %d, %s", repoID, err)
+		return fmt.Errorf("Can't list accession ids from repository %d, %w", repoID, err)
 	}
 	if verbose == true {
 		log.Printf("Exporting %s\n", dir)
@@ -112,12 +112,12 @@ func (api *ArchivesSpaceAPI) ExportAccessions(repoID int, verbose bool) error {
 	for i, id := range ids {
 		data, err := api.GetAccession(repoID, id)
 		if err != nil {
-			return fmt.Errorf("Can't get %s/%d, %s", dir, id, err)
+			return fmt.Errorf("Can't get %s/%d, %w", dir, id, err)
 		}
 		fname := fmt.Sprintf("%d.json", id)
-		err = WriteJSON(c, fname, &data)
+		err = api.exportJSON(c, dir, fname, &data)
 		if err != nil {
-			return fmt.Errorf("Can't write %s/%d.json, %s", dir, id, err)
+			return fmt.Errorf("Can't write %s/%d.json, %w", dir, id, err)
 		}
 		if verbose == true && i > 0 && (i%100) == 0 {
 			log.Printf("%d accessions exported from repository no. %d\n", i, repoID)
@@ -131,23 +131,23 @@ func (api *ArchivesSpaceAPI) ExportSubjects(verbose bool) error {
 	dir := "subjects.ds"
 	c, err := CreateCollection(api, dir)
 	if err != nil {
-		return fmt.Errorf("Can't open collection %s/%s, %s", api.Dataset, dir, err)
+		return fmt.Errorf("Can't open collection %s/%s, %w", api.Dataset, dir, err)
 	}
 	defer c.Close()
 
 	ids, err := api.ListSubjects()
 	if err != nil {
-		return fmt.Errorf("Can't list subject ids, %s", err)
+		return fmt.Errorf("Can't list subject ids, %w", err)
 	}
 	for i, id := range ids {
 		data, err := api.GetSubject(id)
 		if err != nil {
-			return fmt.Errorf("Can't get %s/%d, %s", dir, id, err)
+			return fmt.Errorf("Can't get %s/%d, %w", dir, id, err)
 		}
 		fname := fmt.Sprintf("%d.json", id)
-		err = WriteJSON(c, fname, &data)
+		err = api.exportJSON(c, dir, fname, &data)
 		if err != nil {
-			return fmt.Errorf("Can't write %s/%d.json, %s", dir, id, err)
+			return fmt.Errorf("Can't write %s/%d.json, %w", dir, id, err)
 		}
 		if verbose == true && i > 0 && (i%100) == 0 {
 			log.Printf("%d subjects exported\n", i)
@@ -161,22 +161,22 @@ func (api *ArchivesSpaceAPI) ExportVocabularies(verbose bool) error {
 	dir := "vocabularies.ds"
 	c, err := CreateCollection(api, dir)
 	if err != nil {
-		return fmt.Errorf("Can't open collection %s, %s", api.Dataset, err)
+		return fmt.Errorf("Can't open collection %s, %w", api.Dataset, err)
 	}
 	defer c.Close()
 	ids, err := api.ListVocabularies()
 	if err != nil {
-		return fmt.Errorf("Can't list vocabulary ids, %s", err)
+		return fmt.Errorf("Can't list vocabulary ids, %w", err)
 	}
 	for i, id := range ids {
 		data, err := api.GetVocabulary(id)
 		if err != nil {
-			return fmt.Errorf("Can't get %s/%d, %s", dir, id, err)
+			return fmt.Errorf("Can't get %s/%d, %w", dir, id, err)
 		}
 		fname := fmt.Sprintf("%d.json", id)
-		err = WriteJSON(c, fname, &data)
+		err = api.exportJSON(c, dir, fname, &data)
 		if err != nil {
-			return fmt.Errorf("Can't write %s/%d.json, %s", dir, id, err)
+			return fmt.Errorf("Can't write %s/%d.json, %w", dir, id, err)
 		}
 		if verbose == true && i > 0 && (i%100) == 0 {
 			log.Printf("%d vocabulary terms exported\n", i)
@@ -189,19 +189,19 @@ func (api *ArchivesSpaceAPI) collectTerms(vocID int, verbose bool) error {
 	dir := path.Join(fmt.Sprintf("vocabulary-%d", vocID), "terms.ds")
 	c, err := CreateCollection(api, dir)
 	if err != nil {
-		return fmt.Errorf("Can't open collection %s, %s", api.Dataset, err)
+		return fmt.Errorf("Can't open collection %s, %w", api.Dataset, err)
 	}
 	defer c.Close()
 
 	terms, err := api.ListTerms(vocID)
 	if err != nil {
-		return fmt.Errorf("Can't list term ids for %s, %s", dir, err)
+		return fmt.Errorf("Can't list term ids for %s, %w", dir, err)
 	}
 	for i, term := range terms {
 		fname := fmt.Sprintf("%d.json", term.ID)
-		err = WriteJSON(c, fname, &term)
+		err = api.exportJSON(c, dir, fname, &term)
 		if err != nil {
-			return fmt.Errorf("Can't write %s/%d.json, %s", dir, term.ID, err)
+			return fmt.Errorf("Can't write %s/%d.json, %w", dir, term.ID, err)
 		}
 		if verbose == true && i > 0 && (i%100) == 0 {
 			log.Printf("%d Vocabulary terms exported\n", i)
@@ -214,7 +214,7 @@ func (api *ArchivesSpaceAPI) collectTerms(vocID int, verbose bool) error {
 func (api *ArchivesSpaceAPI) ExportTerms(verbose bool) error {
 	vocIDs, err := api.ListVocabularies()
 	if err != nil {
-		return fmt.Errorf("Can't list vocabulary ids, %s", err)
+		return fmt.Errorf("Can't list vocabulary ids, %w", err)
 	}
 
 	for i, vocID := range vocIDs {
@@ -234,23 +234,23 @@ func (api *ArchivesSpaceAPI) ExportLocations(verbose bool) error {
 	dir := "locations.ds"
 	c, err := CreateCollection(api, dir)
 	if err != nil {
-		return fmt.Errorf("Can't open collection %s, %s", api.Dataset, err)
+		return fmt.Errorf("Can't open collection %s, %w", api.Dataset, err)
 	}
 	defer c.Close()
 
 	ids, err := api.ListLocations()
 	if err != nil {
-		return fmt.Errorf("Can't list location ids, %s", err)
+		return fmt.Errorf("Can't list location ids, %w", err)
 	}
 	for i, id := range ids {
 		data, err := api.GetLocation(id)
 		if err != nil {
-			return fmt.Errorf("Can't get %s/%d, %s", dir, id, err)
+			return fmt.Errorf("Can't get %s/%d, %w", dir, id, err)
 		}
 		fname := fmt.Sprintf("%d.json", id)
-		err = WriteJSON(c, fname, &data)
+		err = api.exportJSON(c, dir, fname, &data)
 		if err != nil {
-			return fmt.Errorf("Can't write %s/%d.json, %s", dir, id, err)
+			return fmt.Errorf("Can't write %s/%d.json, %w", dir, id, err)
 		}
 		if verbose == true && i > 0 && (i%100) == 0 {
 			log.Printf("%d locations exported\n", i)
@@ -264,23 +264,23 @@ func (api *ArchivesSpaceAPI) ExportDigitalObjects(repoID int, verbose bool) erro
 	dir := path.Join(fmt.Sprintf("repository-%d", repoID), "digital_objects.ds")
 	c, err := CreateCollection(api, dir)
 	if err != nil {
-		return fmt.Errorf("Can't open collection %s, %s", api.Dataset, err)
+		return fmt.Errorf("Can't open collection %s, %w", api.Dataset, err)
 	}
 	defer c.Close()
 
 	ids, err := api.ListDigitalObjects(repoID)
 	if err != nil {
-		return fmt.Errorf("Can't list digital_object ids, %s", err)
+		return fmt.Errorf("Can't list digital_object ids, %w", err)
 	}
 	for i, id := range ids {
 		data, err := api.GetDigitalObject(repoID, id)
 		if err != nil {
-			return fmt.Errorf("Can't get %s/%d, %s", dir, id, err)
+			return fmt.Errorf("Can't get %s/%d, %w", dir, id, err)
 		}
 		fname := fmt.Sprintf("%d.json", id)
-		err = WriteJSON(c, fname, &data)
+		err = api.exportJSON(c, dir, fname, &data)
 		if err != nil {
-			return fmt.Errorf("Can't write %s/%d.json, %s", dir, id, err)
+			return fmt.Errorf("Can't write %s/%d.json, %w", dir, id, err)
 		}
 		if verbose == true && i > 0 && (i%100) == 0 {
 			log.Printf("%d digital objects exported\n", i)
@@ -294,23 +294,23 @@ func (api *ArchivesSpaceAPI) ExportResources(repoID int, verbose bool) error {
 	dir := path.Join(fmt.Sprintf("repository-%d", repoID), "resources.ds")
 	c, err := CreateCollection(api, dir)
 	if err != nil {
-		return fmt.Errorf("Can't open collection %s, %s", api.Dataset, err)
+		return fmt.Errorf("Can't open collection %s, %w", api.Dataset, err)
 	}
 	defer c.Close()
 
 	ids, err := api.ListResources(repoID)
 	if err != nil {
-		return fmt.Errorf("Can't list resource ids, %s", err)
+		return fmt.Errorf("Can't list resource ids, %w", err)
 	}
 	for i, id := range ids {
 		data, err := api.GetResource(repoID, id)
 		if err != nil {
-			return fmt.Errorf("Can't get %s/%d, %s", dir, id, err)
+			return fmt.Errorf("Can't get %s/%d, %w", dir, id, err)
 		}
 		fname := fmt.Sprintf("%d.json", id)
-		err = WriteJSON(c, fname, &data)
+		err = api.exportJSON(c, dir, fname, &data)
 		if err != nil {
-			return fmt.Errorf("Can't write %s/%d.json, %s", dir, id, err)
+			return fmt.Errorf("Can't write %s/%d.json, %w", dir, id, err)
 		}
 		if verbose == true && i > 0 && (i%100) == 0 {
 			log.Printf("%d resources exported\n", i)
@@ -320,68 +320,75 @@ func (api *ArchivesSpaceAPI) ExportResources(repoID int, verbose bool) error {
 }
 
 // ExportArchivesSpace exports all content currently support by the Golang API implementation
+// An export manifest is saved to manifest.ds when the export completes.
 func (api *ArchivesSpaceAPI) ExportArchivesSpace(verbose bool) error {
 	var err error
 
+	api.Manifest = NewExportManifest()
+
 	log.Println("Exporting repositories")
 	err = api.ExportRepositories(verbose)
 	if err != nil {
-		return fmt.Errorf("Can't export repositories, %s", err)
+		return fmt.Errorf("Can't export repositories, %w", err)
 	}
 
 	log.Printf("Exporting subjects\n")
 	err = api.ExportSubjects(verbose)
 	if err != nil {
-		return fmt.Errorf("Can't export subjects, %s", err)
+		return fmt.Errorf("Can't export subjects, %w", err)
 	}
 
 	log.Printf("Exporting vocabularies\n")
 	err = api.ExportVocabularies(verbose)
 	if err != nil {
-		return fmt.Errorf("Can't export vocabularies, %s", err)
+		return fmt.Errorf("Can't export vocabularies, %w", err)
 	}
 
 	log.Printf("Exporting terms")
 	err = api.ExportTerms(verbose)
 	if err != nil {
-		return fmt.Errorf("Can't export terms, %s", err)
+		return fmt.Errorf("Can't export terms, %w", err)
 	}
 
 	log.Printf("Exporting locations")
 	err = api.ExportLocations(verbose)
 	if err != nil {
-		return fmt.Errorf("Can't export locations, %s", err)
+		return fmt.Errorf("Can't export locations, %w", err)
 	}
 
 	for _, agentType := range []string{"people", "corporate_entities", "families", "software"} {
 		log.Printf("Exporting agents.ds/%s\n", agentType)
 		err = api.ExportAgents(agentType, verbose)
 		if err != nil {
-			return fmt.Errorf("Can't export agents, %s", err)
+			return fmt.Errorf("Can't export agents, %w", err)
 		}
 	}
 
 	ids, err := api.ListRepositoryIDs()
 	if err != nil {
-		return fmt.Errorf("Can't get a list of repository ids, %s", err)
+		return fmt.Errorf("Can't get a list of repository ids, %w", err)
 	}
 	for _, id := range ids {
 		log.Printf("Exporting repositories/%d/digital_objects.ds\n", id)
 		err = api.ExportDigitalObjects(id, verbose)
 		if err != nil {
-			return fmt.Errorf("Can't export repositories/%d/digital_objects.ds, %s", id, err)
+			return fmt.Errorf("Can't export repositories/%d/digital_objects.ds, %w", id, err)
 		}
 		log.Printf("Exporting repositories/%d/resources\n", id)
 		err = api.ExportResources(id, verbose)
 		if err != nil {
-			return fmt.Errorf("Can't export repositories/%d/accessions.ds, %s", id, err)
+			return fmt.Errorf("Can't export repositories/%d/accessions.ds, %w", id, err)
 		}
 		log.Printf("Exporting repositories/%d/accessions.ds\n", id)
 		err = api.ExportAccessions(id, verbose)
 		if err != nil {
-			return fmt.Errorf("Can't export repositories/%d/accessions, %s", id, err)
+			return fmt.Errorf("Can't export repositories/%d/accessions, %w", id, err)
 		}
 	}
+	err = api.SaveManifest()
+	if err != nil {
+		return fmt.Errorf("Can't save export manifest, %w", err)
+	}
 	log.Printf("Export complete")
 
 	//FIXME: Add other types as we start to use them
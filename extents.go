@@ -0,0 +1,172 @@
+//
+// Package cait is a collection of structures and functions
+// for interacting with ArchivesSpace's REST API
+//
+// @author R. S. Doiel, <rsdoiel@caltech.edu>
+//
+// Copyright (c) 2017, Caltech
+// All rights not granted herein are expressly reserved by Caltech.
+//
+// Redistribution and use in source and binary forms, with or without modification, are permitted provided that the following conditions are met:
+//
+// 1. Redistributions of source code must retain the above copyright notice, this list of conditions and the following disclaimer.
+//
+// 2. Redistributions in binary form must reproduce the above copyright notice, this list of conditions and the following disclaimer in the documentation and/or other materials provided with the distribution.
+//
+// 3. Neither the name of the copyright holder nor the names of its contributors may be used to endorse or promote products derived from this software without specific prior written permission.
+//
+// THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS" AND ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE ARE DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT HOLDER OR CONTRIBUTORS BE LIABLE FOR ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL DAMAGES (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR SERVICES; LOSS OF USE, DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER CAUSED AND ON ANY THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT LIABILITY, OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE OF THIS SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+//
+package cait
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// ExtentUnit describes a measurable extent_type. Factor converts one unit to
+// the base unit of its dimension (meters for length, cubic meters for volume).
+type ExtentUnit struct {
+	Name      string  `json:"name"`
+	Dimension string  `json:"dimension"`
+	Factor    float64 `json:"factor"`
+}
+
+// ExtentUnits holds the extent types that can be converted, keyed by normalized extent_type.
+// Installations using other measured extent types may add to it.
+var ExtentUnits = map[string]*ExtentUnit{
+	"linear_feet":        {Name: "linear_feet", Dimension: "length", Factor: 0.3048},
+	"linear_inches":      {Name: "linear_inches", Dimension: "length", Factor: 0.0254},
+	"linear_meters":      {Name: "linear_meters", Dimension: "length", Factor: 1.0},
+	"linear_centimeters": {Name: "linear_centimeters", Dimension: "length", Factor: 0.01},
+	"cubic_feet":         {Name: "cubic_feet", Dimension: "volume", Factor: 0.028316846592},
+	"cubic_inches":       {Name: "cubic_inches", Dimension: "volume", Factor: 0.000016387064},
+	"cubic_meters":       {Name: "cubic_meters", Dimension: "volume", Factor: 1.0},
+}
+
+// extentTypeAliases maps common hand keyed spellings to their extent_type
+var extentTypeAliases = map[string]string{
+	"linear_foot":       "linear_feet",
+	"linear_ft":         "linear_feet",
+	"lin_ft":            "linear_feet",
+	"lf":                "linear_feet",
+	"linear_meter":      "linear_meters",
+	"linear_metres":     "linear_meters",
+	"linear_m":          "linear_meters",
+	"lm":                "linear_meters",
+	"linear_centimeter": "linear_centimeters",
+	"linear_cm":         "linear_centimeters",
+	"linear_inch":       "linear_inches",
+	"cubic_foot":        "cubic_feet",
+	"cubic_ft":          "cubic_feet",
+	"cu_ft":             "cubic_feet",
+	"cf":                "cubic_feet",
+	"cubic_meter":       "cubic_meters",
+	"cubic_metres":      "cubic_meters",
+	"cubic_m":           "cubic_meters",
+	"cubic_inch":        "cubic_inches",
+}
+
+// NormalizeExtentType lower cases an extent_type, joins words with underscores
+// and resolves common abbreviations (e.g. "Linear Ft." becomes "linear_feet")
+func NormalizeExtentType(s string) string {
+	s = strings.ToLower(strings.TrimSpace(s))
+	s = strings.Replace(s, ".", "", -1)
+	s = strings.Join(strings.FieldsFunc(s, func(r rune) bool {
+		return r == ' ' || r == '_' || r == '-'
+	}), "_")
+	if alias, ok := extentTypeAliases[s]; ok == true {
+		return alias
+	}
+	return s
+}
+
+// ConvertExtent converts value from one extent type to another. Conversion
+// between length and volume is refused since it depends on how the material is housed.
+func ConvertExtent(value float64, from, to string) (float64, error) {
+	src, ok := ExtentUnits[NormalizeExtentType(from)]
+	if ok == false {
+		return 0, fmt.Errorf("%q is not a convertible extent type", from)
+	}
+	dest, ok := ExtentUnits[NormalizeExtentType(to)]
+	if ok == false {
+		return 0, fmt.Errorf("%q is not a convertible extent type", to)
+	}
+	if src.Dimension != dest.Dimension {
+		return 0, fmt.Errorf("can't convert %s (%s) to %s (%s)", src.Name, src.Dimension, dest.Name, dest.Dimension)
+	}
+	return value * src.Factor / dest.Factor, nil
+}
+
+// Value parses the extent's Number (e.g. "1,200.5") as a float64
+func (extent *Extent) Value() (float64, error) {
+	s := strings.Replace(strings.TrimSpace(extent.Number), ",", "", -1)
+	val, err := strconv.ParseFloat(s, 64)
+	if err != nil {
+		return 0, fmt.Errorf("extent number %q, %w", extent.Number, err)
+	}
+	return val, nil
+}
+
+// Convert returns the extent's Number expressed in the extent type to
+func (extent *Extent) Convert(to string) (float64, error) {
+	val, err := extent.Value()
+	if err != nil {
+		return 0, err
+	}
+	return ConvertExtent(val, extent.ExtentType, to)
+}
+
+// SumExtents totals the extents that can be expressed in the extent type to.
+// Extents in other dimensions (e.g. items, gigabytes) or with unparsable numbers
+// are returned in skipped rather than being mixed into the total.
+func SumExtents(extents []*Extent, to string) (float64, []*Extent, error) {
+	if _, ok := ExtentUnits[NormalizeExtentType(to)]; ok == false {
+		return 0, nil, fmt.Errorf("%q is not a convertible extent type", to)
+	}
+	total := 0.0
+	skipped := []*Extent{}
+	for _, extent := range extents {
+		if extent == nil {
+			continue
+		}
+		val, err := extent.Convert(to)
+		if err != nil {
+			skipped = append(skipped, extent)
+			continue
+		}
+		total += val
+	}
+	return total, skipped, nil
+}
+
+// ExtentCalculation is the extent ArchivesSpace computes for a record from the container
+// profiles of the top containers linked to it and its components
+type ExtentCalculation struct {
+	Object         map[string]interface{} `json:"object,omitempty"`
+	Units          string                 `json:"units"`
+	TotalExtent    json.Number            `json:"total_extent"`
+	ContainerCount int                    `json:"container_count"`
+	// Containers breaks the total down by container profile
+	Containers interface{} `json:"containers,omitempty"`
+}
+
+// CalculateExtent asks ArchivesSpace to total the extent of the resource or archival
+// object at recordURI in units ("feet", "inches", "meters" or "centimeters"), an empty
+// units uses the server's default
+func (api *ArchivesSpaceAPI) CalculateExtent(recordURI, units string) (*ExtentCalculation, error) {
+	u := api.callURL("/extent_calculator")
+	q := u.Query()
+	q.Set("record_uri", recordURI)
+	if units != "" {
+		q.Set("unit", units)
+	}
+	u.RawQuery = q.Encode()
+	calc := new(ExtentCalculation)
+	if err := api.GetAPI(u.String(), calc); err != nil {
+		return nil, fmt.Errorf("CalculateExtent(%q, %q) %w", recordURI, units, err)
+	}
+	return calc, nil
+}
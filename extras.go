@@ -0,0 +1,108 @@
+//
+// Package cait is a collection of structures and functions
+// for interacting with ArchivesSpace's REST API
+//
+// @author R. S. Doiel, <rsdoiel@caltech.edu>
+//
+// Copyright (c) 2017, Caltech
+// All rights not granted herein are expressly reserved by Caltech.
+//
+// Redistribution and use in source and binary forms, with or without modification, are permitted provided that the following conditions are met:
+//
+// 1. Redistributions of source code must retain the above copyright notice, this list of conditions and the following disclaimer.
+//
+// 2. Redistributions in binary form must reproduce the above copyright notice, this list of conditions and the following disclaimer in the documentation and/or other materials provided with the distribution.
+//
+// 3. Neither the name of the copyright holder nor the names of its contributors may be used to endorse or promote products derived from this software without specific prior written permission.
+//
+// THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS" AND ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE ARE DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT HOLDER OR CONTRIBUTORS BE LIABLE FOR ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL DAMAGES (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR SERVICES; LOSS OF USE, DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER CAUSED AND ON ANY THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT LIABILITY, OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE OF THIS SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+//
+package cait
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+)
+
+// jsonFieldNames returns the JSON keys a struct type encodes and decodes
+func jsonFieldNames(t reflect.Type) map[string]bool {
+	names := map[string]bool{}
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		name := strings.Split(field.Tag.Get("json"), ",")[0]
+		if name == "-" || field.PkgPath != "" {
+			continue
+		}
+		if name == "" {
+			name = field.Name
+		}
+		names[name] = true
+	}
+	return names
+}
+
+// unmarshalExtras returns the members of the JSON object src that aren't in known
+func unmarshalExtras(src []byte, known map[string]bool) (map[string]json.RawMessage, error) {
+	members := map[string]json.RawMessage{}
+	if err := json.Unmarshal(src, &members); err != nil {
+		return nil, err
+	}
+	extras := map[string]json.RawMessage{}
+	for key, value := range members {
+		if known[key] == false {
+			extras[key] = value
+		}
+	}
+	return extras, nil
+}
+
+// marshalExtras adds extras to the JSON object src without replacing its members
+func marshalExtras(src []byte, extras map[string]json.RawMessage) ([]byte, error) {
+	if len(extras) == 0 {
+		return src, nil
+	}
+	members := map[string]json.RawMessage{}
+	if err := json.Unmarshal(src, &members); err != nil {
+		return nil, err
+	}
+	for key, value := range extras {
+		if _, ok := members[key]; ok == false {
+			members[key] = value
+		}
+	}
+	return json.Marshal(members)
+}
+
+// resourceFields are the keys of a resource record modeled by Resource
+var resourceFields = jsonFieldNames(reflect.TypeOf(Resource{}))
+
+// UnmarshalJSON decodes a resource record keeping the fields Resource doesn't
+// model in Extras so they survive an update
+func (obj *Resource) UnmarshalJSON(src []byte) error {
+	type resource Resource
+	if err := json.Unmarshal(src, (*resource)(obj)); err != nil {
+		return err
+	}
+	extras, err := unmarshalExtras(src, resourceFields)
+	if err != nil {
+		return err
+	}
+	for key, value := range extras {
+		if obj.Extras == nil {
+			obj.Extras = map[string]json.RawMessage{}
+		}
+		obj.Extras[key] = value
+	}
+	return nil
+}
+
+// MarshalJSON encodes a resource record including its Extras
+func (obj *Resource) MarshalJSON() ([]byte, error) {
+	type resource Resource
+	src, err := json.Marshal((*resource)(obj))
+	if err != nil {
+		return nil, err
+	}
+	return marshalExtras(src, obj.Extras)
+}
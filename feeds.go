@@ -0,0 +1,118 @@
+//
+// Package cait is a collection of structures and functions
+// for interacting with ArchivesSpace's REST API
+//
+// @author R. S. Doiel, <rsdoiel@caltech.edu>
+//
+// Copyright (c) 2017, Caltech
+// All rights not granted herein are expressly reserved by Caltech.
+//
+// Redistribution and use in source and binary forms, with or without modification, are permitted provided that the following conditions are met:
+//
+// 1. Redistributions of source code must retain the above copyright notice, this list of conditions and the following disclaimer.
+//
+// 2. Redistributions in binary form must reproduce the above copyright notice, this list of conditions and the following disclaimer in the documentation and/or other materials provided with the distribution.
+//
+// 3. Neither the name of the copyright holder nor the names of its contributors may be used to endorse or promote products derived from this software without specific prior written permission.
+//
+// THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS" AND ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE ARE DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT HOLDER OR CONTRIBUTORS BE LIABLE FOR ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL DAMAGES (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR SERVICES; LOSS OF USE, DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER CAUSED AND ON ANY THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT LIABILITY, OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE OF THIS SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+//
+package cait
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+// DeleteFeed is a page of the URIs of records deleted from ArchivesSpace
+type DeleteFeed struct {
+	FirstPage int      `json:"first_page"`
+	LastPage  int      `json:"last_page"`
+	ThisPage  int      `json:"this_page"`
+	Total     int      `json:"total"`
+	Results   []string `json:"results"`
+}
+
+// GetDeleteFeed returns page (starting at 1) of the delete feed, the URIs of records
+// deleted from ArchivesSpace, so downstream indexes can remove them
+func (api *ArchivesSpaceAPI) GetDeleteFeed(page, pageSize int) (*DeleteFeed, error) {
+	if page < 1 {
+		page = 1
+	}
+	if pageSize < 1 {
+		pageSize = DefaultPageSize
+	}
+	u := api.callURL("/delete-feed")
+	q := u.Query()
+	q.Set("page", fmt.Sprintf("%d", page))
+	q.Set("page_size", fmt.Sprintf("%d", pageSize))
+	u.RawQuery = q.Encode()
+
+	feed := new(DeleteFeed)
+	if err := api.GetAPI(u.String(), feed); err != nil {
+		return nil, fmt.Errorf("GetDeleteFeed(%d, %d) %w", page, pageSize, err)
+	}
+	return feed, nil
+}
+
+// UpdateFeedEntry is a changed record reported by the update feed
+type UpdateFeedEntry struct {
+	Sequence int    `json:"sequence"`
+	URI      string `json:"uri"`
+	Record   Object `json:"record,omitempty"`
+}
+
+// GetUpdateFeed returns the record changes ArchivesSpace has seen after lastSequence, use zero
+// for every change it still holds. Pass the largest Sequence returned on the next call.
+// ArchivesSpace holds the request open until there is a change or about a minute passes so
+// RequestTimeout needs to be longer than that.
+func (api *ArchivesSpaceAPI) GetUpdateFeed(lastSequence int) ([]*UpdateFeedEntry, error) {
+	u := api.callURL("/update-feed")
+	q := u.Query()
+	q.Set("last_sequence", fmt.Sprintf("%d", lastSequence))
+	u.RawQuery = q.Encode()
+
+	entries := []*UpdateFeedEntry{}
+	if err := api.GetAPI(u.String(), &entries); err != nil {
+		return nil, fmt.Errorf("GetUpdateFeed(%d) %w", lastSequence, err)
+	}
+	return entries, nil
+}
+
+// UpdateMonitor tells ArchivesSpace which records this client is editing and returns
+// what it knows of the edits to each record, keyed by URI
+func (api *ArchivesSpaceAPI) UpdateMonitor(edits *ActiveEdits) (Object, error) {
+	u := api.callURL("/update_monitor")
+	content, err := api.API("POST", u.String(), edits)
+	if err != nil {
+		return nil, fmt.Errorf("UpdateMonitor() %w", err)
+	}
+	status := Object{}
+	if err := json.Unmarshal(content, &status); err != nil {
+		return nil, fmt.Errorf("UpdateMonitor() %w", err)
+	}
+	return status, nil
+}
+
+// ChangedRepositories returns the IDs of the repositories holding records modified since
+// the time given. It makes one single hit search per repository so a sync process can
+// skip harvesting repositories that haven't changed.
+func (api *ArchivesSpaceAPI) ChangedRepositories(since time.Time) ([]int, error) {
+	repoIDs, err := api.ListRepositoryIDs()
+	if err != nil {
+		return nil, fmt.Errorf("ChangedRepositories() %w", err)
+	}
+	changed := []int{}
+	for _, repoID := range repoIDs {
+		req := NewSearchRequest(fmt.Sprintf("system_mtime:[%s TO *]", since.UTC().Format("2006-01-02T15:04:05Z"))).InRepository(repoID).WithPageSize(1)
+		results, err := api.SearchRecords(req)
+		if err != nil {
+			return nil, fmt.Errorf("ChangedRepositories() %w", err)
+		}
+		if results.TotalHits > 0 {
+			changed = append(changed, repoID)
+		}
+	}
+	return changed, nil
+}
@@ -0,0 +1,158 @@
+//
+// Package cait is a collection of structures and functions
+// for interacting with ArchivesSpace's REST API
+//
+// @author R. S. Doiel, <rsdoiel@caltech.edu>
+//
+// Copyright (c) 2017, Caltech
+// All rights not granted herein are expressly reserved by Caltech.
+//
+// Redistribution and use in source and binary forms, with or without modification, are permitted provided that the following conditions are met:
+//
+// 1. Redistributions of source code must retain the above copyright notice, this list of conditions and the following disclaimer.
+//
+// 2. Redistributions in binary form must reproduce the above copyright notice, this list of conditions and the following disclaimer in the documentation and/or other materials provided with the distribution.
+//
+// 3. Neither the name of the copyright holder nor the names of its contributors may be used to endorse or promote products derived from this software without specific prior written permission.
+//
+// THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS" AND ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE ARE DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT HOLDER OR CONTRIBUTORS BE LIABLE FOR ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL DAMAGES (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR SERVICES; LOSS OF USE, DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER CAUSED AND ON ANY THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT LIABILITY, OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE OF THIS SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+//
+package cait
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// CreateGroup creates a group in a Repository granting grants_permissions to the
+// member_usernames. On success the group's URI, ID and lock version are updated.
+func (api *ArchivesSpaceAPI) CreateGroup(repoID int, group *Group) (*ResponseMsg, error) {
+	u := api.callURL(fmt.Sprintf("/repositories/%d/groups", repoID))
+	group.JSONModelType = "group"
+	group.LockVersion = "0"
+	responseMsg, err := api.CreateAPI(u.String(), group)
+	if err != nil {
+		return nil, fmt.Errorf("CreateGroup(%d, %q) %w", repoID, group.GroupCode, err)
+	}
+	group.SetURI(responseMsg.URI)
+	group.LockVersion = responseMsg.LockVersion
+	return responseMsg, nil
+}
+
+// GetGroup retrieves a group including its member usernames
+func (api *ArchivesSpaceAPI) GetGroup(repoID, groupID int) (*Group, error) {
+	u := api.callURL(fmt.Sprintf("/repositories/%d/groups/%d", repoID, groupID))
+	q := u.Query()
+	q.Set("list_members", "true")
+	u.RawQuery = q.Encode()
+	group := new(Group)
+	if err := api.GetAPI(u.String(), group); err != nil {
+		return nil, fmt.Errorf("GetGroup(%d, %d) %w", repoID, groupID, err)
+	}
+	group.ID = URIToID(group.URI)
+	return group, nil
+}
+
+// UpdateGroup updates an existing group, its members are replaced by group.MemberUsernames
+func (api *ArchivesSpaceAPI) UpdateGroup(group *Group) (*ResponseMsg, error) {
+	u := api.callURL(group.URI)
+	q := u.Query()
+	q.Set("with_members", "true")
+	u.RawQuery = q.Encode()
+	return api.UpdateAPI(u.String(), group)
+}
+
+// DeleteGroup deletes a group
+func (api *ArchivesSpaceAPI) DeleteGroup(group *Group) (*ResponseMsg, error) {
+	u := api.callURL(group.URI)
+	return api.DeleteAPI(u.String(), group)
+}
+
+// ListGroups returns the groups in a Repository, ArchivesSpace doesn't include
+// their members so use GetGroup when they're needed
+func (api *ArchivesSpaceAPI) ListGroups(repoID int) ([]*Group, error) {
+	u := api.callURL(fmt.Sprintf("/repositories/%d/groups", repoID))
+	groups := []*Group{}
+	if err := api.GetAPI(u.String(), &groups); err != nil {
+		return nil, fmt.Errorf("ListGroups(%d) %w", repoID, err)
+	}
+	for _, group := range groups {
+		group.ID = URIToID(group.URI)
+	}
+	return groups, nil
+}
+
+// ApplyGroup makes the group with template's group code in a Repository match template,
+// updating the group if it exists and creating it if not. Applying the same templates to
+// each repository keeps their access control consistent. It returns the saved group.
+func (api *ArchivesSpaceAPI) ApplyGroup(repoID int, template *Group) (*Group, error) {
+	if template.GroupCode == "" {
+		return nil, fmt.Errorf("ApplyGroup(%d) template is missing a group_code", repoID)
+	}
+	groups, err := api.ListGroups(repoID)
+	if err != nil {
+		return nil, fmt.Errorf("ApplyGroup(%d, %q) %w", repoID, template.GroupCode, err)
+	}
+	group := &Group{
+		GroupCode:         template.GroupCode,
+		Description:       template.Description,
+		MemberUsernames:   append([]string{}, template.MemberUsernames...),
+		GrantsPermissions: append([]string{}, template.GrantsPermissions...),
+	}
+	for _, existing := range groups {
+		if existing.GroupCode == template.GroupCode {
+			group.SetURI(existing.URI)
+			group.LockVersion = existing.LockVersion
+			if _, err := api.UpdateGroup(group); err != nil {
+				return nil, fmt.Errorf("ApplyGroup(%d, %q) %w", repoID, template.GroupCode, err)
+			}
+			return group, nil
+		}
+	}
+	if _, err := api.CreateGroup(repoID, group); err != nil {
+		return nil, fmt.Errorf("ApplyGroup(%d, %q) %w", repoID, template.GroupCode, err)
+	}
+	return group, nil
+}
+
+// ListPermissions returns the permission codes ArchivesSpace defines and their
+// descriptions. level is "repository", "global" or "all", empty means "all".
+func (api *ArchivesSpaceAPI) ListPermissions(level string) ([]*Permission, error) {
+	if level == "" {
+		level = "all"
+	}
+	u := api.callURL("/permissions")
+	q := u.Query()
+	q.Set("level", level)
+	u.RawQuery = q.Encode()
+	permissions := []*Permission{}
+	if err := api.GetAPI(u.String(), &permissions); err != nil {
+		return nil, fmt.Errorf("ListPermissions(%q) %w", level, err)
+	}
+	return permissions, nil
+}
+
+// ValidateGrants returns an error listing any of grants which isn't a permission
+// code ArchivesSpace defines, use it to check a group before applying it
+func (api *ArchivesSpaceAPI) ValidateGrants(grants []string) error {
+	permissions, err := api.ListPermissions("all")
+	if err != nil {
+		return fmt.Errorf("ValidateGrants() %w", err)
+	}
+	known := map[string]bool{}
+	for _, permission := range permissions {
+		known[permission.PermissionCode] = true
+	}
+	unknown := []string{}
+	for _, grant := range grants {
+		if known[grant] == false {
+			unknown = append(unknown, grant)
+		}
+	}
+	if len(unknown) > 0 {
+		sort.Strings(unknown)
+		return fmt.Errorf("ValidateGrants() unknown permissions %s", strings.Join(unknown, ", "))
+	}
+	return nil
+}
@@ -0,0 +1,375 @@
+//
+// Package cait is a collection of structures and functions
+// for interacting with ArchivesSpace's REST API
+//
+// @author R. S. Doiel, <rsdoiel@caltech.edu>
+//
+// Copyright (c) 2017, Caltech
+// All rights not granted herein are expressly reserved by Caltech.
+//
+// Redistribution and use in source and binary forms, with or without modification, are permitted provided that the following conditions are met:
+//
+// 1. Redistributions of source code must retain the above copyright notice, this list of conditions and the following disclaimer.
+//
+// 2. Redistributions in binary form must reproduce the above copyright notice, this list of conditions and the following disclaimer in the documentation and/or other materials provided with the distribution.
+//
+// 3. Neither the name of the copyright holder nor the names of its contributors may be used to endorse or promote products derived from this software without specific prior written permission.
+//
+// THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS" AND ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE ARE DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT HOLDER OR CONTRIBUTORS BE LIABLE FOR ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL DAMAGES (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR SERVICES; LOSS OF USE, DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER CAUSED AND ON ANY THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT LIABILITY, OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE OF THIS SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+//
+package cait
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// Job statuses, a job is finished once it is completed, failed or canceled
+const (
+	JobQueued    = "queued"
+	JobRunning   = "running"
+	JobCompleted = "completed"
+	JobFailed    = "failed"
+	JobCanceled  = "canceled"
+)
+
+// NewJob returns a Job of jobType (e.g. "print_to_pdf_job" or "import_job") whose
+// settings are given in params, e.g. {"source": "/repositories/2/resources/1"}
+func NewJob(jobType string, params map[string]interface{}) *Job {
+	job := new(Job)
+	job.JSONModelType = "job"
+	job.Job = map[string]interface{}{"jsonmodel_type": jobType}
+	for k, v := range params {
+		job.Job[k] = v
+	}
+	return job
+}
+
+// CreateJob submits a job to a repository's queue. On success the job's URI and ID are set.
+func (api *ArchivesSpaceAPI) CreateJob(repoID int, job *Job) (*ResponseMsg, error) {
+	u := api.callURL(fmt.Sprintf("/repositories/%d/jobs", repoID))
+	job.JSONModelType = "job"
+	job.LockVersion = "0"
+	responseMsg, err := api.CreateAPI(u.String(), job)
+	if err != nil {
+		return nil, fmt.Errorf("CreateJob(%d) %w", repoID, err)
+	}
+	job.SetURI(responseMsg.URI)
+	job.LockVersion = responseMsg.LockVersion
+	return responseMsg, nil
+}
+
+// CreateJobWithFiles submits a job along with the files it works on, e.g. an import. The job and
+// files are sent as a multipart form to jobs_with_files. On success the job's URI and ID are set.
+func (api *ArchivesSpaceAPI) CreateJobWithFiles(repoID int, job *Job, fnames ...string) (*ResponseMsg, error) {
+	if len(fnames) == 0 {
+		return nil, fmt.Errorf("CreateJobWithFiles(%d) no files to upload", repoID)
+	}
+	job.JSONModelType = "job"
+	job.LockVersion = "0"
+	src, err := json.Marshal(job)
+	if err != nil {
+		return nil, fmt.Errorf("CreateJobWithFiles(%d) %w", repoID, err)
+	}
+	buf := new(bytes.Buffer)
+	form := multipart.NewWriter(buf)
+	if err := form.WriteField("job", string(src)); err != nil {
+		return nil, fmt.Errorf("CreateJobWithFiles(%d) %w", repoID, err)
+	}
+	for _, fname := range fnames {
+		if err := addFormFile(form, "files[]", fname); err != nil {
+			return nil, fmt.Errorf("CreateJobWithFiles(%d) %w", repoID, err)
+		}
+	}
+	if err := form.Close(); err != nil {
+		return nil, fmt.Errorf("CreateJobWithFiles(%d) %w", repoID, err)
+	}
+
+	u := api.callURL(fmt.Sprintf("/repositories/%d/jobs_with_files", repoID))
+	headers := http.Header{}
+	headers.Set("Content-Type", form.FormDataContentType())
+	res, err := api.sendRequest("POST", u.String(), buf.Bytes(), headers)
+	if err != nil {
+		return nil, fmt.Errorf("CreateJobWithFiles(%d) %w", repoID, err)
+	}
+	defer res.Body.Close()
+	content, err := api.readBody(u.String(), res.Body)
+	if err != nil {
+		return nil, fmt.Errorf("CreateJobWithFiles(%d) %w", repoID, err)
+	}
+	if isSuccess(res.StatusCode) == false {
+		return nil, fmt.Errorf("CreateJobWithFiles(%d) %w", repoID, newAPIError("POST", u.String(), res.StatusCode, res.Status, content))
+	}
+	responseMsg := new(ResponseMsg)
+	if err := json.Unmarshal(content, responseMsg); err != nil {
+		return nil, fmt.Errorf("CreateJobWithFiles(%d) %w", repoID, err)
+	}
+	job.SetURI(responseMsg.URI)
+	job.LockVersion = responseMsg.LockVersion
+	return responseMsg, nil
+}
+
+// addFormFile copies the file fname into form as field
+func addFormFile(form *multipart.Writer, field string, fname string) error {
+	fp, err := os.Open(fname)
+	if err != nil {
+		return err
+	}
+	defer fp.Close()
+	w, err := form.CreateFormFile(field, filepath.Base(fname))
+	if err != nil {
+		return err
+	}
+	_, err = io.Copy(w, fp)
+	return err
+}
+
+// CreateImportJobWithFiles uploads EAD, MARC, CSV or other files and queues an import_job
+// of importType (e.g. "ead_xml", "marcxml", "accession_csv") to load them into a repository
+func (api *ArchivesSpaceAPI) CreateImportJobWithFiles(repoID int, importType string, fnames ...string) (*Job, error) {
+	filenames := []string{}
+	for _, fname := range fnames {
+		filenames = append(filenames, filepath.Base(fname))
+	}
+	job := NewJob("import_job", map[string]interface{}{
+		"import_type": importType,
+		"filenames":   filenames,
+	})
+	if _, err := api.CreateJobWithFiles(repoID, job, fnames...); err != nil {
+		return nil, fmt.Errorf("CreateImportJobWithFiles(%d, %q) %w", repoID, importType, err)
+	}
+	return job, nil
+}
+
+// GetJob retrieves a job, its Status reports its progress
+func (api *ArchivesSpaceAPI) GetJob(repoID, jobID int) (*Job, error) {
+	u := api.callURL(fmt.Sprintf("/repositories/%d/jobs/%d", repoID, jobID))
+	job := new(Job)
+	if err := api.GetAPI(u.String(), job); err != nil {
+		return nil, fmt.Errorf("GetJob(%d, %d) %w", repoID, jobID, err)
+	}
+	job.ID = URIToID(job.URI)
+	return job, nil
+}
+
+// IsFinished returns true once the job has completed, failed or been canceled
+func (job *Job) IsFinished() bool {
+	return job.Status == JobCompleted || job.Status == JobFailed || job.Status == JobCanceled
+}
+
+// WaitForJob polls a job every interval until it is finished or timeout passes,
+// returning the job as last retrieved. A timeout of zero waits indefinitely.
+func (api *ArchivesSpaceAPI) WaitForJob(repoID, jobID int, interval, timeout time.Duration) (*Job, error) {
+	start := time.Now()
+	for {
+		job, err := api.GetJob(repoID, jobID)
+		if err != nil {
+			return nil, err
+		}
+		if job.IsFinished() {
+			return job, nil
+		}
+		if timeout > 0 && time.Since(start)+interval > timeout {
+			return job, fmt.Errorf("WaitForJob(%d, %d) job still %s after %s", repoID, jobID, job.Status, timeout)
+		}
+		time.Sleep(interval)
+	}
+}
+
+// GetJobLog returns a job's log output starting from offset bytes, pass the length
+// of the log read so far to fetch only the new output while a job runs
+func (api *ArchivesSpaceAPI) GetJobLog(repoID, jobID, offset int) (string, error) {
+	u := api.callURL(fmt.Sprintf("/repositories/%d/jobs/%d/log", repoID, jobID))
+	q := u.Query()
+	q.Set("offset", fmt.Sprintf("%d", offset))
+	u.RawQuery = q.Encode()
+	content, err := api.API("GET", u.String(), nil)
+	if err != nil {
+		return "", fmt.Errorf("GetJobLog(%d, %d) %w", repoID, jobID, err)
+	}
+	return string(content), nil
+}
+
+// ListJobRecords returns the URIs of the records a job created, e.g. by an import
+func (api *ArchivesSpaceAPI) ListJobRecords(repoID, jobID int) ([]string, error) {
+	uris := []string{}
+	for page := 1; ; page++ {
+		u := api.callURL(fmt.Sprintf("/repositories/%d/jobs/%d/records", repoID, jobID))
+		q := u.Query()
+		q.Set("page", fmt.Sprintf("%d", page))
+		u.RawQuery = q.Encode()
+		results := struct {
+			LastPage int `json:"last_page"`
+			Results  []struct {
+				Record map[string]interface{} `json:"record"`
+			} `json:"results"`
+		}{}
+		if err := api.GetAPI(u.String(), &results); err != nil {
+			return nil, fmt.Errorf("ListJobRecords(%d, %d) %w", repoID, jobID, err)
+		}
+		for _, item := range results.Results {
+			if uri, ok := item.Record["ref"].(string); ok {
+				uris = append(uris, uri)
+			}
+		}
+		if page >= results.LastPage {
+			return uris, nil
+		}
+	}
+}
+
+// CancelJob asks ArchivesSpace to cancel a queued or running job in a repository
+func (api *ArchivesSpaceAPI) CancelJob(repoID, jobID int) (*ResponseMsg, error) {
+	u := api.callURL(fmt.Sprintf("/repositories/%d/jobs/%d/cancel", repoID, jobID))
+	return api.UpdateAPI(u.String(), nil)
+}
+
+// JobListOptions holds the filtering and paging options for ListJobs. An
+// empty Status or JobTypes list matches everything.
+type JobListOptions struct {
+	Status   []string `json:"status,omitempty"`
+	JobTypes []string `json:"job_types,omitempty"`
+	Page     int      `json:"page,omitempty"`
+	PageSize int      `json:"page_size,omitempty"`
+}
+
+// JobList is a page of jobs as returned by ListJobs
+type JobList struct {
+	FirstPage int    `json:"first_page"`
+	LastPage  int    `json:"last_page"`
+	ThisPage  int    `json:"this_page"`
+	Total     int    `json:"total"`
+	Results   []*Job `json:"results"`
+}
+
+// ListJobs returns a page of jobs for a repository filtered by status and job type.
+// ArchivesSpace can't filter its job listing so when a filter is given every job is
+// fetched, from jobs/active or jobs/archived if Status allows, and the page and
+// Total are worked out from the jobs that match.
+func (api *ArchivesSpaceAPI) ListJobs(repoID int, opts *JobListOptions) (*JobList, error) {
+	if opts == nil {
+		opts = new(JobListOptions)
+	}
+	page, pageSize := opts.Page, opts.PageSize
+	if page < 1 {
+		page = 1
+	}
+	if pageSize < 1 {
+		pageSize = 10
+	}
+	if len(opts.Status) == 0 && len(opts.JobTypes) == 0 {
+		jobs, err := api.listJobsPage(fmt.Sprintf("/repositories/%d/jobs", repoID), page, pageSize)
+		if err != nil {
+			return nil, fmt.Errorf("ListJobs(%d) %w", repoID, err)
+		}
+		return jobs, nil
+	}
+
+	var (
+		all []*Job
+		err error
+	)
+	switch {
+	case len(opts.Status) > 0 && onlyStatuses(opts.Status, JobQueued, JobRunning):
+		u := api.callURL(fmt.Sprintf("/repositories/%d/jobs/active", repoID))
+		err = api.GetAPI(u.String(), &all)
+		for _, job := range all {
+			job.ID = URIToID(job.URI)
+		}
+	case len(opts.Status) > 0 && onlyStatuses(opts.Status, JobCompleted, JobFailed, JobCanceled):
+		all, err = api.allJobs(fmt.Sprintf("/repositories/%d/jobs/archived", repoID))
+	default:
+		all, err = api.allJobs(fmt.Sprintf("/repositories/%d/jobs", repoID))
+	}
+	if err != nil {
+		return nil, fmt.Errorf("ListJobs(%d) %w", repoID, err)
+	}
+	matched := []*Job{}
+	for _, job := range all {
+		if len(opts.Status) > 0 && containsString(opts.Status, job.Status) == false {
+			continue
+		}
+		if len(opts.JobTypes) > 0 && containsString(opts.JobTypes, job.JobType) == false {
+			continue
+		}
+		matched = append(matched, job)
+	}
+	jobs := &JobList{
+		FirstPage: 1,
+		LastPage:  (len(matched) + pageSize - 1) / pageSize,
+		ThisPage:  page,
+		Total:     len(matched),
+		Results:   []*Job{},
+	}
+	if jobs.LastPage < 1 {
+		jobs.LastPage = 1
+	}
+	if first := (page - 1) * pageSize; first < len(matched) {
+		last := first + pageSize
+		if last > len(matched) {
+			last = len(matched)
+		}
+		jobs.Results = matched[first:last]
+	}
+	return jobs, nil
+}
+
+// listJobsPage fetches a page of a paged job listing
+func (api *ArchivesSpaceAPI) listJobsPage(p string, page, pageSize int) (*JobList, error) {
+	u := api.callURL(p)
+	q := u.Query()
+	q.Del("all_ids")
+	q.Set("page", fmt.Sprintf("%d", page))
+	q.Set("page_size", fmt.Sprintf("%d", pageSize))
+	u.RawQuery = q.Encode()
+	jobs := new(JobList)
+	if err := api.GetAPI(u.String(), jobs); err != nil {
+		return nil, err
+	}
+	for _, job := range jobs.Results {
+		job.ID = URIToID(job.URI)
+	}
+	return jobs, nil
+}
+
+// allJobs fetches every page of a paged job listing
+func (api *ArchivesSpaceAPI) allJobs(p string) ([]*Job, error) {
+	all := []*Job{}
+	for page := 1; ; page++ {
+		jobs, err := api.listJobsPage(p, page, 100)
+		if err != nil {
+			return nil, err
+		}
+		all = append(all, jobs.Results...)
+		if page >= jobs.LastPage {
+			return all, nil
+		}
+	}
+}
+
+// onlyStatuses returns true if every status in list is one of allowed
+func onlyStatuses(list []string, allowed ...string) bool {
+	for _, status := range list {
+		if containsString(allowed, status) == false {
+			return false
+		}
+	}
+	return true
+}
+
+// containsString returns true if s is found in list
+func containsString(list []string, s string) bool {
+	for _, item := range list {
+		if item == s {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,170 @@
+//
+// Package cait is a collection of structures and functions
+// for interacting with ArchivesSpace's REST API
+//
+// @author R. S. Doiel, <rsdoiel@caltech.edu>
+//
+// Copyright (c) 2017, Caltech
+// All rights not granted herein are expressly reserved by Caltech.
+//
+// Redistribution and use in source and binary forms, with or without modification, are permitted provided that the following conditions are met:
+//
+// 1. Redistributions of source code must retain the above copyright notice, this list of conditions and the following disclaimer.
+//
+// 2. Redistributions in binary form must reproduce the above copyright notice, this list of conditions and the following disclaimer in the documentation and/or other materials provided with the distribution.
+//
+// 3. Neither the name of the copyright holder nor the names of its contributors may be used to endorse or promote products derived from this software without specific prior written permission.
+//
+// THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS" AND ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE ARE DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT HOLDER OR CONTRIBUTORS BE LIABLE FOR ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL DAMAGES (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR SERVICES; LOSS OF USE, DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER CAUSED AND ON ANY THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT LIABILITY, OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE OF THIS SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+//
+package cait
+
+import (
+	"fmt"
+	htmltemplate "html/template"
+	"io"
+	"io/ioutil"
+	"path"
+	"strings"
+	"text/template"
+	"time"
+)
+
+// GiftLetter holds the accession and donor data merged into a gift acknowledgement letter
+type GiftLetter struct {
+	Date       string        `json:"date"`
+	Repository *Repository   `json:"repository,omitempty"`
+	Accession  *Accession    `json:"accession"`
+	Identifier string        `json:"identifier"`
+	Extents    []string      `json:"extents,omitempty"`
+	Donor      *Agent        `json:"donor"`
+	DonorName  string        `json:"donor_name"`
+	Contact    *AgentContact `json:"contact,omitempty"`
+	Salutation string        `json:"salutation"`
+	Address    []string      `json:"address,omitempty"`
+}
+
+// LetterTemplate is a text or HTML template used to render GiftLetters
+type LetterTemplate struct {
+	Name string
+	text *template.Template
+	html *htmltemplate.Template
+}
+
+// contactAddress returns the postal address lines of an agent contact
+func contactAddress(contact *AgentContact) []string {
+	lines := []string{}
+	for _, s := range []string{contact.Name, contact.Address1, contact.Address2, contact.Address3} {
+		if s = strings.TrimSpace(s); s != "" {
+			lines = append(lines, s)
+		}
+	}
+	place := strings.TrimSpace(contact.City)
+	if contact.Region != "" {
+		if place != "" {
+			place += ", "
+		}
+		place += contact.Region
+	}
+	if contact.PostCode != "" {
+		place = strings.TrimSpace(place + " " + contact.PostCode)
+	}
+	if place != "" {
+		lines = append(lines, place)
+	}
+	if contact.Country != "" {
+		lines = append(lines, contact.Country)
+	}
+	return lines
+}
+
+// agentTypeFromURI returns the agent type path element (e.g. people) from an agent URI
+func agentTypeFromURI(uri string) string {
+	p := strings.Split(uri, "/")
+	if len(p) < 4 || p[1] != "agents" {
+		return ""
+	}
+	return p[2]
+}
+
+// GiftLetters returns the letter data for each source (donor) agent linked to an accession
+func (api *ArchivesSpaceAPI) GiftLetters(repoID, accessionID int) ([]*GiftLetter, error) {
+	accession, err := api.GetAccession(repoID, accessionID)
+	if err != nil {
+		return nil, fmt.Errorf("GiftLetters(%d, %d) %w", repoID, accessionID, err)
+	}
+	repo, err := api.GetRepository(repoID)
+	if err != nil {
+		return nil, fmt.Errorf("GiftLetters(%d, %d) %w", repoID, accessionID, err)
+	}
+	extents := []string{}
+	for _, extent := range accession.Extents {
+		extents = append(extents, strings.TrimSpace(fmt.Sprintf("%s %s", extent.Number, strings.Replace(extent.ExtentType, "_", " ", -1))))
+	}
+	letters := []*GiftLetter{}
+	for _, item := range accession.LinkedAgents {
+		ref, _ := item["ref"].(string)
+		if role, _ := item["role"].(string); role != "source" || ref == "" {
+			continue
+		}
+		donor, err := api.GetAgent(agentTypeFromURI(ref), URIToID(ref))
+		if err != nil {
+			return nil, fmt.Errorf("GiftLetters(%d, %d) %w", repoID, accessionID, err)
+		}
+		letter := &GiftLetter{
+			Date:       time.Now().Format("January 2, 2006"),
+			Repository: repo,
+			Accession:  accession,
+			Identifier: strings.Trim(strings.Join([]string{accession.ID0, accession.ID1, accession.ID2, accession.ID3}, "-"), "-"),
+			Extents:    extents,
+			Donor:      donor,
+			DonorName:  donor.Title,
+			Salutation: donor.Title,
+		}
+		if len(donor.AgentContacts) > 0 {
+			letter.Contact = donor.AgentContacts[0]
+			letter.Address = contactAddress(letter.Contact)
+			if letter.Contact.Salutation != "" {
+				letter.Salutation = letter.Contact.Salutation
+			}
+		}
+		letters = append(letters, letter)
+	}
+	if len(letters) == 0 {
+		return nil, fmt.Errorf("GiftLetters(%d, %d) accession has no source agents", repoID, accessionID)
+	}
+	return letters, nil
+}
+
+// NewLetterTemplate parses src as an HTML template if isHTML is true, otherwise as a text template
+func NewLetterTemplate(name, src string, isHTML bool) (*LetterTemplate, error) {
+	t := &LetterTemplate{Name: name}
+	var err error
+	if isHTML == true {
+		t.html, err = htmltemplate.New(name).Funcs(htmltemplate.FuncMap(TmplMap)).Parse(src)
+	} else {
+		t.text, err = template.New(name).Funcs(TmplMap).Parse(src)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("Can't parse letter template %s, %w", name, err)
+	}
+	return t, nil
+}
+
+// LoadLetterTemplate reads a letter template, files ending in .html or .htm are treated as HTML
+func LoadLetterTemplate(fname string) (*LetterTemplate, error) {
+	src, err := ioutil.ReadFile(fname)
+	if err != nil {
+		return nil, fmt.Errorf("Can't read letter template %s, %w", fname, err)
+	}
+	ext := strings.ToLower(path.Ext(fname))
+	return NewLetterTemplate(path.Base(fname), string(src), ext == ".html" || ext == ".htm")
+}
+
+// Execute renders a letter
+func (t *LetterTemplate) Execute(w io.Writer, letter *GiftLetter) error {
+	if t.html != nil {
+		return t.html.Execute(w, letter)
+	}
+	return t.text.Execute(w, letter)
+}
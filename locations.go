@@ -0,0 +1,126 @@
+//
+// Package cait is a collection of structures and functions
+// for interacting with ArchivesSpace's REST API
+//
+// @author R. S. Doiel, <rsdoiel@caltech.edu>
+//
+// Copyright (c) 2017, Caltech
+// All rights not granted herein are expressly reserved by Caltech.
+//
+// Redistribution and use in source and binary forms, with or without modification, are permitted provided that the following conditions are met:
+//
+// 1. Redistributions of source code must retain the above copyright notice, this list of conditions and the following disclaimer.
+//
+// 2. Redistributions in binary form must reproduce the above copyright notice, this list of conditions and the following disclaimer in the documentation and/or other materials provided with the distribution.
+//
+// 3. Neither the name of the copyright holder nor the names of its contributors may be used to endorse or promote products derived from this software without specific prior written permission.
+//
+// THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS" AND ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE ARE DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT HOLDER OR CONTRIBUTORS BE LIABLE FOR ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL DAMAGES (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR SERVICES; LOSS OF USE, DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER CAUSED AND ON ANY THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT LIABILITY, OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE OF THIS SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+//
+package cait
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+// postLocationBatch posts batch to /locations/batch decoding the response into result
+func (api *ArchivesSpaceAPI) postLocationBatch(batch *LocationBatch, dryRun bool, result interface{}) error {
+	u := api.callURL("/locations/batch")
+	if dryRun == true {
+		q := u.Query()
+		q.Set("dry_run", "true")
+		u.RawQuery = q.Encode()
+	}
+	batch.JSONModelType = "location_batch"
+	content, err := api.API("POST", u.String(), batch)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(content, result)
+}
+
+// CreateLocationBatch creates a location for every combination of the batch's
+// coordinate ranges in one request, each location takes its building, floor, room
+// etc. from the batch. It returns the URIs of the locations created.
+func (api *ArchivesSpaceAPI) CreateLocationBatch(batch *LocationBatch) ([]string, error) {
+	uris := []string{}
+	if err := api.postLocationBatch(batch, false, &uris); err != nil {
+		return nil, fmt.Errorf("CreateLocationBatch() %w", err)
+	}
+	return uris, nil
+}
+
+// PreviewLocationBatch returns the locations CreateLocationBatch would create without creating them
+func (api *ArchivesSpaceAPI) PreviewLocationBatch(batch *LocationBatch) ([]*Location, error) {
+	locations := []*Location{}
+	if err := api.postLocationBatch(batch, true, &locations); err != nil {
+		return nil, fmt.Errorf("PreviewLocationBatch() %w", err)
+	}
+	return locations, nil
+}
+
+// UpdateLocationBatch sets the non-empty fields of update (e.g. building, floor or
+// room) on every location listed in update.RecordURIs in one request
+func (api *ArchivesSpaceAPI) UpdateLocationBatch(update *LocationBatchUpdate) (*ResponseMsg, error) {
+	if len(update.RecordURIs) == 0 {
+		return nil, fmt.Errorf("UpdateLocationBatch() no record_uris to update")
+	}
+	u := api.callURL("/locations/batch_update")
+	update.JSONModelType = "location_batch_update"
+	responseMsg, err := api.UpdateAPI(u.String(), update)
+	if err != nil {
+		return nil, fmt.Errorf("UpdateLocationBatch() %w", err)
+	}
+	return responseMsg, nil
+}
+
+// CreateLocationProfile creates a location (shelving) profile. On success the profile's
+// URI, ID and lock version are updated from the response.
+func (api *ArchivesSpaceAPI) CreateLocationProfile(profile *LocationProfile) (*ResponseMsg, error) {
+	u := api.callURL("/location_profiles")
+	profile.JSONModelType = "location_profile"
+	profile.LockVersion = "0"
+	responseMsg, err := api.CreateAPI(u.String(), profile)
+	if err != nil {
+		return nil, fmt.Errorf("CreateLocationProfile(%q) %w", profile.Name, err)
+	}
+	profile.SetURI(responseMsg.URI)
+	profile.LockVersion = responseMsg.LockVersion
+	return responseMsg, nil
+}
+
+// GetLocationProfile retrieves a location profile
+func (api *ArchivesSpaceAPI) GetLocationProfile(profileID int) (*LocationProfile, error) {
+	u := api.callURL(fmt.Sprintf("/location_profiles/%d", profileID))
+	profile := new(LocationProfile)
+	if err := api.GetAPI(u.String(), profile); err != nil {
+		return nil, fmt.Errorf("GetLocationProfile(%d) %w", profileID, err)
+	}
+	profile.ID = URIToID(profile.URI)
+	return profile, nil
+}
+
+// UpdateLocationProfile updates an existing location profile
+func (api *ArchivesSpaceAPI) UpdateLocationProfile(profile *LocationProfile) (*ResponseMsg, error) {
+	u := api.callURL(profile.URI)
+	return api.UpdateAPI(u.String(), profile)
+}
+
+// DeleteLocationProfile deletes a location profile
+func (api *ArchivesSpaceAPI) DeleteLocationProfile(profile *LocationProfile) (*ResponseMsg, error) {
+	u := api.callURL(profile.URI)
+	return api.DeleteAPI(u.String(), profile)
+}
+
+// ListLocationProfiles return a list of location profile IDs
+// Give modifiedSince to list only the records changed since then.
+func (api *ArchivesSpaceAPI) ListLocationProfiles(modifiedSince ...time.Time) ([]int, error) {
+	u := api.callURL("/location_profiles")
+	q := u.Query()
+	q.Set("all_ids", "true")
+	setModifiedSince(q, modifiedSince)
+	u.RawQuery = q.Encode()
+	return api.ListAPI(u.String())
+}
@@ -0,0 +1,152 @@
+//
+// Package cait is a collection of structures and functions
+// for interacting with ArchivesSpace's REST API
+//
+// @author R. S. Doiel, <rsdoiel@caltech.edu>
+//
+// Copyright (c) 2017, Caltech
+// All rights not granted herein are expressly reserved by Caltech.
+//
+// Redistribution and use in source and binary forms, with or without modification, are permitted provided that the following conditions are met:
+//
+// 1. Redistributions of source code must retain the above copyright notice, this list of conditions and the following disclaimer.
+//
+// 2. Redistributions in binary form must reproduce the above copyright notice, this list of conditions and the following disclaimer in the documentation and/or other materials provided with the distribution.
+//
+// 3. Neither the name of the copyright holder nor the names of its contributors may be used to endorse or promote products derived from this software without specific prior written permission.
+//
+// THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS" AND ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE ARE DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT HOLDER OR CONTRIBUTORS BE LIABLE FOR ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL DAMAGES (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR SERVICES; LOSS OF USE, DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER CAUSED AND ON ANY THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT LIABILITY, OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE OF THIS SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+//
+package cait
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"path"
+	"sort"
+	"sync"
+	"time"
+
+	// Caltech Library Packages
+	"github.com/caltechlibrary/dataset"
+)
+
+// ManifestEntry records a single exported record
+type ManifestEntry struct {
+	URI         string `json:"uri,omitempty"`
+	Path        string `json:"path"`
+	SHA256      string `json:"sha256"`
+	SystemMTime string `json:"system_mtime,omitempty"`
+	ExportedAt  string `json:"exported_at"`
+}
+
+// ExportManifest is an inventory of an export run so downstream consumers
+// can check for completeness and detect corrupted files
+type ExportManifest struct {
+	Started  string           `json:"started"`
+	Finished string           `json:"finished,omitempty"`
+	Entries  []*ManifestEntry `json:"entries"`
+
+	mu sync.Mutex
+}
+
+// NewExportManifest returns an empty manifest with its start time set
+func NewExportManifest() *ExportManifest {
+	return &ExportManifest{
+		Started: time.Now().UTC().Format(time.RFC3339),
+		Entries: []*ManifestEntry{},
+	}
+}
+
+// Add appends an entry to the manifest, it is safe to call from concurrent exports
+func (manifest *ExportManifest) Add(entry *ManifestEntry) {
+	manifest.mu.Lock()
+	defer manifest.mu.Unlock()
+	manifest.Entries = append(manifest.Entries, entry)
+}
+
+// Finish sets the finished time and sorts the entries by path
+func (manifest *ExportManifest) Finish() {
+	manifest.mu.Lock()
+	defer manifest.mu.Unlock()
+	manifest.Finished = time.Now().UTC().Format(time.RFC3339)
+	sort.Slice(manifest.Entries, func(i, j int) bool {
+		return manifest.Entries[i].Path < manifest.Entries[j].Path
+	})
+}
+
+// String return an ExportManifest
+func (manifest *ExportManifest) String() string {
+	return stringify(manifest)
+}
+
+// manifestEntry builds the manifest entry for the JSON src stored at docPath
+func manifestEntry(docPath string, src []byte) *ManifestEntry {
+	checksum := sha256.Sum256(src)
+	entry := &ManifestEntry{
+		Path:       docPath,
+		SHA256:     hex.EncodeToString(checksum[:]),
+		ExportedAt: time.Now().UTC().Format(time.RFC3339),
+	}
+	meta := struct {
+		URI         string `json:"uri"`
+		SystemMTime string `json:"system_mtime"`
+	}{}
+	if err := json.Unmarshal(src, &meta); err == nil {
+		entry.URI = meta.URI
+		entry.SystemMTime = meta.SystemMTime
+	}
+	return entry
+}
+
+// exportJSON writes data to the collection like WriteJSON, adding an entry to
+// api.Manifest when one is set. The entry is made from the document as stored
+// by the collection so its checksum matches the file on disk.
+func (api *ArchivesSpaceAPI) exportJSON(c *dataset.Collection, dir, fname string, data interface{}) error {
+	src, err := json.Marshal(data)
+	if err != nil {
+		return fmt.Errorf("WriteJSON(c, %q, data) -> JSON encode, %w", fname, err)
+	}
+	err = c.CreateJSON(fname, src)
+	if err != nil {
+		return fmt.Errorf("Could not write JSON data, %s, %w", fname, err)
+	}
+	if api.Manifest != nil {
+		stored, err := c.ReadJSON(fname)
+		if err != nil {
+			return fmt.Errorf("Could not read back JSON data, %s, %w", fname, err)
+		}
+		docPath, err := c.DocPath(fname)
+		if err != nil {
+			docPath = path.Join(api.Dataset, dir, fname)
+		}
+		api.Manifest.Add(manifestEntry(docPath, stored))
+	}
+	return nil
+}
+
+// SaveManifest finishes api.Manifest and writes it to the manifest.ds collection
+// keyed by its start time, api.Manifest is then reset for the next export
+func (api *ArchivesSpaceAPI) SaveManifest() error {
+	if api.Manifest == nil {
+		return fmt.Errorf("no export manifest to save")
+	}
+	api.Manifest.Finish()
+	c, err := CreateCollection(api, "manifest.ds")
+	if err != nil {
+		return fmt.Errorf("Can't open collection %s/manifest.ds, %w", api.Dataset, err)
+	}
+	defer c.Close()
+	key := api.Manifest.Started
+	if t, err := time.Parse(time.RFC3339, key); err == nil {
+		key = t.Format("20060102T150405Z")
+	}
+	if err := WriteJSON(c, fmt.Sprintf("%s.json", key), api.Manifest); err != nil {
+		return err
+	}
+	// Start over so the next export doesn't repeat this one's entries
+	api.Manifest = NewExportManifest()
+	return nil
+}
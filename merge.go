@@ -0,0 +1,93 @@
+//
+// Package cait is a collection of structures and functions
+// for interacting with ArchivesSpace's REST API
+//
+// @author R. S. Doiel, <rsdoiel@caltech.edu>
+//
+// Copyright (c) 2017, Caltech
+// All rights not granted herein are expressly reserved by Caltech.
+//
+// Redistribution and use in source and binary forms, with or without modification, are permitted provided that the following conditions are met:
+//
+// 1. Redistributions of source code must retain the above copyright notice, this list of conditions and the following disclaimer.
+//
+// 2. Redistributions in binary form must reproduce the above copyright notice, this list of conditions and the following disclaimer in the documentation and/or other materials provided with the distribution.
+//
+// 3. Neither the name of the copyright holder nor the names of its contributors may be used to endorse or promote products derived from this software without specific prior written permission.
+//
+// THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS" AND ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE ARE DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT HOLDER OR CONTRIBUTORS BE LIABLE FOR ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL DAMAGES (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR SERVICES; LOSS OF USE, DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER CAUSED AND ON ANY THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT LIABILITY, OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE OF THIS SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+//
+package cait
+
+import (
+	"fmt"
+	"strings"
+)
+
+// NewMergeRequest returns a MergeRequest merging the records at victimURIs into the record at targetURI
+func NewMergeRequest(targetURI string, victimURIs ...string) *MergeRequest {
+	merge := new(MergeRequest)
+	merge.JSONModelType = "merge_request"
+	merge.Target = Ref(targetURI)
+	for _, uri := range victimURIs {
+		merge.Victims = append(merge.Victims, Ref(uri))
+	}
+	return merge
+}
+
+// postMergeRequest checks every URI in merge names a kind record then posts it to u.
+// ArchivesSpace replaces the links to the victims with links to the target and deletes the victims.
+func (api *ArchivesSpaceAPI) postMergeRequest(u string, kind string, merge *MergeRequest) (*ResponseMsg, error) {
+	target, _ := merge.Target["ref"].(string)
+	if strings.Contains(target, kind) == false {
+		return nil, fmt.Errorf("target %q is not a %s", target, strings.Trim(kind, "/"))
+	}
+	if len(merge.Victims) == 0 {
+		return nil, fmt.Errorf("no victims to merge into %q", target)
+	}
+	for _, victim := range merge.Victims {
+		uri, _ := victim["ref"].(string)
+		if strings.Contains(uri, kind) == false {
+			return nil, fmt.Errorf("victim %q is not a %s", uri, strings.Trim(kind, "/"))
+		}
+		if uri == target {
+			return nil, fmt.Errorf("can't merge %q into itself", uri)
+		}
+	}
+	merge.JSONModelType = "merge_request"
+	return api.UpdateAPI(u, merge)
+}
+
+// MergeAgents merges the agents at victimURIs into the agent at targetURI, the victims are deleted
+func (api *ArchivesSpaceAPI) MergeAgents(targetURI string, victimURIs ...string) (*ResponseMsg, error) {
+	u := api.callURL("/merge_requests/agent")
+	responseMsg, err := api.postMergeRequest(u.String(), "/agents/", NewMergeRequest(targetURI, victimURIs...))
+	if err != nil {
+		return nil, fmt.Errorf("MergeAgents(%q) %w", targetURI, err)
+	}
+	return responseMsg, nil
+}
+
+// MergeSubjects merges the subjects at victimURIs into the subject at targetURI, the victims are deleted
+func (api *ArchivesSpaceAPI) MergeSubjects(targetURI string, victimURIs ...string) (*ResponseMsg, error) {
+	u := api.callURL("/merge_requests/subject")
+	responseMsg, err := api.postMergeRequest(u.String(), "/subjects/", NewMergeRequest(targetURI, victimURIs...))
+	if err != nil {
+		return nil, fmt.Errorf("MergeSubjects(%q) %w", targetURI, err)
+	}
+	return responseMsg, nil
+}
+
+// MergeTopContainers merges the top containers at victimURIs into the top container at targetURI
+// in the Repository repoID, the victims' instances are moved to the target and the victims deleted
+func (api *ArchivesSpaceAPI) MergeTopContainers(repoID int, targetURI string, victimURIs ...string) (*ResponseMsg, error) {
+	u := api.callURL("/merge_requests/top_container")
+	q := u.Query()
+	q.Set("repo_id", fmt.Sprintf("%d", repoID))
+	u.RawQuery = q.Encode()
+	responseMsg, err := api.postMergeRequest(u.String(), "/top_containers/", NewMergeRequest(targetURI, victimURIs...))
+	if err != nil {
+		return nil, fmt.Errorf("MergeTopContainers(%d, %q) %w", repoID, targetURI, err)
+	}
+	return responseMsg, nil
+}
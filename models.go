@@ -0,0 +1,650 @@
+//
+// Package cait is a collection of structures and functions
+// for interacting with ArchivesSpace's REST API
+//
+// @author R. S. Doiel, <rsdoiel@caltech.edu>
+//
+// Copyright (c) 2017, Caltech
+// All rights not granted herein are expressly reserved by Caltech.
+//
+// Redistribution and use in source and binary forms, with or without modification, are permitted provided that the following conditions are met:
+//
+// 1. Redistributions of source code must retain the above copyright notice, this list of conditions and the following disclaimer.
+//
+// 2. Redistributions in binary form must reproduce the above copyright notice, this list of conditions and the following disclaimer in the documentation and/or other materials provided with the distribution.
+//
+// 3. Neither the name of the copyright holder nor the names of its contributors may be used to endorse or promote products derived from this software without specific prior written permission.
+//
+// THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS" AND ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE ARE DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT HOLDER OR CONTRIBUTORS BE LIABLE FOR ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL DAMAGES (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR SERVICES; LOSS OF USE, DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER CAUSED AND ON ANY THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT LIABILITY, OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE OF THIS SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+//
+package cait
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"sync"
+)
+
+// JSONModel is implemented by the record types the generic Get, Create, Update,
+// Delete and List functions work with. The methods are named GetURI and SetURI
+// because the record structs already have an exported URI field.
+type JSONModel interface {
+	// GetURI returns the record's URI, e.g. /repositories/2/accessions/3
+	GetURI() string
+	// SetURI sets the record's URI (and ID if the type has one)
+	SetURI(uri string)
+}
+
+// modelPtr constrains PT to be a pointer to T implementing JSONModel
+type modelPtr[T any] interface {
+	*T
+	JSONModel
+}
+
+// Get retrieves the record at uri, e.g. Get[Accession](api, "/repositories/2/accessions/3")
+func Get[T any, PT modelPtr[T]](api *ArchivesSpaceAPI, uri string) (*T, error) {
+	obj := PT(new(T))
+	u := api.callURL(uri)
+	if err := api.GetAPI(u.String(), obj); err != nil {
+		return nil, fmt.Errorf("Get(%q) %w", uri, err)
+	}
+	obj.SetURI(obj.GetURI())
+	return (*T)(obj), nil
+}
+
+// Create posts obj to collection (e.g. /repositories/2/accessions) and sets
+// its URI from the response
+func Create[M JSONModel](api *ArchivesSpaceAPI, collection string, obj M) (*ResponseMsg, error) {
+	u := api.callURL(collection)
+	responseMsg, err := api.CreateAPI(u.String(), obj)
+	if err != nil {
+		return nil, fmt.Errorf("Create(%q) %w", collection, err)
+	}
+	if responseMsg.URI != "" {
+		obj.SetURI(responseMsg.URI)
+	}
+	return responseMsg, nil
+}
+
+// Update saves obj back to its URI
+func Update[M JSONModel](api *ArchivesSpaceAPI, obj M) (*ResponseMsg, error) {
+	uri := obj.GetURI()
+	if uri == "" {
+		return nil, fmt.Errorf("Update() record has no URI")
+	}
+	u := api.callURL(uri)
+	responseMsg, err := api.UpdateAPI(u.String(), obj)
+	if err != nil {
+		return nil, fmt.Errorf("Update(%q) %w", uri, err)
+	}
+	return responseMsg, nil
+}
+
+// UpdateWith fetches the record at uri, passes it to mutate then saves it. If the save
+// fails because the record was changed by someone else (a stale lock_version) the record
+// is fetched again and mutate reapplied, up to api.ConflictRetries times. mutate should
+// only change the fields it means to so it can safely be called more than once.
+func UpdateWith[T any, PT modelPtr[T]](api *ArchivesSpaceAPI, uri string, mutate func(*T) error) (*ResponseMsg, error) {
+	return updateWith[T, PT](api, uri, api.ConflictRetries, mutate)
+}
+
+// updateWith is UpdateWith retrying up to retries times after a lock_version conflict
+func updateWith[T any, PT modelPtr[T]](api *ArchivesSpaceAPI, uri string, retries int, mutate func(*T) error) (*ResponseMsg, error) {
+	for attempt := 0; ; attempt++ {
+		obj, err := Get[T, PT](api, uri)
+		if err != nil {
+			return nil, fmt.Errorf("UpdateWith(%q) %w", uri, err)
+		}
+		if err := mutate(obj); err != nil {
+			return nil, fmt.Errorf("UpdateWith(%q) %w", uri, err)
+		}
+		// mutate isn't allowed to move the record
+		PT(obj).SetURI(uri)
+		u := api.callURL(uri)
+		responseMsg, err := api.UpdateAPI(u.String(), obj)
+		if err == nil && isLockConflict(responseMsg) == false {
+			return responseMsg, nil
+		}
+		if err == nil {
+			err = fmt.Errorf("%v", responseMsg.Error)
+		} else if isLockConflictError(err) == false {
+			return nil, fmt.Errorf("UpdateWith(%q) %w", uri, err)
+		}
+		if attempt >= retries {
+			return nil, fmt.Errorf("UpdateWith(%q) lock_version conflict after %d attempts, %w", uri, attempt+1, err)
+		}
+		api.logf("%s changed while updating, retrying (attempt %d of %d)", uri, attempt+1, retries+1)
+	}
+}
+
+// Delete removes obj from ArchivesSpace
+func Delete[M JSONModel](api *ArchivesSpaceAPI, obj M) (*ResponseMsg, error) {
+	uri := obj.GetURI()
+	if uri == "" {
+		return nil, fmt.Errorf("Delete() record has no URI")
+	}
+	u := api.callURL(uri)
+	responseMsg, err := api.DeleteAPI(u.String(), nil)
+	if err != nil {
+		return nil, fmt.Errorf("Delete(%q) %w", uri, err)
+	}
+	return responseMsg, nil
+}
+
+// List retrieves every record in collection, e.g. List[Subject](api, "/subjects")
+func List[T any, PT modelPtr[T]](api *ArchivesSpaceAPI, collection string) ([]*T, error) {
+	u := api.callURL(collection)
+	q := u.Query()
+	q.Set("all_ids", "true")
+	u.RawQuery = q.Encode()
+	ids, err := api.ListAPI(u.String())
+	if err != nil {
+		return nil, fmt.Errorf("List(%q) %w", collection, err)
+	}
+	records := make([]*T, 0, len(ids))
+	for _, id := range ids {
+		obj, err := Get[T, PT](api, fmt.Sprintf("%s/%d", collection, id))
+		if err != nil {
+			return nil, fmt.Errorf("List(%q) %w", collection, err)
+		}
+		records = append(records, obj)
+	}
+	return records, nil
+}
+
+var (
+	jsonModelsMu sync.RWMutex
+	jsonModels   = map[string]func() interface{}{
+		"accession":                func() interface{} { return new(Accession) },
+		"agent_corporate_entity":   func() interface{} { return new(AgentCorporateEntity) },
+		"agent_family":             func() interface{} { return new(AgentFamily) },
+		"agent_person":             func() interface{} { return new(AgentPerson) },
+		"agent_software":           func() interface{} { return new(AgentSoftware) },
+		"archival_object":          func() interface{} { return new(ArchivalObject) },
+		"assessment":               func() interface{} { return new(Assessment) },
+		"classification":           func() interface{} { return new(Classification) },
+		"classification_term":      func() interface{} { return new(ClassificationTerm) },
+		"collection_management":    func() interface{} { return new(CollectionManagement) },
+		"container_profile":        func() interface{} { return new(ContainerProfile) },
+		"custom_report_template":   func() interface{} { return new(CustomReportTemplate) },
+		"default_values":           func() interface{} { return new(DefaultValues) },
+		"digital_object":           func() interface{} { return new(DigitalObject) },
+		"digital_object_component": func() interface{} { return new(DigitalObjectComponent) },
+		"enumeration":              func() interface{} { return new(Enumeration) },
+		"enumeration_value":        func() interface{} { return new(EnumerationValue) },
+		"event":                    func() interface{} { return new(Event) },
+		"group":                    func() interface{} { return new(Group) },
+		"job":                      func() interface{} { return new(Job) },
+		"location":                 func() interface{} { return new(Location) },
+		"location_profile":         func() interface{} { return new(LocationProfile) },
+		"oai_config":               func() interface{} { return new(OAIConfig) },
+		"permission":               func() interface{} { return new(Permission) },
+		"preference":               func() interface{} { return new(Preference) },
+		"rde_template":             func() interface{} { return new(RdeTemplate) },
+		"repository":               func() interface{} { return new(Repository) },
+		"required_fields":          func() interface{} { return new(RequiredFields) },
+		"resource":                 func() interface{} { return new(Resource) },
+		"subject":                  func() interface{} { return new(Subject) },
+		"term":                     func() interface{} { return new(Term) },
+		"top_container":            func() interface{} { return new(TopContainer) },
+		"user":                     func() interface{} { return new(User) },
+		"vocabulary":               func() interface{} { return new(Vocabulary) },
+	}
+)
+
+// RegisterJSONModel maps a jsonmodel_type (e.g. "accession") to a function returning
+// a pointer to the Go value DecodeJSONModel should decode it into. It is an error to
+// register the same name twice, call UnregisterJSONModel first to replace a type.
+func RegisterJSONModel(name string, newModel func() interface{}) error {
+	if name == "" || newModel == nil {
+		return fmt.Errorf("RegisterJSONModel() requires a name and a function")
+	}
+	jsonModelsMu.Lock()
+	defer jsonModelsMu.Unlock()
+	if _, ok := jsonModels[name]; ok == true {
+		return fmt.Errorf("RegisterJSONModel(%q) already registered", name)
+	}
+	jsonModels[name] = newModel
+	return nil
+}
+
+// UnregisterJSONModel removes a jsonmodel_type from the registry
+func UnregisterJSONModel(name string) {
+	jsonModelsMu.Lock()
+	defer jsonModelsMu.Unlock()
+	delete(jsonModels, name)
+}
+
+// JSONModelTypes returns the sorted jsonmodel_type names DecodeJSONModel knows about
+func JSONModelTypes() []string {
+	jsonModelsMu.RLock()
+	defer jsonModelsMu.RUnlock()
+	names := []string{}
+	for name := range jsonModels {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// DecodeJSONModel unmarshals a record into the Go type registered for its
+// jsonmodel_type, e.g. an accession is returned as *Accession. Records with
+// a missing or unregistered jsonmodel_type are returned as an Object.
+func DecodeJSONModel(content []byte) (interface{}, error) {
+	peek := struct {
+		JSONModelType string `json:"jsonmodel_type"`
+	}{}
+	if err := json.Unmarshal(content, &peek); err != nil {
+		return nil, fmt.Errorf("DecodeJSONModel() %w", err)
+	}
+	jsonModelsMu.RLock()
+	newModel, ok := jsonModels[peek.JSONModelType]
+	jsonModelsMu.RUnlock()
+	if ok == false {
+		obj := Object{}
+		if err := json.Unmarshal(content, &obj); err != nil {
+			return nil, fmt.Errorf("DecodeJSONModel() %w", err)
+		}
+		return obj, nil
+	}
+	obj := newModel()
+	if err := json.Unmarshal(content, obj); err != nil {
+		return nil, fmt.Errorf("DecodeJSONModel() %s, %w", peek.JSONModelType, err)
+	}
+	if model, ok := obj.(JSONModel); ok == true {
+		model.SetURI(model.GetURI())
+	}
+	return obj, nil
+}
+
+// GetURI returns the value of the object's uri
+func (obj Object) GetURI() string {
+	uri, _ := obj["uri"].(string)
+	return uri
+}
+
+// SetURI sets the object's uri
+func (obj Object) SetURI(uri string) {
+	obj["uri"] = uri
+}
+
+// GetURI returns the Accession's URI
+func (obj *Accession) GetURI() string {
+	return obj.URI
+}
+
+// SetURI sets the Accession's URI and ID
+func (obj *Accession) SetURI(uri string) {
+	obj.URI = uri
+	obj.ID = URIToID(uri)
+}
+
+// GetURI returns the Agent's URI
+func (obj *Agent) GetURI() string {
+	return obj.URI
+}
+
+// SetURI sets the Agent's URI and ID
+func (obj *Agent) SetURI(uri string) {
+	obj.URI = uri
+	obj.ID = URIToID(uri)
+}
+
+// GetURI returns the AgentCorporateEntity's URI
+func (obj *AgentCorporateEntity) GetURI() string {
+	return obj.URI
+}
+
+// SetURI sets the AgentCorporateEntity's URI and ID
+func (obj *AgentCorporateEntity) SetURI(uri string) {
+	obj.URI = uri
+	obj.ID = URIToID(uri)
+}
+
+// GetURI returns the AgentFamily's URI
+func (obj *AgentFamily) GetURI() string {
+	return obj.URI
+}
+
+// SetURI sets the AgentFamily's URI and ID
+func (obj *AgentFamily) SetURI(uri string) {
+	obj.URI = uri
+	obj.ID = URIToID(uri)
+}
+
+// GetURI returns the AgentPerson's URI
+func (obj *AgentPerson) GetURI() string {
+	return obj.URI
+}
+
+// SetURI sets the AgentPerson's URI and ID
+func (obj *AgentPerson) SetURI(uri string) {
+	obj.URI = uri
+	obj.ID = URIToID(uri)
+}
+
+// GetURI returns the AgentSoftware's URI
+func (obj *AgentSoftware) GetURI() string {
+	return obj.URI
+}
+
+// SetURI sets the AgentSoftware's URI and ID
+func (obj *AgentSoftware) SetURI(uri string) {
+	obj.URI = uri
+	obj.ID = URIToID(uri)
+}
+
+// GetURI returns the ArchivalObject's URI
+func (obj *ArchivalObject) GetURI() string {
+	return obj.URI
+}
+
+// SetURI sets the ArchivalObject's URI and ID
+func (obj *ArchivalObject) SetURI(uri string) {
+	obj.URI = uri
+	obj.ID = URIToID(uri)
+}
+
+// GetURI returns the Assessment's URI
+func (obj *Assessment) GetURI() string {
+	return obj.URI
+}
+
+// SetURI sets the Assessment's URI and ID
+func (obj *Assessment) SetURI(uri string) {
+	obj.URI = uri
+	obj.ID = URIToID(uri)
+}
+
+// GetURI returns the Classification's URI
+func (obj *Classification) GetURI() string {
+	return obj.URI
+}
+
+// SetURI sets the Classification's URI and ID
+func (obj *Classification) SetURI(uri string) {
+	obj.URI = uri
+	obj.ID = URIToID(uri)
+}
+
+// GetURI returns the ClassificationTerm's URI
+func (obj *ClassificationTerm) GetURI() string {
+	return obj.URI
+}
+
+// SetURI sets the ClassificationTerm's URI and ID
+func (obj *ClassificationTerm) SetURI(uri string) {
+	obj.URI = uri
+	obj.ID = URIToID(uri)
+}
+
+// GetURI returns the CollectionManagement's URI
+func (obj *CollectionManagement) GetURI() string {
+	return obj.URI
+}
+
+// SetURI sets the CollectionManagement's URI and ID
+func (obj *CollectionManagement) SetURI(uri string) {
+	obj.URI = uri
+	obj.ID = URIToID(uri)
+}
+
+// GetURI returns the ContainerProfile's URI
+func (obj *ContainerProfile) GetURI() string {
+	return obj.URI
+}
+
+// SetURI sets the ContainerProfile's URI and ID
+func (obj *ContainerProfile) SetURI(uri string) {
+	obj.URI = uri
+	obj.ID = URIToID(uri)
+}
+
+// GetURI returns the CustomReportTemplate's URI
+func (obj *CustomReportTemplate) GetURI() string {
+	return obj.URI
+}
+
+// SetURI sets the CustomReportTemplate's URI and ID
+func (obj *CustomReportTemplate) SetURI(uri string) {
+	obj.URI = uri
+	obj.ID = URIToID(uri)
+}
+
+// GetURI returns the DefaultValues's URI
+func (obj *DefaultValues) GetURI() string {
+	return obj.URI
+}
+
+// SetURI sets the DefaultValues's URI
+func (obj *DefaultValues) SetURI(uri string) {
+	obj.URI = uri
+}
+
+// GetURI returns the DigitalObject's URI
+func (obj *DigitalObject) GetURI() string {
+	return obj.URI
+}
+
+// SetURI sets the DigitalObject's URI and ID
+func (obj *DigitalObject) SetURI(uri string) {
+	obj.URI = uri
+	obj.ID = URIToID(uri)
+}
+
+// GetURI returns the DigitalObjectComponent's URI
+func (obj *DigitalObjectComponent) GetURI() string {
+	return obj.URI
+}
+
+// SetURI sets the DigitalObjectComponent's URI and ID
+func (obj *DigitalObjectComponent) SetURI(uri string) {
+	obj.URI = uri
+	obj.ID = URIToID(uri)
+}
+
+// GetURI returns the Enumeration's URI
+func (obj *Enumeration) GetURI() string {
+	return obj.URI
+}
+
+// SetURI sets the Enumeration's URI and ID
+func (obj *Enumeration) SetURI(uri string) {
+	obj.URI = uri
+	obj.ID = URIToID(uri)
+}
+
+// GetURI returns the EnumerationValue's URI
+func (obj *EnumerationValue) GetURI() string {
+	return obj.URI
+}
+
+// SetURI sets the EnumerationValue's URI and ID
+func (obj *EnumerationValue) SetURI(uri string) {
+	obj.URI = uri
+	obj.ID = URIToID(uri)
+}
+
+// GetURI returns the Event's URI
+func (obj *Event) GetURI() string {
+	return obj.URI
+}
+
+// SetURI sets the Event's URI and ID
+func (obj *Event) SetURI(uri string) {
+	obj.URI = uri
+	obj.ID = URIToID(uri)
+}
+
+// GetURI returns the Group's URI
+func (obj *Group) GetURI() string {
+	return obj.URI
+}
+
+// SetURI sets the Group's URI and ID
+func (obj *Group) SetURI(uri string) {
+	obj.URI = uri
+	obj.ID = URIToID(uri)
+}
+
+// GetURI returns the Job's URI
+func (obj *Job) GetURI() string {
+	return obj.URI
+}
+
+// SetURI sets the Job's URI and ID
+func (obj *Job) SetURI(uri string) {
+	obj.URI = uri
+	obj.ID = URIToID(uri)
+}
+
+// GetURI returns the Location's URI
+func (obj *Location) GetURI() string {
+	return obj.URI
+}
+
+// SetURI sets the Location's URI and ID
+func (obj *Location) SetURI(uri string) {
+	obj.URI = uri
+	obj.ID = URIToID(uri)
+}
+
+// GetURI returns the LocationProfile's URI
+func (obj *LocationProfile) GetURI() string {
+	return obj.URI
+}
+
+// SetURI sets the LocationProfile's URI and ID
+func (obj *LocationProfile) SetURI(uri string) {
+	obj.URI = uri
+	obj.ID = URIToID(uri)
+}
+
+// GetURI returns the OAIConfig's URI
+func (obj *OAIConfig) GetURI() string {
+	return obj.URI
+}
+
+// SetURI sets the OAIConfig's URI
+func (obj *OAIConfig) SetURI(uri string) {
+	obj.URI = uri
+}
+
+// GetURI returns the Permission's URI
+func (obj *Permission) GetURI() string {
+	return obj.URI
+}
+
+// SetURI sets the Permission's URI
+func (obj *Permission) SetURI(uri string) {
+	obj.URI = uri
+}
+
+// GetURI returns the Preference's URI
+func (obj *Preference) GetURI() string {
+	return obj.URI
+}
+
+// SetURI sets the Preference's URI and ID
+func (obj *Preference) SetURI(uri string) {
+	obj.URI = uri
+	obj.ID = URIToID(uri)
+}
+
+// GetURI returns the RdeTemplate's URI
+func (obj *RdeTemplate) GetURI() string {
+	return obj.URI
+}
+
+// SetURI sets the RdeTemplate's URI
+func (obj *RdeTemplate) SetURI(uri string) {
+	obj.URI = uri
+}
+
+// GetURI returns the Repository's URI
+func (obj *Repository) GetURI() string {
+	return obj.URI
+}
+
+// SetURI sets the Repository's URI and ID
+func (obj *Repository) SetURI(uri string) {
+	obj.URI = uri
+	obj.ID = URIToID(uri)
+}
+
+// GetURI returns the RequiredFields's URI
+func (obj *RequiredFields) GetURI() string {
+	return obj.URI
+}
+
+// SetURI sets the RequiredFields's URI
+func (obj *RequiredFields) SetURI(uri string) {
+	obj.URI = uri
+}
+
+// GetURI returns the Resource's URI
+func (obj *Resource) GetURI() string {
+	return obj.URI
+}
+
+// SetURI sets the Resource's URI and ID
+func (obj *Resource) SetURI(uri string) {
+	obj.URI = uri
+	obj.ID = URIToID(uri)
+}
+
+// GetURI returns the Subject's URI
+func (obj *Subject) GetURI() string {
+	return obj.URI
+}
+
+// SetURI sets the Subject's URI and ID
+func (obj *Subject) SetURI(uri string) {
+	obj.URI = uri
+	obj.ID = URIToID(uri)
+}
+
+// GetURI returns the Term's URI
+func (obj *Term) GetURI() string {
+	return obj.URI
+}
+
+// SetURI sets the Term's URI and ID
+func (obj *Term) SetURI(uri string) {
+	obj.URI = uri
+	obj.ID = URIToID(uri)
+}
+
+// GetURI returns the TopContainer's URI
+func (obj *TopContainer) GetURI() string {
+	return obj.URI
+}
+
+// SetURI sets the TopContainer's URI and ID
+func (obj *TopContainer) SetURI(uri string) {
+	obj.URI = uri
+	obj.ID = URIToID(uri)
+}
+
+// GetURI returns the User's URI
+func (obj *User) GetURI() string {
+	return obj.URI
+}
+
+// SetURI sets the User's URI and ID
+func (obj *User) SetURI(uri string) {
+	obj.URI = uri
+	obj.ID = URIToID(uri)
+}
+
+// GetURI returns the Vocabulary's URI
+func (obj *Vocabulary) GetURI() string {
+	return obj.URI
+}
+
+// SetURI sets the Vocabulary's URI and ID
+func (obj *Vocabulary) SetURI(uri string) {
+	obj.URI = uri
+	obj.ID = URIToID(uri)
+}
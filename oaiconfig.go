@@ -0,0 +1,88 @@
+//
+// Package cait is a collection of structures and functions
+// for interacting with ArchivesSpace's REST API
+//
+// @author R. S. Doiel, <rsdoiel@caltech.edu>
+//
+// Copyright (c) 2017, Caltech
+// All rights not granted herein are expressly reserved by Caltech.
+//
+// Redistribution and use in source and binary forms, with or without modification, are permitted provided that the following conditions are met:
+//
+// 1. Redistributions of source code must retain the above copyright notice, this list of conditions and the following disclaimer.
+//
+// 2. Redistributions in binary form must reproduce the above copyright notice, this list of conditions and the following disclaimer in the documentation and/or other materials provided with the distribution.
+//
+// 3. Neither the name of the copyright holder nor the names of its contributors may be used to endorse or promote products derived from this software without specific prior written permission.
+//
+// THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS" AND ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE ARE DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT HOLDER OR CONTRIBUTORS BE LIABLE FOR ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL DAMAGES (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR SERVICES; LOSS OF USE, DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER CAUSED AND ON ANY THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT LIABILITY, OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE OF THIS SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+//
+package cait
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// GetOAIConfig returns the OAI-PMH configuration
+func (api *ArchivesSpaceAPI) GetOAIConfig() (*OAIConfig, error) {
+	u := api.callURL("/oai_config")
+	config := new(OAIConfig)
+	if err := api.GetAPI(u.String(), config); err != nil {
+		return nil, fmt.Errorf("GetOAIConfig() %w", err)
+	}
+	return config, nil
+}
+
+// UpdateOAIConfig saves the OAI-PMH configuration, the lock version is updated on success
+func (api *ArchivesSpaceAPI) UpdateOAIConfig(config *OAIConfig) (*ResponseMsg, error) {
+	u := api.callURL("/oai_config")
+	config.JSONModelType = "oai_config"
+	responseMsg, err := api.UpdateAPI(u.String(), config)
+	if err != nil {
+		return nil, fmt.Errorf("UpdateOAIConfig() %w", err)
+	}
+	config.LockVersion = responseMsg.LockVersion
+	return responseMsg, nil
+}
+
+// decodeList decodes a JSON encoded list of strings, an empty string is an empty list
+func decodeList(s string) ([]string, error) {
+	list := []string{}
+	if s == "" {
+		return list, nil
+	}
+	if err := json.Unmarshal([]byte(s), &list); err != nil {
+		return nil, err
+	}
+	return list, nil
+}
+
+// encodeList encodes list as JSON
+func encodeList(list []string) string {
+	if list == nil {
+		list = []string{}
+	}
+	src, _ := json.Marshal(list)
+	return string(src)
+}
+
+// GetRepoSetCodes returns the codes of the repositories in the repository set
+func (config *OAIConfig) GetRepoSetCodes() ([]string, error) {
+	return decodeList(config.RepoSetCodes)
+}
+
+// SetRepoSetCodes sets the codes of the repositories in the repository set
+func (config *OAIConfig) SetRepoSetCodes(codes []string) {
+	config.RepoSetCodes = encodeList(codes)
+}
+
+// GetSponsorSetNames returns the sponsor names making up the sponsor set
+func (config *OAIConfig) GetSponsorSetNames() ([]string, error) {
+	return decodeList(config.SponsorSetNames)
+}
+
+// SetSponsorSetNames sets the sponsor names making up the sponsor set
+func (config *OAIConfig) SetSponsorSetNames(names []string) {
+	config.SponsorSetNames = encodeList(names)
+}
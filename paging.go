@@ -0,0 +1,247 @@
+//
+// Package cait is a collection of structures and functions
+// for interacting with ArchivesSpace's REST API
+//
+// @author R. S. Doiel, <rsdoiel@caltech.edu>
+//
+// Copyright (c) 2017, Caltech
+// All rights not granted herein are expressly reserved by Caltech.
+//
+// Redistribution and use in source and binary forms, with or without modification, are permitted provided that the following conditions are met:
+//
+// 1. Redistributions of source code must retain the above copyright notice, this list of conditions and the following disclaimer.
+//
+// 2. Redistributions in binary form must reproduce the above copyright notice, this list of conditions and the following disclaimer in the documentation and/or other materials provided with the distribution.
+//
+// 3. Neither the name of the copyright holder nor the names of its contributors may be used to endorse or promote products derived from this software without specific prior written permission.
+//
+// THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS" AND ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE ARE DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT HOLDER OR CONTRIBUTORS BE LIABLE FOR ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL DAMAGES (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR SERVICES; LOSS OF USE, DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER CAUSED AND ON ANY THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT LIABILITY, OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE OF THIS SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+//
+package cait
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"time"
+)
+
+// DefaultPageSize is the page size used by the paged listings when none is given
+const DefaultPageSize = 10
+
+// eachPageSize is the page size used by the Each functions of ArchivesSpaceAPI
+const eachPageSize = 100
+
+// ErrStopIteration can be returned by an Each function's callback to stop early without an error
+var ErrStopIteration = errors.New("stop iteration")
+
+// Page is one page of full records from a paged ArchivesSpace listing
+type Page[T any] struct {
+	FirstPage int `json:"first_page"`
+	LastPage  int `json:"last_page"`
+	ThisPage  int `json:"this_page"`
+	// Total is the number of records across all pages, the listing's
+	// equivalent of total_hits in search results
+	Total   int  `json:"total"`
+	Results []*T `json:"results"`
+}
+
+// setModifiedSince limits a listing to records changed since the latest of the times
+// given, zero times are ignored
+func setModifiedSince(q url.Values, modifiedSince []time.Time) {
+	var since time.Time
+	for _, t := range modifiedSince {
+		if t.After(since) {
+			since = t
+		}
+	}
+	if since.IsZero() == false {
+		q.Set("modified_since", fmt.Sprintf("%d", since.Unix()))
+	}
+}
+
+// ListPage retrieves page (starting at 1) of the records in collection with
+// pageSize records per page, e.g. ListPage[Accession](api, "/repositories/2/accessions", 1, 50).
+// ArchivesSpace caps the page size, usually at 250. Give modifiedSince to list only
+// the records changed since then.
+func ListPage[T any, PT modelPtr[T]](api *ArchivesSpaceAPI, collection string, page, pageSize int, modifiedSince ...time.Time) (*Page[T], error) {
+	if page < 1 {
+		page = 1
+	}
+	if pageSize < 1 {
+		pageSize = DefaultPageSize
+	}
+	u := api.callURL(collection)
+	q := u.Query()
+	q.Set("page", fmt.Sprintf("%d", page))
+	q.Set("page_size", fmt.Sprintf("%d", pageSize))
+	setModifiedSince(q, modifiedSince)
+	u.RawQuery = q.Encode()
+
+	results := new(Page[T])
+	if err := api.GetAPI(u.String(), results); err != nil {
+		return nil, fmt.Errorf("ListPage(%q, %d, %d) %w", collection, page, pageSize, err)
+	}
+	for _, obj := range results.Results {
+		PT(obj).SetURI(PT(obj).GetURI())
+	}
+	return results, nil
+}
+
+// ListAgentsPage returns a page of agents of agentType (e.g. people, corporate_entities)
+func (api *ArchivesSpaceAPI) ListAgentsPage(agentType string, page, pageSize int, modifiedSince ...time.Time) (*Page[Agent], error) {
+	return ListPage[Agent](api, fmt.Sprintf("/agents/%s", agentType), page, pageSize, modifiedSince...)
+}
+
+// ListAccessionsPage returns a page of Accession records from a Repository
+func (api *ArchivesSpaceAPI) ListAccessionsPage(repoID, page, pageSize int, modifiedSince ...time.Time) (*Page[Accession], error) {
+	return ListPage[Accession](api, fmt.Sprintf("/repositories/%d/accessions", repoID), page, pageSize, modifiedSince...)
+}
+
+// ListResourcesPage returns a page of Resource records from a Repository
+func (api *ArchivesSpaceAPI) ListResourcesPage(repoID, page, pageSize int, modifiedSince ...time.Time) (*Page[Resource], error) {
+	return ListPage[Resource](api, fmt.Sprintf("/repositories/%d/resources", repoID), page, pageSize, modifiedSince...)
+}
+
+// ListDigitalObjectsPage returns a page of DigitalObject records from a Repository
+func (api *ArchivesSpaceAPI) ListDigitalObjectsPage(repoID, page, pageSize int, modifiedSince ...time.Time) (*Page[DigitalObject], error) {
+	return ListPage[DigitalObject](api, fmt.Sprintf("/repositories/%d/digital_objects", repoID), page, pageSize, modifiedSince...)
+}
+
+// ListTopContainersPage returns a page of TopContainer records from a Repository
+func (api *ArchivesSpaceAPI) ListTopContainersPage(repoID, page, pageSize int, modifiedSince ...time.Time) (*Page[TopContainer], error) {
+	return ListPage[TopContainer](api, fmt.Sprintf("/repositories/%d/top_containers", repoID), page, pageSize, modifiedSince...)
+}
+
+// ListSubjectsPage returns a page of Subject records
+func (api *ArchivesSpaceAPI) ListSubjectsPage(page, pageSize int, modifiedSince ...time.Time) (*Page[Subject], error) {
+	return ListPage[Subject](api, "/subjects", page, pageSize, modifiedSince...)
+}
+
+// ListLocationsPage returns a page of Location records
+func (api *ArchivesSpaceAPI) ListLocationsPage(page, pageSize int, modifiedSince ...time.Time) (*Page[Location], error) {
+	return ListPage[Location](api, "/locations", page, pageSize, modifiedSince...)
+}
+
+// Iterator steps through every record of a paged listing fetching a page at a time,
+// so large collections can be processed without loading all the IDs up front.
+//
+//	it := NewIterator[Accession](api, "/repositories/2/accessions", 100)
+//	for it.Next() {
+//		accession := it.Record()
+//		...
+//	}
+//	if err := it.Err(); err != nil {
+//		...
+//	}
+type Iterator[T any] struct {
+	fetch   func(page int) (*Page[T], error)
+	page    *Page[T]
+	i       int
+	current *T
+	err     error
+}
+
+// NewIterator returns an Iterator over the records in collection fetching pageSize records
+// at a time. Give modifiedSince to iterate over only the records changed since then.
+func NewIterator[T any, PT modelPtr[T]](api *ArchivesSpaceAPI, collection string, pageSize int, modifiedSince ...time.Time) *Iterator[T] {
+	return &Iterator[T]{
+		fetch: func(page int) (*Page[T], error) {
+			return ListPage[T, PT](api, collection, page, pageSize, modifiedSince...)
+		},
+	}
+}
+
+// Next advances to the next record returning false when there are no more or an error occurred
+func (it *Iterator[T]) Next() bool {
+	if it.err != nil {
+		return false
+	}
+	for it.page == nil || it.i >= len(it.page.Results) {
+		next := 1
+		if it.page != nil {
+			if it.page.ThisPage >= it.page.LastPage {
+				it.current = nil
+				return false
+			}
+			next = it.page.ThisPage + 1
+		}
+		it.page, it.err = it.fetch(next)
+		if it.err != nil {
+			it.current = nil
+			return false
+		}
+		it.i = 0
+	}
+	it.current = it.page.Results[it.i]
+	it.i++
+	return true
+}
+
+// Record returns the current record
+func (it *Iterator[T]) Record() *T {
+	return it.current
+}
+
+// Total returns the number of records in the listing, it is zero until Next is called
+func (it *Iterator[T]) Total() int {
+	if it.page == nil {
+		return 0
+	}
+	return it.page.Total
+}
+
+// Err returns the error, if any, which stopped the iteration
+func (it *Iterator[T]) Err() error {
+	return it.err
+}
+
+// Each calls fn with every record in collection, fetching pageSize records at a time.
+// Iteration stops at the first error from fn which is returned unless it is ErrStopIteration.
+func Each[T any, PT modelPtr[T]](api *ArchivesSpaceAPI, collection string, pageSize int, fn func(*T) error) error {
+	it := NewIterator[T, PT](api, collection, pageSize)
+	for it.Next() {
+		if err := fn(it.Record()); err != nil {
+			if errors.Is(err, ErrStopIteration) == true {
+				return nil
+			}
+			return err
+		}
+	}
+	return it.Err()
+}
+
+// EachAgent calls fn with every agent of agentType (e.g. people, corporate_entities)
+func (api *ArchivesSpaceAPI) EachAgent(agentType string, fn func(*Agent) error) error {
+	return Each[Agent](api, fmt.Sprintf("/agents/%s", agentType), eachPageSize, fn)
+}
+
+// EachAccession calls fn with every Accession record in a Repository
+func (api *ArchivesSpaceAPI) EachAccession(repoID int, fn func(*Accession) error) error {
+	return Each[Accession](api, fmt.Sprintf("/repositories/%d/accessions", repoID), eachPageSize, fn)
+}
+
+// EachResource calls fn with every Resource record in a Repository
+func (api *ArchivesSpaceAPI) EachResource(repoID int, fn func(*Resource) error) error {
+	return Each[Resource](api, fmt.Sprintf("/repositories/%d/resources", repoID), eachPageSize, fn)
+}
+
+// EachDigitalObject calls fn with every DigitalObject record in a Repository
+func (api *ArchivesSpaceAPI) EachDigitalObject(repoID int, fn func(*DigitalObject) error) error {
+	return Each[DigitalObject](api, fmt.Sprintf("/repositories/%d/digital_objects", repoID), eachPageSize, fn)
+}
+
+// EachTopContainer calls fn with every TopContainer record in a Repository
+func (api *ArchivesSpaceAPI) EachTopContainer(repoID int, fn func(*TopContainer) error) error {
+	return Each[TopContainer](api, fmt.Sprintf("/repositories/%d/top_containers", repoID), eachPageSize, fn)
+}
+
+// EachSubject calls fn with every Subject record
+func (api *ArchivesSpaceAPI) EachSubject(fn func(*Subject) error) error {
+	return Each[Subject](api, "/subjects", eachPageSize, fn)
+}
+
+// EachLocation calls fn with every Location record
+func (api *ArchivesSpaceAPI) EachLocation(fn func(*Location) error) error {
+	return Each[Location](api, "/locations", eachPageSize, fn)
+}
@@ -0,0 +1,164 @@
+//
+// Package cait is a collection of structures and functions
+// for interacting with ArchivesSpace's REST API
+//
+// @author R. S. Doiel, <rsdoiel@caltech.edu>
+//
+// Copyright (c) 2017, Caltech
+// All rights not granted herein are expressly reserved by Caltech.
+//
+// Redistribution and use in source and binary forms, with or without modification, are permitted provided that the following conditions are met:
+//
+// 1. Redistributions of source code must retain the above copyright notice, this list of conditions and the following disclaimer.
+//
+// 2. Redistributions in binary form must reproduce the above copyright notice, this list of conditions and the following disclaimer in the documentation and/or other materials provided with the distribution.
+//
+// 3. Neither the name of the copyright holder nor the names of its contributors may be used to endorse or promote products derived from this software without specific prior written permission.
+//
+// THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS" AND ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE ARE DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT HOLDER OR CONTRIBUTORS BE LIABLE FOR ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL DAMAGES (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR SERVICES; LOSS OF USE, DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER CAUSED AND ON ANY THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT LIABILITY, OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE OF THIS SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+//
+package cait
+
+import (
+	"fmt"
+)
+
+// GlobalRepositoryID is the ID of the repository holding global (instance wide) records
+// such as the global preferences
+const GlobalRepositoryID = 1
+
+// currentPreferences retrieves the merged preferences at u
+func (api *ArchivesSpaceAPI) currentPreferences(u string) (*Defaults, error) {
+	current := struct {
+		Defaults *Defaults `json:"defaults"`
+	}{}
+	if err := api.GetAPI(u, &current); err != nil {
+		return nil, err
+	}
+	if current.Defaults == nil {
+		return nil, fmt.Errorf("no defaults in response")
+	}
+	return current.Defaults, nil
+}
+
+// GetCurrentGlobalPreferences returns the preferences in effect across the instance
+func (api *ArchivesSpaceAPI) GetCurrentGlobalPreferences() (*Defaults, error) {
+	u := api.callURL("/current_global_preferences")
+	defaults, err := api.currentPreferences(u.String())
+	if err != nil {
+		return nil, fmt.Errorf("GetCurrentGlobalPreferences() %w", err)
+	}
+	return defaults, nil
+}
+
+// GetCurrentPreferences returns the preferences in effect for the current user in a
+// repository, the global, repository and user preferences merged
+func (api *ArchivesSpaceAPI) GetCurrentPreferences(repoID int) (*Defaults, error) {
+	u := api.callURL(fmt.Sprintf("/repositories/%d/current_preferences", repoID))
+	defaults, err := api.currentPreferences(u.String())
+	if err != nil {
+		return nil, fmt.Errorf("GetCurrentPreferences(%d) %w", repoID, err)
+	}
+	return defaults, nil
+}
+
+// GetPreferenceDefaults returns the defaults a user (the current user if username is
+// empty) inherits in a repository before their own preferences are applied
+func (api *ArchivesSpaceAPI) GetPreferenceDefaults(repoID int, username string) (*Defaults, error) {
+	u := api.callURL(fmt.Sprintf("/repositories/%d/preferences/defaults", repoID))
+	if username != "" {
+		q := u.Query()
+		q.Set("username", username)
+		u.RawQuery = q.Encode()
+	}
+	defaults := new(Defaults)
+	if err := api.GetAPI(u.String(), defaults); err != nil {
+		return nil, fmt.Errorf("GetPreferenceDefaults(%d, %q) %w", repoID, username, err)
+	}
+	return defaults, nil
+}
+
+// NewPreference returns a Preference holding defaults, for the user userID or,
+// when userID is 0, for everyone in the repository it is created in
+func NewPreference(userID int, defaults *Defaults) *Preference {
+	pref := new(Preference)
+	pref.JSONModelType = "preference"
+	pref.UserID = userID
+	pref.Defaults = defaults
+	if pref.Defaults != nil {
+		pref.Defaults.JSONModelType = "defaults"
+	}
+	return pref
+}
+
+// CreatePreference creates a preference in a repository, use GlobalRepositoryID
+// for the global preferences
+func (api *ArchivesSpaceAPI) CreatePreference(repoID int, pref *Preference) (*ResponseMsg, error) {
+	u := api.callURL(fmt.Sprintf("/repositories/%d/preferences", repoID))
+	pref.JSONModelType = "preference"
+	pref.LockVersion = "0"
+	responseMsg, err := api.CreateAPI(u.String(), pref)
+	if err != nil {
+		return nil, fmt.Errorf("CreatePreference(%d) %w", repoID, err)
+	}
+	pref.SetURI(responseMsg.URI)
+	pref.LockVersion = responseMsg.LockVersion
+	return responseMsg, nil
+}
+
+// GetPreference retrieves a preference from a repository
+func (api *ArchivesSpaceAPI) GetPreference(repoID, prefID int) (*Preference, error) {
+	u := api.callURL(fmt.Sprintf("/repositories/%d/preferences/%d", repoID, prefID))
+	pref := new(Preference)
+	if err := api.GetAPI(u.String(), pref); err != nil {
+		return nil, fmt.Errorf("GetPreference(%d, %d) %w", repoID, prefID, err)
+	}
+	pref.ID = URIToID(pref.URI)
+	return pref, nil
+}
+
+// UpdatePreference updates an existing preference
+func (api *ArchivesSpaceAPI) UpdatePreference(pref *Preference) (*ResponseMsg, error) {
+	u := api.callURL(pref.URI)
+	return api.UpdateAPI(u.String(), pref)
+}
+
+// DeletePreference deletes a preference
+func (api *ArchivesSpaceAPI) DeletePreference(pref *Preference) (*ResponseMsg, error) {
+	u := api.callURL(pref.URI)
+	return api.DeleteAPI(u.String(), pref)
+}
+
+// ListPreferences returns the preferences stored in a repository, a userID
+// other than 0 lists only that user's
+func (api *ArchivesSpaceAPI) ListPreferences(repoID, userID int) ([]*Preference, error) {
+	u := api.callURL(fmt.Sprintf("/repositories/%d/preferences", repoID))
+	if userID != 0 {
+		q := u.Query()
+		q.Set("user_id", fmt.Sprintf("%d", userID))
+		u.RawQuery = q.Encode()
+	}
+	prefs := []*Preference{}
+	if err := api.GetAPI(u.String(), &prefs); err != nil {
+		return nil, fmt.Errorf("ListPreferences(%d, %d) %w", repoID, userID, err)
+	}
+	for _, pref := range prefs {
+		pref.ID = URIToID(pref.URI)
+	}
+	return prefs, nil
+}
+
+// GetGlobalPreference returns the instance wide preference record, the one in the
+// global repository without a user, so it can be updated. It is nil if none exists.
+func (api *ArchivesSpaceAPI) GetGlobalPreference() (*Preference, error) {
+	prefs, err := api.ListPreferences(GlobalRepositoryID, 0)
+	if err != nil {
+		return nil, fmt.Errorf("GetGlobalPreference() %w", err)
+	}
+	for _, pref := range prefs {
+		if pref.UserID == 0 {
+			return pref, nil
+		}
+	}
+	return nil, nil
+}
@@ -0,0 +1,216 @@
+//
+// Package cait is a collection of structures and functions
+// for interacting with ArchivesSpace's REST API
+//
+// @author R. S. Doiel, <rsdoiel@caltech.edu>
+//
+// Copyright (c) 2017, Caltech
+// All rights not granted herein are expressly reserved by Caltech.
+//
+// Redistribution and use in source and binary forms, with or without modification, are permitted provided that the following conditions are met:
+//
+// 1. Redistributions of source code must retain the above copyright notice, this list of conditions and the following disclaimer.
+//
+// 2. Redistributions in binary form must reproduce the above copyright notice, this list of conditions and the following disclaimer in the documentation and/or other materials provided with the distribution.
+//
+// 3. Neither the name of the copyright holder nor the names of its contributors may be used to endorse or promote products derived from this software without specific prior written permission.
+//
+// THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS" AND ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE ARE DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT HOLDER OR CONTRIBUTORS BE LIABLE FOR ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL DAMAGES (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR SERVICES; LOSS OF USE, DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER CAUSED AND ON ANY THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT LIABILITY, OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE OF THIS SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+//
+package cait
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// RelabelOptions controls how RelabelTopContainers renumbers the containers of a resource
+type RelabelOptions struct {
+	// Type limits relabeling to containers of this type (e.g. box), empty means all types
+	Type string `json:"type,omitempty"`
+	// NewType, if set, replaces the container type of each relabeled container
+	NewType string `json:"new_type,omitempty"`
+	// Prefix is prepended to each new indicator (e.g. "A-")
+	Prefix string `json:"prefix,omitempty"`
+	// Start is the first number assigned, defaults to 1
+	Start int `json:"start,omitempty"`
+	// Width zero pads the number to the width given
+	Width int `json:"width,omitempty"`
+	// DryRun reports the mapping without saving any changes
+	DryRun bool `json:"dry_run,omitempty"`
+	// MaxRetries is the number of times a save is retried after a lock version conflict
+	MaxRetries int `json:"max_retries,omitempty"`
+}
+
+// ContainerRelabel is the old to new mapping for a single top container
+type ContainerRelabel struct {
+	URI          string `json:"uri"`
+	OldType      string `json:"old_type"`
+	OldIndicator string `json:"old_indicator"`
+	NewType      string `json:"new_type"`
+	NewIndicator string `json:"new_indicator"`
+}
+
+// ContainerRelabelReport describes the result of RelabelTopContainers
+type ContainerRelabelReport struct {
+	ResourceURI string              `json:"resource_uri"`
+	DryRun      bool                `json:"dry_run"`
+	Relabels    []*ContainerRelabel `json:"relabels"`
+	// Shared are containers skipped because they also hold material from other collections
+	Shared []string `json:"shared,omitempty"`
+	// Saved reports the outcome of saving each changed container, nil for a dry run
+	Saved *BatchEditReport `json:"saved,omitempty"`
+}
+
+// indicatorLess orders container indicators naturally so "Box 2" sorts before "Box 10"
+func indicatorLess(a, b string) bool {
+	na, restA := leadingNumber(a)
+	nb, restB := leadingNumber(b)
+	if na != nb {
+		return na < nb
+	}
+	return restA < restB
+}
+
+// leadingNumber splits off the first run of digits in s, returning -1 if there is none
+func leadingNumber(s string) (int, string) {
+	start := strings.IndexFunc(s, unicode.IsDigit)
+	if start < 0 {
+		return -1, s
+	}
+	end := start
+	for end < len(s) && unicode.IsDigit(rune(s[end])) {
+		end++
+	}
+	i, err := strconv.Atoi(s[start:end])
+	if err != nil {
+		return -1, s
+	}
+	return i, s[:start] + s[end:]
+}
+
+// formatIndicator returns the indicator for position n according to opts
+func formatIndicator(n int, opts *RelabelOptions) string {
+	if opts.Width > 0 {
+		return fmt.Sprintf("%s%0*d", opts.Prefix, opts.Width, n)
+	}
+	return fmt.Sprintf("%s%d", opts.Prefix, n)
+}
+
+// collectionTopContainers returns the top containers holding material from the collection uri
+func (api *ArchivesSpaceAPI) collectionTopContainers(repoID int, uri string) ([]*TopContainer, error) {
+	containers := []*TopContainer{}
+	req := NewSearchRequest("*").InRepository(repoID).OfType("top_container").Filter("collection_uri_u_sstr", uri)
+	for {
+		results, err := api.SearchRecords(req)
+		if err != nil {
+			return nil, err
+		}
+		for _, hit := range results.Results {
+			if hit.URI == "" {
+				continue
+			}
+			container, err := api.GetTopContainer(repoID, URIToID(hit.URI))
+			if err != nil {
+				return nil, err
+			}
+			containers = append(containers, container)
+		}
+		if len(results.Results) == 0 || results.HasNextPage() == false {
+			break
+		}
+		req.WithPage(results.ThisPage + 1)
+	}
+	return containers, nil
+}
+
+// RelabelTopContainers renumbers the top containers of a resource in their current
+// indicator order (e.g. Box 1..n after interfiling). Instances refer to top containers
+// by URI so every instance picks up the new label. Containers shared with other
+// collections are skipped and listed in the report rather than relabeled.
+func (api *ArchivesSpaceAPI) RelabelTopContainers(repoID, resourceID int, opts *RelabelOptions) (*ContainerRelabelReport, error) {
+	if opts == nil {
+		opts = new(RelabelOptions)
+	}
+	start := opts.Start
+	if start < 1 {
+		start = 1
+	}
+	resourceURI := fmt.Sprintf("/repositories/%d/resources/%d", repoID, resourceID)
+	containers, err := api.collectionTopContainers(repoID, resourceURI)
+	if err != nil {
+		return nil, fmt.Errorf("RelabelTopContainers(%d, %d) %w", repoID, resourceID, err)
+	}
+
+	report := &ContainerRelabelReport{
+		ResourceURI: resourceURI,
+		DryRun:      opts.DryRun,
+		Relabels:    []*ContainerRelabel{},
+	}
+	selected := []*TopContainer{}
+	for _, container := range containers {
+		if opts.Type != "" && container.Type != opts.Type {
+			continue
+		}
+		shared := false
+		for _, collection := range container.Collection {
+			if ref, _ := collection["ref"].(string); ref != "" && ref != resourceURI {
+				shared = true
+			}
+		}
+		if shared == true {
+			report.Shared = append(report.Shared, container.URI)
+			continue
+		}
+		selected = append(selected, container)
+	}
+	sort.SliceStable(selected, func(i, j int) bool {
+		return indicatorLess(selected[i].Indicator, selected[j].Indicator)
+	})
+
+	mapping := map[string]*ContainerRelabel{}
+	uris := []string{}
+	for i, container := range selected {
+		relabel := &ContainerRelabel{
+			URI:          container.URI,
+			OldType:      container.Type,
+			OldIndicator: container.Indicator,
+			NewType:      container.Type,
+			NewIndicator: formatIndicator(start+i, opts),
+		}
+		if opts.NewType != "" {
+			relabel.NewType = opts.NewType
+		}
+		report.Relabels = append(report.Relabels, relabel)
+		if relabel.NewType != relabel.OldType || relabel.NewIndicator != relabel.OldIndicator {
+			mapping[container.URI] = relabel
+			uris = append(uris, container.URI)
+		}
+	}
+	if opts.DryRun == true || len(uris) == 0 {
+		return report, nil
+	}
+
+	report.Saved, err = api.ApplyToURIs(uris, func(obj Object) (bool, error) {
+		uri, _ := obj["uri"].(string)
+		relabel, ok := mapping[uri]
+		if ok == false {
+			return false, nil
+		}
+		obj["indicator"] = relabel.NewIndicator
+		obj["type"] = relabel.NewType
+		return true, nil
+	}, &BatchEditOptions{RepoID: repoID, MaxRetries: opts.MaxRetries})
+	if err != nil {
+		return nil, fmt.Errorf("RelabelTopContainers(%d, %d) %w", repoID, resourceID, err)
+	}
+	return report, nil
+}
+
+// String return a ContainerRelabelReport
+func (report *ContainerRelabelReport) String() string {
+	return stringify(report)
+}
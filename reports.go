@@ -0,0 +1,206 @@
+//
+// Package cait is a collection of structures and functions
+// for interacting with ArchivesSpace's REST API
+//
+// @author R. S. Doiel, <rsdoiel@caltech.edu>
+//
+// Copyright (c) 2017, Caltech
+// All rights not granted herein are expressly reserved by Caltech.
+//
+// Redistribution and use in source and binary forms, with or without modification, are permitted provided that the following conditions are met:
+//
+// 1. Redistributions of source code must retain the above copyright notice, this list of conditions and the following disclaimer.
+//
+// 2. Redistributions in binary form must reproduce the above copyright notice, this list of conditions and the following disclaimer in the documentation and/or other materials provided with the distribution.
+//
+// 3. Neither the name of the copyright holder nor the names of its contributors may be used to endorse or promote products derived from this software without specific prior written permission.
+//
+// THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS" AND ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE ARE DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT HOLDER OR CONTRIBUTORS BE LIABLE FOR ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL DAMAGES (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR SERVICES; LOSS OF USE, DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER CAUSED AND ON ANY THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT LIABILITY, OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE OF THIS SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+//
+package cait
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"time"
+)
+
+// ReportDefinition describes a report ArchivesSpace can run, as listed by ListReports
+type ReportDefinition struct {
+	Code        string        `json:"code"`
+	URISuffix   string        `json:"uri_suffix,omitempty"`
+	Description string        `json:"description,omitempty"`
+	Model       string        `json:"model,omitempty"`
+	Params      []interface{} `json:"params,omitempty"`
+}
+
+// ListReports returns the reports ArchivesSpace can run keyed by report code
+func (api *ArchivesSpaceAPI) ListReports() (map[string]*ReportDefinition, error) {
+	u := api.callURL("/reports")
+	reports := map[string]*ReportDefinition{}
+	if err := api.GetAPI(u.String(), &reports); err != nil {
+		return nil, fmt.Errorf("ListReports() %w", err)
+	}
+	for code, report := range reports {
+		if report.Code == "" {
+			report.Code = code
+		}
+	}
+	return reports, nil
+}
+
+// GetReportStatic writes the static report asset at name (e.g. "css/report.css") to w
+func (api *ArchivesSpaceAPI) GetReportStatic(name string, w io.Writer) (int64, error) {
+	u := api.callURL("/reports/static/" + strings.TrimPrefix(name, "/"))
+	n, err := api.DownloadAPI(u.String(), w)
+	if err != nil {
+		return n, fmt.Errorf("GetReportStatic(%q) %w", name, err)
+	}
+	return n, nil
+}
+
+// NewReportJob returns a report_job running the report code in format ("csv", "json",
+// "html" or "pdf"), params holds the report's parameters, e.g. a date range
+func NewReportJob(code, format string, params map[string]interface{}) *Job {
+	job := NewJob("report_job", map[string]interface{}{
+		"report_type": code,
+		"format":      format,
+	})
+	if len(params) > 0 {
+		job.Job["job_params"] = params
+	}
+	return job
+}
+
+// RunReport queues the report code in format for a repository, the job's output can be
+// fetched with WriteReport once it completes (see WaitForJob)
+func (api *ArchivesSpaceAPI) RunReport(repoID int, code, format string, params map[string]interface{}) (*Job, error) {
+	job := NewReportJob(code, format, params)
+	if _, err := api.CreateJob(repoID, job); err != nil {
+		return nil, fmt.Errorf("RunReport(%d, %q, %q) %w", repoID, code, format, err)
+	}
+	return job, nil
+}
+
+// ListJobOutputFiles returns the IDs of the files a job produced, e.g. a report
+func (api *ArchivesSpaceAPI) ListJobOutputFiles(repoID, jobID int) ([]int, error) {
+	u := api.callURL(fmt.Sprintf("/repositories/%d/jobs/%d/output_files", repoID, jobID))
+	ids := []int{}
+	if err := api.GetAPI(u.String(), &ids); err != nil {
+		return nil, fmt.Errorf("ListJobOutputFiles(%d, %d) %w", repoID, jobID, err)
+	}
+	return ids, nil
+}
+
+// WriteReport streams the output of a completed report job to w, returning the bytes written
+func (api *ArchivesSpaceAPI) WriteReport(repoID, jobID int, w io.Writer) (int64, error) {
+	ids, err := api.ListJobOutputFiles(repoID, jobID)
+	if err != nil {
+		return 0, fmt.Errorf("WriteReport(%d, %d) %w", repoID, jobID, err)
+	}
+	if len(ids) == 0 {
+		return 0, fmt.Errorf("WriteReport(%d, %d) job has no output", repoID, jobID)
+	}
+	var total int64
+	for _, id := range ids {
+		u := api.callURL(fmt.Sprintf("/repositories/%d/jobs/%d/output_files/%d", repoID, jobID, id))
+		n, err := api.DownloadAPI(u.String(), w)
+		total += n
+		if err != nil {
+			return total, fmt.Errorf("WriteReport(%d, %d) %w", repoID, jobID, err)
+		}
+	}
+	return total, nil
+}
+
+// CreateCustomReportTemplate creates a custom report template in a repository. On success
+// the template's URI, ID and lock version are updated from the response.
+func (api *ArchivesSpaceAPI) CreateCustomReportTemplate(repoID int, tmpl *CustomReportTemplate) (*ResponseMsg, error) {
+	u := api.callURL(fmt.Sprintf("/repositories/%d/custom_report_templates", repoID))
+	tmpl.JSONModelType = "custom_report_template"
+	tmpl.LockVersion = "0"
+	responseMsg, err := api.CreateAPI(u.String(), tmpl)
+	if err != nil {
+		return nil, fmt.Errorf("CreateCustomReportTemplate(%d, %q) %w", repoID, tmpl.Name, err)
+	}
+	tmpl.SetURI(responseMsg.URI)
+	tmpl.LockVersion = responseMsg.LockVersion
+	return responseMsg, nil
+}
+
+// GetCustomReportTemplate retrieves a custom report template from a repository
+func (api *ArchivesSpaceAPI) GetCustomReportTemplate(repoID, tmplID int) (*CustomReportTemplate, error) {
+	u := api.callURL(fmt.Sprintf("/repositories/%d/custom_report_templates/%d", repoID, tmplID))
+	tmpl := new(CustomReportTemplate)
+	if err := api.GetAPI(u.String(), tmpl); err != nil {
+		return nil, fmt.Errorf("GetCustomReportTemplate(%d, %d) %w", repoID, tmplID, err)
+	}
+	tmpl.ID = URIToID(tmpl.URI)
+	return tmpl, nil
+}
+
+// UpdateCustomReportTemplate updates an existing custom report template
+func (api *ArchivesSpaceAPI) UpdateCustomReportTemplate(tmpl *CustomReportTemplate) (*ResponseMsg, error) {
+	u := api.callURL(tmpl.URI)
+	return api.UpdateAPI(u.String(), tmpl)
+}
+
+// DeleteCustomReportTemplate deletes a custom report template
+func (api *ArchivesSpaceAPI) DeleteCustomReportTemplate(tmpl *CustomReportTemplate) (*ResponseMsg, error) {
+	u := api.callURL(tmpl.URI)
+	return api.DeleteAPI(u.String(), tmpl)
+}
+
+// ListCustomReportTemplates return a list of custom report template IDs from a Repository
+// Give modifiedSince to list only the records changed since then.
+func (api *ArchivesSpaceAPI) ListCustomReportTemplates(repoID int, modifiedSince ...time.Time) ([]int, error) {
+	u := api.callURL(fmt.Sprintf("/repositories/%d/custom_report_templates", repoID))
+	q := u.Query()
+	q.Set("all_ids", "true")
+	setModifiedSince(q, modifiedSince)
+	u.RawQuery = q.Encode()
+	return api.ListAPI(u.String())
+}
+
+// ExportCustomReportTemplates returns all the custom report templates of a repository,
+// e.g. to save them as JSON and import them elsewhere with ImportCustomReportTemplate
+func (api *ArchivesSpaceAPI) ExportCustomReportTemplates(repoID int) ([]*CustomReportTemplate, error) {
+	ids, err := api.ListCustomReportTemplates(repoID)
+	if err != nil {
+		return nil, fmt.Errorf("ExportCustomReportTemplates(%d) %w", repoID, err)
+	}
+	templates := []*CustomReportTemplate{}
+	for _, id := range ids {
+		tmpl, err := api.GetCustomReportTemplate(repoID, id)
+		if err != nil {
+			return nil, fmt.Errorf("ExportCustomReportTemplates(%d) %w", repoID, err)
+		}
+		templates = append(templates, tmpl)
+	}
+	return templates, nil
+}
+
+// ImportCustomReportTemplate creates tmpl in a repository or, if the repository has a
+// template of the same name, updates that one. tmpl may come from another instance, its
+// URI and ID are replaced by those of the template imported.
+func (api *ArchivesSpaceAPI) ImportCustomReportTemplate(repoID int, tmpl *CustomReportTemplate) (*ResponseMsg, error) {
+	existing, err := api.ExportCustomReportTemplates(repoID)
+	if err != nil {
+		return nil, fmt.Errorf("ImportCustomReportTemplate(%d, %q) %w", repoID, tmpl.Name, err)
+	}
+	for _, current := range existing {
+		if current.Name == tmpl.Name {
+			tmpl.SetURI(current.URI)
+			tmpl.LockVersion = current.LockVersion
+			tmpl.JSONModelType = "custom_report_template"
+			responseMsg, err := api.UpdateCustomReportTemplate(tmpl)
+			if err != nil {
+				return nil, fmt.Errorf("ImportCustomReportTemplate(%d, %q) %w", repoID, tmpl.Name, err)
+			}
+			tmpl.LockVersion = responseMsg.LockVersion
+			return responseMsg, nil
+		}
+	}
+	return api.CreateCustomReportTemplate(repoID, tmpl)
+}
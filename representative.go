@@ -0,0 +1,113 @@
+//
+// Package cait is a collection of structures and functions
+// for interacting with ArchivesSpace's REST API
+//
+// @author R. S. Doiel, <rsdoiel@caltech.edu>
+//
+// Copyright (c) 2017, Caltech
+// All rights not granted herein are expressly reserved by Caltech.
+//
+// Redistribution and use in source and binary forms, with or without modification, are permitted provided that the following conditions are met:
+//
+// 1. Redistributions of source code must retain the above copyright notice, this list of conditions and the following disclaimer.
+//
+// 2. Redistributions in binary form must reproduce the above copyright notice, this list of conditions and the following disclaimer in the documentation and/or other materials provided with the distribution.
+//
+// 3. Neither the name of the copyright holder nor the names of its contributors may be used to endorse or promote products derived from this software without specific prior written permission.
+//
+// THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS" AND ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE ARE DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT HOLDER OR CONTRIBUTORS BE LIABLE FOR ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL DAMAGES (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR SERVICES; LOSS OF USE, DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER CAUSED AND ON ANY THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT LIABILITY, OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE OF THIS SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+//
+package cait
+
+import (
+	"fmt"
+)
+
+// RepresentativeFileVersion returns the digital object's representative file version or nil
+func (obj *DigitalObject) RepresentativeFileVersion() *FileVersion {
+	for _, fv := range obj.FileVersions {
+		if fv.IsRepresentative == true {
+			return fv
+		}
+	}
+	return nil
+}
+
+// SetRepresentativeFileVersion makes the file version with fileURI the digital object's
+// representative file version clearing the flag on the others. ArchivesSpace only
+// accepts a published file version as representative.
+func (obj *DigitalObject) SetRepresentativeFileVersion(fileURI string) error {
+	var found *FileVersion
+	for _, fv := range obj.FileVersions {
+		if fv.FileURI == fileURI {
+			found = fv
+		}
+	}
+	if found == nil {
+		return fmt.Errorf("%s has no file version %q", obj.URI, fileURI)
+	}
+	if found.Publish == false {
+		return fmt.Errorf("%s file version %q must be published to be representative", obj.URI, fileURI)
+	}
+	for _, fv := range obj.FileVersions {
+		fv.IsRepresentative = (fv == found)
+	}
+	return nil
+}
+
+// RepresentativeInstance returns the resource's representative instance or nil
+func (resource *Resource) RepresentativeInstance() *Instance {
+	for _, instance := range resource.Instances {
+		if instance.IsRepresentative == true {
+			return instance
+		}
+	}
+	return nil
+}
+
+// SetRepresentativeInstance makes the digital object instance linking digitalObjectURI
+// the resource's representative instance clearing the flag on the others
+func (resource *Resource) SetRepresentativeInstance(digitalObjectURI string) error {
+	var found *Instance
+	for _, instance := range resource.Instances {
+		if instance.InstanceType != "digital_object" || instance.DigitalObject == nil {
+			continue
+		}
+		if ref, _ := instance.DigitalObject["ref"].(string); ref == digitalObjectURI {
+			found = instance
+		}
+	}
+	if found == nil {
+		return fmt.Errorf("%s has no digital object instance for %q", resource.URI, digitalObjectURI)
+	}
+	for _, instance := range resource.Instances {
+		instance.IsRepresentative = (instance == found)
+	}
+	return nil
+}
+
+// SetDigitalObjectRepresentativeFileVersion fetches a digital object, marks the file
+// version with fileURI as representative and saves it
+func (api *ArchivesSpaceAPI) SetDigitalObjectRepresentativeFileVersion(repoID, objID int, fileURI string) (*ResponseMsg, error) {
+	obj, err := api.GetDigitalObject(repoID, objID)
+	if err != nil {
+		return nil, err
+	}
+	if err := obj.SetRepresentativeFileVersion(fileURI); err != nil {
+		return nil, err
+	}
+	return api.UpdateDigitalObject(obj)
+}
+
+// SetResourceRepresentativeInstance fetches a resource, marks the instance linking
+// digitalObjectURI as representative and saves it
+func (api *ArchivesSpaceAPI) SetResourceRepresentativeInstance(repoID, resourceID int, digitalObjectURI string) (*ResponseMsg, error) {
+	resource, err := api.GetResource(repoID, resourceID)
+	if err != nil {
+		return nil, err
+	}
+	if err := resource.SetRepresentativeInstance(digitalObjectURI); err != nil {
+		return nil, err
+	}
+	return api.UpdateResource(resource)
+}
@@ -0,0 +1,88 @@
+//
+// Package cait is a collection of structures and functions
+// for interacting with ArchivesSpace's REST API
+//
+// @author R. S. Doiel, <rsdoiel@caltech.edu>
+//
+// Copyright (c) 2017, Caltech
+// All rights not granted herein are expressly reserved by Caltech.
+//
+// Redistribution and use in source and binary forms, with or without modification, are permitted provided that the following conditions are met:
+//
+// 1. Redistributions of source code must retain the above copyright notice, this list of conditions and the following disclaimer.
+//
+// 2. Redistributions in binary form must reproduce the above copyright notice, this list of conditions and the following disclaimer in the documentation and/or other materials provided with the distribution.
+//
+// 3. Neither the name of the copyright holder nor the names of its contributors may be used to endorse or promote products derived from this software without specific prior written permission.
+//
+// THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS" AND ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE ARE DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT HOLDER OR CONTRIBUTORS BE LIABLE FOR ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL DAMAGES (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR SERVICES; LOSS OF USE, DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER CAUSED AND ON ANY THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT LIABILITY, OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE OF THIS SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+//
+package cait
+
+import (
+	"fmt"
+)
+
+// GetRequiredFields returns the fields a repository requires for records of recordType
+// (e.g. "resource" or "agent_person")
+func (api *ArchivesSpaceAPI) GetRequiredFields(repoID int, recordType string) (*RequiredFields, error) {
+	u := api.callURL(fmt.Sprintf("/repositories/%d/required_fields/%s", repoID, recordType))
+	rf := new(RequiredFields)
+	if err := api.GetAPI(u.String(), rf); err != nil {
+		return nil, fmt.Errorf("GetRequiredFields(%d, %q) %w", repoID, recordType, err)
+	}
+	return rf, nil
+}
+
+// SaveRequiredFields saves the required fields for rf.RecordType in a repository,
+// replacing any already saved. The lock version is updated on success.
+func (api *ArchivesSpaceAPI) SaveRequiredFields(repoID int, rf *RequiredFields) (*ResponseMsg, error) {
+	if rf.RecordType == "" {
+		return nil, fmt.Errorf("SaveRequiredFields(%d) missing record type", repoID)
+	}
+	u := api.callURL(fmt.Sprintf("/repositories/%d/required_fields/%s", repoID, rf.RecordType))
+	rf.JSONModelType = "required_fields"
+	if rf.LockVersion == "" {
+		rf.LockVersion = "0"
+	}
+	if rf.SubrecordRequirements == nil {
+		rf.SubrecordRequirements = []map[string]interface{}{}
+	}
+	responseMsg, err := api.UpdateAPI(u.String(), rf)
+	if err != nil {
+		return nil, fmt.Errorf("SaveRequiredFields(%d, %q) %w", repoID, rf.RecordType, err)
+	}
+	if responseMsg.URI != "" {
+		rf.SetURI(responseMsg.URI)
+	}
+	rf.LockVersion = responseMsg.LockVersion
+	return responseMsg, nil
+}
+
+// ApplyRequiredFields saves the required fields policy rf in each repository given,
+// or in every repository if none are given. Each repository's own lock version is
+// used so existing policies are replaced. It returns the IDs of the repositories updated.
+func (api *ArchivesSpaceAPI) ApplyRequiredFields(rf *RequiredFields, repoIDs ...int) ([]int, error) {
+	var err error
+	if len(repoIDs) == 0 {
+		repoIDs, err = api.ListRepositoryIDs()
+		if err != nil {
+			return nil, fmt.Errorf("ApplyRequiredFields() %w", err)
+		}
+	}
+	updated := []int{}
+	for _, repoID := range repoIDs {
+		policy := *rf
+		policy.URI, policy.LockVersion = "", "0"
+		if existing, err := api.GetRequiredFields(repoID, rf.RecordType); err == nil {
+			policy.LockVersion = existing.LockVersion
+		} else if IsNotFound(err) == false {
+			return updated, fmt.Errorf("ApplyRequiredFields() %w", err)
+		}
+		if _, err := api.SaveRequiredFields(repoID, &policy); err != nil {
+			return updated, fmt.Errorf("ApplyRequiredFields() %w", err)
+		}
+		updated = append(updated, repoID)
+	}
+	return updated, nil
+}
@@ -0,0 +1,263 @@
+//
+// Package cait is a collection of structures and functions
+// for interacting with ArchivesSpace's REST API
+//
+// @author R. S. Doiel, <rsdoiel@caltech.edu>
+//
+// Copyright (c) 2017, Caltech
+// All rights not granted herein are expressly reserved by Caltech.
+//
+// Redistribution and use in source and binary forms, with or without modification, are permitted provided that the following conditions are met:
+//
+// 1. Redistributions of source code must retain the above copyright notice, this list of conditions and the following disclaimer.
+//
+// 2. Redistributions in binary form must reproduce the above copyright notice, this list of conditions and the following disclaimer in the documentation and/or other materials provided with the distribution.
+//
+// 3. Neither the name of the copyright holder nor the names of its contributors may be used to endorse or promote products derived from this software without specific prior written permission.
+//
+// THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS" AND ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE ARE DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT HOLDER OR CONTRIBUTORS BE LIABLE FOR ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL DAMAGES (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR SERVICES; LOSS OF USE, DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER CAUSED AND ON ANY THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT LIABILITY, OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE OF THIS SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+//
+package cait
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"math/rand"
+	"net/http"
+	"strconv"
+	"sync/atomic"
+	"time"
+)
+
+// DefaultMaxThrottleRetries is how many times a request answered with
+// 429 Too Many Requests is retried when the RetryPolicy doesn't say
+const DefaultMaxThrottleRetries = 5
+
+// DefaultMaxThrottleDelay caps the wait asked for by a Retry-After header when the
+// RetryPolicy has no MaxDelay
+const DefaultMaxThrottleDelay = 60 * time.Second
+
+// RetryPolicy controls how requests are retried after network errors and 5xx responses
+type RetryPolicy struct {
+	// MaxAttempts is the total number of tries for a request, one or less means no retries
+	MaxAttempts int `json:"max_attempts"`
+	// BaseDelay is the wait before the first retry, doubled for each retry after, defaults to 500ms
+	BaseDelay time.Duration `json:"base_delay,omitempty"`
+	// MaxDelay caps the wait between retries, zero means no cap. It also caps the wait
+	// asked for by a throttled response's Retry-After (DefaultMaxThrottleDelay when zero).
+	MaxDelay time.Duration `json:"max_delay,omitempty"`
+	// Jitter adds up to this fraction of the delay at random (e.g. 0.2) so clients don't retry in step
+	Jitter float64 `json:"jitter,omitempty"`
+	// RetryPOST also retries POST requests. These are not idempotent in ArchivesSpace
+	// (a retried create can make a duplicate record) so they are not retried by default.
+	RetryPOST bool `json:"retry_post,omitempty"`
+	// MaxThrottleRetries is how many times a 429 Too Many Requests response is retried after
+	// waiting for its Retry-After, zero uses DefaultMaxThrottleRetries and negative disables it.
+	// The server didn't process a throttled request so these retries apply to POST too.
+	MaxThrottleRetries int `json:"max_throttle_retries,omitempty"`
+}
+
+// backoff returns how long to wait before retry number attempt (starting at 1)
+func (policy *RetryPolicy) backoff(attempt int) time.Duration {
+	delay := policy.BaseDelay
+	if delay <= 0 {
+		delay = 500 * time.Millisecond
+	}
+	for i := 1; i < attempt; i++ {
+		delay *= 2
+		if policy.MaxDelay > 0 && delay >= policy.MaxDelay {
+			break
+		}
+	}
+	if policy.MaxDelay > 0 && delay > policy.MaxDelay {
+		delay = policy.MaxDelay
+	}
+	if policy.Jitter > 0 {
+		if n := int64(float64(delay) * policy.Jitter); n > 0 {
+			delay += time.Duration(rand.Int63n(n))
+		}
+	}
+	return delay
+}
+
+// attempts returns the number of tries allowed for a request using method
+func (policy *RetryPolicy) attempts(method string) int {
+	if policy == nil || policy.MaxAttempts < 1 || (method == "POST" && policy.RetryPOST == false) {
+		return 1
+	}
+	return policy.MaxAttempts
+}
+
+// throttleRetries returns the number of times a throttled request may be retried
+func (policy *RetryPolicy) throttleRetries() int {
+	if policy == nil || policy.MaxThrottleRetries == 0 {
+		return DefaultMaxThrottleRetries
+	}
+	if policy.MaxThrottleRetries < 0 {
+		return 0
+	}
+	return policy.MaxThrottleRetries
+}
+
+// throttleDelay caps delay, the wait asked for by a throttled response
+func (policy *RetryPolicy) throttleDelay(delay time.Duration) time.Duration {
+	limit := DefaultMaxThrottleDelay
+	if policy != nil && policy.MaxDelay > 0 {
+		limit = policy.MaxDelay
+	}
+	if delay > limit {
+		return limit
+	}
+	return delay
+}
+
+// retryAfter returns the wait requested by a response's Retry-After header, given
+// either in seconds or as an HTTP date. ok is false if the header is missing or invalid.
+func retryAfter(res *http.Response, now time.Time) (time.Duration, bool) {
+	value := res.Header.Get("Retry-After")
+	if value == "" {
+		return 0, false
+	}
+	if seconds, err := strconv.Atoi(value); err == nil {
+		if seconds < 0 {
+			return 0, false
+		}
+		return time.Duration(seconds) * time.Second, true
+	}
+	if t, err := http.ParseTime(value); err == nil {
+		if t.Before(now) {
+			return 0, true
+		}
+		return t.Sub(now), true
+	}
+	return 0, false
+}
+
+// ThrottleCount returns the number of 429 Too Many Requests responses the client has
+// received so callers can see how often ArchivesSpace (or a proxy) is throttling them
+func (api *ArchivesSpaceAPI) ThrottleCount() int64 {
+	return atomic.LoadInt64(&api.throttled)
+}
+
+// doRequest sends a request to ArchivesSpace retrying according to api.Retry.
+// headers are added after api.Headers. The caller must close the response body.
+func (api *ArchivesSpaceAPI) doRequest(method, url string, payload []byte, headers http.Header) (*http.Response, error) {
+	attempts := api.Retry.attempts(method)
+	encoding := ""
+	if api.GzipRequests == true && len(payload) > 0 {
+		compressed, err := gzipPayload(payload)
+		if err != nil {
+			return nil, err
+		}
+		payload, encoding = compressed, "gzip"
+	}
+	throttleRetries := api.Retry.throttleRetries()
+	for attempt := 1; ; attempt++ {
+		req, err := http.NewRequest(method, url, bytes.NewReader(payload))
+		if err != nil {
+			return nil, redactError(err)
+		}
+		req.Header.Set("User-Agent", api.userAgent())
+		for key, values := range api.Headers {
+			req.Header[http.CanonicalHeaderKey(key)] = values
+		}
+		for key, values := range headers {
+			req.Header[http.CanonicalHeaderKey(key)] = values
+		}
+		req.Header.Set("X-ArchivesSpace-Session", api.token())
+		req.Header.Set("Content-Type", "application/json")
+		if contentType := headers.Get("Content-Type"); contentType != "" {
+			req.Header.Set("Content-Type", contentType)
+		}
+		if encoding != "" {
+			req.Header.Set("Content-Encoding", encoding)
+		}
+		// Asking for gzip ourselves means decompressing ourselves, it works
+		// even with a caller's client that has compression disabled
+		if api.DisableCompression == false {
+			req.Header.Set("Accept-Encoding", "gzip")
+		}
+		if api.Proxy != nil && req.URL.Scheme == "http" {
+			for key, values := range api.ProxyHeaders {
+				for _, value := range values {
+					req.Header.Add(key, value)
+				}
+			}
+		}
+		cancel := context.CancelFunc(func() {})
+		if api.Deadline.IsZero() == false {
+			var ctx context.Context
+			ctx, cancel = context.WithDeadline(context.Background(), api.Deadline)
+			req = req.WithContext(ctx)
+		}
+		start := time.Now()
+		res, err := api.httpClient().Do(req)
+		if err != nil {
+			api.debugf(method, url, requestError(err), start)
+		} else {
+			api.debugf(method, url, res.Status, start)
+		}
+		if err == nil && res.StatusCode == http.StatusTooManyRequests {
+			atomic.AddInt64(&api.throttled, 1)
+			if throttleRetries > 0 {
+				throttleRetries--
+				delay, ok := retryAfter(res, time.Now())
+				if ok == false {
+					delay = api.Retry.backoff(1)
+				}
+				delay = api.Retry.throttleDelay(delay)
+				io.Copy(ioutil.Discard, res.Body)
+				res.Body.Close()
+				cancel()
+				if api.Deadline.IsZero() == false && time.Now().Add(delay).After(api.Deadline) {
+					return nil, fmt.Errorf("%s %s deadline exceeded waiting %s to retry a throttled request", method, RedactURL(url), delay)
+				}
+				api.logf("%s %s throttled, retrying in %s", method, RedactURL(url), delay)
+				time.Sleep(delay)
+				// A throttled request doesn't use up one of the policy's attempts
+				attempt--
+				continue
+			}
+		}
+		if attempt >= attempts || (err == nil && res.StatusCode < 500) {
+			if err != nil {
+				cancel()
+				return nil, redactError(err)
+			}
+			if err := decompressResponse(res); err != nil {
+				cancel()
+				return nil, err
+			}
+			// The deadline's context must live until the caller has read the body
+			res.Body = &cancelOnClose{ReadCloser: res.Body, cancel: cancel}
+			return res, nil
+		}
+		if res != nil {
+			io.Copy(ioutil.Discard, res.Body)
+			res.Body.Close()
+		}
+		cancel()
+		delay := api.Retry.backoff(attempt)
+		if api.Deadline.IsZero() == false && time.Now().Add(delay).After(api.Deadline) {
+			return nil, fmt.Errorf("%s %s deadline exceeded after %d attempts", method, RedactURL(url), attempt)
+		}
+		api.logf("%s %s failed (attempt %d of %d), retrying in %s", method, RedactURL(url), attempt, attempts, delay)
+		time.Sleep(delay)
+	}
+}
+
+// cancelOnClose releases a request's context when its response body is closed
+type cancelOnClose struct {
+	io.ReadCloser
+	cancel context.CancelFunc
+}
+
+// Close closes the body then cancels the context
+func (body *cancelOnClose) Close() error {
+	err := body.ReadCloser.Close()
+	body.cancel()
+	return err
+}
@@ -0,0 +1,151 @@
+//
+// Package cait is a collection of structures and functions
+// for interacting with ArchivesSpace's REST API
+//
+// @author R. S. Doiel, <rsdoiel@caltech.edu>
+//
+// Copyright (c) 2017, Caltech
+// All rights not granted herein are expressly reserved by Caltech.
+//
+// Redistribution and use in source and binary forms, with or without modification, are permitted provided that the following conditions are met:
+//
+// 1. Redistributions of source code must retain the above copyright notice, this list of conditions and the following disclaimer.
+//
+// 2. Redistributions in binary form must reproduce the above copyright notice, this list of conditions and the following disclaimer in the documentation and/or other materials provided with the distribution.
+//
+// 3. Neither the name of the copyright holder nor the names of its contributors may be used to endorse or promote products derived from this software without specific prior written permission.
+//
+// THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS" AND ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE ARE DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT HOLDER OR CONTRIBUTORS BE LIABLE FOR ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL DAMAGES (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR SERVICES; LOSS OF USE, DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER CAUSED AND ON ANY THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT LIABILITY, OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE OF THIS SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+//
+package cait
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"sort"
+	"text/template"
+	"time"
+)
+
+// SavedSearch is a named search definition that can be run by name. Query is
+// a Go text/template so recurring reports can take parameters, e.g.
+// "accession_date:[{{.fy_start}} TO *]". The values today (YYYY-MM-DD) and
+// year (YYYY) are always available to the template.
+type SavedSearch struct {
+	Name        string            `json:"name" toml:"name"`
+	Description string            `json:"description,omitempty" toml:"description"`
+	RepoID      int               `json:"repo_id,omitempty" toml:"repo_id"`
+	Query       string            `json:"q" toml:"q"`
+	Types       []string          `json:"types,omitempty" toml:"types"`
+	Filters     map[string]string `json:"filters,omitempty" toml:"filters"`
+	Params      map[string]string `json:"params,omitempty" toml:"params"`
+}
+
+// AddSavedSearch adds (or replaces) a saved search definition
+func (api *ArchivesSpaceAPI) AddSavedSearch(search *SavedSearch) error {
+	if search == nil || search.Name == "" {
+		return fmt.Errorf("saved search requires a name")
+	}
+	if _, err := template.New(search.Name).Parse(search.Query); err != nil {
+		return fmt.Errorf("saved search %q, %w", search.Name, err)
+	}
+	if api.SavedSearches == nil {
+		api.SavedSearches = map[string]*SavedSearch{}
+	}
+	api.SavedSearches[search.Name] = search
+	return nil
+}
+
+// LoadSavedSearches reads a JSON file holding a list of saved search definitions.
+// Saved searches normally come from the client config (see InstanceConfig), the
+// ones loaded here replace any with the same name.
+func (api *ArchivesSpaceAPI) LoadSavedSearches(fname string) error {
+	src, err := ioutil.ReadFile(fname)
+	if err != nil {
+		return fmt.Errorf("Can't read saved searches %s, %w", fname, err)
+	}
+	searches := []*SavedSearch{}
+	if err := json.Unmarshal(src, &searches); err != nil {
+		return fmt.Errorf("Can't decode saved searches %s, %w", fname, err)
+	}
+	for _, search := range searches {
+		if err := api.AddSavedSearch(search); err != nil {
+			return fmt.Errorf("%s, %w", fname, err)
+		}
+	}
+	return nil
+}
+
+// ListSavedSearches returns the names of the saved searches available
+func (api *ArchivesSpaceAPI) ListSavedSearches() []string {
+	names := []string{}
+	for name := range api.SavedSearches {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Render returns the query string for the saved search. Values in params
+// override the search's default Params.
+func (search *SavedSearch) Render(params map[string]string) (string, error) {
+	now := time.Now()
+	data := map[string]string{
+		"today": now.Format("2006-01-02"),
+		"year":  now.Format("2006"),
+	}
+	for k, v := range search.Params {
+		data[k] = v
+	}
+	for k, v := range params {
+		data[k] = v
+	}
+	tmpl, err := template.New(search.Name).Option("missingkey=error").Parse(search.Query)
+	if err != nil {
+		return "", fmt.Errorf("saved search %q, %w", search.Name, err)
+	}
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, data); err != nil {
+		return "", fmt.Errorf("saved search %q, %w", search.Name, err)
+	}
+	return buf.String(), nil
+}
+
+// RunSavedSearch renders the named saved search with params and returns all
+// the matching results
+func (api *ArchivesSpaceAPI) RunSavedSearch(name string, params map[string]string) ([]Object, error) {
+	search, ok := api.SavedSearches[name]
+	if ok == false {
+		return nil, fmt.Errorf("No saved search named %q", name)
+	}
+	q, err := search.Render(params)
+	if err != nil {
+		return nil, err
+	}
+	req := NewSearchRequest(q).InRepository(search.RepoID).OfType(search.Types...)
+	for field, value := range search.Filters {
+		req.Filter(field, value)
+	}
+	results := []Object{}
+	for {
+		p, err := api.SearchRecords(req)
+		if err != nil {
+			return nil, fmt.Errorf("RunSavedSearch(%q) %w", name, err)
+		}
+		for _, hit := range p.Results {
+			results = append(results, hit.Fields)
+		}
+		if len(p.Results) == 0 || p.HasNextPage() == false {
+			break
+		}
+		req.WithPage(p.ThisPage + 1)
+	}
+	return results, nil
+}
+
+// String return a SavedSearch
+func (search *SavedSearch) String() string {
+	return stringify(search)
+}
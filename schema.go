@@ -20,12 +20,16 @@
 package cait
 
 import (
+	"crypto/tls"
 	"encoding/json"
 	"encoding/xml"
 	"fmt"
+	"net/http"
 	"net/url"
 	"strconv"
 	"strings"
+	"sync"
+	"time"
 )
 
 //
@@ -47,6 +51,63 @@ type ArchivesSpaceAPI struct {
 	Htdocs       string   `json:"htdocs,omitempty"`
 	HtdocsIndex  string   `json:"htdocs_index,omitempty"`
 	Templates    string   `json:"templates,omitempty"`
+
+	// Credentials, when set, supplies the password at Login instead of Password
+	Credentials CredentialProvider `json:"-"`
+
+	SavedSearches map[string]*SavedSearch `json:"saved_searches,omitempty"`
+
+	// Client is the http.Client used for requests, http.DefaultClient if nil
+	Client *http.Client `json:"-"`
+
+	// ConnectTimeout and RequestTimeout limit how long connecting and each request may take,
+	// zero means no limit. Set them with WithConnectTimeout and WithRequestTimeout.
+	ConnectTimeout time.Duration `json:"connect_timeout,omitempty"`
+	RequestTimeout time.Duration `json:"request_timeout,omitempty"`
+	// TLSConfig, when set, is used for HTTPS connections. Set it with WithTLSConfig.
+	TLSConfig *tls.Config `json:"-"`
+	// DisableCompression stops asking for gzip compressed responses
+	DisableCompression bool `json:"disable_compression,omitempty"`
+	// GzipRequests compresses request bodies. Set it with WithGzipRequests.
+	GzipRequests bool `json:"gzip_requests,omitempty"`
+	// Cache, when set, makes GET requests conditional on the cached copy being stale
+	Cache ResponseCache `json:"-"`
+	// Headers are added to every request. The session and content type headers can't be overridden.
+	Headers http.Header `json:"-"`
+	// UserAgent replaces DefaultUserAgent() as the User-Agent header sent with every request
+	UserAgent string `json:"user_agent,omitempty"`
+	// Proxy and ProxyHeaders, when set, send requests through a forward proxy. Set them with WithProxy.
+	Proxy        *url.URL    `json:"-"`
+	ProxyHeaders http.Header `json:"-"`
+	// Deadline, when set, fails any request (including retries) still running at that time
+	Deadline time.Time `json:"deadline,omitempty"`
+
+	// Retry, when set, retries requests failing with network errors or 5xx responses
+	Retry *RetryPolicy `json:"retry,omitempty"`
+	// ConflictRetries is how many times UpdateWith refetches a record, reapplies the
+	// changes and saves it again after a stale lock_version conflict
+	ConflictRetries int `json:"conflict_retries,omitempty"`
+
+	// OnReauthenticate, when set, is called after the client logs in again because
+	// ArchivesSpace reported the session expired. err is the result of the login.
+	OnReauthenticate func(err error) `json:"-"`
+
+	// Logger, when set, receives messages about retries and session renewals
+	Logger Logger `json:"-"`
+	// Debug also logs every request's method, URL, status and timing to Logger
+	Debug bool `json:"debug,omitempty"`
+
+	// MaxResponseSize is the largest response body in bytes API() will read, zero means no limit
+	MaxResponseSize int64 `json:"max_response_size,omitempty"`
+
+	// Manifest, when set, records each record written by the Export functions
+	Manifest *ExportManifest `json:"-"`
+
+	// authMu guards AuthToken, renewMu makes sure only one goroutine renews a session
+	authMu  sync.RWMutex
+	renewMu sync.Mutex
+	// throttled counts 429 Too Many Requests responses, see ThrottleCount()
+	throttled int64
 }
 
 // ResponseMsg is a structure to hold the JSON portion of a response from the ArchivesSpaceAPI
@@ -230,6 +291,7 @@ type Accession struct {
 	AccessRestrictionsNote string                   `json:"access_restrictions_note"`
 	UseRestrictions        bool                     `json:"use_restrictions"`
 	UseRestrictionsNote    string                   `json:"use_restrictions_note"`
+	LangMaterials          []map[string]interface{} `json:"lang_materials,omitempty"`
 
 	//	LinkedAgents           []*Agent                 `json:"linked_agents"`
 
@@ -297,16 +359,9 @@ type ActiveEdits struct {
 
 // AdvancedQuery JSONModel(:advanced_query)
 type AdvancedQuery struct {
-	Query map[string]interface{} `json:"query,omitempty"` //FIXME, maybe this should be an interface to boolean_query, field_query, data_field_query,boolean_field_query and Object?
-
-	LockVersion    json.Number       `json:"lock_version,Number"`
-	JSONModelType  string            `json:"jsonmodel_type,omitempty"`
-	CreatedBy      string            `json:"created_by,omitempty,omitempty"`
-	LastModifiedBy string            `json:"last_modified_by,omitempty"`
-	UserMTime      string            `json:"user_mtime,omitempty,omitempty"`
-	SystemMTime    string            `json:"system_mtime,omitempty,omitempty"`
-	CreateTime     string            `json:"create_time,omitempty,omitempty"`
-	Repository     map[string]string `json:"repository,omitempty"`
+	// Query is a *BooleanQuery, *FieldQuery, *DateFieldQuery or *BooleanFieldQuery
+	Query         AdvancedQueryTerm `json:"query"`
+	JSONModelType string            `json:"jsonmodel_type,omitempty"`
 }
 
 // Agent represents an ArchivesSpace complete agent record from the client point of view
@@ -366,6 +421,7 @@ type AgentContact struct {
 
 // AgentCorporateEntity JSONModel(:agent_corporate_entity)
 type AgentCorporateEntity struct {
+	ID                        int                      `json:"id,omitempty"`
 	URI                       string                   `json:"uri,omitempty"`
 	Title                     string                   `json:"title,omitempty"`
 	IsLinkedToPublishedRecord bool                     `json:"is_linked_to_published_record,omitempty"`
@@ -375,7 +431,7 @@ type AgentCorporateEntity struct {
 	ExternalDocuments         []map[string]interface{} `json:"external_documents,omitempty"`
 	RightsStatements          []*RightsStatement       `json:"rights_statements,omitempty"`
 	SystemGenerated           bool                     `json:"system_generated,omitempty"`
-	Notes                     string                   `json:"notes,omitempty"`
+	Notes                     []map[string]interface{} `json:"notes,omitempty"`
 	DatesOfExistance          []*Date                  `json:"dates_of_existence,omitempty"`
 	Publish                   bool                     `json:"publish,omitempty"`
 
@@ -388,25 +444,26 @@ type AgentCorporateEntity struct {
 	CreateTime     string            `json:"create_time,omitempty,omitempty"`
 	Repository     map[string]string `json:"repository,omitempty"`
 
-	Names         []*NameCorporateEntity `json:"names,omitempty"`
-	DisplayName   *NameCorporateEntity   `json:"display_name,omitempty"`
-	RelatedAgents map[string]interface{} `json:"related_agents,omitempty"`
+	Names         []*NameCorporateEntity   `json:"names,omitempty"`
+	DisplayName   *NameCorporateEntity     `json:"display_name,omitempty"`
+	RelatedAgents []map[string]interface{} `json:"related_agents,omitempty"`
 }
 
 // AgentFamily JSONModel(:agent_family)
 type AgentFamily struct {
-	URI                       string              `json:"uri,omitempty"`
-	Title                     string              `json:"title,omitempty"`
-	IsLinkedToPublishedRecord bool                `json:"is_linked_to_published_record,omitempty"`
-	AgentType                 string              `json:"agent_type,omitempty"` //Enum: agent_person agent_corporate_entity agent_software agent_family user
-	AgentContacts             []*AgentContact     `json:"agent_contacts,omitempty"`
-	LinkedAgentRoles          []string            `json:"linked_agent_roles,omitempty"`
-	ExternalDocuments         []*ExternalDocument `json:"external_documents,omitempty"`
-	RightsStatements          []*RightsStatement  `json:"rights_statements,omitempty"`
-	SystemGenerated           bool                `json:"system_generated,omitempty"`
-	Notes                     string              `json:"notes,omitempty"`
-	DatesOfExistance          []*Date             `json:"dates_of_existence,omitempty"`
-	Publish                   bool                `json:"publish,omitempty"`
+	ID                        int                      `json:"id,omitempty"`
+	URI                       string                   `json:"uri,omitempty"`
+	Title                     string                   `json:"title,omitempty"`
+	IsLinkedToPublishedRecord bool                     `json:"is_linked_to_published_record,omitempty"`
+	AgentType                 string                   `json:"agent_type,omitempty"` //Enum: agent_person agent_corporate_entity agent_software agent_family user
+	AgentContacts             []*AgentContact          `json:"agent_contacts,omitempty"`
+	LinkedAgentRoles          []string                 `json:"linked_agent_roles,omitempty"`
+	ExternalDocuments         []*ExternalDocument      `json:"external_documents,omitempty"`
+	RightsStatements          []*RightsStatement       `json:"rights_statements,omitempty"`
+	SystemGenerated           bool                     `json:"system_generated,omitempty"`
+	Notes                     []map[string]interface{} `json:"notes,omitempty"`
+	DatesOfExistance          []*Date                  `json:"dates_of_existence,omitempty"`
+	Publish                   bool                     `json:"publish,omitempty"`
 
 	LockVersion    json.Number       `json:"lock_version,Number"`
 	JSONModelType  string            `json:"jsonmodel_type,omitempty"`
@@ -417,13 +474,14 @@ type AgentFamily struct {
 	CreateTime     string            `json:"create_time,omitempty,omitempty"`
 	Repository     map[string]string `json:"repository,omitempty"`
 
-	Names         []*NameFamily          `json:"names,omitempty"`
-	DisplayName   *NameFamily            `json:"display_name,omitempty"`
-	RelatedAgents map[string]interface{} `json:"related_agents,omitempty"`
+	Names         []*NameFamily            `json:"names,omitempty"`
+	DisplayName   *NameFamily              `json:"display_name,omitempty"`
+	RelatedAgents []map[string]interface{} `json:"related_agents,omitempty"`
 }
 
 // AgentPerson JSONModel(:agent_person)
 type AgentPerson struct {
+	ID                        int                      `json:"id,omitempty"`
 	URI                       string                   `json:"uri,omitempty"`
 	Title                     string                   `json:"title,omitempty"`
 	IsLinkedToPublishedRecord bool                     `json:"is_linked_to_published_record,omitempty"`
@@ -433,7 +491,7 @@ type AgentPerson struct {
 	ExternalDocuments         []map[string]interface{} `json:"external_documents,omitempty"`
 	RightsStatements          []*RightsStatement       `json:"rights_statements,omitempty"`
 	SystemGenerated           bool                     `json:"system_generated,omitempty"`
-	Notes                     string                   `json:"notes,omitempty"`
+	Notes                     []map[string]interface{} `json:"notes,omitempty"`
 	DatesOfExistance          []*Date                  `json:"dates_of_existence,omitempty"`
 	Publish                   bool                     `json:"publish,omitempty"`
 
@@ -446,9 +504,9 @@ type AgentPerson struct {
 	CreateTime     string            `json:"create_time,omitempty,omitempty"`
 	Repository     map[string]string `json:"repository,omitempty"`
 
-	Names         []*NamePerson          `json:"names,omitempty"`
-	DisplayName   *NamePerson            `json:"display_name,omitempty"`
-	RelatedAgents map[string]interface{} `json:"related_agents,omitempty"`
+	Names         []*NamePerson            `json:"names,omitempty"`
+	DisplayName   *NamePerson              `json:"display_name,omitempty"`
+	RelatedAgents []map[string]interface{} `json:"related_agents,omitempty"`
 }
 
 // AgentRelationshipAssociative JSONModel(:agent_relationship_associative)
@@ -529,16 +587,17 @@ type AgentRelationshipSubordinatesuperior struct {
 
 // AgentSoftware JSONModel(:agent_software)
 type AgentSoftware struct {
+	ID                        int                      `json:"id,omitempty"`
 	URI                       string                   `json:"uri,omitempty"`
 	Title                     string                   `json:"title,omitempty"`
 	IsLinkedToPublishedRecord bool                     `json:"is_linked_to_published_record,omitempty"`
 	AgentType                 string                   `json:"agent_type,omitempty"` // ENUM as: agent_person agent_corporate_entity agent_software agent_family user
-	AgentContacts             []*AgentContact          `json:"agent_contacts"`
-	LinkedAgentRoles          string                   `json:"linked_agent_roles,omitempty"`
+	AgentContacts             []*AgentContact          `json:"agent_contacts,omitempty"`
+	LinkedAgentRoles          []string                 `json:"linked_agent_roles,omitempty"`
 	ExternalDocuments         []map[string]interface{} `json:"external_documents,omitempty"`
-	RightsStatements          []*RightsStatement       `json:"rights_statements"`
+	RightsStatements          []*RightsStatement       `json:"rights_statements,omitempty"`
 	SystemGenerated           bool                     `json:"system_generated,omitempty"`
-	Notes                     []*NoteText              `json:"notes,omitmepty"`
+	Notes                     []map[string]interface{} `json:"notes,omitempty"`
 	DatesOfExistance          []*Date                  `json:"dates_of_existence,omitempty"`
 	Publish                   bool                     `json:"publish"`
 
@@ -551,20 +610,25 @@ type AgentSoftware struct {
 	CreateTime     string            `json:"create_time,omitempty,omitempty"`
 	Repository     map[string]string `json:"repository,omitempty"`
 
-	DisplayName *NameSoftware   `json:"display_name,omitempty"`
-	Names       []*NameSoftware `json:"names,omitempty"`
+	DisplayName   *NameSoftware            `json:"display_name,omitempty"`
+	Names         []*NameSoftware          `json:"names,omitempty"`
+	RelatedAgents []map[string]interface{} `json:"related_agents,omitempty"`
 }
 
 // ArchivalObject JSONModel(:archival_object)
 type ArchivalObject struct {
+	ID                int                      `json:"id,omitempty"`
 	URI               string                   `json:"uri,omitempty"`
+	ArkName           *ArkName                 `json:"ark_name,omitempty"`
+	ExternalArkURL    string                   `json:"external_ark_url,omitempty"`
 	ExternalIDs       []*ExternalID            `json:"external_ids"`
 	Title             string                   `json:"title,omitempty"`
 	Language          string                   `json:"language,omitempty"`
 	Publish           bool                     `json:"publish"`
 	Subjects          []map[string]interface{} `json:"subjects"`
-	LinkedEvents      []map[string]interface{} `json:"linked_events,omitmepty"`
+	LinkedEvents      []map[string]interface{} `json:"linked_events,omitempty"`
 	Extents           []*Extent                `json:"extents"`
+	Dates             []*Date                  `json:"dates,omitempty"`
 	ExternalDocuments []map[string]interface{} `json:"external_documents"`
 	RightsStatements  []*RightsStatement       `json:"rights_statements"`
 	LinkedAgents      []*Agent                 `json:"linked_agents"`
@@ -579,20 +643,20 @@ type ArchivalObject struct {
 	CreateTime     string            `json:"create_time,omitempty,omitempty"`
 	Repository     map[string]string `json:"repository,omitempty"`
 
-	RefID                    string                 `json:"ref_id,omitempty"`
-	ConponentID              string                 `json:"component_id,omitempty"`
-	Level                    string                 `json:"level,omitempty"`
-	OtherLevel               string                 `json:"other_level,omitempty"`
-	DisplayString            string                 `json:"display_string,omitempty"`
-	RestrictionsApply        bool                   `json:"restrictions_apply,omitempty"`
-	RepositoryProcessingNote string                 `json:"repository_processing_note,omitempty"`
-	Parent                   map[string]interface{} `json:"parent,omitempty"`
-	Resource                 map[string]interface{} `json:"resource,omitempty"`
-	Series                   map[string]interface{} `json:"series,omitempty"`
-	Position                 int                    `json:"position,omitempty"`
-	Instances                []*Instance            `json:"instances,omitempty"`
-	Notes                    []*NoteText            `json:"notes,omitempty"`
-	HasUnpublishedAncester   bool                   `json:"has_unpublished_ancestor,omitempty"`
+	RefID                    string                   `json:"ref_id,omitempty"`
+	ComponentID              string                   `json:"component_id,omitempty"`
+	Level                    string                   `json:"level,omitempty"`
+	OtherLevel               string                   `json:"other_level,omitempty"`
+	DisplayString            string                   `json:"display_string,omitempty"`
+	RestrictionsApply        bool                     `json:"restrictions_apply,omitempty"`
+	RepositoryProcessingNote string                   `json:"repository_processing_note,omitempty"`
+	Parent                   map[string]interface{}   `json:"parent,omitempty"`
+	Resource                 map[string]interface{}   `json:"resource,omitempty"`
+	Series                   map[string]interface{}   `json:"series,omitempty"`
+	Position                 int                      `json:"position,omitempty"`
+	Instances                []*Instance              `json:"instances,omitempty"`
+	Notes                    []map[string]interface{} `json:"notes,omitempty"`
+	HasUnpublishedAncester   bool                     `json:"has_unpublished_ancestor,omitempty"`
 }
 
 // ArchivalRecordChildren JSONModel(:archival_record_children)
@@ -609,10 +673,45 @@ type ArchivalRecordChildren struct {
 	Repository     map[string]string `json:"repository,omitempty"`
 }
 
-// BooleanFieldQuery JSONModel(:boolean_field_query)
-type BooleanFieldQuery struct {
-	Field string `json:"field,omitempty"`
-	Value bool   `json:"value,omitempty"`
+// ArkName JSONModel(:ark_name) holds a record's current ARK and those it had before
+type ArkName struct {
+	Current           string   `json:"current,omitempty"`
+	CurrentIsExternal bool     `json:"current_is_external,omitempty"`
+	Previous          []string `json:"previous,omitempty"`
+	JSONModelType     string   `json:"jsonmodel_type,omitempty"`
+}
+
+// Assessment JSONModel(:assessment)
+type Assessment struct {
+	ID  int    `json:"id,omitempty"`
+	URI string `json:"uri,omitempty"`
+	// Records, SurveyedBy and Reviewer are lists of refs to the records assessed and the agents involved
+	Records                  []map[string]interface{} `json:"records,omitempty"`
+	SurveyedBy               []map[string]interface{} `json:"surveyed_by,omitempty"`
+	Reviewer                 []map[string]interface{} `json:"reviewer,omitempty"`
+	SurveyBegin              string                   `json:"survey_begin,omitempty"`
+	SurveyEnd                string                   `json:"survey_end,omitempty"`
+	SurveyedDuration         string                   `json:"surveyed_duration,omitempty"`
+	SurveyedExtent           string                   `json:"surveyed_extent,omitempty"`
+	ReviewRequired           bool                     `json:"review_required,omitempty"`
+	ReviewNote               string                   `json:"review_note,omitempty"`
+	Purpose                  string                   `json:"purpose,omitempty"`
+	Scope                    string                   `json:"scope,omitempty"`
+	SensitiveMaterial        bool                     `json:"sensitive_material,omitempty"`
+	GeneralAssessmentNote    string                   `json:"general_assessment_note,omitempty"`
+	SpecialFormatNote        string                   `json:"special_format_note,omitempty"`
+	ExhibitionValueNote      string                   `json:"exhibition_value_note,omitempty"`
+	MonetaryValue            string                   `json:"monetary_value,omitempty"`
+	MonetaryValueNote        string                   `json:"monetary_value_note,omitempty"`
+	ConservationNote         string                   `json:"conservation_note,omitempty"`
+	Inactive                 bool                     `json:"inactive,omitempty"`
+	Ratings                  []*AssessmentAttribute   `json:"ratings,omitempty"`
+	Formats                  []*AssessmentAttribute   `json:"formats,omitempty"`
+	ConservationIssues       []*AssessmentAttribute   `json:"conservation_issues,omitempty"`
+	ExternalDocuments        []map[string]interface{} `json:"external_documents,omitempty"`
+	Collections              []map[string]interface{} `json:"collections,omitempty"`
+	ExternalIDs              []*ExternalID            `json:"external_ids,omitempty"`
+	AssessmentAttributeNotes []map[string]interface{} `json:"assessment_attribute_notes,omitempty"`
 
 	LockVersion    json.Number       `json:"lock_version,Number"`
 	JSONModelType  string            `json:"jsonmodel_type,omitempty"`
@@ -624,32 +723,64 @@ type BooleanFieldQuery struct {
 	Repository     map[string]string `json:"repository,omitempty"`
 }
 
+// AssessmentAttribute JSONModel(:assessment_attribute) is a rating, format or
+// conservation issue recorded in an Assessment. DefinitionID identifies its
+// assessment attribute definition, Label and Global are filled in by ArchivesSpace.
+type AssessmentAttribute struct {
+	DefinitionID int    `json:"definition_id"`
+	Value        string `json:"value,omitempty"`
+	Note         string `json:"note,omitempty"`
+	Label        string `json:"label,omitempty"`
+	Global       bool   `json:"global,omitempty"`
+
+	JSONModelType string `json:"jsonmodel_type,omitempty"`
+}
+
+// AssessmentAttributeDefinitions JSONModel(:assessment_attribute_definitions) lists the
+// ratings, formats and conservation issues available to a Repository's assessments
+type AssessmentAttributeDefinitions struct {
+	Definitions []*AssessmentAttributeDefinition `json:"definitions"`
+
+	LockVersion   json.Number `json:"lock_version,omitempty"`
+	JSONModelType string      `json:"jsonmodel_type,omitempty"`
+}
+
+// AssessmentAttributeDefinition describes one rating, format or conservation issue.
+// Global definitions are shared by all repositories and can't be changed.
+type AssessmentAttributeDefinition struct {
+	ID       int    `json:"id,omitempty"`
+	Label    string `json:"label"`
+	Type     string `json:"type"` // ENUM as: rating format conservation_issue
+	Global   bool   `json:"global,omitempty"`
+	Position int    `json:"position,omitempty"`
+	Readonly bool   `json:"readonly,omitempty"`
+}
+
+// BooleanFieldQuery JSONModel(:boolean_field_query)
+type BooleanFieldQuery struct {
+	Field         string `json:"field"`
+	Value         bool   `json:"value"`
+	JSONModelType string `json:"jsonmodel_type,omitempty"`
+}
+
 // BooleanQuery JSONModel(:boolean_query)
 type BooleanQuery struct {
-	Op string `json:"op,omitempty"` // ENUM as: string AND OR NOT
-	//FIXME: this needs to be re-thought, do I use an interface type, a struct?
-	Subqueries map[string]interface{} `json:"subqueries,omitempty"` // One of 	JSONModel(:boolean_query) object,JSONModel(:field_query) object,JSONModel(:boolean_field_query) object,JSONModel(:date_field_query) object
-
-	LockVersion    json.Number       `json:"lock_version,Number"`
-	JSONModelType  string            `json:"jsonmodel_type,omitempty"`
-	CreatedBy      string            `json:"created_by,omitempty,omitempty"`
-	LastModifiedBy string            `json:"last_modified_by,omitempty"`
-	UserMTime      string            `json:"user_mtime,omitempty,omitempty"`
-	SystemMTime    string            `json:"system_mtime,omitempty,omitempty"`
-	CreateTime     string            `json:"create_time,omitempty,omitempty"`
-	Repository     map[string]string `json:"repository,omitempty"`
+	Op            string              `json:"op"` // ENUM as: string AND OR NOT
+	Subqueries    []AdvancedQueryTerm `json:"subqueries"`
+	JSONModelType string              `json:"jsonmodel_type,omitempty"`
 }
 
 // Classification JSONModel(:classification)
 type Classification struct {
-	URI           string                 `json:"uri,omitempty"`
-	Identifier    string                 `json:"identifier,omitempty"`
-	Title         string                 `json:"title,omitempty"`
-	Description   string                 `json:"description,omitempty"`
-	Publish       bool                   `json:"publish,omitempty"` //NOTE: default should true
-	PathFromRoot  map[string]interface{} `json:"path_from_root,omitempty"`
-	LinkedRecords map[string]interface{} `json:"linked_records,omitempty"`
-	Creator       map[string]interface{} `json:"creator,omitempty"`
+	ID            int                      `json:"id,omitempty"`
+	URI           string                   `json:"uri,omitempty"`
+	Identifier    string                   `json:"identifier,omitempty"`
+	Title         string                   `json:"title,omitempty"`
+	Description   string                   `json:"description,omitempty"`
+	Publish       bool                     `json:"publish"` //NOTE: ArchivesSpace defaults to true
+	PathFromRoot  []map[string]interface{} `json:"path_from_root,omitempty"`
+	LinkedRecords []map[string]interface{} `json:"linked_records,omitempty"`
+	Creator       map[string]interface{}   `json:"creator,omitempty"`
 
 	LockVersion    json.Number `json:"lock_version,Number"`
 	JSONModelType  string      `json:"jsonmodel_type,omitempty"`
@@ -662,14 +793,15 @@ type Classification struct {
 
 // ClassificationTerm JSONModel(:classification_term)
 type ClassificationTerm struct {
-	URI           string                 `json:"uri,omitempty"`
-	Identifier    string                 `json:"identifier,omitempty"`
-	Title         string                 `json:"title,omitempty"`
-	Description   string                 `json:"description,omitempty"`
-	Publish       bool                   `json:"publish,omitempty"` //NOTE: default should true
-	PathFromRoot  map[string]interface{} `json:"path_from_root,omitempty"`
-	LinkedRecords map[string]interface{} `json:"linked_records,omitempty"`
-	Creator       map[string]interface{} `json:"creator,omitempty"`
+	ID            int                      `json:"id,omitempty"`
+	URI           string                   `json:"uri,omitempty"`
+	Identifier    string                   `json:"identifier,omitempty"`
+	Title         string                   `json:"title,omitempty"`
+	Description   string                   `json:"description,omitempty"`
+	Publish       bool                     `json:"publish"` //NOTE: ArchivesSpace defaults to true
+	PathFromRoot  []map[string]interface{} `json:"path_from_root,omitempty"`
+	LinkedRecords []map[string]interface{} `json:"linked_records,omitempty"`
+	Creator       map[string]interface{}   `json:"creator,omitempty"`
 
 	LockVersion    json.Number       `json:"lock_version,Number"`
 	JSONModelType  string            `json:"jsonmodel_type,omitempty"`
@@ -731,14 +863,16 @@ type ClassificationTree struct {
 
 // CollectionManagement JSONModel(:collection_management)
 type CollectionManagement struct {
+	ID                             int           `json:"id,omitempty"`
 	URI                            string        `json:"uri,omitempty"`
-	ExternalIDs                    []*ExternalID `json:"external_ids"`
+	ExternalIDs                    []*ExternalID `json:"external_ids,omitempty"`
 	ProcessingHoursPerFootEstimate string        `json:"processing_hours_per_foot_estimate,omitempty"`
 	ProcessingTotalExtent          string        `json:"processing_total_extent,omitempty"`
 	ProcessingTotalExtentType      string        `json:"processing_total_extent_type,omitempty"`
 	ProcessingHoursTotal           string        `json:"processing_hours_total,omitempty"`
 	ProcessingPlan                 string        `json:"processing_plan,omitempty"`
 	ProcessingPriority             string        `json:"processing_priority,omitempty"`
+	ProcessingStatus               string        `json:"processing_status,omitempty"`
 	ProcessingFundingSource        string        `json:"processing_funding_source,omitempty"`
 	Processors                     string        `json:"processors,omitempty"`
 	RightsDetermined               bool          `json:"rights_determined,omitempty"`
@@ -778,12 +912,16 @@ type Container struct {
 }
 
 // ContainerLocation JSONModel(:container_location)
+//
+// StartDate and EndDate are "YYYY-MM-DD" strings and the location URI is in Ref
+// ("ref"), matching what ArchivesSpace sends. Earlier releases declared them as
+// *Date and "location" which couldn't decode a real container_location.
 type ContainerLocation struct {
 	Status    string                 `json:"status,omitempty"`
-	StartDate *Date                  `json:"start_date,omitempty"`
-	EndDate   *Date                  `json:"end_date,omitempty"`
+	StartDate string                 `json:"start_date,omitempty"`
+	EndDate   string                 `json:"end_date,omitempty"`
 	Note      string                 `json:"note,omitempty"`
-	Ref       string                 `json:"location,omitempty"`
+	Ref       string                 `json:"ref,omitempty"`
 	Resolved  map[string]interface{} `json:"_resolved,omitempty"`
 
 	LockVersion    json.Number       `json:"lock_version,Number"`
@@ -798,14 +936,16 @@ type ContainerLocation struct {
 
 // ContainerProfile JSONModel(:container_profile)
 type ContainerProfile struct {
+	ID              int    `json:"id,omitempty"`
 	URI             string `json:"uri,omitempty"`
 	Name            string `json:"name,omitempty"`
 	URL             string `json:"url,omitempty"`
 	DimensionUnits  string `json:"dimension_units,omitempty"`
-	ExtentDimension string `json:"extent_dimension,omitempty" ` //ENUM as: height width depth
+	ExtentDimension string `json:"extent_dimension,omitempty"` //ENUM as: height width depth
 	Height          string `json:"height,omitempty"`
 	Width           string `json:"width,omitempty"`
-	Depth           string `json:"width,omitempty"`
+	Depth           string `json:"depth,omitempty"`
+	StackingLimit   string `json:"stacking_limit,omitempty"`
 	DisplayString   string `json:"display_string,omitempty"`
 
 	LockVersion    json.Number       `json:"lock_version,Number"`
@@ -841,18 +981,11 @@ type Date struct {
 
 // DateFieldQuery JSONModel(:date_field_query)
 type DateFieldQuery struct {
-	Comparator string `json:"comparator,omitempty"` // ENUM as: greater_than lesser_than equal
-	Field      string `json:"field,omitempty"`
-	Value      *Date  `json:"value,omitempty"`
-
-	LockVersion    json.Number       `json:"lock_version,Number"`
-	JSONModelType  string            `json:"jsonmodel_type,omitempty"`
-	CreatedBy      string            `json:"created_by,omitempty,omitempty"`
-	LastModifiedBy string            `json:"last_modified_by,omitempty"`
-	UserMTime      string            `json:"user_mtime,omitempty,omitempty"`
-	SystemMTime    string            `json:"system_mtime,omitempty,omitempty"`
-	CreateTime     string            `json:"create_time,omitempty,omitempty"`
-	Repository     map[string]string `json:"repository,omitempty"`
+	Comparator    string `json:"comparator,omitempty"` // ENUM as: greater_than lesser_than equal empty
+	Field         string `json:"field"`
+	Value         string `json:"value,omitempty"` // YYYY-MM-DD
+	Negated       bool   `json:"negated,omitempty"`
+	JSONModelType string `json:"jsonmodel_type,omitempty"`
 }
 
 // Deaccession JSONModel(:deaccession)
@@ -875,6 +1008,26 @@ type Deaccession struct {
 	Repository     map[string]string `json:"repository,omitempty"`
 }
 
+// CustomReportTemplate JSONModel(:custom_report_template), Data is the template's
+// fields and conditions as JSON text
+type CustomReportTemplate struct {
+	ID          int    `json:"id,omitempty"`
+	URI         string `json:"uri,omitempty"`
+	Name        string `json:"name"`
+	Description string `json:"description,omitempty"`
+	Limit       int    `json:"limit,omitempty"`
+	Data        string `json:"data"`
+
+	LockVersion    json.Number       `json:"lock_version,Number"`
+	JSONModelType  string            `json:"jsonmodel_type,omitempty"`
+	CreatedBy      string            `json:"created_by,omitempty,omitempty"`
+	LastModifiedBy string            `json:"last_modified_by,omitempty"`
+	UserMTime      string            `json:"user_mtime,omitempty,omitempty"`
+	SystemMTime    string            `json:"system_mtime,omitempty,omitempty"`
+	CreateTime     string            `json:"create_time,omitempty,omitempty"`
+	Repository     map[string]string `json:"repository,omitempty"`
+}
+
 // DefaultValues JSONModel(:default_values)
 type DefaultValues struct {
 	URI        string                 `json:"uri,omitempty"`
@@ -891,10 +1044,27 @@ type DefaultValues struct {
 	Repository     map[string]string `json:"repository,omitempty"`
 }
 
+// RequiredFields JSONModel(:required_fields) lists the fields a repository requires
+// beyond the schema's own, for records of RecordType and their subrecords
+type RequiredFields struct {
+	URI                   string                   `json:"uri,omitempty"`
+	RecordType            string                   `json:"record_type,omitempty"`
+	SubrecordRequirements []map[string]interface{} `json:"subrecord_requirements"`
+
+	LockVersion    json.Number       `json:"lock_version,Number"`
+	JSONModelType  string            `json:"jsonmodel_type,omitempty"`
+	CreatedBy      string            `json:"created_by,omitempty,omitempty"`
+	LastModifiedBy string            `json:"last_modified_by,omitempty"`
+	UserMTime      string            `json:"user_mtime,omitempty,omitempty"`
+	SystemMTime    string            `json:"system_mtime,omitempty,omitempty"`
+	CreateTime     string            `json:"create_time,omitempty,omitempty"`
+	Repository     map[string]string `json:"repository,omitempty"`
+}
+
 // Defaults JSONModel(:defaults)
 type Defaults struct {
-	ShowSuppressed             bool   `json:"show_suppressed,omitempty"`
-	Publish                    bool   `json:"publish,omitempty"`
+	ShowSuppressed             bool   `json:"show_suppressed"`
+	Publish                    bool   `json:"publish"`
 	AccessionBrowseColumn1     string `json:"accession_browse_column_1,omitempty"`      // enum string identifier accession_date acquisition_type resource_type restrictions_apply access_restrictions use_restrictions publish no_value
 	AccessionBrowseColumn2     string `json:"accession_browse_column_2,omitempty"`      // enum string identifier accession_date acquisition_type resource_type restrictions_apply access_restrictions use_restrictions publish no_value
 	AccessionBrowseColumn3     string `json:"accession_browse_column_3,omitempty"`      // enum string identifier accession_date acquisition_type resource_type restrictions_apply access_restrictions use_restrictions publish no_value
@@ -910,7 +1080,7 @@ type Defaults struct {
 	DigitalObjectBrowseColumn3 string `json:"digital_object_browse_column_3,omitempty"` // enum string digital_object_id digital_object_type level restrictions publish no_value
 	DigitalObjectBrowseColumn4 string `json:"digital_object_browse_column_4,omitempty"` // enum string digital_object_id digital_object_type level restrictions publish no_value
 	DigitalObjectBrowseColumn5 string `json:"digital_object_browse_column_5,omitempty"` // enum string digital_object_id digital_object_type level restrictions publish no_value
-	DefaultValues              bool   `json:"default_values,omitempty"`
+	DefaultValues              bool   `json:"default_values"`
 	NoteOrder                  string `json:"note_order,omitempty"`
 
 	LockVersion    json.Number       `json:"lock_version,Number"`
@@ -926,8 +1096,8 @@ type Defaults struct {
 // DigitalObject represents a digital object that will eventually become a EAD at COA
 type DigitalObject struct {
 	ID                int                      `json:"id,omitempty"`
-	URI               string                   `json:"uri,omitmepty"`
-	ExternalIDs       []string                 `json:"external_ids"`
+	URI               string                   `json:"uri,omitempty"`
+	ExternalIDs       []*ExternalID            `json:"external_ids"`
 	Title             string                   `json:"title,omitempty"`
 	Language          string                   `json:"language,omitempty"`
 	Publish           bool                     `json:"publish"`
@@ -938,7 +1108,7 @@ type DigitalObject struct {
 	ExternalDocuments []map[string]interface{} `json:"external_documents"`
 	RightsStatements  []*RightsStatement       `json:"rights_statements"`
 	LinkedAgents      []*Agent                 `json:"linked_agents"`
-	Suppressed        bool                     `json:"suppressed,omitmepty"`
+	Suppressed        bool                     `json:"suppressed,omitempty"`
 
 	LockVersion    json.Number       `json:"lock_version,Number"`
 	JSONModelType  string            `json:"jsonmodel_type,omitempty"`
@@ -949,20 +1119,21 @@ type DigitalObject struct {
 	CreateTime     string            `json:"create_time,omitempty,omitempty"`
 	Repository     map[string]string `json:"repository,omitempty"`
 
-	DigitalObjectID      string                   `json:"digital_object_id,omitmepty"`
-	Level                string                   `json:"level,omitmepty"`
+	DigitalObjectID      string                   `json:"digital_object_id,omitempty"`
+	Level                string                   `json:"level,omitempty"`
 	DigitalObjectType    string                   `json:"digital_object_type"`
 	FileVersions         []*FileVersion           `json:"file_versions,omitempty"`
-	Restrictions         bool                     `json:"restrictions,omitmepty"`
-	Tree                 map[string]interface{}   `json:"tree,omitmepty"`
-	Notes                []map[string]interface{} `json:"notes,omitmepty"`
+	Restrictions         bool                     `json:"restrictions,omitempty"`
+	Tree                 map[string]interface{}   `json:"tree,omitempty"`
+	Notes                []map[string]interface{} `json:"notes,omitempty"`
 	CollectionManagement *CollectionManagement    `json:"collection_management,omitempty"`
-	UserDefined          []map[string]interface{} `json:"user_defined,omitmepty"`
+	UserDefined          []map[string]interface{} `json:"user_defined,omitempty"`
 	LinkedInstances      []map[string]interface{} `json:"linked_instances,omitempty"`
 }
 
 // DigitalObjectComponent JSONModel(:digital_object_component)
 type DigitalObjectComponent struct {
+	ID                int                      `json:"id,omitempty"`
 	URI               string                   `json:"uri,omitempty"`
 	ExternalIDs       []*ExternalID            `json:"external_ids,omitempty"`
 	Title             string                   `json:"title,omitempty"`
@@ -986,15 +1157,15 @@ type DigitalObjectComponent struct {
 	CreateTime     string            `json:"create_time,omitempty,omitempty"`
 	Repository     map[string]string `json:"repository,omitempty"`
 
-	ComponentID            string                 `json:"component_id,omitempty"`
-	Label                  string                 `json:"label,omitempty"`
-	DisplayString          string                 `json:"display_string,omitempty"`
-	FileVersions           []*FileVersion         `json:"file_versions,omitempty"`
-	Parent                 map[string]interface{} `json:"parent,omitempty"`
-	DigitalObject          *DigitalObject         `json:"digital_object,omitempty"`
-	Position               int                    `json:"position,omitempty"`
-	Notes                  []*NoteText            `json:"notes,omitempty"`
-	HasUnpublishedAncestor bool                   `json:"has_unpublished_ancestor,omitempty"`
+	ComponentID            string                   `json:"component_id,omitempty"`
+	Label                  string                   `json:"label,omitempty"`
+	DisplayString          string                   `json:"display_string,omitempty"`
+	FileVersions           []*FileVersion           `json:"file_versions,omitempty"`
+	Parent                 map[string]interface{}   `json:"parent,omitempty"`
+	DigitalObject          map[string]interface{}   `json:"digital_object,omitempty"`
+	Position               int                      `json:"position,omitempty"`
+	Notes                  []map[string]interface{} `json:"notes,omitempty"`
+	HasUnpublishedAncestor bool                     `json:"has_unpublished_ancestor,omitempty"`
 }
 
 // DigitalObjectTree JSONModel(:digital_object_tree)
@@ -1039,6 +1210,7 @@ type DigitalRecordChildren struct {
 
 // Enumeration JSONModel(:enumeration)
 type Enumeration struct {
+	ID                int                 `json:"id,omitempty"`
 	URI               string              `json:"uri,omitempty"`
 	Name              string              `json:"name,omitempty"`
 	DefaultValue      string              `json:"default_value,omitempty"`
@@ -1077,6 +1249,7 @@ type EnumerationMigration struct {
 
 // EnumerationValue JSONModel(:enumeration_value)
 type EnumerationValue struct {
+	ID         int    `json:"id,omitempty"`
 	URI        string `json:"uri,omitempty"`
 	Value      string `json:"value,omitempty"`
 	Position   int    `json:"position,omitempty"`
@@ -1094,6 +1267,7 @@ type EnumerationValue struct {
 
 // Event JSONModel(:event)
 type Event struct {
+	ID                int                      `json:"id,omitempty"`
 	URI               string                   `json:"uri,omitempty"`
 	ExternalIDs       []*ExternalID            `json:"external_ids,omitempty"`
 	ExternalDocuments []map[string]interface{} `json:"external_documents,omitempty"`
@@ -1103,8 +1277,8 @@ type Event struct {
 	Outcome           string                   `json:"outcome,omitempty"`
 	OutcomeNote       string                   `json:"outcome_note,omitempty"`
 	Suppressed        bool                     `json:"suppressed,omitempty"`
-	LinkedAgents      []*Agent                 `json:"linked_agents,omitempty"`
-	LinkedRecords     map[string]interface{}   `json:"linked_records,omitempty"`
+	LinkedAgents      []map[string]interface{} `json:"linked_agents,omitempty"`
+	LinkedRecords     []map[string]interface{} `json:"linked_records,omitempty"`
 
 	LockVersion    json.Number       `json:"lock_version,Number"`
 	JSONModelType  string            `json:"jsonmodel_type,omitempty"`
@@ -1168,19 +1342,12 @@ type ExternalID struct {
 
 // FieldQuery JSONModel(:field_query)
 type FieldQuery struct {
-	Negated bool   `json:"negated,omitempty"`
-	Field   string `json:"field,omitempty"`
-	Value   string `json:"value,omitempty"`
-	Literal bool   `json:"literal,omitempty"`
-
-	LockVersion    json.Number       `json:"lock_version,Number"`
-	JSONModelType  string            `json:"jsonmodel_type,omitempty"`
-	CreatedBy      string            `json:"created_by,omitempty,omitempty"`
-	LastModifiedBy string            `json:"last_modified_by,omitempty"`
-	UserMTime      string            `json:"user_mtime,omitempty,omitempty"`
-	SystemMTime    string            `json:"system_mtime,omitempty,omitempty"`
-	CreateTime     string            `json:"create_time,omitempty,omitempty"`
-	Repository     map[string]string `json:"repository,omitempty"`
+	Negated       bool   `json:"negated,omitempty"`
+	Field         string `json:"field"`
+	Value         string `json:"value,omitempty"`
+	Literal       bool   `json:"literal,omitempty"`
+	Comparator    string `json:"comparator,omitempty"` // ENUM as: contains empty
+	JSONModelType string `json:"jsonmodel_type,omitempty"`
 }
 
 // FileVersion JSONModel(:file_version)
@@ -1196,6 +1363,8 @@ type FileVersion struct {
 	FileSizeBytes         int    `json:"file_size_bytes,omitempty"`
 	Checksum              string `json:"checksum,omitempty"`
 	ChecksumMethod        string `json:"checksum_method,omitempty"`
+	IsRepresentative      bool   `json:"is_representative,omitempty"`
+	Caption               string `json:"caption,omitempty"`
 
 	LockVersion    json.Number       `json:"lock_version,Number"`
 	JSONModelType  string            `json:"jsonmodel_type,omitempty"`
@@ -1227,6 +1396,7 @@ type FindAndReplaceJob struct {
 
 // Group JSONModel(:group)
 type Group struct {
+	ID                int      `json:"id,omitempty"`
 	URI               string   `json:"uri,omitempty"`
 	GroupCode         string   `json:"group_code,omitempty"`
 	Description       string   `json:"description,omitempty"`
@@ -1260,10 +1430,11 @@ type ImportJob struct {
 
 // Instance JSONModel(:instance)
 type Instance struct {
-	InstanceType  string                 `json:"instance_type,omitempty"`
-	Container     *Container             `json:"container,omitempty"`
-	SubContainer  *SubContainer          `json:"sub_container,omitempty"`
-	DigitalObject map[string]interface{} `json:"digital_object,omitempty"`
+	InstanceType     string                 `json:"instance_type,omitempty"`
+	Container        *Container             `json:"container,omitempty"`
+	SubContainer     *SubContainer          `json:"sub_container,omitempty"`
+	DigitalObject    map[string]interface{} `json:"digital_object,omitempty"`
+	IsRepresentative bool                   `json:"is_representative,omitempty"`
 
 	LockVersion    json.Number       `json:"lock_version,Number"`
 	JSONModelType  string            `json:"jsonmodel_type,omitempty"`
@@ -1277,6 +1448,7 @@ type Instance struct {
 
 // Job JSONModel(:job)
 type Job struct {
+	ID            int                    `json:"id,omitempty"`
 	URI           string                 `json:"uri,omitempty"`
 	JobType       string                 `json:"job_type,omitempty"`
 	Job           map[string]interface{} `json:"job,omitempty"`
@@ -1305,18 +1477,22 @@ type Location struct {
 	Title                string        `json:"title,omitempty"`
 	ExternalIDs          []*ExternalID `json:"external_ids,omitempty"`
 	Building             string        `json:"building,omitempty"`
-	Floor                string        `json:"Floor,omitempty"`
-	Room                 string        `json:"Room,omitempty"`
+	Floor                string        `json:"floor,omitempty"`
+	Room                 string        `json:"room,omitempty"`
 	Area                 string        `json:"area,omitempty"`
 	Barcode              string        `json:"barcode,omitempty"`
-	Classification       `json:"string,omitempty"`
-	Coordinate1Label     string `json:"coordinatel_1_label"`
-	Coordinate1Indicator string `json:"coordinate_1_indicator,omitempty"`
-	Coordinate2Label     string `json:"coordinate_2_label,omitempty"`
-	Coordinate2Indicator string `json:"coordinate_2_indicator,omitempty"`
-	Coordinate3Label     string `json:"coordinate_3_label,omitempty"`
-	Coordinate3Indicator string `json:"coordinate_3_indicator,omitempty"`
-	Temporary            string `json:"temporary,omitempty"`
+	Classification       string        `json:"classification,omitempty"`
+	Coordinate1Label     string        `json:"coordinate_1_label,omitempty"`
+	Coordinate1Indicator string        `json:"coordinate_1_indicator,omitempty"`
+	Coordinate2Label     string        `json:"coordinate_2_label,omitempty"`
+	Coordinate2Indicator string        `json:"coordinate_2_indicator,omitempty"`
+	Coordinate3Label     string        `json:"coordinate_3_label,omitempty"`
+	Coordinate3Indicator string        `json:"coordinate_3_indicator,omitempty"`
+	Temporary            string        `json:"temporary,omitempty"`
+	TemporaryQuestion    bool          `json:"temporary_question,omitempty"`
+	// OwnerRepo is a ref to the repository which owns the location, if any
+	OwnerRepo map[string]interface{}   `json:"owner_repo,omitempty"`
+	Functions []map[string]interface{} `json:"functions,omitempty"`
 
 	LockVersion    json.Number       `json:"lock_version,Number"`
 	JSONModelType  string            `json:"jsonmodel_type,omitempty"`
@@ -1334,18 +1510,18 @@ type LocationBatch struct {
 	Title                string        `json:"title,omitempty"`
 	ExternalIDs          []*ExternalID `json:"external_ids,omitempty"`
 	Building             string        `json:"building,omitempty"`
-	Floor                string        `json:"Floor,omitempty"`
-	Room                 string        `json:"Room,omitempty"`
+	Floor                string        `json:"floor,omitempty"`
+	Room                 string        `json:"room,omitempty"`
 	Area                 string        `json:"area,omitempty"`
 	Barcode              string        `json:"barcode,omitempty"`
-	Classification       `json:"string,omitempty"`
-	Coordinate1Label     string `json:"coordinatel_1_label"`
-	Coordinate1Indicator string `json:"coordinate_1_indicator,omitempty"`
-	Coordinate2Label     string `json:"coordinate_2_label,omitempty"`
-	Coordinate2Indicator string `json:"coordinate_2_indicator,omitempty"`
-	Coordinate3Label     string `json:"coordinate_3_label,omitempty"`
-	Coordinate3Indicator string `json:"coordinate_3_indicator,omitempty"`
-	Temporary            string `json:"temporary,omitempty"`
+	Classification       string        `json:"classification,omitempty"`
+	Coordinate1Label     string        `json:"coordinate_1_label,omitempty"`
+	Coordinate1Indicator string        `json:"coordinate_1_indicator,omitempty"`
+	Coordinate2Label     string        `json:"coordinate_2_label,omitempty"`
+	Coordinate2Indicator string        `json:"coordinate_2_indicator,omitempty"`
+	Coordinate3Label     string        `json:"coordinate_3_label,omitempty"`
+	Coordinate3Indicator string        `json:"coordinate_3_indicator,omitempty"`
+	Temporary            string        `json:"temporary,omitempty"`
 
 	LockVersion    json.Number       `json:"lock_version,Number"`
 	JSONModelType  string            `json:"jsonmodel_type,omitempty"`
@@ -1356,10 +1532,20 @@ type LocationBatch struct {
 	CreateTime     string            `json:"create_time,omitempty,omitempty"`
 	Repository     map[string]string `json:"repository,omitempty"`
 
-	Locations        []*Location            `json:"locations,omitempty"`
-	Coordinate1Range map[string]interface{} `json:"coordinate_1_range,omitempty"`
-	Coordinate2Range map[string]interface{} `json:"coordinate_2_range,omitempty"`
-	Coordinate3Range map[string]interface{} `json:"coordinate_3_range,omitempty"`
+	Locations        []*Location      `json:"locations,omitempty"`
+	Coordinate1Range *CoordinateRange `json:"coordinate_1_range,omitempty"`
+	Coordinate2Range *CoordinateRange `json:"coordinate_2_range,omitempty"`
+	Coordinate3Range *CoordinateRange `json:"coordinate_3_range,omitempty"`
+}
+
+// CoordinateRange describes a run of location coordinates in a LocationBatch,
+// e.g. {Label: "Shelf", Start: "1", End: "7"}. Start and End may be letters.
+type CoordinateRange struct {
+	Label  string `json:"label"`
+	Prefix string `json:"prefix,omitempty"`
+	Start  string `json:"start"`
+	End    string `json:"end"`
+	Suffix string `json:"suffix,omitempty"`
 }
 
 // LocationBatchUpdate JSONModel(:location_batch_update)
@@ -1368,18 +1554,41 @@ type LocationBatchUpdate struct {
 	Title                string        `json:"title,omitempty"`
 	ExternalIDs          []*ExternalID `json:"external_ids,omitempty"`
 	Building             string        `json:"building,omitempty"`
-	Floor                string        `json:"Floor,omitempty"`
-	Room                 string        `json:"Room,omitempty"`
+	Floor                string        `json:"floor,omitempty"`
+	Room                 string        `json:"room,omitempty"`
 	Area                 string        `json:"area,omitempty"`
 	Barcode              string        `json:"barcode,omitempty"`
-	Classification       `json:"string,omitempty"`
-	Coordinate1Label     string `json:"coordinatel_1_label"`
-	Coordinate1Indicator string `json:"coordinate_1_indicator,omitempty"`
-	Coordinate2Label     string `json:"coordinate_2_label,omitempty"`
-	Coordinate2Indicator string `json:"coordinate_2_indicator,omitempty"`
-	Coordinate3Label     string `json:"coordinate_3_label,omitempty"`
-	Coordinate3Indicator string `json:"coordinate_3_indicator,omitempty"`
-	Temporary            string `json:"temporary,omitempty"`
+	Classification       string        `json:"classification,omitempty"`
+	Coordinate1Label     string        `json:"coordinate_1_label,omitempty"`
+	Coordinate1Indicator string        `json:"coordinate_1_indicator,omitempty"`
+	Coordinate2Label     string        `json:"coordinate_2_label,omitempty"`
+	Coordinate2Indicator string        `json:"coordinate_2_indicator,omitempty"`
+	Coordinate3Label     string        `json:"coordinate_3_label,omitempty"`
+	Coordinate3Indicator string        `json:"coordinate_3_indicator,omitempty"`
+	Temporary            string        `json:"temporary,omitempty"`
+
+	LockVersion    json.Number       `json:"lock_version,Number"`
+	JSONModelType  string            `json:"jsonmodel_type,omitempty"`
+	CreatedBy      string            `json:"created_by,omitempty,omitempty"`
+	LastModifiedBy string            `json:"last_modified_by,omitempty"`
+	UserMTime      string            `json:"user_mtime,omitempty,omitempty"`
+	SystemMTime    string            `json:"system_mtime,omitempty,omitempty"`
+	CreateTime     string            `json:"create_time,omitempty,omitempty"`
+	Repository     map[string]string `json:"repository,omitempty"`
+
+	RecordURIs []string `json:"record_uris,omitempty"`
+}
+
+// LocationProfile JSONModel(:location_profile)
+type LocationProfile struct {
+	ID             int    `json:"id,omitempty"`
+	URI            string `json:"uri,omitempty"`
+	Name           string `json:"name,omitempty"`
+	DimensionUnits string `json:"dimension_units,omitempty"`
+	Height         string `json:"height,omitempty"`
+	Width          string `json:"width,omitempty"`
+	Depth          string `json:"depth,omitempty"`
+	DisplayString  string `json:"display_string,omitempty"`
 
 	LockVersion    json.Number       `json:"lock_version,Number"`
 	JSONModelType  string            `json:"jsonmodel_type,omitempty"`
@@ -1389,15 +1598,36 @@ type LocationBatchUpdate struct {
 	SystemMTime    string            `json:"system_mtime,omitempty,omitempty"`
 	CreateTime     string            `json:"create_time,omitempty,omitempty"`
 	Repository     map[string]string `json:"repository,omitempty"`
+}
+
+// OAIConfig JSONModel(:oai_config) configures the OAI-PMH harvest interface. RepoSetCodes
+// and SponsorSetNames hold JSON encoded lists, see SetRepoSetCodes and SetSponsorSetNames.
+type OAIConfig struct {
+	URI                   string `json:"uri,omitempty"`
+	OAIRecordPrefix       string `json:"oai_record_prefix,omitempty"`
+	OAIAdminEmail         string `json:"oai_admin_email,omitempty"`
+	OAIRepositoryName     string `json:"oai_repository_name,omitempty"`
+	RepoSetName           string `json:"repo_set_name,omitempty"`
+	RepoSetCodes          string `json:"repo_set_codes,omitempty"`
+	RepoSetDescription    string `json:"repo_set_description,omitempty"`
+	SponsorSetName        string `json:"sponsor_set_name,omitempty"`
+	SponsorSetNames       string `json:"sponsor_set_names,omitempty"`
+	SponsorSetDescription string `json:"sponsor_set_description,omitempty"`
 
-	RecordURIs []*Location `json:"record_uris,omitempty"`
+	LockVersion    json.Number `json:"lock_version,Number"`
+	JSONModelType  string      `json:"jsonmodel_type,omitempty"`
+	CreatedBy      string      `json:"created_by,omitempty,omitempty"`
+	LastModifiedBy string      `json:"last_modified_by,omitempty"`
+	UserMTime      string      `json:"user_mtime,omitempty,omitempty"`
+	SystemMTime    string      `json:"system_mtime,omitempty,omitempty"`
+	CreateTime     string      `json:"create_time,omitempty,omitempty"`
 }
 
-// MergeRequest JSONModel(:merge_request)
+// MergeRequest JSONModel(:merge_request), the victims are merged into the target
 type MergeRequest struct {
-	URI     string                 `json:"uri,omitempty"`
-	Target  map[string]interface{} `json:"target,omitempty"`
-	Victims map[string]interface{} `json:"victims,omitempty"`
+	URI     string                   `json:"uri,omitempty"`
+	Target  map[string]interface{}   `json:"target,omitempty"`
+	Victims []map[string]interface{} `json:"victims,omitempty"`
 
 	LockVersion    json.Number       `json:"lock_version,Number"`
 	JSONModelType  string            `json:"jsonmodel_type,omitempty"`
@@ -1431,10 +1661,12 @@ type NameCorporateEntity struct {
 	CreateTime     string            `json:"create_time,omitempty,omitempty"`
 	Repository     map[string]string `json:"repository,omitempty"`
 
-	PrimaryName      string `json:"primary_name,omitempty"`
-	SubordinateName1 string `json:"subordinate_name_1,omitempty"`
-	SubordinateName2 string `json:"subordinate_name_2,omitempty"`
-	Number           string `json:"number,omitempty"`
+	PrimaryName       string `json:"primary_name,omitempty"`
+	SubordinateName1  string `json:"subordinate_name_1,omitempty"`
+	SubordinateName2  string `json:"subordinate_name_2,omitempty"`
+	Number            string `json:"number,omitempty"`
+	Location          string `json:"location,omitempty"`
+	ConferenceMeeting bool   `json:"conference_meeting,omitempty"`
 }
 
 // NameFamily JSONModel(:name_family)
@@ -1829,6 +2061,7 @@ type Permission struct {
 
 // Preference JSONModel(:preference)
 type Preference struct {
+	ID       int       `json:"id,omitempty"`
 	URI      string    `json:"uri,omitempty"`
 	UserID   int       `json:"user_id,omitempty"`
 	Defaults *Defaults `json:"defaults,omitempty"`
@@ -1918,9 +2151,9 @@ type Repository struct {
 
 // RepositoryWithAgent JSONModel(:repository_with_agent)
 type RepositoryWithAgent struct {
-	URI                 string                 `json:"uri,omitempty"`
-	Repository          map[string]interface{} `json:"repository,omitempty"`
-	AgentRepresentation *AgentCorporateEntity  `json:"agent_representation,omitempty"`
+	URI                 string                `json:"uri,omitempty"`
+	Repository          *Repository           `json:"repository,omitempty"`
+	AgentRepresentation *AgentCorporateEntity `json:"agent_representation,omitempty"`
 
 	LockVersion    json.Number `json:"lock_version,Number"`
 	JSONModelType  string      `json:"jsonmodel_type,omitempty"`
@@ -1936,9 +2169,12 @@ type Resource struct {
 	ID                int                      `json:"id,omitempty"`
 	XMLName           xml.Name                 `json:"-"`
 	URI               string                   `json:"uri,omitempty"`
+	ArkName           *ArkName                 `json:"ark_name,omitempty"`
+	ExternalArkURL    string                   `json:"external_ark_url,omitempty"`
 	ExternalIDs       []*ExternalID            `json:"external_ids,omitempty"`
 	Title             string                   `json:"title,omitempty"`
 	Language          string                   `json:"language,omitempty"`
+	LangMaterials     []map[string]interface{} `json:"lang_materials,omitempty"`
 	Publish           bool                     `json:"publish,omitempty"`
 	Subjects          []map[string]interface{} `json:"subjects,omitempty"`
 	LinkedEvents      []map[string]interface{} `json:"linked_events,omitempty"`
@@ -1947,9 +2183,9 @@ type Resource struct {
 	ExternalDocuments []map[string]interface{} `json:"external_documents,omitempty"`
 
 	//	RightsStatements  []*RightsStatement       `json:"rights_statement"`
-	RightsStatements []interface{} `json:"rights_statements,omitempty"`
-	LinkedAgents     []*Agent      `json:"linked_agents,ommitempty"`
-	Suppressed       bool          `json:"suppressed,omitempty"`
+	RightsStatements []interface{}            `json:"rights_statements,omitempty"`
+	LinkedAgents     []map[string]interface{} `json:"linked_agents,omitempty"`
+	Suppressed       bool                     `json:"suppressed,omitempty"`
 
 	LockVersion    json.Number       `json:"lock_version,Number"`
 	JSONModelType  string            `json:"jsonmodel_type,omitempty"`
@@ -1969,30 +2205,39 @@ type Resource struct {
 	ResourceType string                 `json:"resource_type,omitempty"`
 	Tree         map[string]interface{} `json:"tree,omitempty"`
 
-	Restrictions                bool                     `json:"restrictioons,omitempty"`
+	Restrictions                bool                     `json:"restrictions,omitempty"`
 	RepositoryProcessingNote    string                   `json:"repository_processing_note,omitempty"`
 	EADID                       string                   `xml:"control>recordid" json:"ead_id,omitempty"`
 	EADLocation                 string                   `xml:"control>location" json:"ead_location,omitempty"`
 	FindingAidTitle             string                   `xml:"control>filedesc>titlestmt>titleproper" json:"finding_aid_title,omitempty"`
 	FindingAidSubtitle          string                   `xml:"control>filedesc>titlestmt>subtitle" json:"finding_aid_subtitle,omitempty"`
-	FindingAidFileTitle         string                   `xml:"control>filedesc>titlestmt>filing_title" json:"find_aid_filing_title,omitempty"`
+	FindingAidFileTitle         string                   `xml:"control>filedesc>titlestmt>filing_title" json:"finding_aid_filing_title,omitempty"`
 	FindingAidDate              string                   `json:"finding_aid_date,omitempty"`
 	FindingAidAuthor            string                   `xml:"control>filedesc>titlestmt>author" json:"finding_aid_author,omitempty"`
-	FindingAidDescriptionRultes string                   `json:"finding_aid_decription_rules,omitempty"`
+	FindingAidDescriptionRultes string                   `json:"finding_aid_description_rules,omitempty"`
 	FindingAidLanguage          string                   `json:"finding_aid_language,omitempty"`
+	FindingAidScript            string                   `json:"finding_aid_script,omitempty"`
+	FindingAidLanguageNote      string                   `json:"finding_aid_language_note,omitempty"`
 	FindingAidSponsor           string                   `xml:"control>filedesc>titlestmt>sponsor" json:"finding_aid_sponsor,omitempty"`
 	FindingAidEditionStatement  string                   `json:"finding_aid_edition_statement,omitempty"`
 	FindingAidSeriesStatement   string                   `json:"finding_aid_series_statement,omitempty"`
-	FindingAidStatus            string                   `json:"finging_aid_status,omitempty"`
+	FindingAidStatus            string                   `json:"finding_aid_status,omitempty"`
 	FindingAidNote              string                   `json:"finding_aid_note,omitempty"`
 	RevisionStatements          []*RevisionStatement     `json:"revision_statements,omitempty"`
 	Instances                   []*Instance              `json:"instances,omitempty"`
-	Deaccessions                []*Deaccession           `json:"deaccession,omitempty"`
+	Deaccessions                []*Deaccession           `json:"deaccessions,omitempty"`
 	CollectionManagement        *CollectionManagement    `json:"collection_management,omitempty"`
 	UserDefined                 *UserDefined             `json:"user_defined,omitempty"`
 	ReleatedAccessions          []map[string]interface{} `json:"related_accessions,omitempty"`
 	Classifications             []map[string]interface{} `json:"classifications,omitempty"`
 	Notes                       []map[string]interface{} `json:"notes,omitempty"`
+	MetadataRightsDeclarations  []map[string]interface{} `json:"metadata_rights_declarations,omitempty"`
+	Slug                        string                   `json:"slug,omitempty"`
+	IsSlugAuto                  bool                     `json:"is_slug_auto,omitempty"`
+
+	// Extras holds the fields of the record that Resource doesn't model, they are
+	// sent back unchanged when the resource is updated
+	Extras map[string]json.RawMessage `json:"-"`
 }
 
 // ResourceTree JSONModel(:resource_tree)
@@ -2015,11 +2260,11 @@ type ResourceTree struct {
 	CreateTime     string            `json:"create_time,omitempty,omitempty"`
 	Repository     map[string]string `json:"repository,omitempty"`
 
-	FindingAidFilingTitle string                 `json:"finding_aid_filing_title,omitempty"`
-	Level                 string                 `json:"level,omitempty"`
-	InstanceTypes         []string               `json:"instance_types,omitempty"`
-	Containers            map[string]interface{} `json:"containers,omitempty"`
-	Children              []*ResourceTree        `json:"children,omitempty"`
+	FindingAidFilingTitle string                   `json:"finding_aid_filing_title,omitempty"`
+	Level                 string                   `json:"level,omitempty"`
+	InstanceTypes         []string                 `json:"instance_types,omitempty"`
+	Containers            []map[string]interface{} `json:"containers,omitempty"`
+	Children              []*ResourceTree          `json:"children,omitempty"`
 }
 
 // RevisionStatement JSONModel(:revision_statement)
@@ -2027,6 +2272,7 @@ type RevisionStatement struct {
 	URI         string `json:"uri,omitempty"`
 	Date        string `json:"date,omitempty"`
 	Description string `json:"description,omitempty"`
+	Publish     bool   `json:"publish,omitempty"`
 
 	LockVersion    json.Number       `json:"lock_version,Number"`
 	JSONModelType  string            `json:"jsonmodel_type,omitempty"`
@@ -2166,22 +2412,27 @@ type Term struct {
 }
 
 // TopContainer JSONModel(:top_container)
+//
+// ContainerLocations, ActiveRestrictions, Series and Collection are lists as
+// ArchivesSpace sends them. Earlier releases declared them as maps which failed to
+// decode any top container that had a location, restriction or linked collection.
 type TopContainer struct {
-	URI                string                 `json:"uri,omitempty"`
-	Indicator          string                 `json:"indicator,omitempty"`
-	Type               string                 `json:"type,omitempty"`
-	Barcode            string                 `json:"barcode,omitempty"`
-	DisplayString      string                 `json:"display_string,omitempty"`
-	LongDisplayString  string                 `json:"long_display_string,omitempty"`
-	ILSHoldingID       string                 `json:"ils_holding_id,omitempty"`
-	ILSItemID          string                 `json:"ils_item_id,omitempty"`
-	ExportedToILS      string                 `json:"exported_to_ils,omitempty"`
-	Restricted         bool                   `json:"restricted,omitempty"`
-	ActiveRestrictions map[string]interface{} `json:"active_restrictions,omitempty"`
-	ContainerLocations map[string]interface{} `json:"container_locations,omitempty"`
-	ContainerProfile   map[string]interface{} `json:"container_profile,omitempty"`
-	Series             map[string]interface{} `json:"series,omitempty"`
-	Collection         map[string]interface{} `json:"collection,omitempty"`
+	ID                 int                      `json:"id,omitempty"`
+	URI                string                   `json:"uri,omitempty"`
+	Indicator          string                   `json:"indicator,omitempty"`
+	Type               string                   `json:"type,omitempty"`
+	Barcode            string                   `json:"barcode,omitempty"`
+	DisplayString      string                   `json:"display_string,omitempty"`
+	LongDisplayString  string                   `json:"long_display_string,omitempty"`
+	ILSHoldingID       string                   `json:"ils_holding_id,omitempty"`
+	ILSItemID          string                   `json:"ils_item_id,omitempty"`
+	ExportedToILS      string                   `json:"exported_to_ils,omitempty"`
+	Restricted         bool                     `json:"restricted,omitempty"`
+	ActiveRestrictions []map[string]interface{} `json:"active_restrictions,omitempty"`
+	ContainerLocations []*ContainerLocation     `json:"container_locations,omitempty"`
+	ContainerProfile   map[string]interface{}   `json:"container_profile,omitempty"`
+	Series             []map[string]interface{} `json:"series,omitempty"`
+	Collection         []map[string]interface{} `json:"collection,omitempty"`
 
 	LockVersion    json.Number       `json:"lock_version,Number"`
 	JSONModelType  string            `json:"jsonmodel_type,omitempty"`
@@ -2197,12 +2448,13 @@ type TopContainer struct {
 
 // User is a JSONModel used to administer ArchivesSpace
 type User struct {
+	ID           int                    `json:"id,omitempty"`
 	URI          string                 `json:"uri,omitempty"`
 	Username     string                 `json:"username,omitempty"`
 	Name         string                 `json:"name,omitempty"`
 	IsSystemUser bool                   `json:"is_system_user,omitempty"`
-	Permissions  map[string]string      `json:"permissions,omitempty"`
-	Groups       map[string]interface{} `json:"groups,omitempty"`
+	Permissions  map[string][]string    `json:"permissions,omitempty"`
+	Groups       []string               `json:"groups,omitempty"`
 	EMail        string                 `json:"email,omitempty"`
 	FirstName    string                 `json:"first_name,omitempty"`
 	LastName     string                 `json:"last_name,omitempty"`
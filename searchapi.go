@@ -0,0 +1,306 @@
+//
+// Package cait is a collection of structures and functions
+// for interacting with ArchivesSpace's REST API
+//
+// @author R. S. Doiel, <rsdoiel@caltech.edu>
+//
+// Copyright (c) 2017, Caltech
+// All rights not granted herein are expressly reserved by Caltech.
+//
+// Redistribution and use in source and binary forms, with or without modification, are permitted provided that the following conditions are met:
+//
+// 1. Redistributions of source code must retain the above copyright notice, this list of conditions and the following disclaimer.
+//
+// 2. Redistributions in binary form must reproduce the above copyright notice, this list of conditions and the following disclaimer in the documentation and/or other materials provided with the distribution.
+//
+// 3. Neither the name of the copyright holder nor the names of its contributors may be used to endorse or promote products derived from this software without specific prior written permission.
+//
+// THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS" AND ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE ARE DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT HOLDER OR CONTRIBUTORS BE LIABLE FOR ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL DAMAGES (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR SERVICES; LOSS OF USE, DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER CAUSED AND ON ANY THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT LIABILITY, OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE OF THIS SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+//
+package cait
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"time"
+)
+
+// SearchRequest describes a query against the ArchivesSpace search API, build one
+// with NewSearchRequest and run it with SearchRecords, e.g.
+//
+//	req := NewSearchRequest("title:papers").InRepository(2).OfType("resource").SortBy("title_sort asc")
+//	results, err := api.SearchRecords(req)
+type SearchRequest struct {
+	// RepoID limits the search to a repository, zero searches all repositories
+	RepoID int `json:"repo_id,omitempty"`
+	// Q is the keyword query in Solr syntax, e.g. title:papers or identifier:"MSS 12"
+	Q string `json:"q,omitempty"`
+	// Types limits the search to the record types listed (e.g. accession, resource)
+	Types []string `json:"types,omitempty"`
+	// FilterTerms restricts the results to records whose fields match, e.g. {"publish": "true"}
+	FilterTerms map[string]string `json:"filter_terms,omitempty"`
+	// FilterQueries are Solr queries the results must also match, e.g. system_mtime:[NOW-1DAY TO *]
+	FilterQueries []string `json:"filter_queries,omitempty"`
+	// Facets lists the fields to count values of, e.g. primary_type or subjects
+	Facets []string `json:"facets,omitempty"`
+	// Sort orders the results, e.g. "title_sort asc" or "create_time desc"
+	Sort     string `json:"sort,omitempty"`
+	Page     int    `json:"page,omitempty"`
+	PageSize int    `json:"page_size,omitempty"`
+	// AdvancedQuery holds field by field conditions, see Advanced
+	AdvancedQuery *AdvancedQuery `json:"aq,omitempty"`
+}
+
+// NewSearchRequest returns a SearchRequest for q, an empty q matches every record
+func NewSearchRequest(q string) *SearchRequest {
+	return &SearchRequest{Q: q, Page: 1}
+}
+
+// InRepository limits the search to the Repository repoID
+func (req *SearchRequest) InRepository(repoID int) *SearchRequest {
+	req.RepoID = repoID
+	return req
+}
+
+// OfType limits the search to the record types given
+func (req *SearchRequest) OfType(types ...string) *SearchRequest {
+	req.Types = append(req.Types, types...)
+	return req
+}
+
+// Filter restricts the results to records where field has value
+func (req *SearchRequest) Filter(field, value string) *SearchRequest {
+	if req.FilterTerms == nil {
+		req.FilterTerms = map[string]string{}
+	}
+	req.FilterTerms[field] = value
+	return req
+}
+
+// DateRange restricts the results to records whose date field (e.g. create_time,
+// system_mtime or user_mtime) falls between from and to, a zero time leaves that end open
+func (req *SearchRequest) DateRange(field string, from, to time.Time) *SearchRequest {
+	start, end := "*", "*"
+	if from.IsZero() == false {
+		start = from.UTC().Format(time.RFC3339)
+	}
+	if to.IsZero() == false {
+		end = to.UTC().Format(time.RFC3339)
+	}
+	req.FilterQueries = append(req.FilterQueries, fmt.Sprintf("%s:[%s TO %s]", field, start, end))
+	return req
+}
+
+// Facet asks for the counts of the values of fields in the results
+func (req *SearchRequest) Facet(fields ...string) *SearchRequest {
+	req.Facets = append(req.Facets, fields...)
+	return req
+}
+
+// SortBy orders the results, e.g. "title_sort asc"
+func (req *SearchRequest) SortBy(sort string) *SearchRequest {
+	req.Sort = sort
+	return req
+}
+
+// WithPage sets the page of results to return, counting from 1
+func (req *SearchRequest) WithPage(page int) *SearchRequest {
+	req.Page = page
+	return req
+}
+
+// WithPageSize sets the number of results per page
+func (req *SearchRequest) WithPageSize(pageSize int) *SearchRequest {
+	req.PageSize = pageSize
+	return req
+}
+
+// Values returns the request as search API query parameters
+func (req *SearchRequest) Values() (url.Values, error) {
+	v := url.Values{}
+	if req.AdvancedQuery != nil {
+		if err := validateTerm(req.AdvancedQuery.Query); err != nil {
+			return nil, fmt.Errorf("advanced query %w", err)
+		}
+		v.Set("aq", req.AdvancedQuery.String())
+	}
+	if req.Q != "" || req.AdvancedQuery == nil {
+		q := req.Q
+		if q == "" {
+			q = "*"
+		}
+		v.Set("q", q)
+	}
+	page := req.Page
+	if page < 1 {
+		page = 1
+	}
+	v.Set("page", fmt.Sprintf("%d", page))
+	if req.PageSize > 0 {
+		v.Set("page_size", fmt.Sprintf("%d", req.PageSize))
+	}
+	for _, t := range req.Types {
+		v.Add("type[]", t)
+	}
+	for field, value := range req.FilterTerms {
+		src, err := json.Marshal(map[string]string{field: value})
+		if err != nil {
+			return nil, fmt.Errorf("filter %s, %w", field, err)
+		}
+		v.Add("filter_term[]", string(src))
+	}
+	for _, fq := range req.FilterQueries {
+		v.Add("filter_query[]", fq)
+	}
+	for _, field := range req.Facets {
+		v.Add("facet[]", field)
+	}
+	if req.Sort != "" {
+		v.Set("sort", req.Sort)
+	}
+	return v, nil
+}
+
+// SearchHit is a record found by SearchRecords. JSON holds the full record as JSON text.
+type SearchHit struct {
+	ID            string   `json:"id"`
+	URI           string   `json:"uri"`
+	Title         string   `json:"title"`
+	PrimaryType   string   `json:"primary_type"`
+	Types         []string `json:"types,omitempty"`
+	Identifier    string   `json:"identifier,omitempty"`
+	Repository    string   `json:"repository,omitempty"`
+	Publish       bool     `json:"publish,omitempty"`
+	Suppressed    bool     `json:"suppressed,omitempty"`
+	JSONModelType string   `json:"jsonmodel_type,omitempty"`
+	JSON          string   `json:"json,omitempty"`
+	// Record is JSON decoded by DecodeJSONModel, e.g. an *Accession, it is set by SearchAll
+	Record interface{} `json:"-"`
+	// Fields holds every field of the hit including the ones not listed above
+	Fields Object `json:"-"`
+}
+
+// UnmarshalJSON decodes a search hit keeping all of its fields in Fields
+func (hit *SearchHit) UnmarshalJSON(src []byte) error {
+	type searchHit SearchHit
+	if err := json.Unmarshal(src, (*searchHit)(hit)); err != nil {
+		return err
+	}
+	hit.Fields = Object{}
+	return json.Unmarshal(src, &hit.Fields)
+}
+
+// FacetCount is the number of results having a facet value
+type FacetCount struct {
+	Value string `json:"value"`
+	Count int    `json:"count"`
+}
+
+// SearchResults is a page of results from SearchRecords. Facets holds the counts
+// for each field asked for, most frequent value first.
+type SearchResults struct {
+	FirstPage   int                      `json:"first_page"`
+	LastPage    int                      `json:"last_page"`
+	ThisPage    int                      `json:"this_page"`
+	OffsetFirst int                      `json:"offset_first"`
+	OffsetLast  int                      `json:"offset_last"`
+	TotalHits   int                      `json:"total_hits"`
+	Results     []*SearchHit             `json:"results"`
+	Facets      map[string][]*FacetCount `json:"-"`
+}
+
+// HasNextPage returns true if there are more pages of results after this one
+func (results *SearchResults) HasNextPage() bool {
+	return results.ThisPage < results.LastPage
+}
+
+// decodeFacets turns Solr's facet_fields lists, which alternate value and count,
+// into FacetCounts
+func decodeFacets(fields map[string][]interface{}) map[string][]*FacetCount {
+	facets := map[string][]*FacetCount{}
+	for field, list := range fields {
+		counts := []*FacetCount{}
+		for i := 0; i+1 < len(list); i += 2 {
+			count, _ := list[i+1].(float64)
+			counts = append(counts, &FacetCount{Value: fmt.Sprintf("%v", list[i]), Count: int(count)})
+		}
+		facets[field] = counts
+	}
+	return facets
+}
+
+// SearchRecords runs req against the search API returning the page of results asked for
+func (api *ArchivesSpaceAPI) SearchRecords(req *SearchRequest) (*SearchResults, error) {
+	if req == nil {
+		req = NewSearchRequest("")
+	}
+	u := api.callURL(`/search`)
+	if req.RepoID != 0 {
+		u = api.callURL(fmt.Sprintf(`/repositories/%d/search`, req.RepoID))
+	}
+	v, err := req.Values()
+	if err != nil {
+		return nil, fmt.Errorf("SearchRecords(%q) %w", req.Q, err)
+	}
+	u.RawQuery = v.Encode()
+
+	page := struct {
+		SearchResults
+		RawFacets json.RawMessage `json:"facets"`
+	}{}
+	if err := api.GetAPI(u.String(), &page); err != nil {
+		return nil, fmt.Errorf("SearchRecords(%q) %w", req.Q, err)
+	}
+	results := &page.SearchResults
+	// Facets are only an object when they were asked for
+	facets := struct {
+		FacetFields map[string][]interface{} `json:"facet_fields"`
+	}{}
+	if len(page.RawFacets) > 0 && page.RawFacets[0] == '{' {
+		if err := json.Unmarshal(page.RawFacets, &facets); err != nil {
+			return nil, fmt.Errorf("SearchRecords(%q) facets %w", req.Q, err)
+		}
+	}
+	results.Facets = decodeFacets(facets.FacetFields)
+	return results, nil
+}
+
+// SearchAll runs req calling fn with each hit on every page of results, starting from
+// req's page. Each hit's Record holds its decoded record. Iteration stops at the first
+// error from fn which is returned unless it is ErrStopIteration.
+func (api *ArchivesSpaceAPI) SearchAll(req *SearchRequest, fn func(hit SearchHit) error) error {
+	if req == nil {
+		req = NewSearchRequest("")
+	}
+	// Work on a copy so the caller's request keeps its page
+	pageReq := *req
+	if pageReq.Page < 1 {
+		pageReq.Page = 1
+	}
+	for {
+		results, err := api.SearchRecords(&pageReq)
+		if err != nil {
+			return fmt.Errorf("SearchAll(%q) %w", req.Q, err)
+		}
+		for _, hit := range results.Results {
+			if hit.JSON != "" {
+				hit.Record, err = DecodeJSONModel([]byte(hit.JSON))
+				if err != nil {
+					return fmt.Errorf("SearchAll(%q) %s, %w", req.Q, hit.URI, err)
+				}
+			}
+			if err := fn(*hit); err != nil {
+				if errors.Is(err, ErrStopIteration) == true {
+					return nil
+				}
+				return err
+			}
+		}
+		if len(results.Results) == 0 || results.HasNextPage() == false {
+			return nil
+		}
+		pageReq.Page = results.ThisPage + 1
+	}
+}
@@ -0,0 +1,174 @@
+//
+// Package cait is a collection of structures and functions
+// for interacting with ArchivesSpace's REST API
+//
+// @author R. S. Doiel, <rsdoiel@caltech.edu>
+//
+// Copyright (c) 2017, Caltech
+// All rights not granted herein are expressly reserved by Caltech.
+//
+// Redistribution and use in source and binary forms, with or without modification, are permitted provided that the following conditions are met:
+//
+// 1. Redistributions of source code must retain the above copyright notice, this list of conditions and the following disclaimer.
+//
+// 2. Redistributions in binary form must reproduce the above copyright notice, this list of conditions and the following disclaimer in the documentation and/or other materials provided with the distribution.
+//
+// 3. Neither the name of the copyright holder nor the names of its contributors may be used to endorse or promote products derived from this software without specific prior written permission.
+//
+// THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS" AND ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE ARE DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT HOLDER OR CONTRIBUTORS BE LIABLE FOR ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL DAMAGES (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR SERVICES; LOSS OF USE, DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER CAUSED AND ON ANY THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT LIABILITY, OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE OF THIS SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+//
+package cait
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// SavedSession is the session token written by SaveSession
+type SavedSession struct {
+	APIURL   string `json:"api_url"`
+	Username string `json:"username,omitempty"`
+	Token    string `json:"token"`
+	Saved    string `json:"saved"`
+}
+
+// token returns the current session token
+func (api *ArchivesSpaceAPI) token() string {
+	api.authMu.RLock()
+	defer api.authMu.RUnlock()
+	return api.AuthToken
+}
+
+// setToken replaces the session token
+func (api *ArchivesSpaceAPI) setToken(token string) {
+	api.authMu.Lock()
+	defer api.authMu.Unlock()
+	api.AuthToken = token
+}
+
+// sessionErrorSize is how much of a 403 or 412 response sessionExpired reads,
+// enough for the error payload ArchivesSpace sends
+const sessionErrorSize = 64 << 10
+
+// sessionExpired returns true if a response reports the session token is no
+// longer valid. The response body is restored so it can still be read.
+func (api *ArchivesSpaceAPI) sessionExpired(res *http.Response) bool {
+	if res.StatusCode != http.StatusPreconditionFailed && res.StatusCode != http.StatusForbidden {
+		return false
+	}
+	rest := res.Body
+	body, err := ioutil.ReadAll(io.LimitReader(rest, sessionErrorSize))
+	res.Body = struct {
+		io.Reader
+		io.Closer
+	}{io.MultiReader(bytes.NewReader(body), rest), rest}
+	if err != nil {
+		// Leave the failed read for the caller to report
+		return false
+	}
+	if res.StatusCode == http.StatusPreconditionFailed {
+		return true
+	}
+	// A 403 is also returned for missing permissions, only an expired session is worth a new login
+	return strings.Contains(string(body), "SESSION_")
+}
+
+// renewSession logs in again unless another goroutine has already replaced
+// the expired token, calling api.OnReauthenticate with the result
+func (api *ArchivesSpaceAPI) renewSession(expired string) error {
+	api.renewMu.Lock()
+	defer api.renewMu.Unlock()
+	if api.token() != expired {
+		return nil
+	}
+	api.logf("session expired, logging in again as %s", api.Username)
+	err := api.Login()
+	if api.OnReauthenticate != nil {
+		api.OnReauthenticate(err)
+	}
+	return err
+}
+
+// sendRequest is doRequest with a single retry after a new login when the
+// session has expired. Sessions are only renewed if a username and password (or
+// credential provider) are set.
+func (api *ArchivesSpaceAPI) sendRequest(method, url string, payload []byte, headers http.Header) (*http.Response, error) {
+	token := api.token()
+	res, err := api.doRequest(method, url, payload, headers)
+	if err != nil || api.hasCredentials() == false || api.sessionExpired(res) == false {
+		return res, err
+	}
+	if err := api.renewSession(token); err != nil {
+		return res, nil
+	}
+	res.Body.Close()
+	return api.doRequest(method, url, payload, headers)
+}
+
+// SaveSession writes the current session token to fname, readable only by the
+// owner, so a later run can reuse it with LoadSession
+func (api *ArchivesSpaceAPI) SaveSession(fname string) error {
+	token := api.token()
+	if token == "" {
+		return fmt.Errorf("SaveSession(%q) not logged in", fname)
+	}
+	src, err := json.MarshalIndent(&SavedSession{
+		APIURL:   api.BaseURL.String(),
+		Username: api.Username,
+		Token:    token,
+		Saved:    time.Now().UTC().Format(time.RFC3339),
+	}, "", "    ")
+	if err != nil {
+		return fmt.Errorf("SaveSession(%q) %w", fname, err)
+	}
+	if err := ioutil.WriteFile(fname, src, 0600); err != nil {
+		return fmt.Errorf("SaveSession(%q) %w", fname, err)
+	}
+	return nil
+}
+
+// LoadSession sets the session token saved in fname. It is an error if the
+// session was saved for a different ArchivesSpace instance or user.
+func (api *ArchivesSpaceAPI) LoadSession(fname string) error {
+	src, err := ioutil.ReadFile(fname)
+	if err != nil {
+		return fmt.Errorf("LoadSession(%q) %w", fname, err)
+	}
+	session := new(SavedSession)
+	if err := json.Unmarshal(src, session); err != nil {
+		return fmt.Errorf("LoadSession(%q) %w", fname, err)
+	}
+	if session.APIURL != api.BaseURL.String() || session.Username != api.Username {
+		return fmt.Errorf("LoadSession(%q) session is for %s at %s", fname, session.Username, session.APIURL)
+	}
+	if session.Token == "" {
+		return fmt.Errorf("LoadSession(%q) no token saved", fname)
+	}
+	api.setToken(session.Token)
+	return nil
+}
+
+// LoginWithSession reuses the session saved in fname if ArchivesSpace still
+// accepts it, otherwise it logs in and saves the new session
+func (api *ArchivesSpaceAPI) LoginWithSession(fname string) error {
+	if err := api.LoadSession(fname); err == nil {
+		before := api.token()
+		if _, err := api.API("GET", api.buildURL("/users/current-user", nil), nil); err == nil {
+			if api.token() != before {
+				// The stored token was rejected and the session renewed, keep the new one
+				return api.SaveSession(fname)
+			}
+			return nil
+		}
+	}
+	if err := api.Login(); err != nil {
+		return err
+	}
+	return api.SaveSession(fname)
+}
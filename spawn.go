@@ -0,0 +1,111 @@
+//
+// Package cait is a collection of structures and functions
+// for interacting with ArchivesSpace's REST API
+//
+// @author R. S. Doiel, <rsdoiel@caltech.edu>
+//
+// Copyright (c) 2017, Caltech
+// All rights not granted herein are expressly reserved by Caltech.
+//
+// Redistribution and use in source and binary forms, with or without modification, are permitted provided that the following conditions are met:
+//
+// 1. Redistributions of source code must retain the above copyright notice, this list of conditions and the following disclaimer.
+//
+// 2. Redistributions in binary form must reproduce the above copyright notice, this list of conditions and the following disclaimer in the documentation and/or other materials provided with the distribution.
+//
+// 3. Neither the name of the copyright holder nor the names of its contributors may be used to endorse or promote products derived from this software without specific prior written permission.
+//
+// THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS" AND ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE ARE DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT HOLDER OR CONTRIBUTORS BE LIABLE FOR ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL DAMAGES (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR SERVICES; LOSS OF USE, DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER CAUSED AND ON ANY THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT LIABILITY, OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE OF THIS SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+//
+package cait
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// copyRefs copies a list of linked records so the spawned resource doesn't share them with the accession
+func copyRefs(refs []map[string]interface{}) []map[string]interface{} {
+	if refs == nil {
+		return nil
+	}
+	copied := []map[string]interface{}{}
+	for _, ref := range refs {
+		item := map[string]interface{}{}
+		for k, v := range ref {
+			item[k] = v
+		}
+		copied = append(copied, item)
+	}
+	return copied
+}
+
+// NewResourceFromAccession returns a Resource pre-populated from accession the way the staff
+// interface's spawn does: the title, dates, extents, linked agents, subjects, classifications
+// and languages of the materials are copied and the resource is related to the accession. The content and condition
+// descriptions become scope and contents and physical description notes.
+func NewResourceFromAccession(accession *Accession) *Resource {
+	obj := new(Resource)
+	obj.JSONModelType = "resource"
+	obj.Title = accession.Title
+	obj.Level = "collection"
+	obj.ResourceType = accession.ResourceType
+	for _, date := range accession.Dates {
+		d := *date
+		obj.Dates = append(obj.Dates, &d)
+	}
+	for _, extent := range accession.Extents {
+		e := *extent
+		obj.Extents = append(obj.Extents, &e)
+	}
+	obj.LinkedAgents = copyRefs(accession.LinkedAgents)
+	obj.Subjects = copyRefs(accession.Subjects)
+	obj.Classifications = copyRefs(accession.Classifications)
+	obj.LangMaterials = copyRefs(accession.LangMaterials)
+	obj.ReleatedAccessions = []map[string]interface{}{Ref(accession.URI)}
+	for noteType, content := range map[string]string{
+		"scopecontent": accession.ContentDescription,
+		"physdesc":     accession.ConditionDescription,
+	} {
+		if content == "" {
+			continue
+		}
+		obj.Notes = append(obj.Notes, map[string]interface{}{
+			"jsonmodel_type": "note_multipart",
+			"type":           noteType,
+			"publish":        true,
+			"subnotes": []map[string]interface{}{
+				{"jsonmodel_type": "note_text", "content": content, "publish": true},
+			},
+		})
+	}
+	return obj
+}
+
+// SpawnResourceFromAccession creates a Resource in the accession's Repository from the accession
+// (see NewResourceFromAccession) and returns it. Fields set in overrides replace the spawned
+// values, e.g. the identifier (ID0 to ID3) and level which an accession doesn't supply.
+// ArchivesSpace lists the new resource in the accession's related_resources, which is updated
+// to match.
+func (api *ArchivesSpaceAPI) SpawnResourceFromAccession(accession *Accession, overrides *Resource) (*Resource, error) {
+	repoID := URIToRepoID(accession.URI)
+	if repoID == 0 {
+		return nil, fmt.Errorf("SpawnResourceFromAccession() accession %q is not in a repository", accession.URI)
+	}
+	obj := NewResourceFromAccession(accession)
+	if overrides != nil {
+		// Resource's fields are omitempty so only the fields set in overrides are applied
+		src, err := json.Marshal(overrides)
+		if err != nil {
+			return nil, fmt.Errorf("SpawnResourceFromAccession(%q) %w", accession.URI, err)
+		}
+		if err := json.Unmarshal(src, obj); err != nil {
+			return nil, fmt.Errorf("SpawnResourceFromAccession(%q) %w", accession.URI, err)
+		}
+	}
+	if _, err := api.CreateResource(repoID, obj); err != nil {
+		return nil, fmt.Errorf("SpawnResourceFromAccession(%q) %w", accession.URI, err)
+	}
+	accession.RelatedResources = append(accession.RelatedResources, Ref(obj.URI))
+	return obj, nil
+}
@@ -0,0 +1,189 @@
+//
+// Package cait is a collection of structures and functions
+// for interacting with ArchivesSpace's REST API
+//
+// @author R. S. Doiel, <rsdoiel@caltech.edu>
+//
+// Copyright (c) 2017, Caltech
+// All rights not granted herein are expressly reserved by Caltech.
+//
+// Redistribution and use in source and binary forms, with or without modification, are permitted provided that the following conditions are met:
+//
+// 1. Redistributions of source code must retain the above copyright notice, this list of conditions and the following disclaimer.
+//
+// 2. Redistributions in binary form must reproduce the above copyright notice, this list of conditions and the following disclaimer in the documentation and/or other materials provided with the distribution.
+//
+// 3. Neither the name of the copyright holder nor the names of its contributors may be used to endorse or promote products derived from this software without specific prior written permission.
+//
+// THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS" AND ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE ARE DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT HOLDER OR CONTRIBUTORS BE LIABLE FOR ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL DAMAGES (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR SERVICES; LOSS OF USE, DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER CAUSED AND ON ANY THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT LIABILITY, OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE OF THIS SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+//
+package cait
+
+import (
+	"fmt"
+)
+
+// StacksRecord is a short description of a top container or location found by ScanStacks
+type StacksRecord struct {
+	URI     string `json:"uri"`
+	Title   string `json:"title,omitempty"`
+	Barcode string `json:"barcode,omitempty"`
+}
+
+// StacksReport lists the top containers and locations needing stacks management attention
+type StacksReport struct {
+	// Homeless are top containers without a current location
+	Homeless []*StacksRecord `json:"homeless"`
+	// Unlinked are top containers not linked to any resource, accession or archival object
+	Unlinked []*StacksRecord `json:"unlinked"`
+	// EmptyLocations are locations no top container currently occupies. Locations are
+	// shared by repositories so this is only set when every repository was scanned.
+	EmptyLocations []*StacksRecord `json:"empty_locations,omitempty"`
+}
+
+// ScanStacks reports homeless and unlinked top containers in the repositories given
+// along with, when every repository is scanned, the locations holding no containers.
+// If no repository IDs are given all repositories are scanned.
+func (api *ArchivesSpaceAPI) ScanStacks(repoIDs ...int) (*StacksReport, error) {
+	allRepoIDs, err := api.ListRepositoryIDs()
+	if err != nil {
+		return nil, fmt.Errorf("ScanStacks() %w", err)
+	}
+	if len(repoIDs) == 0 {
+		repoIDs = allRepoIDs
+	}
+	everyRepository := true
+	for _, repoID := range allRepoIDs {
+		if containsInt(repoIDs, repoID) == false {
+			everyRepository = false
+		}
+	}
+	report := &StacksReport{
+		Homeless: []*StacksRecord{},
+		Unlinked: []*StacksRecord{},
+	}
+	occupied := map[string]bool{}
+	for _, repoID := range repoIDs {
+		ids, err := api.ListTopContainers(repoID)
+		if err != nil {
+			return nil, fmt.Errorf("ScanStacks() %w", err)
+		}
+		for _, id := range ids {
+			container, err := api.GetTopContainer(repoID, id)
+			if err != nil {
+				return nil, fmt.Errorf("ScanStacks() %w", err)
+			}
+			rec := &StacksRecord{
+				URI:     container.URI,
+				Title:   container.DisplayString,
+				Barcode: container.Barcode,
+			}
+			hasLocation := false
+			for _, cl := range container.ContainerLocations {
+				if cl.Status == "current" && cl.Ref != "" {
+					occupied[cl.Ref] = true
+					hasLocation = true
+				}
+			}
+			if hasLocation == false {
+				report.Homeless = append(report.Homeless, rec)
+			}
+			if len(container.Collection) == 0 && len(container.Series) == 0 {
+				report.Unlinked = append(report.Unlinked, rec)
+			}
+		}
+	}
+
+	if everyRepository == false {
+		return report, nil
+	}
+	locations, err := api.allLocations()
+	if err != nil {
+		return nil, fmt.Errorf("ScanStacks() %w", err)
+	}
+	report.EmptyLocations = []*StacksRecord{}
+	for _, location := range locations {
+		if occupied[location.URI] == false {
+			report.EmptyLocations = append(report.EmptyLocations, &StacksRecord{
+				URI:     location.URI,
+				Title:   location.Title,
+				Barcode: location.Barcode,
+			})
+		}
+	}
+	return report, nil
+}
+
+// containsInt returns true if n is found in list
+func containsInt(list []int, n int) bool {
+	for _, item := range list {
+		if item == n {
+			return true
+		}
+	}
+	return false
+}
+
+// String return a StacksReport
+func (report *StacksReport) String() string {
+	return stringify(report)
+}
+
+// SpaceCalculatorLocation is a location in SpaceCalculatorResults, Count is the number of
+// containers which fit and Reason why the space couldn't be calculated
+type SpaceCalculatorLocation struct {
+	Ref    string `json:"ref"`
+	Count  int    `json:"count,omitempty"`
+	Reason string `json:"reason,omitempty"`
+}
+
+// SpaceCalculatorResults JSONModel(:space_calculator_results) reports how many containers
+// of a container profile fit in a set of locations
+type SpaceCalculatorResults struct {
+	ContainerProfile        map[string]interface{}     `json:"container_profile,omitempty"`
+	TotalSpacesAvailable    int                        `json:"total_spaces_available"`
+	LocationsWithSpace      []*SpaceCalculatorLocation `json:"locations_with_space"`
+	LocationsWithoutSpace   []*SpaceCalculatorLocation `json:"locations_without_space"`
+	UncalculatableLocations []*SpaceCalculatorLocation `json:"uncalculatable_locations"`
+	JSONModelType           string                     `json:"jsonmodel_type,omitempty"`
+}
+
+// SpaceByBuilding calculates the space available for containers of the container profile
+// at containerProfileURI in a building, optionally narrowed to a floor, room and area
+func (api *ArchivesSpaceAPI) SpaceByBuilding(containerProfileURI, building, floor, room, area string) (*SpaceCalculatorResults, error) {
+	u := api.callURL("/space_calculator/by_building")
+	q := u.Query()
+	q.Set("container_profile_uri", containerProfileURI)
+	q.Set("building", building)
+	for param, value := range map[string]string{"floor": floor, "room": room, "area": area} {
+		if value != "" {
+			q.Set(param, value)
+		}
+	}
+	u.RawQuery = q.Encode()
+	results := new(SpaceCalculatorResults)
+	if err := api.GetAPI(u.String(), results); err != nil {
+		return nil, fmt.Errorf("SpaceByBuilding(%q, %q) %w", containerProfileURI, building, err)
+	}
+	return results, nil
+}
+
+// SpaceByLocation calculates the space available for containers of the container profile
+// at containerProfileURI in the locations given
+func (api *ArchivesSpaceAPI) SpaceByLocation(containerProfileURI string, locationURIs ...string) (*SpaceCalculatorResults, error) {
+	if len(locationURIs) == 0 {
+		return nil, fmt.Errorf("SpaceByLocation(%q) no locations given", containerProfileURI)
+	}
+	u := api.callURL("/space_calculator/by_location")
+	q := u.Query()
+	q.Set("container_profile_uri", containerProfileURI)
+	for _, uri := range locationURIs {
+		q.Add("location_uris[]", uri)
+	}
+	u.RawQuery = q.Encode()
+	results := new(SpaceCalculatorResults)
+	if err := api.GetAPI(u.String(), results); err != nil {
+		return nil, fmt.Errorf("SpaceByLocation(%q) %w", containerProfileURI, err)
+	}
+	return results, nil
+}
@@ -0,0 +1,133 @@
+//
+// Package cait is a collection of structures and functions
+// for interacting with ArchivesSpace's REST API
+//
+// @author R. S. Doiel, <rsdoiel@caltech.edu>
+//
+// Copyright (c) 2017, Caltech
+// All rights not granted herein are expressly reserved by Caltech.
+//
+// Redistribution and use in source and binary forms, with or without modification, are permitted provided that the following conditions are met:
+//
+// 1. Redistributions of source code must retain the above copyright notice, this list of conditions and the following disclaimer.
+//
+// 2. Redistributions in binary form must reproduce the above copyright notice, this list of conditions and the following disclaimer in the documentation and/or other materials provided with the distribution.
+//
+// 3. Neither the name of the copyright holder nor the names of its contributors may be used to endorse or promote products derived from this software without specific prior written permission.
+//
+// THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS" AND ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE ARE DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT HOLDER OR CONTRIBUTORS BE LIABLE FOR ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL DAMAGES (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR SERVICES; LOSS OF USE, DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER CAUSED AND ON ANY THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT LIABILITY, OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE OF THIS SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+//
+package cait
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+)
+
+// PlaceholderTitles are titles (compared case insensitively, ignoring brackets)
+// left on records when the real title wasn't known at migration time
+var PlaceholderTitles = []string{
+	"untitled",
+	"untitled collection",
+	"no title",
+	"title",
+	"tbd",
+	"to be determined",
+	"placeholder",
+	"unknown",
+}
+
+// StubResource describes a resource that looks like an empty or placeholder record
+type StubResource struct {
+	URI        string   `json:"uri"`
+	Title      string   `json:"title"`
+	Identifier string   `json:"identifier,omitempty"`
+	Reasons    []string `json:"reasons"`
+}
+
+// alphanumeric returns the lower cased letters and digits of s
+func alphanumeric(s string) string {
+	return strings.Map(func(r rune) rune {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			return unicode.ToLower(r)
+		}
+		return -1
+	}, s)
+}
+
+// IsPlaceholderTitle returns true if title is empty, a known placeholder, has no
+// letters or only repeats the record's identifier parts
+func IsPlaceholderTitle(title string, identifiers ...string) bool {
+	t := strings.ToLower(strings.Trim(strings.TrimSpace(title), "[]()"))
+	if t == "" {
+		return true
+	}
+	for _, placeholder := range PlaceholderTitles {
+		if t == placeholder {
+			return true
+		}
+	}
+	if strings.IndexFunc(t, unicode.IsLetter) < 0 {
+		return true
+	}
+	id := alphanumeric(strings.Join(identifiers, ""))
+	return id != "" && alphanumeric(t) == id
+}
+
+// resourceChildCount returns the number of top level archival objects in a resource
+func (api *ArchivesSpaceAPI) resourceChildCount(uri string) (int, error) {
+	u := api.callURL(uri + "/tree/root")
+	root := struct {
+		ChildCount int `json:"child_count"`
+	}{}
+	if err := api.GetAPI(u.String(), &root); err != nil {
+		return 0, err
+	}
+	return root.ChildCount, nil
+}
+
+// FindStubResources lists resources in a repository with no archival objects,
+// no notes or a placeholder title so migration leftovers can be reviewed
+func (api *ArchivesSpaceAPI) FindStubResources(repoID int) ([]*StubResource, error) {
+	ids, err := api.ListResources(repoID)
+	if err != nil {
+		return nil, fmt.Errorf("FindStubResources(%d) %w", repoID, err)
+	}
+	stubs := []*StubResource{}
+	for _, id := range ids {
+		resource, err := api.GetResource(repoID, id)
+		if err != nil {
+			return nil, fmt.Errorf("FindStubResources(%d) %w", repoID, err)
+		}
+		identifiers := []string{}
+		for _, s := range []string{resource.ID0, resource.ID1, resource.ID2, resource.ID3} {
+			if s != "" {
+				identifiers = append(identifiers, s)
+			}
+		}
+		reasons := []string{}
+		count, err := api.resourceChildCount(resource.URI)
+		if err != nil {
+			return nil, fmt.Errorf("FindStubResources(%d) %s, %w", repoID, resource.URI, err)
+		}
+		if count == 0 {
+			reasons = append(reasons, "no archival objects")
+		}
+		if len(resource.Notes) == 0 {
+			reasons = append(reasons, "no notes")
+		}
+		if IsPlaceholderTitle(resource.Title, identifiers...) {
+			reasons = append(reasons, "placeholder title")
+		}
+		if len(reasons) > 0 {
+			stubs = append(stubs, &StubResource{
+				URI:        resource.URI,
+				Title:      resource.Title,
+				Identifier: strings.Join(identifiers, "-"),
+				Reasons:    reasons,
+			})
+		}
+	}
+	return stubs, nil
+}
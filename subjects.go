@@ -0,0 +1,174 @@
+//
+// Package cait is a collection of structures and functions
+// for interacting with ArchivesSpace's REST API
+//
+// @author R. S. Doiel, <rsdoiel@caltech.edu>
+//
+// Copyright (c) 2017, Caltech
+// All rights not granted herein are expressly reserved by Caltech.
+//
+// Redistribution and use in source and binary forms, with or without modification, are permitted provided that the following conditions are met:
+//
+// 1. Redistributions of source code must retain the above copyright notice, this list of conditions and the following disclaimer.
+//
+// 2. Redistributions in binary form must reproduce the above copyright notice, this list of conditions and the following disclaimer in the documentation and/or other materials provided with the distribution.
+//
+// 3. Neither the name of the copyright holder nor the names of its contributors may be used to endorse or promote products derived from this software without specific prior written permission.
+//
+// THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS" AND ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE ARE DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT HOLDER OR CONTRIBUTORS BE LIABLE FOR ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL DAMAGES (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR SERVICES; LOSS OF USE, DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER CAUSED AND ON ANY THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT LIABILITY, OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE OF THIS SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+//
+package cait
+
+import (
+	"fmt"
+	"strings"
+)
+
+// SubjectRecordTypes are the record types that can link subjects, used to scope subject searches
+var SubjectRecordTypes = []string{"accession", "resource", "archival_object", "digital_object", "digital_object_component"}
+
+// checkSubjectURI makes sure uri refers to a subject
+func checkSubjectURI(uri string) error {
+	if strings.HasPrefix(uri, "/subjects/") == false || URIToID(uri) == 0 {
+		return fmt.Errorf("%q is not a subject URI", uri)
+	}
+	return nil
+}
+
+// checkSubjectReplacement returns an error unless oldURI and newURIs are subject
+// URIs and oldURI isn't among its own replacements
+func checkSubjectReplacement(oldURI string, newURIs []string) error {
+	if err := checkSubjectURI(oldURI); err != nil {
+		return err
+	}
+	for _, uri := range newURIs {
+		if err := checkSubjectURI(uri); err != nil {
+			return err
+		}
+		if uri == oldURI {
+			return fmt.Errorf("can't replace a subject with itself")
+		}
+	}
+	return nil
+}
+
+// subjectSearchOptions copies opts defaulting Types to SubjectRecordTypes
+func subjectSearchOptions(opts *BatchEditOptions) *BatchEditOptions {
+	o := BatchEditOptions{}
+	if opts != nil {
+		o = *opts
+	}
+	if len(o.Types) == 0 {
+		o.Types = SubjectRecordTypes
+	}
+	return &o
+}
+
+// SubjectTerm returns a term for a new Subject's Terms, e.g.
+// SubjectTerm("Seismology", "topical", "/vocabularies/1")
+func SubjectTerm(term, termType, vocabularyURI string) map[string]interface{} {
+	return map[string]interface{}{
+		"jsonmodel_type": "term",
+		"term":           term,
+		"term_type":      termType,
+		"vocabulary":     vocabularyURI,
+	}
+}
+
+// NewSubject returns a Subject from source (e.g. lcsh, local) in the vocabulary given
+// made of terms, see SubjectTerm
+func NewSubject(vocabularyURI, source string, terms ...map[string]interface{}) *Subject {
+	return &Subject{
+		Source:        source,
+		Vocabulary:    vocabularyURI,
+		Terms:         terms,
+		JSONModelType: "subject",
+	}
+}
+
+// AttachSubject links a subject to the record at recordURI (e.g. an accession, resource
+// or archival object). A record already linked to the subject is left unchanged.
+func (api *ArchivesSpaceAPI) AttachSubject(recordURI, subjectURI string) (*ResponseMsg, error) {
+	if err := checkSubjectURI(subjectURI); err != nil {
+		return nil, err
+	}
+	return UpdateWith(api, recordURI, func(obj *Object) error {
+		addRef(*obj, "subjects", subjectURI)
+		return nil
+	})
+}
+
+// AttachSubjectToAccession links a subject to an Accession
+func (api *ArchivesSpaceAPI) AttachSubjectToAccession(repoID, accessionID int, subjectURI string) (*ResponseMsg, error) {
+	return api.AttachSubject(fmt.Sprintf("/repositories/%d/accessions/%d", repoID, accessionID), subjectURI)
+}
+
+// AttachSubjectToResource links a subject to a Resource
+func (api *ArchivesSpaceAPI) AttachSubjectToResource(repoID, resourceID int, subjectURI string) (*ResponseMsg, error) {
+	return api.AttachSubject(fmt.Sprintf("/repositories/%d/resources/%d", repoID, resourceID), subjectURI)
+}
+
+// AddSubject links a subject to each record in uris. Records already linked are left unchanged.
+func (api *ArchivesSpaceAPI) AddSubject(subjectURI string, uris []string, opts *BatchEditOptions) (*BatchEditReport, error) {
+	if err := checkSubjectURI(subjectURI); err != nil {
+		return nil, err
+	}
+	return api.ApplyToURIs(uris, func(obj Object) (bool, error) {
+		return addRef(obj, "subjects", subjectURI), nil
+	}, opts)
+}
+
+// AddSubjectToSearch links a subject to every record matching query
+func (api *ArchivesSpaceAPI) AddSubjectToSearch(subjectURI, query string, opts *BatchEditOptions) (*BatchEditReport, error) {
+	if err := checkSubjectURI(subjectURI); err != nil {
+		return nil, err
+	}
+	return api.ApplyToSearch(query, func(obj Object) (bool, error) {
+		return addRef(obj, "subjects", subjectURI), nil
+	}, subjectSearchOptions(opts))
+}
+
+// replaceSubjectRefs returns a TransformFunc swapping the link to oldURI for links to newURIs
+func replaceSubjectRefs(oldURI string, newURIs []string) TransformFunc {
+	return func(obj Object) (bool, error) {
+		if hasRef(obj, "subjects", oldURI) == false {
+			return false, nil
+		}
+		subjects := []interface{}{}
+		for _, item := range refList(obj, "subjects") {
+			if m, ok := item.(map[string]interface{}); ok == true && m["ref"] == oldURI {
+				continue
+			}
+			subjects = append(subjects, item)
+		}
+		obj["subjects"] = subjects
+		for _, uri := range newURIs {
+			addRef(obj, "subjects", uri)
+		}
+		return true, nil
+	}
+}
+
+// ReplaceSubject replaces the link to oldURI with links to newURIs on every record
+// linked to the old subject. Passing several new subjects splits a broad heading,
+// passing none removes the old subject. The old subject record itself is kept.
+func (api *ArchivesSpaceAPI) ReplaceSubject(oldURI string, newURIs []string, opts *BatchEditOptions) (*BatchEditReport, error) {
+	if err := checkSubjectReplacement(oldURI, newURIs); err != nil {
+		return nil, fmt.Errorf("ReplaceSubject(%q) %w", oldURI, err)
+	}
+	subject, err := api.GetSubject(URIToID(oldURI))
+	if err != nil {
+		return nil, fmt.Errorf("ReplaceSubject(%q) %w", oldURI, err)
+	}
+	// The search narrows the candidates by title, the transform only touches exact ref matches
+	query := fmt.Sprintf("subjects:%q", subject.Title)
+	return api.ApplyToSearch(query, replaceSubjectRefs(oldURI, newURIs), subjectSearchOptions(opts))
+}
+
+// ReplaceSubjectInURIs is like ReplaceSubject but works on an explicit list of record URIs
+func (api *ArchivesSpaceAPI) ReplaceSubjectInURIs(oldURI string, newURIs []string, uris []string, opts *BatchEditOptions) (*BatchEditReport, error) {
+	if err := checkSubjectReplacement(oldURI, newURIs); err != nil {
+		return nil, fmt.Errorf("ReplaceSubjectInURIs(%q) %w", oldURI, err)
+	}
+	return api.ApplyToURIs(uris, replaceSubjectRefs(oldURI, newURIs), opts)
+}
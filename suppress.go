@@ -0,0 +1,116 @@
+//
+// Package cait is a collection of structures and functions
+// for interacting with ArchivesSpace's REST API
+//
+// @author R. S. Doiel, <rsdoiel@caltech.edu>
+//
+// Copyright (c) 2017, Caltech
+// All rights not granted herein are expressly reserved by Caltech.
+//
+// Redistribution and use in source and binary forms, with or without modification, are permitted provided that the following conditions are met:
+//
+// 1. Redistributions of source code must retain the above copyright notice, this list of conditions and the following disclaimer.
+//
+// 2. Redistributions in binary form must reproduce the above copyright notice, this list of conditions and the following disclaimer in the documentation and/or other materials provided with the distribution.
+//
+// 3. Neither the name of the copyright holder nor the names of its contributors may be used to endorse or promote products derived from this software without specific prior written permission.
+//
+// THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS" AND ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE ARE DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT HOLDER OR CONTRIBUTORS BE LIABLE FOR ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL DAMAGES (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR SERVICES; LOSS OF USE, DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER CAUSED AND ON ANY THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT LIABILITY, OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE OF THIS SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+//
+package cait
+
+import (
+	"fmt"
+	"strings"
+)
+
+// SetSuppressed suppresses (or, with suppressed false, unsuppresses) the record at uri.
+// Suppressed records are hidden from the public interface and from users without
+// the permission to view them. uri is an accession, resource, archival object or
+// digital object URI, so a list of URIs can be suppressed in a loop during review.
+func (api *ArchivesSpaceAPI) SetSuppressed(uri string, suppressed bool) (*ResponseMsg, error) {
+	if uri == "" {
+		return nil, fmt.Errorf("SetSuppressed() missing URI")
+	}
+	u := api.callURL(strings.TrimSuffix(uri, "/") + "/suppressed")
+	q := u.Query()
+	q.Set("suppressed", fmt.Sprintf("%t", suppressed))
+	u.RawQuery = q.Encode()
+	responseMsg, err := api.UpdateAPI(u.String(), nil)
+	if err != nil {
+		return nil, fmt.Errorf("SetSuppressed(%q, %t) %w", uri, suppressed, err)
+	}
+	return responseMsg, nil
+}
+
+// SuppressAccession suppresses an accession
+func (api *ArchivesSpaceAPI) SuppressAccession(accession *Accession) (*ResponseMsg, error) {
+	return api.suppressAccession(accession, true)
+}
+
+// UnsuppressAccession unsuppresses an accession
+func (api *ArchivesSpaceAPI) UnsuppressAccession(accession *Accession) (*ResponseMsg, error) {
+	return api.suppressAccession(accession, false)
+}
+
+func (api *ArchivesSpaceAPI) suppressAccession(accession *Accession, suppressed bool) (*ResponseMsg, error) {
+	responseMsg, err := api.SetSuppressed(accession.URI, suppressed)
+	if err == nil {
+		accession.Suppressed = suppressed
+	}
+	return responseMsg, err
+}
+
+// SuppressResource suppresses a resource
+func (api *ArchivesSpaceAPI) SuppressResource(obj *Resource) (*ResponseMsg, error) {
+	return api.suppressResource(obj, true)
+}
+
+// UnsuppressResource unsuppresses a resource
+func (api *ArchivesSpaceAPI) UnsuppressResource(obj *Resource) (*ResponseMsg, error) {
+	return api.suppressResource(obj, false)
+}
+
+func (api *ArchivesSpaceAPI) suppressResource(obj *Resource, suppressed bool) (*ResponseMsg, error) {
+	responseMsg, err := api.SetSuppressed(obj.URI, suppressed)
+	if err == nil {
+		obj.Suppressed = suppressed
+	}
+	return responseMsg, err
+}
+
+// SuppressArchivalObject suppresses an archival object
+func (api *ArchivesSpaceAPI) SuppressArchivalObject(obj *ArchivalObject) (*ResponseMsg, error) {
+	return api.suppressArchivalObject(obj, true)
+}
+
+// UnsuppressArchivalObject unsuppresses an archival object
+func (api *ArchivesSpaceAPI) UnsuppressArchivalObject(obj *ArchivalObject) (*ResponseMsg, error) {
+	return api.suppressArchivalObject(obj, false)
+}
+
+func (api *ArchivesSpaceAPI) suppressArchivalObject(obj *ArchivalObject, suppressed bool) (*ResponseMsg, error) {
+	responseMsg, err := api.SetSuppressed(obj.URI, suppressed)
+	if err == nil {
+		obj.Suppressed = suppressed
+	}
+	return responseMsg, err
+}
+
+// SuppressDigitalObject suppresses a digital object
+func (api *ArchivesSpaceAPI) SuppressDigitalObject(obj *DigitalObject) (*ResponseMsg, error) {
+	return api.suppressDigitalObject(obj, true)
+}
+
+// UnsuppressDigitalObject unsuppresses a digital object
+func (api *ArchivesSpaceAPI) UnsuppressDigitalObject(obj *DigitalObject) (*ResponseMsg, error) {
+	return api.suppressDigitalObject(obj, false)
+}
+
+func (api *ArchivesSpaceAPI) suppressDigitalObject(obj *DigitalObject, suppressed bool) (*ResponseMsg, error) {
+	responseMsg, err := api.SetSuppressed(obj.URI, suppressed)
+	if err == nil {
+		obj.Suppressed = suppressed
+	}
+	return responseMsg, err
+}
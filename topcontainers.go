@@ -0,0 +1,261 @@
+//
+// Package cait is a collection of structures and functions
+// for interacting with ArchivesSpace's REST API
+//
+// @author R. S. Doiel, <rsdoiel@caltech.edu>
+//
+// Copyright (c) 2017, Caltech
+// All rights not granted herein are expressly reserved by Caltech.
+//
+// Redistribution and use in source and binary forms, with or without modification, are permitted provided that the following conditions are met:
+//
+// 1. Redistributions of source code must retain the above copyright notice, this list of conditions and the following disclaimer.
+//
+// 2. Redistributions in binary form must reproduce the above copyright notice, this list of conditions and the following disclaimer in the documentation and/or other materials provided with the distribution.
+//
+// 3. Neither the name of the copyright holder nor the names of its contributors may be used to endorse or promote products derived from this software without specific prior written permission.
+//
+// THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS" AND ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE ARE DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT HOLDER OR CONTRIBUTORS BE LIABLE FOR ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL DAMAGES (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR SERVICES; LOSS OF USE, DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER CAUSED AND ON ANY THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT LIABILITY, OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE OF THIS SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+//
+package cait
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"time"
+)
+
+// GetTopContainer retrieves a top container record from a Repository
+func (api *ArchivesSpaceAPI) GetTopContainer(repoID, containerID int) (*TopContainer, error) {
+	u := api.callURL(fmt.Sprintf("/repositories/%d/top_containers/%d", repoID, containerID))
+
+	container := new(TopContainer)
+	err := api.GetAPI(u.String(), container)
+	if err != nil {
+		return nil, fmt.Errorf("GetTopContainer(%d, %d) %w", repoID, containerID, err)
+	}
+	container.ID = URIToID(container.URI)
+	return container, nil
+}
+
+// CreateTopContainer creates a top container in a Repository. On success the container's
+// URI, ID and lock version are updated from the response.
+func (api *ArchivesSpaceAPI) CreateTopContainer(repoID int, container *TopContainer) (*ResponseMsg, error) {
+	u := api.callURL(fmt.Sprintf("/repositories/%d/top_containers", repoID))
+	container.JSONModelType = "top_container"
+	container.LockVersion = "0"
+	responseMsg, err := api.CreateAPI(u.String(), container)
+	if err != nil {
+		return nil, fmt.Errorf("CreateTopContainer(%d) %w", repoID, err)
+	}
+	container.SetURI(responseMsg.URI)
+	container.LockVersion = responseMsg.LockVersion
+	return responseMsg, nil
+}
+
+// UpdateTopContainer updates an existing top container
+func (api *ArchivesSpaceAPI) UpdateTopContainer(container *TopContainer) (*ResponseMsg, error) {
+	u := api.callURL(container.URI)
+	return api.UpdateAPI(u.String(), container)
+}
+
+// DeleteTopContainer deletes a top container
+func (api *ArchivesSpaceAPI) DeleteTopContainer(container *TopContainer) (*ResponseMsg, error) {
+	u := api.callURL(container.URI)
+	return api.DeleteAPI(u.String(), container)
+}
+
+// ListTopContainers return a list of top container IDs from a Repository
+// Give modifiedSince to list only the records changed since then.
+func (api *ArchivesSpaceAPI) ListTopContainers(repoID int, modifiedSince ...time.Time) ([]int, error) {
+	u := api.callURL(fmt.Sprintf(`/repositories/%d/top_containers`, repoID))
+	q := u.Query()
+	q.Set("all_ids", "true")
+	setModifiedSince(q, modifiedSince)
+	u.RawQuery = q.Encode()
+	return api.ListAPI(u.String())
+}
+
+// GetTopContainerByBarcode retrieves a top container record from a Repository by its barcode
+func (api *ArchivesSpaceAPI) GetTopContainerByBarcode(repoID int, barcode string) (*TopContainer, error) {
+	u := api.callURL(fmt.Sprintf("/repositories/%d/top_containers/by_barcode/%s", repoID, url.PathEscape(barcode)))
+
+	container := new(TopContainer)
+	err := api.GetAPI(u.String(), container)
+	if err != nil {
+		return nil, fmt.Errorf("GetTopContainerByBarcode(%d, %q) %w", repoID, barcode, err)
+	}
+	container.ID = URIToID(container.URI)
+	return container, nil
+}
+
+// bulkUpdateTopContainers posts a bulk top container operation returning the IDs updated
+func (api *ArchivesSpaceAPI) bulkUpdateTopContainers(repoID int, operation string, data interface{}) ([]int, error) {
+	u := api.callURL(fmt.Sprintf("/repositories/%d/top_containers/bulk/%s", repoID, operation))
+	content, err := api.API("POST", u.String(), data)
+	if err != nil {
+		return nil, err
+	}
+	response := struct {
+		Updated []int       `json:"updated"`
+		Error   interface{} `json:"error,omitempty"`
+	}{}
+	if err := json.Unmarshal(content, &response); err != nil {
+		return nil, err
+	}
+	if response.Error != nil {
+		return nil, fmt.Errorf("%v", response.Error)
+	}
+	return response.Updated, nil
+}
+
+// UpdateTopContainerBarcodes sets the barcodes of many top containers in one request.
+// barcodes maps a top container URI to its new barcode. It returns the IDs updated.
+func (api *ArchivesSpaceAPI) UpdateTopContainerBarcodes(repoID int, barcodes map[string]string) ([]int, error) {
+	ids, err := api.bulkUpdateTopContainers(repoID, "barcodes", barcodes)
+	if err != nil {
+		return nil, fmt.Errorf("UpdateTopContainerBarcodes(%d) %w", repoID, err)
+	}
+	return ids, nil
+}
+
+// UpdateTopContainerLocations sets the current location of many top containers in one request.
+// locations maps a top container URI to a location URI. It returns the IDs updated.
+func (api *ArchivesSpaceAPI) UpdateTopContainerLocations(repoID int, locations map[string]string) ([]int, error) {
+	ids, err := api.bulkUpdateTopContainers(repoID, "locations", locations)
+	if err != nil {
+		return nil, fmt.Errorf("UpdateTopContainerLocations(%d) %w", repoID, err)
+	}
+	return ids, nil
+}
+
+// batchUpdateTopContainers sets param to value on the top containers ids in one request,
+// returning the number of records updated
+func (api *ArchivesSpaceAPI) batchUpdateTopContainers(repoID int, operation string, ids []int, param, value string) (int, error) {
+	if len(ids) == 0 {
+		return 0, fmt.Errorf("no top containers given")
+	}
+	u := api.callURL(fmt.Sprintf("/repositories/%d/top_containers/batch/%s", repoID, operation))
+	q := u.Query()
+	for _, id := range ids {
+		q.Add("ids[]", fmt.Sprintf("%d", id))
+	}
+	q.Set(param, value)
+	u.RawQuery = q.Encode()
+	content, err := api.API("POST", u.String(), nil)
+	if err != nil {
+		return 0, err
+	}
+	response := struct {
+		RecordsUpdated int         `json:"records_updated"`
+		Error          interface{} `json:"error,omitempty"`
+	}{}
+	if err := json.Unmarshal(content, &response); err != nil {
+		return 0, err
+	}
+	if response.Error != nil {
+		return 0, fmt.Errorf("%v", response.Error)
+	}
+	return response.RecordsUpdated, nil
+}
+
+// UpdateTopContainerILSHoldingIDs sets the ILS holding ID of the top containers ids, returning
+// the number updated
+func (api *ArchivesSpaceAPI) UpdateTopContainerILSHoldingIDs(repoID int, ids []int, ilsHoldingID string) (int, error) {
+	n, err := api.batchUpdateTopContainers(repoID, "ils_holding_id", ids, "ils_holding_id", ilsHoldingID)
+	if err != nil {
+		return n, fmt.Errorf("UpdateTopContainerILSHoldingIDs(%d, %q) %w", repoID, ilsHoldingID, err)
+	}
+	return n, nil
+}
+
+// UpdateTopContainerProfiles sets the container profile of the top containers ids, returning
+// the number updated
+func (api *ArchivesSpaceAPI) UpdateTopContainerProfiles(repoID int, ids []int, containerProfileURI string) (int, error) {
+	n, err := api.batchUpdateTopContainers(repoID, "container_profile", ids, "container_profile_uri", containerProfileURI)
+	if err != nil {
+		return n, fmt.Errorf("UpdateTopContainerProfiles(%d, %q) %w", repoID, containerProfileURI, err)
+	}
+	return n, nil
+}
+
+// MoveTopContainers sets the current location of the top containers ids to the one location,
+// e.g. when relocating a shelf, returning the number updated. Use UpdateTopContainerLocations
+// to give each container its own location.
+func (api *ArchivesSpaceAPI) MoveTopContainers(repoID int, ids []int, locationURI string) (int, error) {
+	n, err := api.batchUpdateTopContainers(repoID, "location", ids, "location_uri", locationURI)
+	if err != nil {
+		return n, fmt.Errorf("MoveTopContainers(%d, %q) %w", repoID, locationURI, err)
+	}
+	return n, nil
+}
+
+// TopContainerSearch holds the filters for SearchTopContainers, empty fields aren't used
+type TopContainerSearch struct {
+	// Query is a keyword query, e.g. an indicator or part of a barcode
+	Query string `json:"q,omitempty"`
+	// Resources and Accessions are the URIs of the collections the containers hold
+	Resources  []string `json:"collection_resource,omitempty"`
+	Accessions []string `json:"collection_accession,omitempty"`
+	// ContainerProfiles and Locations are container profile and location URIs
+	ContainerProfiles []string `json:"container_profile,omitempty"`
+	Locations         []string `json:"location,omitempty"`
+	Barcodes          []string `json:"barcodes,omitempty"`
+	// Exported and Empty, when set, match containers which have (or haven't) been
+	// exported to the ILS or have no linked records
+	Exported *bool `json:"exported,omitempty"`
+	Empty    *bool `json:"empty,omitempty"`
+}
+
+// SearchTopContainers returns the top containers in a Repository matching search
+func (api *ArchivesSpaceAPI) SearchTopContainers(repoID int, search *TopContainerSearch) ([]*TopContainer, error) {
+	if search == nil {
+		search = new(TopContainerSearch)
+	}
+	u := api.callURL(fmt.Sprintf("/repositories/%d/top_containers/search", repoID))
+	q := url.Values{}
+	if search.Query != "" {
+		q.Set("q", search.Query)
+	}
+	for param, values := range map[string][]string{
+		"collection_resource[]":  search.Resources,
+		"collection_accession[]": search.Accessions,
+		"container_profile[]":    search.ContainerProfiles,
+		"location[]":             search.Locations,
+		"barcodes[]":             search.Barcodes,
+	} {
+		for _, value := range values {
+			q.Add(param, value)
+		}
+	}
+	if search.Exported != nil {
+		q.Set("exported", fmt.Sprintf("%t", *search.Exported))
+	}
+	if search.Empty != nil {
+		q.Set("empty", fmt.Sprintf("%t", *search.Empty))
+	}
+	u.RawQuery = q.Encode()
+
+	// The search returns Solr's response, each document holds the record as JSON text
+	results := struct {
+		Response struct {
+			Docs []struct {
+				JSON string `json:"json"`
+			} `json:"docs"`
+		} `json:"response"`
+	}{}
+	if err := api.GetAPI(u.String(), &results); err != nil {
+		return nil, fmt.Errorf("SearchTopContainers(%d) %w", repoID, err)
+	}
+	containers := []*TopContainer{}
+	for _, doc := range results.Response.Docs {
+		container := new(TopContainer)
+		if err := json.Unmarshal([]byte(doc.JSON), container); err != nil {
+			return nil, fmt.Errorf("SearchTopContainers(%d) %w", repoID, err)
+		}
+		container.ID = URIToID(container.URI)
+		containers = append(containers, container)
+	}
+	return containers, nil
+}
@@ -0,0 +1,100 @@
+//
+// Package cait is a collection of structures and functions
+// for interacting with ArchivesSpace's REST API
+//
+// @author R. S. Doiel, <rsdoiel@caltech.edu>
+//
+// Copyright (c) 2017, Caltech
+// All rights not granted herein are expressly reserved by Caltech.
+//
+// Redistribution and use in source and binary forms, with or without modification, are permitted provided that the following conditions are met:
+//
+// 1. Redistributions of source code must retain the above copyright notice, this list of conditions and the following disclaimer.
+//
+// 2. Redistributions in binary form must reproduce the above copyright notice, this list of conditions and the following disclaimer in the documentation and/or other materials provided with the distribution.
+//
+// 3. Neither the name of the copyright holder nor the names of its contributors may be used to endorse or promote products derived from this software without specific prior written permission.
+//
+// THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS" AND ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE ARE DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT HOLDER OR CONTRIBUTORS BE LIABLE FOR ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL DAMAGES (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR SERVICES; LOSS OF USE, DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER CAUSED AND ON ANY THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT LIABILITY, OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE OF THIS SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+//
+package cait
+
+import (
+	"fmt"
+	"strings"
+)
+
+// TransferRecord moves the accession, resource or digital object at uri to the
+// Repository targetRepoID, along with its components and instances. It returns the
+// server's response, whose URI (when given) is the record's new URI.
+func (api *ArchivesSpaceAPI) TransferRecord(uri string, targetRepoID int) (*ResponseMsg, error) {
+	if uri == "" {
+		return nil, fmt.Errorf("TransferRecord() missing URI")
+	}
+	u := api.callURL(strings.TrimSuffix(uri, "/") + "/transfer")
+	q := u.Query()
+	q.Set("target_repo", fmt.Sprintf("/repositories/%d", targetRepoID))
+	u.RawQuery = q.Encode()
+	responseMsg, err := api.UpdateAPI(u.String(), nil)
+	if err != nil {
+		return nil, fmt.Errorf("TransferRecord(%q, %d) %w", uri, targetRepoID, err)
+	}
+	return responseMsg, nil
+}
+
+// transferredURI returns the record's URI after a transfer to targetRepoID
+func transferredURI(uri string, targetRepoID int, responseMsg *ResponseMsg) string {
+	if responseMsg != nil && responseMsg.URI != "" {
+		return responseMsg.URI
+	}
+	parts := strings.Split(strings.TrimPrefix(uri, "/"), "/")
+	if len(parts) < 2 || parts[0] != "repositories" {
+		return uri
+	}
+	parts[1] = fmt.Sprintf("%d", targetRepoID)
+	return "/" + strings.Join(parts, "/")
+}
+
+// TransferAccession moves an accession to the Repository targetRepoID, updating its URI
+func (api *ArchivesSpaceAPI) TransferAccession(accession *Accession, targetRepoID int) (*ResponseMsg, error) {
+	responseMsg, err := api.TransferRecord(accession.URI, targetRepoID)
+	if err != nil {
+		return nil, err
+	}
+	accession.SetURI(transferredURI(accession.URI, targetRepoID, responseMsg))
+	return responseMsg, nil
+}
+
+// TransferResource moves a resource and its archival objects to the Repository targetRepoID, updating its URI
+func (api *ArchivesSpaceAPI) TransferResource(obj *Resource, targetRepoID int) (*ResponseMsg, error) {
+	responseMsg, err := api.TransferRecord(obj.URI, targetRepoID)
+	if err != nil {
+		return nil, err
+	}
+	obj.SetURI(transferredURI(obj.URI, targetRepoID, responseMsg))
+	return responseMsg, nil
+}
+
+// TransferDigitalObject moves a digital object to the Repository targetRepoID, updating its URI
+func (api *ArchivesSpaceAPI) TransferDigitalObject(obj *DigitalObject, targetRepoID int) (*ResponseMsg, error) {
+	responseMsg, err := api.TransferRecord(obj.URI, targetRepoID)
+	if err != nil {
+		return nil, err
+	}
+	obj.SetURI(transferredURI(obj.URI, targetRepoID, responseMsg))
+	return responseMsg, nil
+}
+
+// TransferRepository moves all of the records in the Repository repoID to the
+// Repository targetRepoID, e.g. when consolidating two repositories
+func (api *ArchivesSpaceAPI) TransferRepository(repoID, targetRepoID int) (*ResponseMsg, error) {
+	u := api.callURL(fmt.Sprintf("/repositories/%d/transfer", repoID))
+	q := u.Query()
+	q.Set("target_repo", fmt.Sprintf("/repositories/%d", targetRepoID))
+	u.RawQuery = q.Encode()
+	responseMsg, err := api.UpdateAPI(u.String(), nil)
+	if err != nil {
+		return nil, fmt.Errorf("TransferRepository(%d, %d) %w", repoID, targetRepoID, err)
+	}
+	return responseMsg, nil
+}
@@ -0,0 +1,162 @@
+//
+// Package cait is a collection of structures and functions
+// for interacting with ArchivesSpace's REST API
+//
+// @author R. S. Doiel, <rsdoiel@caltech.edu>
+//
+// Copyright (c) 2017, Caltech
+// All rights not granted herein are expressly reserved by Caltech.
+//
+// Redistribution and use in source and binary forms, with or without modification, are permitted provided that the following conditions are met:
+//
+// 1. Redistributions of source code must retain the above copyright notice, this list of conditions and the following disclaimer.
+//
+// 2. Redistributions in binary form must reproduce the above copyright notice, this list of conditions and the following disclaimer in the documentation and/or other materials provided with the distribution.
+//
+// 3. Neither the name of the copyright holder nor the names of its contributors may be used to endorse or promote products derived from this software without specific prior written permission.
+//
+// THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS" AND ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE ARE DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT HOLDER OR CONTRIBUTORS BE LIABLE FOR ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL DAMAGES (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR SERVICES; LOSS OF USE, DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER CAUSED AND ON ANY THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT LIABILITY, OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE OF THIS SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+//
+package cait
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"strconv"
+)
+
+// GetResourceTree returns a Resource with its archival objects nested as children
+// in one request, so a finding aid's hierarchy can be traversed without fetching
+// each component. Very large trees may be slow or refused by newer versions of
+// ArchivesSpace.
+func (api *ArchivesSpaceAPI) GetResourceTree(repoID, resourceID int) (*ResourceTree, error) {
+	u := api.callURL(fmt.Sprintf("/repositories/%d/resources/%d/tree", repoID, resourceID))
+	tree := new(ResourceTree)
+	if err := api.GetAPI(u.String(), tree); err != nil {
+		return nil, fmt.Errorf("GetResourceTree(%d, %d) %w", repoID, resourceID, err)
+	}
+	return tree, nil
+}
+
+// Walk calls fn for tree and each node below it depth first in display order.
+// depth is zero for tree itself. Walking stops at the first error from fn which
+// is returned unless it is ErrStopIteration.
+func (tree *ResourceTree) Walk(fn func(node *ResourceTree, depth int) error) error {
+	if err := tree.walk(fn, 0); err != nil && errors.Is(err, ErrStopIteration) == false {
+		return err
+	}
+	return nil
+}
+
+// walk does the work for Walk
+func (tree *ResourceTree) walk(fn func(node *ResourceTree, depth int) error, depth int) error {
+	if err := fn(tree, depth); err != nil {
+		return err
+	}
+	for _, child := range tree.Children {
+		if err := child.walk(fn, depth+1); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// TreeNode is a node returned by the large tree endpoints (tree/root, tree/node and
+// tree/waypoint) of newer ArchivesSpace versions. A node's children are fetched in
+// pages called waypoints, Waypoints is how many there are and WaypointSize how many
+// children each holds. Children is only filled in by GetFullResourceTree.
+type TreeNode struct {
+	URI          string                   `json:"uri,omitempty"`
+	Title        string                   `json:"title,omitempty"`
+	ParsedTitle  string                   `json:"parsed_title,omitempty"`
+	Level        string                   `json:"level,omitempty"`
+	Position     int                      `json:"position,omitempty"`
+	ChildCount   int                      `json:"child_count"`
+	Waypoints    int                      `json:"waypoints"`
+	WaypointSize int                      `json:"waypoint_size"`
+	Containers   []map[string]interface{} `json:"containers,omitempty"`
+	// PrecomputedWaypoints holds waypoints sent along with a node, keyed by parent
+	// URI ("" for the root) then waypoint number, saving a request for each
+	PrecomputedWaypoints map[string]map[string][]*TreeNode `json:"precomputed_waypoints,omitempty"`
+
+	JSONModelType string `json:"jsonmodel_type,omitempty"`
+
+	Children []*TreeNode `json:"-"`
+}
+
+// GetResourceTreeRoot returns the root of a Resource's tree with its first waypoint
+func (api *ArchivesSpaceAPI) GetResourceTreeRoot(repoID, resourceID int) (*TreeNode, error) {
+	u := api.callURL(fmt.Sprintf("/repositories/%d/resources/%d/tree/root", repoID, resourceID))
+	node := new(TreeNode)
+	if err := api.GetAPI(u.String(), node); err != nil {
+		return nil, fmt.Errorf("GetResourceTreeRoot(%d, %d) %w", repoID, resourceID, err)
+	}
+	return node, nil
+}
+
+// GetResourceTreeNode returns the archival object at nodeURI as a node of its Resource's tree
+func (api *ArchivesSpaceAPI) GetResourceTreeNode(repoID, resourceID int, nodeURI string) (*TreeNode, error) {
+	u := api.callURL(fmt.Sprintf("/repositories/%d/resources/%d/tree/node", repoID, resourceID))
+	q := url.Values{}
+	q.Set("node_uri", nodeURI)
+	u.RawQuery = q.Encode()
+	node := new(TreeNode)
+	if err := api.GetAPI(u.String(), node); err != nil {
+		return nil, fmt.Errorf("GetResourceTreeNode(%d, %d, %q) %w", repoID, resourceID, nodeURI, err)
+	}
+	return node, nil
+}
+
+// GetResourceTreeWaypoint returns waypoint number offset of the children of the
+// node at parentURI, an empty parentURI means the children of the Resource itself
+func (api *ArchivesSpaceAPI) GetResourceTreeWaypoint(repoID, resourceID int, parentURI string, offset int) ([]*TreeNode, error) {
+	u := api.callURL(fmt.Sprintf("/repositories/%d/resources/%d/tree/waypoint", repoID, resourceID))
+	q := url.Values{}
+	q.Set("offset", strconv.Itoa(offset))
+	q.Set("parent_node", parentURI)
+	u.RawQuery = q.Encode()
+	nodes := []*TreeNode{}
+	if err := api.GetAPI(u.String(), &nodes); err != nil {
+		return nil, fmt.Errorf("GetResourceTreeWaypoint(%d, %d, %q, %d) %w", repoID, resourceID, parentURI, offset, err)
+	}
+	return nodes, nil
+}
+
+// GetFullResourceTree fetches a Resource's whole tree through the large tree
+// endpoints, stitching each node's waypoints together into its Children. It
+// suits resources too large for GetResourceTree, at the cost of a request for
+// each waypoint which wasn't precomputed.
+func (api *ArchivesSpaceAPI) GetFullResourceTree(repoID, resourceID int) (*TreeNode, error) {
+	root, err := api.GetResourceTreeRoot(repoID, resourceID)
+	if err != nil {
+		return nil, fmt.Errorf("GetFullResourceTree(%d, %d) %w", repoID, resourceID, err)
+	}
+	// The root's waypoints are keyed by "", each other node by its URI
+	type pending struct {
+		node      *TreeNode
+		parentKey string
+	}
+	queue := []pending{{node: root, parentKey: ""}}
+	for len(queue) > 0 {
+		item := queue[0]
+		queue = queue[1:]
+		node := item.node
+		for offset := 0; offset < node.Waypoints; offset++ {
+			children, ok := root.PrecomputedWaypoints[item.parentKey][strconv.Itoa(offset)]
+			if ok == false {
+				children, err = api.GetResourceTreeWaypoint(repoID, resourceID, item.parentKey, offset)
+				if err != nil {
+					return nil, fmt.Errorf("GetFullResourceTree(%d, %d) %w", repoID, resourceID, err)
+				}
+			}
+			node.Children = append(node.Children, children...)
+		}
+		for _, child := range node.Children {
+			if child.ChildCount > 0 {
+				queue = append(queue, pending{node: child, parentKey: child.URI})
+			}
+		}
+	}
+	return root, nil
+}
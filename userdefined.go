@@ -0,0 +1,188 @@
+//
+// Package cait is a collection of structures and functions
+// for interacting with ArchivesSpace's REST API
+//
+// @author R. S. Doiel, <rsdoiel@caltech.edu>
+//
+// Copyright (c) 2017, Caltech
+// All rights not granted herein are expressly reserved by Caltech.
+//
+// Redistribution and use in source and binary forms, with or without modification, are permitted provided that the following conditions are met:
+//
+// 1. Redistributions of source code must retain the above copyright notice, this list of conditions and the following disclaimer.
+//
+// 2. Redistributions in binary form must reproduce the above copyright notice, this list of conditions and the following disclaimer in the documentation and/or other materials provided with the distribution.
+//
+// 3. Neither the name of the copyright holder nor the names of its contributors may be used to endorse or promote products derived from this software without specific prior written permission.
+//
+// THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS" AND ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE ARE DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT HOLDER OR CONTRIBUTORS BE LIABLE FOR ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL DAMAGES (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR SERVICES; LOSS OF USE, DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER CAUSED AND ON ANY THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT LIABILITY, OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE OF THIS SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+//
+package cait
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// UserDefinedMapping maps an installation's friendly field names to the
+// user_defined field codes they are stored in, e.g.
+// {"appraisal_value": "real_1", "gift_agreement_no": "text_3"}
+type UserDefinedMapping map[string]string
+
+// userDefinedField returns the reflected struct field of ud for a user_defined code (e.g. real_1)
+func userDefinedField(ud *UserDefined, code string) (reflect.Value, error) {
+	v := reflect.ValueOf(ud).Elem()
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		name := strings.Split(t.Field(i).Tag.Get("json"), ",")[0]
+		if name == code && userDefinedCode(name) == true {
+			return v.Field(i), nil
+		}
+	}
+	return reflect.Value{}, fmt.Errorf("%q is not a user_defined field", code)
+}
+
+// userDefinedCode returns true for the numbered user_defined field codes (e.g. boolean_1, text_4)
+func userDefinedCode(code string) bool {
+	for _, prefix := range []string{"boolean_", "integer_", "real_", "string_", "text_", "date_", "enum_"} {
+		if strings.HasPrefix(code, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// LoadUserDefinedMapping reads a JSON object of friendly name to user_defined field code
+func LoadUserDefinedMapping(fname string) (UserDefinedMapping, error) {
+	src, err := ioutil.ReadFile(fname)
+	if err != nil {
+		return nil, fmt.Errorf("Can't read user defined mapping %s, %w", fname, err)
+	}
+	m := UserDefinedMapping{}
+	if err := json.Unmarshal(src, &m); err != nil {
+		return nil, fmt.Errorf("Can't decode user defined mapping %s, %w", fname, err)
+	}
+	if err := m.Validate(); err != nil {
+		return nil, fmt.Errorf("%s, %w", fname, err)
+	}
+	return m, nil
+}
+
+// Validate checks that each name maps to a real user_defined field and that
+// no field is claimed by more than one name
+func (m UserDefinedMapping) Validate() error {
+	ud := new(UserDefined)
+	seen := map[string]string{}
+	for _, name := range m.Names() {
+		code := m[name]
+		if _, err := userDefinedField(ud, code); err != nil {
+			return fmt.Errorf("%s, %w", name, err)
+		}
+		if other, ok := seen[code]; ok == true {
+			return fmt.Errorf("%s and %s both map to %s", other, name, code)
+		}
+		seen[code] = name
+	}
+	return nil
+}
+
+// Names returns the sorted friendly names in the mapping
+func (m UserDefinedMapping) Names() []string {
+	names := []string{}
+	for name := range m {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Get returns the value stored in ud for the friendly name
+func (m UserDefinedMapping) Get(ud *UserDefined, name string) (interface{}, error) {
+	code, ok := m[name]
+	if ok == false {
+		return nil, fmt.Errorf("%q is not a mapped user defined field", name)
+	}
+	if ud == nil {
+		return nil, nil
+	}
+	f, err := userDefinedField(ud, code)
+	if err != nil {
+		return nil, err
+	}
+	return f.Interface(), nil
+}
+
+// Set stores value in ud under the field mapped to the friendly name. Boolean fields
+// accept a bool or a string parsable as one, date fields accept a *Date and the
+// remaining fields accept strings or numbers.
+func (m UserDefinedMapping) Set(ud *UserDefined, name string, value interface{}) error {
+	code, ok := m[name]
+	if ok == false {
+		return fmt.Errorf("%q is not a mapped user defined field", name)
+	}
+	f, err := userDefinedField(ud, code)
+	if err != nil {
+		return err
+	}
+	switch f.Kind() {
+	case reflect.Bool:
+		switch b := value.(type) {
+		case bool:
+			f.SetBool(b)
+		case string:
+			val, err := strconv.ParseBool(b)
+			if err != nil {
+				return fmt.Errorf("%s (%s) expects a boolean, %w", name, code, err)
+			}
+			f.SetBool(val)
+		default:
+			return fmt.Errorf("%s (%s) expects a boolean, got %T", name, code, value)
+		}
+	case reflect.String:
+		switch s := value.(type) {
+		case string:
+			f.SetString(s)
+		case int, int64, float64, json.Number:
+			f.SetString(fmt.Sprintf("%v", s))
+		default:
+			return fmt.Errorf("%s (%s) expects a string, got %T", name, code, value)
+		}
+	case reflect.Ptr:
+		d, ok := value.(*Date)
+		if ok == false {
+			return fmt.Errorf("%s (%s) expects a *Date, got %T", name, code, value)
+		}
+		f.Set(reflect.ValueOf(d))
+	}
+	return nil
+}
+
+// ToMap returns the mapped user defined values of ud keyed by friendly name
+func (m UserDefinedMapping) ToMap(ud *UserDefined) map[string]interface{} {
+	out := map[string]interface{}{}
+	for _, name := range m.Names() {
+		if val, err := m.Get(ud, name); err == nil {
+			out[name] = val
+		}
+	}
+	return out
+}
+
+// GetUserDefined returns an accession's user defined value by friendly name
+func (accession *Accession) GetUserDefined(m UserDefinedMapping, name string) (interface{}, error) {
+	return m.Get(accession.UserDefined, name)
+}
+
+// SetUserDefined sets an accession's user defined value by friendly name
+func (accession *Accession) SetUserDefined(m UserDefinedMapping, name string, value interface{}) error {
+	if accession.UserDefined == nil {
+		accession.UserDefined = new(UserDefined)
+		accession.UserDefined.JSONModelType = "user_defined"
+	}
+	return m.Set(accession.UserDefined, name, value)
+}
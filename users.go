@@ -0,0 +1,137 @@
+//
+// Package cait is a collection of structures and functions
+// for interacting with ArchivesSpace's REST API
+//
+// @author R. S. Doiel, <rsdoiel@caltech.edu>
+//
+// Copyright (c) 2017, Caltech
+// All rights not granted herein are expressly reserved by Caltech.
+//
+// Redistribution and use in source and binary forms, with or without modification, are permitted provided that the following conditions are met:
+//
+// 1. Redistributions of source code must retain the above copyright notice, this list of conditions and the following disclaimer.
+//
+// 2. Redistributions in binary form must reproduce the above copyright notice, this list of conditions and the following disclaimer in the documentation and/or other materials provided with the distribution.
+//
+// 3. Neither the name of the copyright holder nor the names of its contributors may be used to endorse or promote products derived from this software without specific prior written permission.
+//
+// THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS" AND ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE ARE DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT HOLDER OR CONTRIBUTORS BE LIABLE FOR ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL DAMAGES (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR SERVICES; LOSS OF USE, DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER CAUSED AND ON ANY THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT LIABILITY, OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE OF THIS SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+//
+package cait
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+)
+
+// userURL returns the URL for p with the password query parameter ArchivesSpace
+// expects when creating a user or changing a password, it is omitted when empty
+func (api *ArchivesSpaceAPI) userURL(p, password string) *url.URL {
+	u := api.callURL(p)
+	if password != "" {
+		q := u.Query()
+		q.Set("password", password)
+		u.RawQuery = q.Encode()
+	}
+	return u
+}
+
+// CreateUser creates a user account with password. Set user.IsAdmin to create an
+// administrator, only an administrator's session may do so. On success the user's
+// URI, ID and lock version are updated from the response.
+func (api *ArchivesSpaceAPI) CreateUser(user *User, password string) (*ResponseMsg, error) {
+	if password == "" {
+		return nil, fmt.Errorf("CreateUser(%q) a password is required", user.Username)
+	}
+	u := api.userURL("/users", password)
+	user.JSONModelType = "user"
+	user.LockVersion = "0"
+	responseMsg, err := api.CreateAPI(u.String(), user)
+	if err != nil {
+		return nil, fmt.Errorf("CreateUser(%q) %w", user.Username, err)
+	}
+	user.SetURI(responseMsg.URI)
+	user.LockVersion = responseMsg.LockVersion
+	return responseMsg, nil
+}
+
+// GetUser retrieves a user account
+func (api *ArchivesSpaceAPI) GetUser(userID int) (*User, error) {
+	u := api.callURL(fmt.Sprintf("/users/%d", userID))
+	user := new(User)
+	if err := api.GetAPI(u.String(), user); err != nil {
+		return nil, fmt.Errorf("GetUser(%d) %w", userID, err)
+	}
+	user.ID = URIToID(user.URI)
+	return user, nil
+}
+
+// GetCurrentUser retrieves the account the client is logged in as along with its
+// effective permissions
+func (api *ArchivesSpaceAPI) GetCurrentUser() (*User, error) {
+	u := api.callURL("/users/current-user")
+	user := new(User)
+	if err := api.GetAPI(u.String(), user); err != nil {
+		return nil, fmt.Errorf("GetCurrentUser() %w", err)
+	}
+	user.ID = URIToID(user.URI)
+	return user, nil
+}
+
+// globalPermissions is the key of the permissions a user holds in every repository
+const globalPermissions = "_archivesspace"
+
+// HasPermission returns true if the user holds permission (e.g. "delete_archival_record")
+// in the Repository repoID, either there or across all repositories. Admins hold every permission.
+func (user *User) HasPermission(repoID int, permission string) bool {
+	if user.IsAdmin {
+		return true
+	}
+	for _, key := range []string{fmt.Sprintf("/repositories/%d", repoID), globalPermissions} {
+		if containsString(user.Permissions[key], permission) {
+			return true
+		}
+	}
+	return false
+}
+
+// RequirePermissions returns an error naming the permissions the current user lacks in
+// the Repository repoID, call it before starting work the user may not be allowed to finish
+func (api *ArchivesSpaceAPI) RequirePermissions(repoID int, permissions ...string) error {
+	user, err := api.GetCurrentUser()
+	if err != nil {
+		return fmt.Errorf("RequirePermissions(%d) %w", repoID, err)
+	}
+	missing := []string{}
+	for _, permission := range permissions {
+		if user.HasPermission(repoID, permission) == false {
+			missing = append(missing, permission)
+		}
+	}
+	if len(missing) > 0 {
+		return fmt.Errorf("RequirePermissions(%d) %s lacks %s", repoID, user.Username, strings.Join(missing, ", "))
+	}
+	return nil
+}
+
+// UpdateUser updates an existing user account, a non-empty password replaces the user's password
+func (api *ArchivesSpaceAPI) UpdateUser(user *User, password string) (*ResponseMsg, error) {
+	u := api.userURL(user.URI, password)
+	return api.UpdateAPI(u.String(), user)
+}
+
+// DeleteUser deletes a user account
+func (api *ArchivesSpaceAPI) DeleteUser(user *User) (*ResponseMsg, error) {
+	u := api.callURL(user.URI)
+	return api.DeleteAPI(u.String(), user)
+}
+
+// ListUsers return a list of user IDs
+func (api *ArchivesSpaceAPI) ListUsers() ([]int, error) {
+	u := api.callURL("/users")
+	q := u.Query()
+	q.Set("all_ids", "true")
+	u.RawQuery = q.Encode()
+	return api.ListAPI(u.String())
+}
@@ -0,0 +1,90 @@
+//
+// Package cait is a collection of structures and functions
+// for interacting with ArchivesSpace's REST API
+//
+// @author R. S. Doiel, <rsdoiel@caltech.edu>
+//
+// Copyright (c) 2017, Caltech
+// All rights not granted herein are expressly reserved by Caltech.
+//
+// Redistribution and use in source and binary forms, with or without modification, are permitted provided that the following conditions are met:
+//
+// 1. Redistributions of source code must retain the above copyright notice, this list of conditions and the following disclaimer.
+//
+// 2. Redistributions in binary form must reproduce the above copyright notice, this list of conditions and the following disclaimer in the documentation and/or other materials provided with the distribution.
+//
+// 3. Neither the name of the copyright holder nor the names of its contributors may be used to endorse or promote products derived from this software without specific prior written permission.
+//
+// THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS" AND ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE ARE DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT HOLDER OR CONTRIBUTORS BE LIABLE FOR ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL DAMAGES (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR SERVICES; LOSS OF USE, DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER CAUSED AND ON ANY THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT LIABILITY, OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE OF THIS SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+//
+package cait
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// ServerVersion is the ArchivesSpace version reported by /version
+type ServerVersion struct {
+	Major int `json:"major"`
+	Minor int `json:"minor"`
+	Patch int `json:"patch"`
+	// Raw is the text returned by /version, e.g. "ArchivesSpace (v2.0.1)"
+	Raw string `json:"raw"`
+}
+
+var versionNumber = regexp.MustCompile(`v?(\d+)\.(\d+)(?:\.(\d+))?`)
+
+// ParseServerVersion parses an ArchivesSpace version string, e.g. "ArchivesSpace (v2.0.1)" or "2.8.1"
+func ParseServerVersion(s string) (*ServerVersion, error) {
+	m := versionNumber.FindStringSubmatch(s)
+	if m == nil {
+		return nil, fmt.Errorf("can't find a version number in %q", s)
+	}
+	version := &ServerVersion{Raw: strings.TrimSpace(s)}
+	version.Major, _ = strconv.Atoi(m[1])
+	version.Minor, _ = strconv.Atoi(m[2])
+	if m[3] != "" {
+		version.Patch, _ = strconv.Atoi(m[3])
+	}
+	return version, nil
+}
+
+// Compare returns -1, 0 or 1 as version is older, the same as or newer than major.minor.patch
+func (version *ServerVersion) Compare(major, minor, patch int) int {
+	for _, d := range []int{version.Major - major, version.Minor - minor, version.Patch - patch} {
+		switch {
+		case d < 0:
+			return -1
+		case d > 0:
+			return 1
+		}
+	}
+	return 0
+}
+
+// AtLeast returns true if version is major.minor.patch or newer, e.g. AtLeast(2, 1, 0)
+func (version *ServerVersion) AtLeast(major, minor, patch int) bool {
+	return version.Compare(major, minor, patch) >= 0
+}
+
+// String returns the version as v<major>.<minor>.<patch>
+func (version *ServerVersion) String() string {
+	return fmt.Sprintf("v%d.%d.%d", version.Major, version.Minor, version.Patch)
+}
+
+// GetVersion returns the version of the ArchivesSpace server
+func (api *ArchivesSpaceAPI) GetVersion() (*ServerVersion, error) {
+	u := api.callURL("/version")
+	content, err := api.API("GET", u.String(), nil)
+	if err != nil {
+		return nil, fmt.Errorf("GetVersion() %w", err)
+	}
+	version, err := ParseServerVersion(string(content))
+	if err != nil {
+		return nil, fmt.Errorf("GetVersion() %w", err)
+	}
+	return version, nil
+}